@@ -0,0 +1,302 @@
+// Package alert forwards the error logs to a sink such as an ops chat on Telegram. Alerts are queued and sent in the
+// background so logging never waits on the network, and identical errors are aggregated and rate limited so an error
+// loop does not flood the sink.
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	sendTimeout   = 10 * time.Second
+	maxStackDepth = 20
+)
+
+// Alert is an error log line sent to the sink
+type Alert struct {
+	App     string            `json:"app"`
+	Level   string            `json:"level"`
+	Message string            `json:"message"`
+	Time    time.Time         `json:"time"`
+	Context map[string]string `json:"context,omitempty"` // the fields of the log line, such as the correlation id
+	Stack   string            `json:"stack,omitempty"`
+	// Count is the number of times the alert was logged again within Window after it was first sent
+	Count  int           `json:"count,omitempty"`
+	Window time.Duration `json:"window,omitempty"`
+	// Dropped is the number of alerts dropped by a full queue or the rate limit since the previous alert was sent
+	Dropped int64 `json:"dropped,omitempty"`
+}
+
+// Summary returns the repetitions of the alert, e.g. "×37 in last 5m", or an empty string for a first alert
+func (a Alert) Summary() string {
+	if a.Count == 0 {
+		return ""
+	}
+	window := a.Window.String()
+	if strings.HasSuffix(window, "m0s") {
+		window = strings.TrimSuffix(window, "0s")
+	}
+	if strings.HasSuffix(window, "h0m") {
+		window = strings.TrimSuffix(window, "0m")
+	}
+	return fmt.Sprintf("×%d in last %s", a.Count, window)
+}
+
+type Options struct {
+	App       string
+	QueueSize int
+	// Window is how long identical alerts are aggregated into a single summary after the first one is sent
+	Window time.Duration
+	// RateLimit is the number of alerts sent per minute at most
+	RateLimit int
+}
+
+func DefaultOptions() Options {
+	return Options{
+		App:       "expense-tracker-bot",
+		QueueSize: 100,
+		Window:    5 * time.Minute,
+		RateLimit: 20,
+	}
+}
+
+// group holds the repetitions of an alert since it was sent
+type group struct {
+	last  Alert
+	count int
+	start time.Time
+}
+
+// Alerter is a zerolog.LevelWriter that turns the error logs into alerts. Add it to the output of the logger with
+// zerolog.MultiLevelWriter, Start it and Close it on shutdown to send the pending summaries.
+type Alerter struct {
+	sink    Sink
+	options Options
+	now     func() time.Time
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan Alert
+	done   chan struct{}
+
+	dropped atomic.Int64
+
+	// only used by the goroutine started by Start
+	groups     map[string]*group
+	tokens     float64
+	lastRefill time.Time
+}
+
+func New(sink Sink, options Options) *Alerter {
+	now := time.Now()
+	return &Alerter{
+		sink:       sink,
+		options:    options,
+		now:        time.Now,
+		queue:      make(chan Alert, options.QueueSize),
+		done:       make(chan struct{}),
+		groups:     map[string]*group{},
+		tokens:     float64(options.RateLimit),
+		lastRefill: now,
+	}
+}
+
+// Start sends the queued alerts in the background until the alerter is closed
+func (a *Alerter) Start() {
+	go func() {
+		defer close(a.done)
+		ticker := time.NewTicker(min(a.options.Window, time.Minute))
+		defer ticker.Stop()
+		for {
+			select {
+			case alert, ok := <-a.queue:
+				if !ok {
+					a.flush(time.Time{})
+					return
+				}
+				a.handle(alert)
+			case <-ticker.C:
+				a.flush(a.now())
+			}
+		}
+	}()
+}
+
+// Close stops taking alerts and waits for the queued alerts and the pending summaries to be sent
+func (a *Alerter) Close(ctx context.Context) error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Write ignores the log lines without a level
+func (a *Alerter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// WriteLevel queues the error log lines without blocking, dropping them when the queue is full. Fatal and panic log
+// lines are sent right away as the program is about to exit.
+func (a *Alerter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level < zerolog.ErrorLevel || level == zerolog.NoLevel || level == zerolog.Disabled {
+		return len(p), nil
+	}
+	alert := a.parse(level, p)
+
+	if level >= zerolog.FatalLevel {
+		a.send(alert)
+		return len(p), nil
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return len(p), nil
+	}
+	select {
+	case a.queue <- alert:
+	default:
+		a.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// parse reads the message and the fields of a json log line, the line is copied as zerolog reuses its buffer
+func (a *Alerter) parse(level zerolog.Level, p []byte) Alert {
+	alert := Alert{
+		App:   a.options.App,
+		Level: level.String(),
+		Time:  a.now(),
+		Stack: stack(),
+	}
+	var fields map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(p))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		alert.Message = strings.TrimSpace(string(p))
+		return alert
+	}
+	for key, value := range fields {
+		switch key {
+		case zerolog.LevelFieldName, zerolog.TimestampFieldName:
+		case zerolog.MessageFieldName:
+			alert.Message = fmt.Sprint(value)
+		default:
+			if alert.Context == nil {
+				alert.Context = map[string]string{}
+			}
+			alert.Context[key] = fmt.Sprint(value)
+		}
+	}
+	return alert
+}
+
+// handle sends the first of identical alerts and counts the rest until the window is over
+func (a *Alerter) handle(alert Alert) {
+	now := a.now()
+	key := fingerprint(alert)
+	if g, ok := a.groups[key]; ok {
+		if now.Sub(g.start) < a.options.Window {
+			g.count++
+			g.last = alert
+			return
+		}
+		a.summarise(g)
+	}
+	a.groups[key] = &group{last: alert, start: now}
+	a.sendLimited(alert, now)
+}
+
+// flush sends the summaries of the groups whose window is over, or of every group for a zero time
+func (a *Alerter) flush(now time.Time) {
+	for key, g := range a.groups {
+		if !now.IsZero() && now.Sub(g.start) < a.options.Window {
+			continue
+		}
+		a.summarise(g)
+		delete(a.groups, key)
+	}
+}
+
+func (a *Alerter) summarise(g *group) {
+	if g.count == 0 {
+		return
+	}
+	summary := g.last
+	summary.Count = g.count
+	summary.Window = a.options.Window
+	a.sendLimited(summary, a.now())
+}
+
+// sendLimited sends the alert unless more than RateLimit alerts were sent in the last minute
+func (a *Alerter) sendLimited(alert Alert, now time.Time) {
+	rate := float64(a.options.RateLimit)
+	a.tokens = min(rate, a.tokens+now.Sub(a.lastRefill).Minutes()*rate)
+	a.lastRefill = now
+	if a.tokens < 1 {
+		a.dropped.Add(1)
+		return
+	}
+	a.tokens--
+	a.send(alert)
+}
+
+func (a *Alerter) send(alert Alert) {
+	alert.Dropped = a.dropped.Swap(0)
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	err := a.sink.Send(ctx, alert)
+	if err != nil {
+		// logged below the error level, so a failing sink does not alert itself
+		log.Warn().Msgf("Error sending alert: %v", err)
+	}
+}
+
+var digits = regexp.MustCompile(`\d+`)
+
+// fingerprint identifies identical alerts, ignoring the ids and amounts in their messages
+func fingerprint(alert Alert) string {
+	return alert.Level + " " + digits.ReplaceAllString(alert.Message, "#")
+}
+
+// stack returns the functions that logged the alert, leaving out the frames of zerolog and the alerter
+func stack() string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var b strings.Builder
+	depth := 0
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/rs/zerolog") &&
+			!strings.HasPrefix(frame.Function, "github.com/aattwwss/telegram-expense-bot/alert.(*Alerter)") &&
+			depth < maxStackDepth {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+			depth++
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
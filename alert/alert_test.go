@@ -0,0 +1,162 @@
+package alert
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type recordingSink struct {
+	mu     sync.Mutex
+	alerts []Alert
+}
+
+func (s *recordingSink) Send(ctx context.Context, alert Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alerts = append(s.alerts, alert)
+	return nil
+}
+
+func newTestAlerter(sink Sink, now *time.Time) *Alerter {
+	options := DefaultOptions()
+	options.QueueSize = 2
+	a := New(sink, options)
+	a.now = func() time.Time { return *now }
+	a.lastRefill = *now
+	return a
+}
+
+func TestAlerter_AggregatesIdenticalAlerts(t *testing.T) {
+	sink := &recordingSink{}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := newTestAlerter(sink, &now)
+
+	for i := 0; i < 38; i++ {
+		a.handle(Alert{Level: "error", Message: "GetById error: id=" + strings.Repeat("1", i+1)})
+		now = now.Add(time.Second)
+	}
+	a.handle(Alert{Level: "error", Message: "another error"})
+	if len(sink.alerts) != 2 {
+		t.Fatalf("expected the first of the identical alerts and the other alert, got %d", len(sink.alerts))
+	}
+
+	a.flush(now)
+	if len(sink.alerts) != 2 {
+		t.Fatalf("expected no summary within the window, got %d alerts", len(sink.alerts))
+	}
+
+	now = now.Add(5 * time.Minute)
+	a.flush(now)
+	if len(sink.alerts) != 3 {
+		t.Fatalf("expected a summary after the window, got %d alerts", len(sink.alerts))
+	}
+	if got := sink.alerts[2].Summary(); got != "×37 in last 5m" {
+		t.Errorf("expected summary ×37 in last 5m, got %q", got)
+	}
+	if len(a.groups) != 0 {
+		t.Errorf("expected the groups to be forgotten, got %d", len(a.groups))
+	}
+}
+
+func TestAlerter_RateLimit(t *testing.T) {
+	sink := &recordingSink{}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := newTestAlerter(sink, &now)
+
+	for i := 0; i < 25; i++ {
+		a.handle(Alert{Level: "error", Message: "error " + string(rune('a'+i))})
+	}
+	if len(sink.alerts) != 20 {
+		t.Fatalf("expected 20 alerts within the rate limit, got %d", len(sink.alerts))
+	}
+
+	now = now.Add(time.Minute)
+	a.handle(Alert{Level: "error", Message: "later error"})
+	if len(sink.alerts) != 21 {
+		t.Fatalf("expected an alert after a minute, got %d", len(sink.alerts))
+	}
+	if got := sink.alerts[20].Dropped; got != 5 {
+		t.Errorf("expected 5 dropped alerts to be reported, got %d", got)
+	}
+}
+
+func TestAlerter_WriteLevel(t *testing.T) {
+	sink := &recordingSink{}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := newTestAlerter(sink, &now)
+	logger := zerolog.New(a).With().Str("correlation_id", "abc").Logger()
+
+	logger.Info().Msg("ignored")
+	logger.Error().Int64("user_id", 1234567890).Msg(`bad "quote" <b>`)
+
+	if len(a.queue) != 1 {
+		t.Fatalf("expected only the error to be queued, got %d", len(a.queue))
+	}
+	alert := <-a.queue
+	if alert.Message != `bad "quote" <b>` || alert.Level != "error" {
+		t.Errorf("unexpected alert %+v", alert)
+	}
+	if alert.Context["correlation_id"] != "abc" || alert.Context["user_id"] != "1234567890" {
+		t.Errorf("expected the fields of the log line, got %v", alert.Context)
+	}
+	if !strings.Contains(alert.Stack, "TestAlerter_WriteLevel") || strings.Contains(alert.Stack, "zerolog") {
+		t.Errorf("expected the stack of the caller, got %s", alert.Stack)
+	}
+}
+
+func TestAlerter_DropsWhenQueueFull(t *testing.T) {
+	sink := &recordingSink{}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := newTestAlerter(sink, &now)
+	logger := zerolog.New(a)
+
+	for i := 0; i < 5; i++ {
+		logger.Error().Msg("error")
+	}
+
+	if len(a.queue) != 2 || a.dropped.Load() != 3 {
+		t.Errorf("expected 2 queued and 3 dropped, got %d and %d", len(a.queue), a.dropped.Load())
+	}
+}
+
+func TestAlerter_CloseSendsPending(t *testing.T) {
+	sink := &recordingSink{}
+	a := New(sink, DefaultOptions())
+	a.Start()
+	logger := zerolog.New(a)
+
+	logger.Error().Msg("error 1")
+	logger.Error().Msg("error 2")
+	logger.Error().Msg("error 3")
+
+	err := a.Close(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.alerts) != 2 || sink.alerts[1].Count != 2 {
+		t.Errorf("expected the first alert and a summary of 2, got %+v", sink.alerts)
+	}
+	logger.Error().Msg("after close")
+}
+
+func TestAlert_Summary(t *testing.T) {
+	tests := []struct {
+		alert Alert
+		want  string
+	}{
+		{Alert{}, ""},
+		{Alert{Count: 37, Window: 5 * time.Minute}, "×37 in last 5m"},
+		{Alert{Count: 2, Window: time.Hour}, "×2 in last 1h"},
+		{Alert{Count: 3, Window: 90 * time.Second}, "×3 in last 1m30s"},
+	}
+	for _, tt := range tests {
+		if got := tt.alert.Summary(); got != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, got)
+		}
+	}
+}
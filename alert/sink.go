@@ -0,0 +1,150 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	telegramApiEndpoint = "https://api.telegram.org/bot%s/sendMessage"
+	// telegram allows 4096 characters in a message, the stack is cut to leave room for the rest
+	maxStackLength = 2500
+)
+
+// Sink delivers the alerts. It is called by the goroutine of the alerter, and by the logging goroutine for fatal logs.
+type Sink interface {
+	Send(ctx context.Context, alert Alert) error
+}
+
+// TelegramSink sends the alerts as html messages to a chat
+type TelegramSink struct {
+	url    string
+	chatId string
+	client *http.Client
+}
+
+func NewTelegramSink(token, chatId string) TelegramSink {
+	return TelegramSink{
+		url:    fmt.Sprintf(telegramApiEndpoint, token),
+		chatId: chatId,
+		client: &http.Client{Timeout: sendTimeout},
+	}
+}
+
+func (s TelegramSink) Send(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(map[string]string{
+		"chat_id":    s.chatId,
+		"text":       FormatHtml(alert),
+		"parse_mode": "HTML",
+	})
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.client, s.url, body)
+}
+
+// FormatHtml formats the alert as a telegram html message, escaping the message and the fields
+func FormatHtml(alert Alert) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<b>%s</b>", html.EscapeString(alert.App))
+	if summary := alert.Summary(); summary != "" {
+		fmt.Fprintf(&b, " %s", html.EscapeString(summary))
+	}
+	fmt.Fprintf(&b, "<code>\n\nlevel: %s\ntime : %s\nmsg  : %s</code>", html.EscapeString(alert.Level),
+		alert.Time.Format("2006-01-02 15:04:05.000"), html.EscapeString(alert.Message))
+	if alert.Dropped > 0 {
+		fmt.Fprintf(&b, "\n\n<i>%d alerts dropped since the previous alert</i>", alert.Dropped)
+	}
+	if len(alert.Context) > 0 {
+		keys := make([]string, 0, len(alert.Context))
+		for key := range alert.Context {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		b.WriteString("\n\n<code>")
+		for _, key := range keys {
+			fmt.Fprintf(&b, "%s: %s\n", html.EscapeString(key), html.EscapeString(alert.Context[key]))
+		}
+		b.WriteString("</code>")
+	}
+	if alert.Stack != "" {
+		stack := alert.Stack
+		if len(stack) > maxStackLength {
+			stack = stack[:maxStackLength] + "\n..."
+		}
+		fmt.Fprintf(&b, "\n\n<pre>%s</pre>", html.EscapeString(stack))
+	}
+	return b.String()
+}
+
+// WebhookSink posts the alerts as json to a url
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string) WebhookSink {
+	return WebhookSink{url: url, client: &http.Client{Timeout: sendTimeout}}
+}
+
+func (s WebhookSink) Send(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.client, s.url, body)
+}
+
+// WriterSink writes the alerts as json lines, such as to a local file
+type WriterSink struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterSink(w io.Writer) WriterSink {
+	return WriterSink{mu: &sync.Mutex{}, w: w}
+}
+
+func (s WriterSink) Send(ctx context.Context, alert Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return json.NewEncoder(s.w).Encode(alert)
+}
+
+func post(ctx context.Context, client *http.Client, endpoint string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		// the url of the telegram sink holds the bot token
+		return fmt.Errorf("posting alert: %w", redactUrl(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("posting alert: status %d: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// redactUrl drops the url from the errors of the http client
+func redactUrl(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
@@ -0,0 +1,88 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTelegramSink_Escaping(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("expected a json body, got %s: %v", body, err)
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+	sink := TelegramSink{url: server.URL, chatId: "-100", client: server.Client()}
+
+	err := sink.Send(context.Background(), Alert{
+		App:     "bot",
+		Level:   "error",
+		Message: `parse "1 < 2" & fail`,
+		Context: map[string]string{"handler": "<command:stats>"},
+		Count:   37,
+		Window:  5 * time.Minute,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["chat_id"] != "-100" || got["parse_mode"] != "HTML" {
+		t.Errorf("unexpected request %v", got)
+	}
+	for _, want := range []string{`parse &#34;1 &lt; 2&#34; &amp; fail`, "handler: &lt;command:stats&gt;", "<b>bot</b> ×37 in last 5m"} {
+		if !strings.Contains(got["text"], want) {
+			t.Errorf("expected %q in %s", want, got["text"])
+		}
+	}
+}
+
+func TestTelegramSink_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"ok":false}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+	sink := TelegramSink{url: server.URL, chatId: "-100", client: server.Client()}
+
+	err := sink.Send(context.Background(), Alert{Message: "error"})
+
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("expected the status in the error, got %v", err)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	err := NewWebhookSink(server.URL).Send(context.Background(), Alert{Message: `a "b"`, Count: 2})
+
+	if err != nil || got.Message != `a "b"` || got.Count != 2 {
+		t.Errorf("unexpected alert %+v: %v", got, err)
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink(&buf)
+
+	sink.Send(context.Background(), Alert{Message: "one"})
+	sink.Send(context.Background(), Alert{Message: "two"})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"message":"two"`) {
+		t.Errorf("expected a json line per alert, got %q", buf.String())
+	}
+}
@@ -0,0 +1,66 @@
+// Package audit carries who made a change and where it came from in the context, so the changes to the transactions
+// are recorded with their origin down in the database
+package audit
+
+import (
+	"context"
+	"strings"
+)
+
+// Source is where a change came from
+type Source string
+
+const (
+	SourceBot       Source = "bot"
+	SourceApi       Source = "api"
+	SourceApp       Source = "app"
+	SourceImport    Source = "import"
+	SourceRecurring Source = "recurring"
+	SourceJob       Source = "job"
+)
+
+// ActorUser is the actor of the changes made by the user, a system job is the actor job:<name>
+const ActorUser = "user"
+
+const jobActorPrefix = "job:"
+
+// Origin is who made a change and where it came from
+type Origin struct {
+	Actor  string
+	Source Source
+}
+
+// IsJob returns whether the change was made by a system job rather than the user
+func (o Origin) IsJob() bool {
+	return strings.HasPrefix(o.Actor, jobActorPrefix)
+}
+
+// JobName returns the name of the system job that made the change, or an empty string when the user made it
+func (o Origin) JobName() string {
+	name, _ := strings.CutPrefix(o.Actor, jobActorPrefix)
+	if name == o.Actor {
+		return ""
+	}
+	return name
+}
+
+type originKey struct{}
+
+// WithSource returns a context whose changes are made by the user from the source
+func WithSource(ctx context.Context, source Source) context.Context {
+	return context.WithValue(ctx, originKey{}, Origin{Actor: ActorUser, Source: source})
+}
+
+// WithJob returns a context whose changes are made by the system job
+func WithJob(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, originKey{}, Origin{Actor: jobActorPrefix + name, Source: SourceJob})
+}
+
+// FromContext returns the origin of the changes made with the context, which is the user on the bot unless set
+// otherwise
+func FromContext(ctx context.Context) Origin {
+	if origin, ok := ctx.Value(originKey{}).(Origin); ok {
+		return origin
+	}
+	return Origin{Actor: ActorUser, Source: SourceBot}
+}
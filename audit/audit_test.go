@@ -0,0 +1,33 @@
+package audit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		want    Origin
+		wantJob string
+	}{
+		{name: "defaults to the user on the bot", ctx: context.Background(), want: Origin{Actor: ActorUser, Source: SourceBot}},
+		{name: "user on the api", ctx: WithSource(context.Background(), SourceApi), want: Origin{Actor: ActorUser, Source: SourceApi}},
+		{name: "system job", ctx: WithJob(context.Background(), "trash"), want: Origin{Actor: "job:trash", Source: SourceJob}, wantJob: "trash"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromContext(tt.ctx)
+			if got != tt.want {
+				t.Errorf("FromContext() = %+v, want %+v", got, tt.want)
+			}
+			if got.IsJob() != (tt.wantJob != "") {
+				t.Errorf("IsJob() = %v, want %v", got.IsJob(), tt.wantJob != "")
+			}
+			if got.JobName() != tt.wantJob {
+				t.Errorf("JobName() = %q, want %q", got.JobName(), tt.wantJob)
+			}
+		})
+	}
+}
@@ -1,9 +1,28 @@
 package config
 
+import "time"
+
 type EnvConfig struct {
 	TelegramApiToken string `env:"TELEGRAM_API_TOKEN"`
 
-	NumRoutines int `env:"NUM_ROUTINES"`
+	NumRoutines     int           `env:"NUM_ROUTINES"`
+	ShardBacklog    int           `env:"SHARD_BACKLOG" envDefault:"100"`
+	UpdateTimeout   time.Duration `env:"UPDATE_TIMEOUT" envDefault:"30s"`
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"20s"`
+
+	// MessageContextTtl is how long the keyboards of the bot keep working, PendingEntryTtl is how long an amount waits
+	// for its category before DefaultCategoryId is applied, when there is one
+	MessageContextTtl time.Duration `env:"MESSAGE_CONTEXT_TTL" envDefault:"24h"`
+	PendingEntryTtl   time.Duration `env:"PENDING_ENTRY_TTL" envDefault:"15m"`
+	DefaultCategoryId int           `env:"DEFAULT_CATEGORY_ID"`
+
+	// CallbackSecret signs the data of the keyboard buttons, derived from the bot token when empty
+	CallbackSecret     string `env:"CALLBACK_SECRET"`
+	CallbackAcceptJson bool   `env:"CALLBACK_ACCEPT_JSON"`
+
+	// UserRateLimit is the number of messages a minute handled for each user, with bursts of up to UserRateBurst
+	UserRateLimit int `env:"USER_RATE_LIMIT" envDefault:"30"`
+	UserRateBurst int `env:"USER_RATE_BURST" envDefault:"10"`
 
 	AppHost string `env:"APP_HOST"`
 	AppPort string `env:"APP_PORT"`
@@ -18,6 +37,20 @@ type EnvConfig struct {
 	WebhookHost    string `env:"WEBHOOK_HOST"`
 	WebhookEnabled bool   `env:"WEBHOOK_ENABLED"`
 
+	ApiEnabled bool `env:"API_ENABLED"`
+
+	WebAppUrl string `env:"WEBAPP_URL"`
+
+	MetricsEnabled bool `env:"METRICS_ENABLED"`
+
+	LogLevel  string `env:"LOG_LEVEL" envDefault:"info"`
+	LogRedact bool   `env:"LOG_REDACT"`
+
 	LogTelegramToken  string `env:"LOG_TELEGRAM_TOKEN"`
 	LogTelegramChatId string `env:"LOG_TELEGRAM_CHAT_ID"`
+
+	AlertWebhookUrl string        `env:"ALERT_WEBHOOK_URL"`
+	AlertFile       string        `env:"ALERT_FILE"`
+	AlertWindow     time.Duration `env:"ALERT_WINDOW" envDefault:"5m"`
+	AlertRateLimit  int           `env:"ALERT_RATE_LIMIT" envDefault:"20"`
 }
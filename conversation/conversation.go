@@ -0,0 +1,383 @@
+// Package conversation runs the multi-step flows of the bot, such as picking the category of an amount. A flow is a
+// state machine over typed data: each state handles the buttons tapped and the text replied while the flow is in it,
+// and returns the transition to the next state. Conversations are stored between updates, so a flow survives restarts,
+// and expire after the timeout of their flow unless they are cancelled first.
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/logging"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// expireTimeout bounds the time taken by the timeout of a single conversation
+const expireTimeout = 10 * time.Second
+
+type State string
+
+// Transition is returned by a step to move the conversation to another state or end it
+type Transition struct {
+	next State
+	end  bool
+}
+
+// Stay keeps the conversation in its state, e.g. to ask again for a text that could not be read
+func Stay() Transition {
+	return Transition{}
+}
+
+func Goto(state State) Transition {
+	return Transition{next: state}
+}
+
+// End deletes the conversation once the flow is done
+func End() Transition {
+	return Transition{end: true}
+}
+
+// Conversation is a flow in progress with a user in a chat
+type Conversation[T any] struct {
+	Id        int
+	ChatId    int64
+	UserId    int64
+	State     State
+	Data      T
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Step handles a state of a flow. A state with OnText takes the next text the user sends in the chat instead of it
+// being handled as a command or an amount. The changes a step makes to the data are saved unless it returns an error.
+type Step[T any] struct {
+	OnText     func(ctx context.Context, bot *sender.Sender, c *Conversation[T], msg *tgbotapi.Message) (Transition, error)
+	OnCallback func(ctx context.Context, bot *sender.Sender, c *Conversation[T], callbackQuery *tgbotapi.CallbackQuery) (Transition, error)
+}
+
+type Flow[T any] struct {
+	// Name identifies the flow of the stored conversations, it must not change once conversations are stored
+	Name    string
+	Initial State
+	Steps   map[State]Step[T]
+	// Timeout is how long the conversation waits for the user, it is restarted on every step
+	Timeout time.Duration
+	// OnTimeout and OnCancel are optional, they are called after the conversation is deleted
+	OnTimeout func(ctx context.Context, bot *sender.Sender, c Conversation[T])
+	OnCancel  func(ctx context.Context, bot *sender.Sender, c Conversation[T])
+}
+
+// Store persists the conversations
+type Store interface {
+	Insert(ctx context.Context, c entity.Conversation) (int, error)
+	GetById(ctx context.Context, id int) (*entity.Conversation, error)
+	FindLatestAwaitingText(ctx context.Context, chatId int64, userId int64, now time.Time) (*entity.Conversation, error)
+	Extend(ctx context.Context, id int, now time.Time, expiresAt time.Time) error
+	Update(ctx context.Context, c entity.Conversation) error
+	DeleteById(ctx context.Context, id int) error
+	TakeByChatIdAndUserId(ctx context.Context, chatId int64, userId int64) ([]entity.Conversation, error)
+	TakeExpired(ctx context.Context, now time.Time) ([]entity.Conversation, error)
+}
+
+// Manager starts the registered flows and routes the updates to their conversations
+type Manager struct {
+	store Store
+	flows map[string]runner
+	now   func() time.Time
+}
+
+func NewManager(store Store) *Manager {
+	return &Manager{store: store, flows: map[string]runner{}, now: time.Now}
+}
+
+// Register adds a flow to the manager, before any update is handled
+func Register[T any](m *Manager, flow Flow[T]) {
+	if _, ok := flow.Steps[flow.Initial]; !ok {
+		panic(fmt.Sprintf("conversation: flow %s has no step for its initial state %s", flow.Name, flow.Initial))
+	}
+	if _, ok := m.flows[flow.Name]; ok {
+		panic(fmt.Sprintf("conversation: flow %s is registered twice", flow.Name))
+	}
+	m.flows[flow.Name] = flowRunner[T]{flow: flow}
+}
+
+// Start stores a new conversation of the flow in its initial state and returns its id, which the buttons of the flow
+// carry as their message context id
+func Start[T any](ctx context.Context, m *Manager, name string, chatId int64, userId int64, data T) (int, error) {
+	r, ok := m.flows[name].(flowRunner[T])
+	if !ok {
+		return 0, fmt.Errorf("conversation: no flow %s with data %T", name, data)
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return 0, err
+	}
+	now := m.now()
+	return m.store.Insert(ctx, entity.Conversation{
+		ChatId:       chatId,
+		UserId:       userId,
+		Flow:         name,
+		State:        string(r.flow.Initial),
+		Data:         encoded,
+		AwaitingText: r.awaitsText(r.flow.Initial),
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(r.flow.Timeout),
+	})
+}
+
+// HandleCallback runs the step of the conversation for a button tapped on one of its menus. It returns an
+// entity.ErrNotFound when the conversation is over, has expired or belongs to someone else.
+func (m *Manager) HandleCallback(ctx context.Context, bot *sender.Sender, id int, callbackQuery *tgbotapi.CallbackQuery) error {
+	e, r, err := m.get(ctx, id, callbackQuery.Message.Chat.ID, callbackQuery.From.ID)
+	if err != nil {
+		return err
+	}
+	err = m.extend(ctx, *e, r)
+	if err != nil {
+		return err
+	}
+	return m.run(ctx, e, r, func() (Transition, error) {
+		return r.onCallback(ctx, bot, e, callbackQuery)
+	})
+}
+
+// HandleText passes the text to the latest conversation of the user in the chat that waits for one, and returns false
+// when there is none so the text is handled as usual
+func (m *Manager) HandleText(ctx context.Context, bot *sender.Sender, msg *tgbotapi.Message) (bool, error) {
+	e, err := m.store.FindLatestAwaitingText(ctx, msg.Chat.ID, msg.From.ID, m.now())
+	if err != nil || e == nil {
+		return false, err
+	}
+	r, ok := m.flows[e.Flow]
+	if !ok {
+		return false, fmt.Errorf("conversation %d of unknown flow %s", e.Id, e.Flow)
+	}
+	err = m.extend(ctx, *e, r)
+	if errors.Is(err, entity.ErrNotFound) {
+		// expired or cancelled in the meantime
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, m.run(ctx, e, r, func() (Transition, error) {
+		return r.onText(ctx, bot, e, msg)
+	})
+}
+
+// Cancel ends the conversations of the user in the chat and returns how many there were
+func (m *Manager) Cancel(ctx context.Context, bot *sender.Sender, chatId int64, userId int64) (int, error) {
+	taken, err := m.store.TakeByChatIdAndUserId(ctx, chatId, userId)
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range taken {
+		if r, ok := m.flows[e.Flow]; ok {
+			r.onCancel(ctx, bot, e)
+		}
+	}
+	return len(taken), nil
+}
+
+// CancelById ends the conversation of a cancel button. It returns an entity.ErrNotFound when the conversation is
+// over, has expired or belongs to someone else.
+func (m *Manager) CancelById(ctx context.Context, bot *sender.Sender, id int, callbackQuery *tgbotapi.CallbackQuery) error {
+	e, r, err := m.get(ctx, id, callbackQuery.Message.Chat.ID, callbackQuery.From.ID)
+	if err != nil {
+		return err
+	}
+	err = m.store.DeleteById(ctx, e.Id)
+	if err != nil {
+		return err
+	}
+	r.onCancel(ctx, bot, *e)
+	return nil
+}
+
+// Expire ends the conversations that have expired at now and returns how many there were. A conversation is taken
+// before its timeout runs, so it is never handled twice even if the user replies at the same time.
+func (m *Manager) Expire(ctx context.Context, bot *sender.Sender, now time.Time) (int, error) {
+	taken, err := m.store.TakeExpired(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range taken {
+		r, ok := m.flows[e.Flow]
+		if !ok {
+			log.Ctx(ctx).Error().Msgf("Expired conversation %d of unknown flow %s", e.Id, e.Flow)
+			continue
+		}
+		// the conversations are already taken, so they time out even after the context is cancelled
+		expireCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), expireTimeout)
+		expireCtx = logging.WithFields(expireCtx, map[string]any{"flow": e.Flow, "user_id": e.UserId, "chat_id": e.ChatId})
+		r.onTimeout(expireCtx, bot.WithContext(expireCtx), e)
+		cancel()
+	}
+	return len(taken), nil
+}
+
+// get returns the conversation and its flow, if it is still running and belongs to the user in the chat
+func (m *Manager) get(ctx context.Context, id int, chatId int64, userId int64) (*entity.Conversation, runner, error) {
+	e, err := m.store.GetById(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if e.ChatId != chatId || e.UserId != userId {
+		return nil, nil, fmt.Errorf("conversation %w: id=%d belongs to user %d in chat %d", entity.ErrNotFound, id, e.UserId, e.ChatId)
+	}
+	if !m.now().Before(e.ExpiresAt) {
+		return nil, nil, fmt.Errorf("conversation %w: id=%d expired at %v", entity.ErrNotFound, id, e.ExpiresAt)
+	}
+	r, ok := m.flows[e.Flow]
+	if !ok {
+		return nil, nil, fmt.Errorf("conversation %d of unknown flow %s", e.Id, e.Flow)
+	}
+	return e, r, nil
+}
+
+// extend restarts the timeout of the conversation before its step runs, which fails once it has been taken to expire
+func (m *Manager) extend(ctx context.Context, e entity.Conversation, r runner) error {
+	now := m.now()
+	return m.store.Extend(ctx, e.Id, now, now.Add(r.timeout()))
+}
+
+// run runs the step and saves the transition it returns
+func (m *Manager) run(ctx context.Context, e *entity.Conversation, r runner, step func() (Transition, error)) error {
+	transition, err := step()
+	if err != nil {
+		return err
+	}
+	if transition.end {
+		err = m.store.DeleteById(ctx, e.Id)
+		if errors.Is(err, entity.ErrNotFound) {
+			// cancelled while the step ran
+			return nil
+		}
+		return err
+	}
+	if transition.next != "" {
+		e.State = string(transition.next)
+	}
+	e.AwaitingText = r.awaitsText(State(e.State))
+	e.ExpiresAt = m.now().Add(r.timeout())
+	return m.store.Update(ctx, *e)
+}
+
+// runner runs a flow on the stored conversations, hiding the type of its data from the manager
+type runner interface {
+	timeout() time.Duration
+	awaitsText(state State) bool
+	onText(ctx context.Context, bot *sender.Sender, e *entity.Conversation, msg *tgbotapi.Message) (Transition, error)
+	onCallback(ctx context.Context, bot *sender.Sender, e *entity.Conversation, callbackQuery *tgbotapi.CallbackQuery) (Transition, error)
+	onTimeout(ctx context.Context, bot *sender.Sender, e entity.Conversation)
+	onCancel(ctx context.Context, bot *sender.Sender, e entity.Conversation)
+}
+
+type flowRunner[T any] struct {
+	flow Flow[T]
+}
+
+func (r flowRunner[T]) timeout() time.Duration {
+	return r.flow.Timeout
+}
+
+func (r flowRunner[T]) awaitsText(state State) bool {
+	return r.flow.Steps[state].OnText != nil
+}
+
+func (r flowRunner[T]) onText(ctx context.Context, bot *sender.Sender, e *entity.Conversation, msg *tgbotapi.Message) (Transition, error) {
+	step, err := r.step(e)
+	if err != nil {
+		return Transition{}, err
+	}
+	if step.OnText == nil {
+		return Transition{}, fmt.Errorf("conversation %d: state %s of flow %s takes no text", e.Id, e.State, e.Flow)
+	}
+	return r.runStep(e, func(c *Conversation[T]) (Transition, error) {
+		return step.OnText(ctx, bot, c, msg)
+	})
+}
+
+func (r flowRunner[T]) onCallback(ctx context.Context, bot *sender.Sender, e *entity.Conversation, callbackQuery *tgbotapi.CallbackQuery) (Transition, error) {
+	step, err := r.step(e)
+	if err != nil {
+		return Transition{}, err
+	}
+	if step.OnCallback == nil {
+		return Transition{}, fmt.Errorf("conversation %d: state %s of flow %s takes no button", e.Id, e.State, e.Flow)
+	}
+	return r.runStep(e, func(c *Conversation[T]) (Transition, error) {
+		return step.OnCallback(ctx, bot, c, callbackQuery)
+	})
+}
+
+func (r flowRunner[T]) onTimeout(ctx context.Context, bot *sender.Sender, e entity.Conversation) {
+	if r.flow.OnTimeout == nil {
+		return
+	}
+	c, err := decode[T](e)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error decoding expired conversation %d: %v", e.Id, err)
+		return
+	}
+	r.flow.OnTimeout(ctx, bot, c)
+}
+
+func (r flowRunner[T]) onCancel(ctx context.Context, bot *sender.Sender, e entity.Conversation) {
+	if r.flow.OnCancel == nil {
+		return
+	}
+	c, err := decode[T](e)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error decoding cancelled conversation %d: %v", e.Id, err)
+		return
+	}
+	r.flow.OnCancel(ctx, bot, c)
+}
+
+func (r flowRunner[T]) step(e *entity.Conversation) (Step[T], error) {
+	step, ok := r.flow.Steps[State(e.State)]
+	if !ok {
+		return Step[T]{}, fmt.Errorf("conversation %d: flow %s has no state %s", e.Id, e.Flow, e.State)
+	}
+	return step, nil
+}
+
+// runStep runs the step on the decoded conversation and encodes the data it changed back into e
+func (r flowRunner[T]) runStep(e *entity.Conversation, step func(c *Conversation[T]) (Transition, error)) (Transition, error) {
+	c, err := decode[T](*e)
+	if err != nil {
+		return Transition{}, err
+	}
+	transition, err := step(&c)
+	if err != nil {
+		return Transition{}, err
+	}
+	e.Data, err = json.Marshal(c.Data)
+	if err != nil {
+		return Transition{}, err
+	}
+	return transition, nil
+}
+
+func decode[T any](e entity.Conversation) (Conversation[T], error) {
+	c := Conversation[T]{
+		Id:        e.Id,
+		ChatId:    e.ChatId,
+		UserId:    e.UserId,
+		State:     State(e.State),
+		CreatedAt: e.CreatedAt,
+		ExpiresAt: e.ExpiresAt,
+	}
+	err := json.Unmarshal(e.Data, &c.Data)
+	if err != nil {
+		return c, fmt.Errorf("decoding data of conversation %d: %w", e.Id, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,295 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type memoryStore struct {
+	conversations map[int]entity.Conversation
+	lastId        int
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{conversations: map[int]entity.Conversation{}}
+}
+
+func (m *memoryStore) Insert(ctx context.Context, c entity.Conversation) (int, error) {
+	m.lastId++
+	c.Id = m.lastId
+	m.conversations[c.Id] = c
+	return c.Id, nil
+}
+
+func (m *memoryStore) GetById(ctx context.Context, id int) (*entity.Conversation, error) {
+	c, ok := m.conversations[id]
+	if !ok {
+		return nil, fmt.Errorf("conversation %w: id=%d", entity.ErrNotFound, id)
+	}
+	return &c, nil
+}
+
+func (m *memoryStore) FindLatestAwaitingText(ctx context.Context, chatId int64, userId int64, now time.Time) (*entity.Conversation, error) {
+	var latest *entity.Conversation
+	for _, c := range m.conversations {
+		if c.ChatId == chatId && c.UserId == userId && c.AwaitingText && c.ExpiresAt.After(now) && (latest == nil || c.Id > latest.Id) {
+			latest = &c
+		}
+	}
+	return latest, nil
+}
+
+func (m *memoryStore) Extend(ctx context.Context, id int, now time.Time, expiresAt time.Time) error {
+	c, ok := m.conversations[id]
+	if !ok || !c.ExpiresAt.After(now) {
+		return fmt.Errorf("conversation %w: id=%d", entity.ErrNotFound, id)
+	}
+	c.ExpiresAt = expiresAt
+	m.conversations[id] = c
+	return nil
+}
+
+func (m *memoryStore) Update(ctx context.Context, c entity.Conversation) error {
+	if _, ok := m.conversations[c.Id]; !ok {
+		return fmt.Errorf("conversation %w: id=%d", entity.ErrNotFound, c.Id)
+	}
+	m.conversations[c.Id] = c
+	return nil
+}
+
+func (m *memoryStore) DeleteById(ctx context.Context, id int) error {
+	if _, ok := m.conversations[id]; !ok {
+		return fmt.Errorf("conversation %w: id=%d", entity.ErrNotFound, id)
+	}
+	delete(m.conversations, id)
+	return nil
+}
+
+func (m *memoryStore) TakeByChatIdAndUserId(ctx context.Context, chatId int64, userId int64) ([]entity.Conversation, error) {
+	var taken []entity.Conversation
+	for id, c := range m.conversations {
+		if c.ChatId == chatId && c.UserId == userId {
+			taken = append(taken, c)
+			delete(m.conversations, id)
+		}
+	}
+	return taken, nil
+}
+
+func (m *memoryStore) TakeExpired(ctx context.Context, now time.Time) ([]entity.Conversation, error) {
+	var taken []entity.Conversation
+	for id, c := range m.conversations {
+		if !c.ExpiresAt.After(now) {
+			taken = append(taken, c)
+			delete(m.conversations, id)
+		}
+	}
+	return taken, nil
+}
+
+// budget is a test flow that asks for a category with a button, then for an amount as text
+type budget struct {
+	Category string `json:"category"`
+	Amount   int    `json:"amount"`
+}
+
+type recorder struct {
+	done      []budget
+	timedOut  []budget
+	cancelled []budget
+}
+
+func newBudgetFlow(r *recorder) Flow[budget] {
+	return Flow[budget]{
+		Name:    "budget",
+		Initial: "category",
+		Timeout: 10 * time.Minute,
+		Steps: map[State]Step[budget]{
+			"category": {
+				OnCallback: func(ctx context.Context, bot *sender.Sender, c *Conversation[budget], callbackQuery *tgbotapi.CallbackQuery) (Transition, error) {
+					c.Data.Category = callbackQuery.Data
+					return Goto("amount"), nil
+				},
+			},
+			"amount": {
+				OnText: func(ctx context.Context, bot *sender.Sender, c *Conversation[budget], msg *tgbotapi.Message) (Transition, error) {
+					amount, err := strconv.Atoi(msg.Text)
+					if err != nil {
+						return Stay(), nil
+					}
+					c.Data.Amount = amount
+					r.done = append(r.done, c.Data)
+					return End(), nil
+				},
+			},
+		},
+		OnTimeout: func(ctx context.Context, bot *sender.Sender, c Conversation[budget]) {
+			r.timedOut = append(r.timedOut, c.Data)
+		},
+		OnCancel: func(ctx context.Context, bot *sender.Sender, c Conversation[budget]) {
+			r.cancelled = append(r.cancelled, c.Data)
+		},
+	}
+}
+
+func newTestManager(t *testing.T) (*Manager, *memoryStore, *recorder, *time.Time) {
+	t.Helper()
+	store := newMemoryStore()
+	m := NewManager(store)
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	r := &recorder{}
+	Register(m, newBudgetFlow(r))
+	return m, store, r, &now
+}
+
+func callbackQuery(chatId int64, userId int64, data string) *tgbotapi.CallbackQuery {
+	return &tgbotapi.CallbackQuery{
+		From:    &tgbotapi.User{ID: userId},
+		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: chatId}},
+		Data:    data,
+	}
+}
+
+func textMessage(chatId int64, userId int64, text string) *tgbotapi.Message {
+	return &tgbotapi.Message{From: &tgbotapi.User{ID: userId}, Chat: &tgbotapi.Chat{ID: chatId}, Text: text}
+}
+
+func TestManager_Flow(t *testing.T) {
+	m, store, r, _ := newTestManager(t)
+	ctx := context.Background()
+
+	id, err := Start(ctx, m, "budget", 3, 1, budget{})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if handled, err := m.HandleText(ctx, nil, textMessage(3, 1, "5")); handled || err != nil {
+		t.Errorf("expected no text awaited before the category, got %v, %v", handled, err)
+	}
+
+	if err := m.HandleCallback(ctx, nil, id, callbackQuery(3, 1, "food")); err != nil {
+		t.Fatalf("HandleCallback: %v", err)
+	}
+	if c := store.conversations[id]; c.State != "amount" || !c.AwaitingText || string(c.Data) != `{"category":"food","amount":0}` {
+		t.Errorf("unexpected conversation after the category %+v", c)
+	}
+
+	if handled, err := m.HandleText(ctx, nil, textMessage(3, 1, "abc")); !handled || err != nil {
+		t.Errorf("expected the text to be handled, got %v, %v", handled, err)
+	}
+	if c, ok := store.conversations[id]; !ok || c.State != "amount" {
+		t.Errorf("expected the conversation to stay in amount, got %+v", c)
+	}
+
+	if handled, err := m.HandleText(ctx, nil, textMessage(3, 1, "42")); !handled || err != nil {
+		t.Errorf("expected the text to be handled, got %v, %v", handled, err)
+	}
+	if len(store.conversations) != 0 {
+		t.Errorf("expected the conversation to end, got %v", store.conversations)
+	}
+	if len(r.done) != 1 || r.done[0] != (budget{Category: "food", Amount: 42}) {
+		t.Errorf("unexpected result %+v", r.done)
+	}
+}
+
+func TestManager_HandleCallback_NotFound(t *testing.T) {
+	m, store, _, now := newTestManager(t)
+	ctx := context.Background()
+	id, _ := Start(ctx, m, "budget", 3, 1, budget{})
+
+	tests := []struct {
+		name  string
+		id    int
+		query *tgbotapi.CallbackQuery
+	}{
+		{"unknown", id + 1, callbackQuery(3, 1, "food")},
+		{"other user", id, callbackQuery(3, 2, "food")},
+		{"other chat", id, callbackQuery(4, 1, "food")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.HandleCallback(ctx, nil, tt.id, tt.query)
+			if !errors.Is(err, entity.ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+
+	*now = now.Add(time.Hour)
+	if err := m.HandleCallback(ctx, nil, id, callbackQuery(3, 1, "food")); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("expected ErrNotFound once expired, got %v", err)
+	}
+	if store.conversations[id].State != "category" {
+		t.Errorf("expected the conversation unchanged, got %+v", store.conversations[id])
+	}
+}
+
+func TestManager_StepRestartsTimeout(t *testing.T) {
+	m, store, r, now := newTestManager(t)
+	ctx := context.Background()
+	id, _ := Start(ctx, m, "budget", 3, 1, budget{})
+
+	*now = now.Add(8 * time.Minute)
+	m.HandleCallback(ctx, nil, id, callbackQuery(3, 1, "food"))
+	*now = now.Add(8 * time.Minute)
+
+	expired, err := m.Expire(ctx, sender.New(nil, sender.DefaultOptions()), *now)
+	if err != nil || expired != 0 {
+		t.Errorf("expected nothing expired within the restarted timeout, got %d, %v", expired, err)
+	}
+	if _, ok := store.conversations[id]; !ok {
+		t.Fatalf("expected the conversation to be kept")
+	}
+
+	expired, err = m.Expire(ctx, sender.New(nil, sender.DefaultOptions()), now.Add(3*time.Minute))
+	if err != nil || expired != 1 {
+		t.Errorf("expected the conversation to expire, got %d, %v", expired, err)
+	}
+	if len(r.timedOut) != 1 || r.timedOut[0].Category != "food" {
+		t.Errorf("expected the timeout with the data collected, got %+v", r.timedOut)
+	}
+}
+
+func TestManager_Cancel(t *testing.T) {
+	m, store, r, _ := newTestManager(t)
+	ctx := context.Background()
+	Start(ctx, m, "budget", 3, 1, budget{Category: "food"})
+	Start(ctx, m, "budget", 3, 1, budget{Category: "rent"})
+	otherId, _ := Start(ctx, m, "budget", 3, 2, budget{})
+
+	cancelled, err := m.Cancel(ctx, nil, 3, 1)
+	if err != nil || cancelled != 2 {
+		t.Errorf("expected 2 conversations cancelled, got %d, %v", cancelled, err)
+	}
+	if len(r.cancelled) != 2 || len(store.conversations) != 1 {
+		t.Errorf("expected only the conversations of the user cancelled, got %+v", store.conversations)
+	}
+
+	if err := m.CancelById(ctx, nil, otherId, callbackQuery(3, 1, "")); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("expected ErrNotFound cancelling the conversation of another user, got %v", err)
+	}
+	if err := m.CancelById(ctx, nil, otherId, callbackQuery(3, 2, "")); err != nil {
+		t.Errorf("CancelById: %v", err)
+	}
+	if len(store.conversations) != 0 || len(r.cancelled) != 3 {
+		t.Errorf("expected the conversation cancelled, got %+v", store.conversations)
+	}
+}
+
+func TestStart_UnknownFlow(t *testing.T) {
+	m, _, _, _ := newTestManager(t)
+	if _, err := Start(context.Background(), m, "settings", 3, 1, budget{}); err == nil {
+		t.Error("expected an error for an unknown flow")
+	}
+	if _, err := Start(context.Background(), m, "budget", 3, 1, "wrong data"); err == nil {
+		t.Error("expected an error for data of another type")
+	}
+}
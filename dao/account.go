@@ -0,0 +1,129 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AccountDAO struct {
+	db *pgxpool.Pool
+}
+
+func NewAccountDAO(db *pgxpool.Pool) AccountDAO {
+	return AccountDAO{db: db}
+}
+
+func (dao AccountDAO) FindAllByUserId(ctx context.Context, userId int64) ([]entity.Account, error) {
+	var accounts []entity.Account
+	sql := `
+			SELECT id, user_id, name, opening_balance, currency
+			FROM account
+			WHERE user_id = $1
+			ORDER BY id
+			`
+	err := pgxscan.Select(ctx, dao.db, &accounts, sql, userId)
+	if err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (dao AccountDAO) GetById(ctx context.Context, id int, userId int64) (entity.Account, error) {
+	var accounts []*entity.Account
+	sql := `
+			SELECT id, user_id, name, opening_balance, currency
+			FROM account
+			WHERE id = $1 AND user_id = $2
+			`
+	err := pgxscan.Select(ctx, dao.db, &accounts, sql, id, userId)
+	if err != nil {
+		return entity.Account{}, err
+	}
+	if len(accounts) == 0 {
+		return entity.Account{}, fmt.Errorf("account %w: id=%d userId=%d", entity.ErrNotFound, id, userId)
+	}
+	return *accounts[0], nil
+}
+
+// Insert adds an account of the user, whose name must differ from the other accounts of the user ignoring case
+func (dao AccountDAO) Insert(ctx context.Context, account entity.Account) (int, error) {
+	var lastInsertId int
+	sql := `
+		INSERT INTO account (user_id, name, opening_balance, currency)
+		VALUES ($1, $2, $3, $4) RETURNING id
+		`
+	err := dao.db.QueryRow(ctx, sql, account.UserId, account.Name, account.OpeningBalance, account.Currency).Scan(&lastInsertId)
+	if isPgError(err, pgUniqueViolation) {
+		return 0, fmt.Errorf("account %w: name %s already exists", entity.ErrConflict, account.Name)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return lastInsertId, nil
+}
+
+func (dao AccountDAO) UpdateOpeningBalance(ctx context.Context, id int, userId int64, openingBalance int64) error {
+	sql := `
+		UPDATE account
+		SET opening_balance = $3
+		WHERE id = $1 AND user_id = $2
+		`
+	tag, err := dao.db.Exec(ctx, sql, id, userId, openingBalance)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("account %w: id=%d userId=%d", entity.ErrNotFound, id, userId)
+	}
+	return nil
+}
+
+// DeleteById deletes an account that has no transactions or transfers
+func (dao AccountDAO) DeleteById(ctx context.Context, id int, userId int64) error {
+	sql := `
+		DELETE FROM account
+		WHERE id = $1 AND user_id = $2
+		`
+	tag, err := dao.db.Exec(ctx, sql, id, userId)
+	if isPgError(err, pgForeignKeyViolation) {
+		return fmt.Errorf("account %w: id=%d still has transactions", entity.ErrConflict, id)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("account %w: id=%d userId=%d", entity.ErrNotFound, id, userId)
+	}
+	return nil
+}
+
+// FindBalancesByUserId returns the balance of each account of the user, which is its opening balance with the amounts
+// of its transactions by the multiplier of their type and the transfers in and out of it
+func (dao AccountDAO) FindBalancesByUserId(ctx context.Context, userId int64) ([]entity.AccountBalance, error) {
+	var balances []entity.AccountBalance
+	sql := `
+			SELECT a.id,
+			       a.name,
+			       a.currency,
+			       a.opening_balance
+			           + COALESCE((SELECT SUM(t.amount * tt.multiplier)
+			                       FROM transaction t
+			                       JOIN category c on t.category_id = c.id
+			                       JOIN transaction_type tt on c.transaction_type_id = tt.id
+			                       WHERE t.account_id = a.id AND t.deleted_time IS NULL), 0)
+			           + COALESCE((SELECT SUM(tf.amount) FROM transfer tf WHERE tf.to_account_id = a.id), 0)
+			           - COALESCE((SELECT SUM(tf.amount) FROM transfer tf WHERE tf.from_account_id = a.id), 0) as balance
+			FROM account a
+			WHERE a.user_id = $1
+			ORDER BY a.id
+			`
+	err := pgxscan.Select(ctx, dao.db, &balances, sql, userId)
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
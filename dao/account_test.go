@@ -0,0 +1,121 @@
+//go:build integration
+
+package dao
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+func TestAccountDAO_Insert_Conflict(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+	seedUser(t, ctx, 200)
+
+	dao := NewAccountDAO(testPool)
+	if _, err := dao.Insert(ctx, entity.Account{UserId: 100, Name: "DBS card", Currency: "SGD"}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	_, err := dao.Insert(ctx, entity.Account{UserId: 100, Name: "dbs CARD", Currency: "SGD"})
+	if !errors.Is(err, entity.ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	if _, err := dao.Insert(ctx, entity.Account{UserId: 200, Name: "DBS card", Currency: "SGD"}); err != nil {
+		t.Errorf("expected another user to reuse the name, got %v", err)
+	}
+}
+
+func TestAccountDAO_FindBalancesByUserId(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+
+	dao := NewAccountDAO(testPool)
+	cash, _ := dao.Insert(ctx, entity.Account{UserId: 100, Name: "Cash", OpeningBalance: 5000, Currency: "SGD"})
+	card, _ := dao.Insert(ctx, entity.Account{UserId: 100, Name: "DBS card", Currency: "SGD"})
+
+	transactionDao := NewTransactionDao(testPool)
+	now := time.Now()
+	for _, txn := range []entity.Transaction{
+		{Datetime: now, CategoryId: 4, UserId: 100, Amount: 550, Currency: "SGD", AccountId: &cash},
+		{Datetime: now, CategoryId: 4, UserId: 100, Amount: 1200, Currency: "SGD", AccountId: &card},
+		{Datetime: now, CategoryId: 4, UserId: 100, Amount: 300, Currency: "SGD"},
+	} {
+		if _, err := transactionDao.Insert(ctx, txn); err != nil {
+			t.Fatalf("insert txn: %v", err)
+		}
+	}
+	// an ATM withdrawal
+	if _, err := NewTransferDAO(testPool).Insert(ctx, entity.Transfer{Datetime: now, UserId: 100, FromAccountId: card, ToAccountId: cash, Amount: 2000, Currency: "SGD"}); err != nil {
+		t.Fatalf("insert transfer: %v", err)
+	}
+
+	balances, err := dao.FindBalancesByUserId(ctx, 100)
+	if err != nil {
+		t.Fatalf("FindBalancesByUserId: %v", err)
+	}
+	if len(balances) != 2 {
+		t.Fatalf("len = %d, want 2", len(balances))
+	}
+	if balances[0].Name != "Cash" || balances[0].Balance != 5000-550+2000 {
+		t.Errorf("unexpected cash balance %+v", balances[0])
+	}
+	if balances[1].Name != "DBS card" || balances[1].Balance != -1200-2000 {
+		t.Errorf("unexpected card balance %+v", balances[1])
+	}
+}
+
+func TestAccountDAO_DeleteById(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+
+	dao := NewAccountDAO(testPool)
+	unused, _ := dao.Insert(ctx, entity.Account{UserId: 100, Name: "Cash", Currency: "SGD"})
+	used, _ := dao.Insert(ctx, entity.Account{UserId: 100, Name: "DBS card", Currency: "SGD"})
+	_, err := NewTransactionDao(testPool).Insert(ctx, entity.Transaction{Datetime: time.Now(), CategoryId: 4, UserId: 100, Amount: 100, Currency: "SGD", AccountId: &used})
+	if err != nil {
+		t.Fatalf("insert txn: %v", err)
+	}
+
+	if err := dao.DeleteById(ctx, used, 100); !errors.Is(err, entity.ErrConflict) {
+		t.Errorf("expected ErrConflict for an account with transactions, got %v", err)
+	}
+	if err := dao.DeleteById(ctx, unused, 200); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for the account of another user, got %v", err)
+	}
+	if err := dao.DeleteById(ctx, unused, 100); err != nil {
+		t.Errorf("DeleteById: %v", err)
+	}
+}
+
+func TestTransactionDAO_ListByMonthAndYear_Account(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+
+	card, _ := NewAccountDAO(testPool).Insert(ctx, entity.Account{UserId: 100, Name: "DBS card", Currency: "SGD"})
+	dao := NewTransactionDao(testPool)
+	dt := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	dao.Insert(ctx, entity.Transaction{Datetime: dt, CategoryId: 4, UserId: 100, Amount: 100, Currency: "SGD", AccountId: &card})
+	dao.Insert(ctx, entity.Transaction{Datetime: dt, CategoryId: 4, UserId: 100, Amount: 200, Currency: "SGD"})
+
+	dateFrom, dateTo := dt.AddDate(0, 0, -1), dt.AddDate(0, 0, 1)
+	count, err := dao.CountListByMonthAndYear(ctx, dateFrom, dateTo, 100, card)
+	if err != nil || count != 1 {
+		t.Fatalf("expected 1 transaction of the account, got %d: %v", count, err)
+	}
+	results, err := dao.ListByMonthAndYear(ctx, dateFrom, dateTo, 0, 10, false, 100, card)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("expected 1 transaction of the account, got %v: %v", results, err)
+	}
+	if results[0].AccountName == nil || *results[0].AccountName != "DBS card" {
+		t.Errorf("expected the name of the account, got %v", results[0].AccountName)
+	}
+}
@@ -0,0 +1,63 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ApiTokenDAO struct {
+	db *pgxpool.Pool
+}
+
+func NewApiTokenDAO(db *pgxpool.Pool) ApiTokenDAO {
+	return ApiTokenDAO{db: db}
+}
+
+func (dao ApiTokenDAO) Insert(ctx context.Context, apiToken entity.ApiToken) (int, error) {
+	var lastInsertId int
+	sql := `
+		INSERT INTO api_token (user_id, token_hash)
+		VALUES ($1, $2) RETURNING id
+		`
+	err := dao.db.QueryRow(ctx, sql, apiToken.UserId, apiToken.TokenHash).Scan(&lastInsertId)
+	if err != nil {
+		return 0, err
+	}
+	return lastInsertId, nil
+}
+
+// FindUserIdByTokenHash returns the owner of the token and records that the token was used, or nil if there is no
+// such token
+func (dao ApiTokenDAO) FindUserIdByTokenHash(ctx context.Context, tokenHash string) (*int64, error) {
+	var userId int64
+	sql := `
+		UPDATE api_token
+		SET last_used_time = NOW()
+		WHERE token_hash = $1
+		RETURNING user_id
+		`
+	err := dao.db.QueryRow(ctx, sql, tokenHash).Scan(&userId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &userId, nil
+}
+
+func (dao ApiTokenDAO) DeleteByUserId(ctx context.Context, userId int64) error {
+	sql := `
+		DELETE FROM api_token
+		WHERE user_id = $1
+		`
+	_, err := dao.db.Exec(ctx, sql, userId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
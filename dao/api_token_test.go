@@ -0,0 +1,76 @@
+//go:build integration
+
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+func TestApiTokenDAO_InsertAndFindUserIdByTokenHash(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+
+	dao := NewApiTokenDAO(testPool)
+	hash := "0000000000000000000000000000000000000000000000000000000000000001"
+
+	_, err := dao.Insert(ctx, entity.ApiToken{UserId: 100, TokenHash: hash})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	userId, err := dao.FindUserIdByTokenHash(ctx, hash)
+	if err != nil {
+		t.Fatalf("FindUserIdByTokenHash: %v", err)
+	}
+	if userId == nil || *userId != 100 {
+		t.Errorf("userId = %v, want 100", userId)
+	}
+
+	var lastUsedSet bool
+	err = testPool.QueryRow(ctx, `SELECT last_used_time IS NOT NULL FROM api_token WHERE token_hash = $1`, hash).Scan(&lastUsedSet)
+	if err != nil {
+		t.Fatalf("query last_used_time: %v", err)
+	}
+	if !lastUsedSet {
+		t.Errorf("expected last_used_time to be recorded")
+	}
+}
+
+func TestApiTokenDAO_FindUserIdByTokenHash_NotFound(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+
+	userId, err := NewApiTokenDAO(testPool).FindUserIdByTokenHash(ctx, "missing")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if userId != nil {
+		t.Errorf("expected nil, got %d", *userId)
+	}
+}
+
+func TestApiTokenDAO_DeleteByUserId(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+	seedUser(t, ctx, 200)
+
+	dao := NewApiTokenDAO(testPool)
+	dao.Insert(ctx, entity.ApiToken{UserId: 100, TokenHash: "hash-100"})
+	dao.Insert(ctx, entity.ApiToken{UserId: 200, TokenHash: "hash-200"})
+
+	if err := dao.DeleteByUserId(ctx, 100); err != nil {
+		t.Fatalf("DeleteByUserId: %v", err)
+	}
+
+	if userId, _ := dao.FindUserIdByTokenHash(ctx, "hash-100"); userId != nil {
+		t.Errorf("expected token of user 100 to be revoked")
+	}
+	if userId, _ := dao.FindUserIdByTokenHash(ctx, "hash-200"); userId == nil {
+		t.Errorf("expected token of user 200 to remain")
+	}
+}
@@ -2,13 +2,20 @@ package dao
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/aattwwss/telegram-expense-bot/entity"
 	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const (
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+)
+
 type CategoryDAO struct {
 	db *pgxpool.Pool
 }
@@ -17,14 +24,16 @@ func NewCategoryDAO(db *pgxpool.Pool) CategoryDAO {
 	return CategoryDAO{db: db}
 }
 
-func (dao CategoryDAO) FindAll(ctx context.Context) ([]*entity.Category, error) {
+// FindAllByUserId returns the shared categories together with the custom categories of the user
+func (dao CategoryDAO) FindAllByUserId(ctx context.Context, userId int64) ([]*entity.Category, error) {
 	var categories []*entity.Category
 	sql := `
-			SELECT id, name, transaction_type_id 
-			FROM category 
-			ORDER BY display_order
+			SELECT id, name, transaction_type_id, user_id
+			FROM category
+			WHERE user_id IS NULL OR user_id = $1
+			ORDER BY display_order, id
 			`
-	err := pgxscan.Select(ctx, dao.db, &categories, sql)
+	err := pgxscan.Select(ctx, dao.db, &categories, sql, userId)
 	if err != nil {
 		return nil, err
 	}
@@ -34,8 +43,8 @@ func (dao CategoryDAO) FindAll(ctx context.Context) ([]*entity.Category, error)
 func (dao CategoryDAO) FindByTransactionTypeId(ctx context.Context, transactionTypeId int) ([]*entity.Category, error) {
 	var categories []*entity.Category
 	sql := `
-			SELECT id, name, transaction_type_id 
-			FROM category 
+			SELECT id, name, transaction_type_id, user_id
+			FROM category
 			WHERE transaction_type_id = $1
 			ORDER BY display_order
 			`
@@ -49,8 +58,8 @@ func (dao CategoryDAO) FindByTransactionTypeId(ctx context.Context, transactionT
 func (dao CategoryDAO) GetById(ctx context.Context, id int) (entity.Category, error) {
 	var categories []*entity.Category
 	sql := `
-			SELECT id, name, transaction_type_id 
-			FROM category 
+			SELECT id, name, transaction_type_id, user_id
+			FROM category
 			WHERE id = $1
 			`
 	err := pgxscan.Select(ctx, dao.db, &categories, sql, id)
@@ -58,7 +67,68 @@ func (dao CategoryDAO) GetById(ctx context.Context, id int) (entity.Category, er
 		return entity.Category{}, err
 	}
 	if len(categories) == 0 {
-		return entity.Category{}, fmt.Errorf("category not found: id=%d", id)
+		return entity.Category{}, fmt.Errorf("category %w: id=%d", entity.ErrNotFound, id)
 	}
 	return *categories[0], nil
 }
+
+// Insert adds a custom category of the user after all the existing categories
+func (dao CategoryDAO) Insert(ctx context.Context, category entity.Category) (int, error) {
+	var lastInsertId int
+	sql := `
+		INSERT INTO category (name, transaction_type_id, user_id, display_order)
+		VALUES ($1, $2, $3, (SELECT COALESCE(MAX(display_order), 0) + 1 FROM category)) RETURNING id
+		`
+	err := dao.db.QueryRow(ctx, sql, category.Name, category.TransactionTypeId, category.UserId).Scan(&lastInsertId)
+	if isPgError(err, pgUniqueViolation) {
+		return 0, fmt.Errorf("category %w: name %s already exists", entity.ErrConflict, category.Name)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return lastInsertId, nil
+}
+
+// UpdateName renames a custom category, the shared categories cannot be renamed
+func (dao CategoryDAO) UpdateName(ctx context.Context, id int, userId int64, name string) error {
+	sql := `
+		UPDATE category
+		SET name = $3
+		WHERE id = $1 AND user_id = $2
+		`
+	tag, err := dao.db.Exec(ctx, sql, id, userId, name)
+	if isPgError(err, pgUniqueViolation) {
+		return fmt.Errorf("category %w: name %s already exists", entity.ErrConflict, name)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("category %w: id=%d userId=%d", entity.ErrNotFound, id, userId)
+	}
+	return nil
+}
+
+// DeleteById deletes a custom category that has no transactions, the shared categories cannot be deleted
+func (dao CategoryDAO) DeleteById(ctx context.Context, id int, userId int64) error {
+	sql := `
+		DELETE FROM category
+		WHERE id = $1 AND user_id = $2
+		`
+	tag, err := dao.db.Exec(ctx, sql, id, userId)
+	if isPgError(err, pgForeignKeyViolation) {
+		return fmt.Errorf("category %w: id=%d still has transactions", entity.ErrConflict, id)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("category %w: id=%d userId=%d", entity.ErrNotFound, id, userId)
+	}
+	return nil
+}
+
+func isPgError(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
@@ -0,0 +1,116 @@
+//go:build integration
+
+package dao
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+func TestCategoryDAO_InsertAndFindAllByUserId(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+	seedUser(t, ctx, 200)
+
+	dao := NewCategoryDAO(testPool)
+	userId := int64(100)
+	id, err := dao.Insert(ctx, entity.Category{Name: "Pets", TransactionTypeId: 1, UserId: &userId})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	own, err := dao.FindAllByUserId(ctx, 100)
+	if err != nil {
+		t.Fatalf("FindAllByUserId: %v", err)
+	}
+	others, err := dao.FindAllByUserId(ctx, 200)
+	if err != nil {
+		t.Fatalf("FindAllByUserId: %v", err)
+	}
+	if len(own) != len(others)+1 {
+		t.Fatalf("expected user 100 to see one more category than user 200, got %d and %d", len(own), len(others))
+	}
+	last := own[len(own)-1]
+	if last.Id != id || last.UserId == nil || *last.UserId != 100 {
+		t.Errorf("expected the custom category to be listed last, got %+v", last)
+	}
+}
+
+func TestCategoryDAO_Insert_Conflict(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+	seedUser(t, ctx, 200)
+
+	dao := NewCategoryDAO(testPool)
+	userId, otherUserId := int64(100), int64(200)
+	if _, err := dao.Insert(ctx, entity.Category{Name: "Pets", TransactionTypeId: 1, UserId: &userId}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	_, err := dao.Insert(ctx, entity.Category{Name: "Pets", TransactionTypeId: 1, UserId: &userId})
+	if !errors.Is(err, entity.ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	if _, err := dao.Insert(ctx, entity.Category{Name: "Pets", TransactionTypeId: 1, UserId: &otherUserId}); err != nil {
+		t.Errorf("expected another user to reuse the name, got %v", err)
+	}
+}
+
+func TestCategoryDAO_UpdateName(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+
+	dao := NewCategoryDAO(testPool)
+	userId := int64(100)
+	id, _ := dao.Insert(ctx, entity.Category{Name: "Pets", TransactionTypeId: 1, UserId: &userId})
+
+	if err := dao.UpdateName(ctx, id, 100, "Cats"); err != nil {
+		t.Fatalf("UpdateName: %v", err)
+	}
+	got, err := dao.GetById(ctx, id)
+	if err != nil {
+		t.Fatalf("GetById: %v", err)
+	}
+	if got.Name != "Cats" {
+		t.Errorf("Name = %q, want Cats", got.Name)
+	}
+
+	if err := dao.UpdateName(ctx, id, 200, "Dogs"); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("expected ErrNotFound renaming another user's category, got %v", err)
+	}
+	shared, _ := dao.FindByTransactionTypeId(ctx, 1)
+	if err := dao.UpdateName(ctx, shared[0].Id, 100, "Dogs"); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("expected ErrNotFound renaming a shared category, got %v", err)
+	}
+}
+
+func TestCategoryDAO_DeleteById(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+
+	dao := NewCategoryDAO(testPool)
+	userId := int64(100)
+	unused, _ := dao.Insert(ctx, entity.Category{Name: "Pets", TransactionTypeId: 1, UserId: &userId})
+	used, _ := dao.Insert(ctx, entity.Category{Name: "Plants", TransactionTypeId: 1, UserId: &userId})
+	_, err := NewTransactionDao(testPool).Insert(ctx, entity.Transaction{CategoryId: used, UserId: 100, Amount: 100, Currency: "SGD"})
+	if err != nil {
+		t.Fatalf("insert txn: %v", err)
+	}
+
+	if err := dao.DeleteById(ctx, unused, 100); err != nil {
+		t.Fatalf("DeleteById: %v", err)
+	}
+	if _, err := dao.GetById(ctx, unused); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("expected the category to be deleted, got %v", err)
+	}
+	if err := dao.DeleteById(ctx, used, 100); !errors.Is(err, entity.ErrConflict) {
+		t.Errorf("expected ErrConflict deleting a category with transactions, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const conversationColumns = "id, chat_id, user_id, flow, state, data, awaiting_text, created_at, expires_at"
+
+type ConversationDAO struct {
+	db *pgxpool.Pool
+}
+
+func NewConversationDAO(db *pgxpool.Pool) ConversationDAO {
+	return ConversationDAO{db: db}
+}
+
+func (dao ConversationDAO) Insert(ctx context.Context, c entity.Conversation) (int, error) {
+	var lastInsertId int
+	sql := `
+		INSERT INTO conversation (chat_id, user_id, flow, state, data, awaiting_text, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id
+		`
+	err := dao.db.QueryRow(ctx, sql, c.ChatId, c.UserId, c.Flow, c.State, c.Data, c.AwaitingText, c.CreatedAt, c.ExpiresAt).Scan(&lastInsertId)
+	if err != nil {
+		return 0, err
+	}
+	return lastInsertId, nil
+}
+
+func (dao ConversationDAO) GetById(ctx context.Context, id int) (*entity.Conversation, error) {
+	var conversations []entity.Conversation
+	sql := `SELECT ` + conversationColumns + ` FROM conversation WHERE id = $1`
+	err := pgxscan.Select(ctx, dao.db, &conversations, sql, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(conversations) == 0 {
+		return nil, fmt.Errorf("conversation %w: id=%d", entity.ErrNotFound, id)
+	}
+	return &conversations[0], nil
+}
+
+// FindLatestAwaitingText returns the latest conversation of the user in the chat that expects a text reply, or nil
+func (dao ConversationDAO) FindLatestAwaitingText(ctx context.Context, chatId int64, userId int64, now time.Time) (*entity.Conversation, error) {
+	var conversations []entity.Conversation
+	sql := `
+			SELECT ` + conversationColumns + `
+			FROM conversation
+			WHERE chat_id = $1 AND user_id = $2 AND awaiting_text AND expires_at > $3
+			ORDER BY id DESC
+			LIMIT 1
+			`
+	err := pgxscan.Select(ctx, dao.db, &conversations, sql, chatId, userId, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(conversations) == 0 {
+		return nil, nil
+	}
+	return &conversations[0], nil
+}
+
+// Extend moves the expiry of a conversation that has not expired at now, so it is not timed out while it is handled
+func (dao ConversationDAO) Extend(ctx context.Context, id int, now time.Time, expiresAt time.Time) error {
+	sql := `UPDATE conversation SET expires_at = $3 WHERE id = $1 AND expires_at > $2`
+	tag, err := dao.db.Exec(ctx, sql, id, now, expiresAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("conversation %w: id=%d", entity.ErrNotFound, id)
+	}
+	return nil
+}
+
+func (dao ConversationDAO) Update(ctx context.Context, c entity.Conversation) error {
+	sql := `
+		UPDATE conversation
+		SET state = $2, data = $3, awaiting_text = $4, expires_at = $5
+		WHERE id = $1
+		`
+	tag, err := dao.db.Exec(ctx, sql, c.Id, c.State, c.Data, c.AwaitingText, c.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("conversation %w: id=%d", entity.ErrNotFound, c.Id)
+	}
+	return nil
+}
+
+func (dao ConversationDAO) DeleteById(ctx context.Context, id int) error {
+	sql := `DELETE FROM conversation WHERE id = $1`
+	tag, err := dao.db.Exec(ctx, sql, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("conversation %w: id=%d", entity.ErrNotFound, id)
+	}
+	return nil
+}
+
+// TakeByChatIdAndUserId deletes and returns the conversations of the user in the chat
+func (dao ConversationDAO) TakeByChatIdAndUserId(ctx context.Context, chatId int64, userId int64) ([]entity.Conversation, error) {
+	var conversations []entity.Conversation
+	sql := `DELETE FROM conversation WHERE chat_id = $1 AND user_id = $2 RETURNING ` + conversationColumns
+	err := pgxscan.Select(ctx, dao.db, &conversations, sql, chatId, userId)
+	if err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
+// TakeExpired deletes and returns the conversations that have expired
+func (dao ConversationDAO) TakeExpired(ctx context.Context, now time.Time) ([]entity.Conversation, error) {
+	var conversations []entity.Conversation
+	sql := `DELETE FROM conversation WHERE expires_at <= $1 RETURNING ` + conversationColumns
+	err := pgxscan.Select(ctx, dao.db, &conversations, sql, now)
+	if err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
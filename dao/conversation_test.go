@@ -0,0 +1,113 @@
+//go:build integration
+
+package dao
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+func insertConversation(t *testing.T, ctx context.Context, dao ConversationDAO, userId int64, awaitingText bool, expiresAt time.Time) int {
+	t.Helper()
+	id, err := dao.Insert(ctx, entity.Conversation{
+		ChatId: 200, UserId: userId, Flow: "entry", State: "category", Data: []byte(`{"text": "5.50 lunch"}`),
+		AwaitingText: awaitingText, CreatedAt: expiresAt.Add(-time.Hour), ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("insert conversation: %v", err)
+	}
+	return id
+}
+
+func TestConversationDAO_UpdateAndDelete(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	dao := NewConversationDAO(testPool)
+	now := time.Now()
+
+	id := insertConversation(t, ctx, dao, 100, false, now.Add(time.Minute))
+	got, err := dao.GetById(ctx, id)
+	if err != nil {
+		t.Fatalf("GetById: %v", err)
+	}
+	if got.Flow != "entry" || got.State != "category" || got.UserId != 100 {
+		t.Errorf("unexpected conversation %+v", got)
+	}
+
+	got.State = "description"
+	got.Data = []byte(`{"text": "5.50 dinner"}`)
+	got.AwaitingText = true
+	if err := dao.Update(ctx, *got); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	found, err := dao.FindLatestAwaitingText(ctx, 200, 100, now)
+	if err != nil || found == nil || found.Id != id || found.State != "description" {
+		t.Errorf("expected the updated conversation awaiting text, got %+v, %v", found, err)
+	}
+
+	if err := dao.DeleteById(ctx, id); err != nil {
+		t.Fatalf("DeleteById: %v", err)
+	}
+	if err := dao.DeleteById(ctx, id); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+	if err := dao.Update(ctx, *got); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("expected ErrNotFound updating a deleted conversation, got %v", err)
+	}
+}
+
+func TestConversationDAO_ExtendAndTakeExpired(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	dao := NewConversationDAO(testPool)
+	now := time.Now()
+
+	liveId := insertConversation(t, ctx, dao, 100, false, now.Add(time.Minute))
+	expiredId := insertConversation(t, ctx, dao, 100, true, now.Add(-time.Minute))
+
+	if err := dao.Extend(ctx, expiredId, now, now.Add(time.Hour)); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("expected ErrNotFound extending an expired conversation, got %v", err)
+	}
+	if err := dao.Extend(ctx, liveId, now, now.Add(time.Hour)); err != nil {
+		t.Errorf("Extend: %v", err)
+	}
+	if found, err := dao.FindLatestAwaitingText(ctx, 200, 100, now); err != nil || found != nil {
+		t.Errorf("expected no live conversation awaiting text, got %+v, %v", found, err)
+	}
+
+	taken, err := dao.TakeExpired(ctx, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("TakeExpired: %v", err)
+	}
+	if len(taken) != 1 || taken[0].Id != expiredId {
+		t.Errorf("expected only the expired conversation, got %+v", taken)
+	}
+	if _, err := dao.GetById(ctx, liveId); err != nil {
+		t.Errorf("expected the extended conversation to be kept, got %v", err)
+	}
+}
+
+func TestConversationDAO_TakeByChatIdAndUserId(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	dao := NewConversationDAO(testPool)
+	now := time.Now()
+
+	insertConversation(t, ctx, dao, 100, false, now.Add(time.Minute))
+	otherId := insertConversation(t, ctx, dao, 101, false, now.Add(time.Minute))
+
+	taken, err := dao.TakeByChatIdAndUserId(ctx, 200, 100)
+	if err != nil {
+		t.Fatalf("TakeByChatIdAndUserId: %v", err)
+	}
+	if len(taken) != 1 || taken[0].UserId != 100 {
+		t.Errorf("expected the conversation of user 100, got %+v", taken)
+	}
+	if _, err := dao.GetById(ctx, otherId); err != nil {
+		t.Errorf("expected the conversation of the other user to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CurrencyDAO struct {
+	db *pgxpool.Pool
+}
+
+func NewCurrencyDAO(db *pgxpool.Pool) CurrencyDAO {
+	return CurrencyDAO{db: db}
+}
+
+func (dao CurrencyDAO) GetAll(ctx context.Context) ([]entity.Currency, error) {
+	var currencies []entity.Currency
+	sql := `
+			SELECT code, denominator
+			FROM currency
+			ORDER BY code
+			`
+	err := pgxscan.Select(ctx, dao.db, &currencies, sql)
+	if err != nil {
+		return nil, err
+	}
+	return currencies, nil
+}
@@ -0,0 +1,52 @@
+//go:build integration
+
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+func TestCurrencyDAO_GetAll(t *testing.T) {
+	ctx := context.Background()
+
+	currencies, err := NewCurrencyDAO(testPool).GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(currencies) < 150 {
+		t.Errorf("expected every ISO 4217 currency seeded, got %d", len(currencies))
+	}
+
+	denominators := map[string]int{}
+	for _, c := range currencies {
+		denominators[c.Code] = c.Denominator
+	}
+	for code, want := range map[string]int{"SGD": 100, "JPY": 1, "KWD": 1000, "CLF": 10000} {
+		if denominators[code] != want {
+			t.Errorf("%s denominator = %d, want %d", code, denominators[code], want)
+		}
+	}
+
+	_, err = testPool.Exec(ctx, "INSERT INTO currency (code, denominator) VALUES ('ZZZ', 50)")
+	if err == nil {
+		t.Errorf("expected a denominator that is not a power of 10 rejected")
+	}
+}
+
+func TestCurrencyDAO_UserCurrency(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+
+	userDao := NewUserDao(testPool)
+	err := userDao.Insert(ctx, entity.User{Id: 12345, Locale: "en", Currency: "KWD", Timezone: "Asia/Kuwait"})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	user, err := userDao.FindUserById(ctx, 12345)
+	if err != nil || user == nil || user.Currency != "KWD" {
+		t.Fatalf("expected a user with KWD, got %+v, %v", user, err)
+	}
+}
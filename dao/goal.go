@@ -0,0 +1,164 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const goalColumns = `
+				g.id, g.user_id, g.chat_id, g.name, g.target, g.currency, g.deadline, g.monthly_amount,
+				g.next_contribution_time, g.reached_time, g.create_time,
+				COALESCE((SELECT SUM(c.amount) FROM goal_contribution c WHERE c.goal_id = g.id), 0) as saved`
+
+type GoalDAO struct {
+	db *pgxpool.Pool
+}
+
+func NewGoalDAO(db *pgxpool.Pool) GoalDAO {
+	return GoalDAO{db: db}
+}
+
+func (dao GoalDAO) FindAllByUserId(ctx context.Context, userId int64) ([]entity.Goal, error) {
+	var goals []entity.Goal
+	sql := `SELECT ` + goalColumns + `
+				FROM goal g
+				WHERE g.user_id = $1
+				ORDER BY g.id
+				`
+	err := pgxscan.Select(ctx, dao.db, &goals, sql, userId)
+	if err != nil {
+		return nil, err
+	}
+	return goals, nil
+}
+
+func (dao GoalDAO) GetById(ctx context.Context, id int) (entity.Goal, error) {
+	var goals []*entity.Goal
+	sql := `SELECT ` + goalColumns + `
+				FROM goal g
+				WHERE g.id = $1
+				`
+	err := pgxscan.Select(ctx, dao.db, &goals, sql, id)
+	if err != nil {
+		return entity.Goal{}, err
+	}
+	if len(goals) == 0 {
+		return entity.Goal{}, fmt.Errorf("goal %w: id=%d", entity.ErrNotFound, id)
+	}
+	return *goals[0], nil
+}
+
+// Insert adds a goal of the user, whose name must differ from the other goals of the user ignoring case
+func (dao GoalDAO) Insert(ctx context.Context, goal entity.Goal) (int, error) {
+	var lastInsertId int
+	sql := `
+		INSERT INTO goal (user_id, chat_id, name, target, currency, deadline)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+		`
+	err := dao.db.QueryRow(ctx, sql, goal.UserId, goal.ChatId, goal.Name, goal.Target, goal.Currency, goal.Deadline).Scan(&lastInsertId)
+	if isPgError(err, pgUniqueViolation) {
+		return 0, fmt.Errorf("goal %w: name %s already exists", entity.ErrConflict, goal.Name)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return lastInsertId, nil
+}
+
+// DeleteById deletes a goal with its contributions
+func (dao GoalDAO) DeleteById(ctx context.Context, id int, userId int64) error {
+	sql := `
+		DELETE FROM goal
+		WHERE id = $1 AND user_id = $2
+		`
+	tag, err := dao.db.Exec(ctx, sql, id, userId)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("goal %w: id=%d userId=%d", entity.ErrNotFound, id, userId)
+	}
+	return nil
+}
+
+// UpdateMonthlyAmount sets the amount contributed every month from nextContributionTime, or stops the monthly
+// contributions when it is 0
+func (dao GoalDAO) UpdateMonthlyAmount(ctx context.Context, id int, userId int64, monthlyAmount int64, nextContributionTime time.Time) error {
+	sql := `
+		UPDATE goal
+		SET monthly_amount = $3,
+		    next_contribution_time = CASE WHEN $3::bigint > 0 THEN $4::timestamptz END
+		WHERE id = $1 AND user_id = $2
+		`
+	tag, err := dao.db.Exec(ctx, sql, id, userId, monthlyAmount, nextContributionTime)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("goal %w: id=%d userId=%d", entity.ErrNotFound, id, userId)
+	}
+	return nil
+}
+
+// InsertContribution adds a contribution to a goal of the user
+func (dao GoalDAO) InsertContribution(ctx context.Context, contribution entity.GoalContribution, userId int64) error {
+	sql := `
+		INSERT INTO goal_contribution (goal_id, datetime, amount, currency, automatic)
+		SELECT g.id, $3, $4, $5, $6
+		FROM goal g
+		WHERE g.id = $1 AND g.user_id = $2
+		`
+	tag, err := dao.db.Exec(ctx, sql, contribution.GoalId, userId, contribution.Datetime, contribution.Amount, contribution.Currency, contribution.Automatic)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("goal %w: id=%d userId=%d", entity.ErrNotFound, contribution.GoalId, userId)
+	}
+	return nil
+}
+
+// InsertMonthlyContributions adds the monthly contribution of every goal due at now and moves its next contribution a
+// month later, and returns the ids of the goals contributed to. A goal that is already reached gets none.
+func (dao GoalDAO) InsertMonthlyContributions(ctx context.Context, now time.Time) ([]int, error) {
+	var ids []int
+	sql := `
+		WITH due AS (
+			UPDATE goal
+			SET next_contribution_time = next_contribution_time + interval '1 month'
+			WHERE monthly_amount > 0 AND reached_time IS NULL AND next_contribution_time <= $1
+			RETURNING id, monthly_amount, currency, next_contribution_time - interval '1 month' as datetime
+		)
+		INSERT INTO goal_contribution (goal_id, datetime, amount, currency, automatic)
+		SELECT id, datetime, monthly_amount, currency, true
+		FROM due
+		RETURNING goal_id
+		`
+	err := pgxscan.Select(ctx, dao.db, &ids, sql, now)
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// MarkReached records that the goal was reached at now, and returns true only the first time its contributions reach
+// its target
+func (dao GoalDAO) MarkReached(ctx context.Context, id int, now time.Time) (bool, error) {
+	sql := `
+		UPDATE goal g
+		SET reached_time = $2
+		WHERE g.id = $1
+		  AND g.reached_time IS NULL
+		  AND g.target <= (SELECT COALESCE(SUM(c.amount), 0) FROM goal_contribution c WHERE c.goal_id = g.id)
+		`
+	tag, err := dao.db.Exec(ctx, sql, id, now)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,83 @@
+//go:build integration
+
+package dao
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+func TestGoalDAO_ContributeAndMarkReached(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+	seedUser(t, ctx, 200)
+
+	dao := NewGoalDAO(testPool)
+	id, err := dao.Insert(ctx, entity.Goal{UserId: 100, ChatId: 100, Name: "Japan trip", Target: 300000, Currency: "SGD"})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if _, err := dao.Insert(ctx, entity.Goal{UserId: 100, ChatId: 100, Name: "japan TRIP", Target: 100, Currency: "SGD"}); !errors.Is(err, entity.ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+
+	now := time.Now()
+	if err := dao.InsertContribution(ctx, entity.GoalContribution{GoalId: id, Datetime: now, Amount: 100000, Currency: "SGD"}, 200); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for the goal of another user, got %v", err)
+	}
+	if err := dao.InsertContribution(ctx, entity.GoalContribution{GoalId: id, Datetime: now, Amount: 200000, Currency: "SGD"}, 100); err != nil {
+		t.Fatalf("InsertContribution: %v", err)
+	}
+	if reached, err := dao.MarkReached(ctx, id, now); err != nil || reached {
+		t.Errorf("expected the goal not reached yet, got %v, %v", reached, err)
+	}
+
+	dao.InsertContribution(ctx, entity.GoalContribution{GoalId: id, Datetime: now, Amount: 100000, Currency: "SGD"}, 100)
+	if reached, err := dao.MarkReached(ctx, id, now); err != nil || !reached {
+		t.Errorf("expected the goal reached, got %v, %v", reached, err)
+	}
+	if reached, _ := dao.MarkReached(ctx, id, now); reached {
+		t.Errorf("expected the goal to be reached only once")
+	}
+
+	goal, err := dao.GetById(ctx, id)
+	if err != nil || goal.Saved != 300000 || goal.ReachedTime == nil {
+		t.Errorf("unexpected goal %+v: %v", goal, err)
+	}
+}
+
+func TestGoalDAO_InsertMonthlyContributions(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+
+	dao := NewGoalDAO(testPool)
+	due, _ := dao.Insert(ctx, entity.Goal{UserId: 100, ChatId: 100, Name: "Japan trip", Target: 300000, Currency: "SGD"})
+	later, _ := dao.Insert(ctx, entity.Goal{UserId: 100, ChatId: 100, Name: "Laptop", Target: 200000, Currency: "SGD"})
+	dao.Insert(ctx, entity.Goal{UserId: 100, ChatId: 100, Name: "Car", Target: 2000000, Currency: "SGD"})
+
+	first := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	dao.UpdateMonthlyAmount(ctx, due, 100, 25000, first)
+	dao.UpdateMonthlyAmount(ctx, later, 100, 10000, first.AddDate(0, 1, 0))
+
+	ids, err := dao.InsertMonthlyContributions(ctx, first.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("InsertMonthlyContributions: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != due {
+		t.Fatalf("expected only the goal due to be contributed to, got %v", ids)
+	}
+	if ids, _ := dao.InsertMonthlyContributions(ctx, first.Add(2*time.Hour)); len(ids) != 0 {
+		t.Errorf("expected one contribution a month, got %v", ids)
+	}
+
+	goal, _ := dao.GetById(ctx, due)
+	if goal.Saved != 25000 || goal.NextContributionTime == nil || !goal.NextContributionTime.Equal(first.AddDate(0, 1, 0)) {
+		t.Errorf("unexpected goal %+v", goal)
+	}
+}
@@ -0,0 +1,129 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IouDAO struct {
+	db *pgxpool.Pool
+}
+
+func NewIouDAO(db *pgxpool.Pool) IouDAO {
+	return IouDAO{db: db}
+}
+
+func (dao IouDAO) Insert(ctx context.Context, iou entity.Iou) (int, error) {
+	var lastInsertId int
+	sql := `
+		INSERT INTO iou (user_id, person, amount, currency, description, datetime)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+		`
+	err := dao.db.QueryRow(ctx, sql, iou.UserId, iou.Person, iou.Amount, iou.Currency, iou.Description, iou.Datetime).Scan(&lastInsertId)
+	if err != nil {
+		return 0, err
+	}
+	return lastInsertId, nil
+}
+
+// SettleIfClear settles the outstanding entries of the person in the currency, ignoring case, once they add up to
+// nothing
+func (dao IouDAO) SettleIfClear(ctx context.Context, userId int64, person string, currency string, now time.Time) error {
+	sql := `
+		UPDATE iou
+		SET settled_time = $4
+		WHERE user_id = $1 AND lower(person) = lower($2) AND currency = $3 AND settled_time IS NULL
+		  AND (SELECT SUM(i.amount)
+		       FROM iou i
+		       WHERE i.user_id = $1 AND lower(i.person) = lower($2) AND i.currency = $3 AND i.settled_time IS NULL) = 0
+		`
+	_, err := dao.db.Exec(ctx, sql, userId, person, currency, now)
+	return err
+}
+
+// GetBalance returns the sum of the outstanding entries of the person in the currency, ignoring case
+func (dao IouDAO) GetBalance(ctx context.Context, userId int64, person string, currency string) (int64, error) {
+	var balance int64
+	sql := `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM iou
+		WHERE user_id = $1 AND lower(person) = lower($2) AND currency = $3 AND settled_time IS NULL
+		`
+	err := dao.db.QueryRow(ctx, sql, userId, person, currency).Scan(&balance)
+	if err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
+
+// FindBalancesByUserId returns the balance of every person with outstanding entries in each currency, named as they
+// were first typed
+func (dao IouDAO) FindBalancesByUserId(ctx context.Context, userId int64) ([]entity.IouBalance, error) {
+	var balances []entity.IouBalance
+	sql := `
+			SELECT (array_agg(person ORDER BY datetime, id))[1] as person,
+			       SUM(amount) as balance,
+			       currency,
+			       MIN(datetime) as open_since
+			FROM iou
+			WHERE user_id = $1 AND settled_time IS NULL
+			GROUP BY lower(person), currency
+			HAVING SUM(amount) <> 0
+			ORDER BY MIN(datetime)
+			`
+	err := pgxscan.Select(ctx, dao.db, &balances, sql, userId)
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+func (dao IouDAO) UpsertReminder(ctx context.Context, reminder entity.IouReminder) error {
+	sql := `
+		INSERT INTO iou_reminder (user_id, chat_id, days)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET chat_id = EXCLUDED.chat_id,
+		    days = EXCLUDED.days
+		`
+	_, err := dao.db.Exec(ctx, sql, reminder.UserId, reminder.ChatId, reminder.Days)
+	return err
+}
+
+func (dao IouDAO) DeleteReminder(ctx context.Context, userId int64) error {
+	sql := `
+		DELETE FROM iou_reminder
+		WHERE user_id = $1
+		`
+	_, err := dao.db.Exec(ctx, sql, userId)
+	return err
+}
+
+// FindDueReminders returns the reminders that were never sent or were last sent at least their days before now
+func (dao IouDAO) FindDueReminders(ctx context.Context, now time.Time) ([]entity.IouReminder, error) {
+	var reminders []entity.IouReminder
+	sql := `
+			SELECT r.user_id, r.chat_id, r.days, r.last_sent_time, u.timezone, u.locale
+			FROM iou_reminder r JOIN app_user u on r.user_id = u.id
+			WHERE r.last_sent_time IS NULL OR r.last_sent_time + r.days * interval '1 day' <= $1
+			`
+	err := pgxscan.Select(ctx, dao.db, &reminders, sql, now)
+	if err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+func (dao IouDAO) MarkReminderSent(ctx context.Context, userId int64, now time.Time) error {
+	sql := `
+		UPDATE iou_reminder
+		SET last_sent_time = $2
+		WHERE user_id = $1
+		`
+	_, err := dao.db.Exec(ctx, sql, userId, now)
+	return err
+}
@@ -0,0 +1,78 @@
+//go:build integration
+
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+func TestIouDAO_SettleIfClear(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+
+	dao := NewIouDAO(testPool)
+	now := time.Now()
+	dao.Insert(ctx, entity.Iou{UserId: 100, Person: "Alice", Amount: 5000, Currency: "SGD", Description: "concert", Datetime: now.AddDate(0, 0, -2)})
+	dao.Insert(ctx, entity.Iou{UserId: 100, Person: "Bob", Amount: -2000, Currency: "SGD", Datetime: now.AddDate(0, 0, -1)})
+	dao.Insert(ctx, entity.Iou{UserId: 100, Person: "alice", Amount: -2000, Currency: "SGD", Datetime: now})
+	if err := dao.SettleIfClear(ctx, 100, "alice", "SGD", now); err != nil {
+		t.Fatalf("SettleIfClear: %v", err)
+	}
+
+	balances, err := dao.FindBalancesByUserId(ctx, 100)
+	if err != nil || len(balances) != 2 {
+		t.Fatalf("expected 2 balances, got %+v: %v", balances, err)
+	}
+	if balances[0].Person != "Alice" || balances[0].Balance != 3000 || balances[1].Person != "Bob" || balances[1].Balance != -2000 {
+		t.Errorf("unexpected balances %+v", balances)
+	}
+
+	dao.Insert(ctx, entity.Iou{UserId: 100, Person: "ALICE", Amount: -3000, Currency: "SGD", Datetime: now})
+	if err := dao.SettleIfClear(ctx, 100, "ALICE", "SGD", now); err != nil {
+		t.Fatalf("SettleIfClear: %v", err)
+	}
+	if balance, err := dao.GetBalance(ctx, 100, "Alice", "SGD"); err != nil || balance != 0 {
+		t.Errorf("expected Alice settled, got %d: %v", balance, err)
+	}
+
+	// a new debt after settling is outstanding from when it was added
+	dao.Insert(ctx, entity.Iou{UserId: 100, Person: "Alice", Amount: 1000, Currency: "SGD", Datetime: now})
+	balances, _ = dao.FindBalancesByUserId(ctx, 100)
+	if len(balances) != 2 || balances[1].Person != "Alice" || balances[1].Balance != 1000 || !balances[1].OpenSince.Equal(now.Truncate(time.Microsecond)) {
+		t.Errorf("unexpected balances after settling %+v", balances)
+	}
+}
+
+func TestIouDAO_FindDueReminders(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+	seedUser(t, ctx, 200)
+
+	dao := NewIouDAO(testPool)
+	now := time.Now()
+	dao.UpsertReminder(ctx, entity.IouReminder{UserId: 100, ChatId: 100, Days: 7})
+	dao.UpsertReminder(ctx, entity.IouReminder{UserId: 200, ChatId: 200, Days: 7})
+	dao.MarkReminderSent(ctx, 200, now.AddDate(0, 0, -3))
+
+	reminders, err := dao.FindDueReminders(ctx, now)
+	if err != nil || len(reminders) != 1 || reminders[0].UserId != 100 || reminders[0].Days != 7 {
+		t.Errorf("expected the reminder never sent only, got %+v: %v", reminders, err)
+	}
+
+	reminders, _ = dao.FindDueReminders(ctx, now.AddDate(0, 0, 4))
+	if len(reminders) != 2 {
+		t.Errorf("expected both reminders due a week after the last one, got %+v", reminders)
+	}
+
+	dao.DeleteReminder(ctx, 100)
+	reminders, _ = dao.FindDueReminders(ctx, now)
+	if len(reminders) != 0 {
+		t.Errorf("expected no reminder after deleting, got %+v", reminders)
+	}
+}
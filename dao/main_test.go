@@ -29,7 +29,7 @@ func TestMain(m *testing.M) {
 
 func clearTables(t *testing.T, ctx context.Context) {
 	t.Helper()
-	tables := []string{"transaction", "message_context", "app_user"}
+	tables := []string{"transaction_split", "transaction_audit", "iou_reminder", "iou", "goal_contribution", "goal", "transfer", "transaction", "account", "message_context", "conversation", "reminder", "api_token", "category WHERE user_id IS NOT NULL", "app_user"}
 	for _, table := range tables {
 		if _, err := testPool.Exec(ctx, "DELETE FROM "+table); err != nil {
 			t.Fatalf("clear %s: %v", table, err)
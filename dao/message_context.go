@@ -3,6 +3,7 @@ package dao
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/aattwwss/telegram-expense-bot/entity"
 	"github.com/georgysavva/scany/v2/pgxscan"
@@ -20,10 +21,10 @@ func NewMessageContextDao(db *pgxpool.Pool) MessageContextDAO {
 func (dao MessageContextDAO) Insert(ctx context.Context, messageContext entity.MessageContext) (int, error) {
 	var lastInsertId int
 	sql := `
-		INSERT INTO message_context ( message, chat_id, message_id, created_at )
-		VALUES ($1,$2,$3,$4) RETURNING id
+		INSERT INTO message_context ( message, chat_id, message_id, created_at, expires_at )
+		VALUES ($1,$2,$3,$4,$5) RETURNING id
 		`
-	err := dao.db.QueryRow(ctx, sql, messageContext.Message, messageContext.ChatId, messageContext.MessageId, messageContext.CreatedAt).Scan(&lastInsertId)
+	err := dao.db.QueryRow(ctx, sql, messageContext.Message, messageContext.ChatId, messageContext.MessageId, messageContext.CreatedAt, messageContext.ExpiresAt).Scan(&lastInsertId)
 	if err != nil {
 		return 0, err
 	}
@@ -33,7 +34,7 @@ func (dao MessageContextDAO) Insert(ctx context.Context, messageContext entity.M
 func (dao MessageContextDAO) GetById(ctx context.Context, id int) (*entity.MessageContext, error) {
 	var messageContextEntities []entity.MessageContext
 	sql := `
-			SELECT id, message, chat_id, message_id, created_at
+			SELECT id, message, chat_id, message_id, created_at, expires_at
 			FROM message_context
             WHERE id = $1;
 			`
@@ -42,7 +43,7 @@ func (dao MessageContextDAO) GetById(ctx context.Context, id int) (*entity.Messa
 		return nil, err
 	}
 	if len(messageContextEntities) == 0 {
-		return nil, fmt.Errorf("message context not found: id=%d", id)
+		return nil, fmt.Errorf("message context %w: id=%d", entity.ErrNotFound, id)
 	}
 	return &messageContextEntities[0], nil
 }
@@ -55,3 +56,13 @@ func (dao MessageContextDAO) DeleteById(ctx context.Context, id int) error {
 	}
 	return nil
 }
+
+// DeleteExpired deletes the message contexts that have expired and returns how many were deleted
+func (dao MessageContextDAO) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	sql := `DELETE FROM message_context WHERE expires_at <= $1`
+	tag, err := dao.db.Exec(ctx, sql, now)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,48 @@
+//go:build integration
+
+package dao
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+func insertMessageContext(t *testing.T, ctx context.Context, dao MessageContextDAO, message string, expiresAt time.Time) int {
+	t.Helper()
+	id, err := dao.Insert(ctx, entity.MessageContext{
+		ChatId: 200, MessageId: 1, Message: message,
+		CreatedAt: expiresAt.Add(-time.Hour), ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("insert message context: %v", err)
+	}
+	return id
+}
+
+func TestMessageContextDAO_DeleteExpired(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	dao := NewMessageContextDao(testPool)
+	now := time.Now()
+
+	expiredId := insertMessageContext(t, ctx, dao, "/list", now.Add(-time.Minute))
+	liveId := insertMessageContext(t, ctx, dao, "/undo", now.Add(time.Minute))
+
+	deleted, err := dao.DeleteExpired(ctx, now)
+	if err != nil {
+		t.Fatalf("DeleteExpired: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected the expired menu to be deleted, got %d", deleted)
+	}
+	if _, err := dao.GetById(ctx, expiredId); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for the expired menu, got %v", err)
+	}
+	if got, err := dao.GetById(ctx, liveId); err != nil || got.Message != "/undo" {
+		t.Errorf("expected the live menu to be kept, got %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,96 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ReminderDAO struct {
+	db *pgxpool.Pool
+}
+
+func NewReminderDAO(db *pgxpool.Pool) ReminderDAO {
+	return ReminderDAO{db: db}
+}
+
+func (dao ReminderDAO) GetByUserId(ctx context.Context, userId int64) (*entity.Reminder, error) {
+	var reminders []*entity.Reminder
+	sql := `
+			SELECT r.user_id, r.chat_id, r.enabled, r.remind_at, r.quiet_start, r.quiet_end, r.snoozed_until, r.last_sent_on, u.timezone, u.locale
+			FROM reminder r JOIN app_user u on r.user_id = u.id
+			WHERE r.user_id = $1
+			`
+	err := pgxscan.Select(ctx, dao.db, &reminders, sql, userId)
+	if err != nil {
+		return nil, err
+	}
+	if len(reminders) == 0 {
+		return nil, nil
+	}
+	return reminders[0], nil
+}
+
+func (dao ReminderDAO) FindAllEnabled(ctx context.Context) ([]*entity.Reminder, error) {
+	var reminders []*entity.Reminder
+	sql := `
+			SELECT r.user_id, r.chat_id, r.enabled, r.remind_at, r.quiet_start, r.quiet_end, r.snoozed_until, r.last_sent_on, u.timezone, u.locale
+			FROM reminder r JOIN app_user u on r.user_id = u.id
+			WHERE r.enabled
+			`
+	err := pgxscan.Select(ctx, dao.db, &reminders, sql)
+	if err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+func (dao ReminderDAO) Upsert(ctx context.Context, reminder entity.Reminder) error {
+	sql := `
+		INSERT INTO reminder (user_id, chat_id, enabled, remind_at, quiet_start, quiet_end)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE
+		SET chat_id = EXCLUDED.chat_id,
+		    enabled = EXCLUDED.enabled,
+		    remind_at = EXCLUDED.remind_at,
+		    quiet_start = EXCLUDED.quiet_start,
+		    quiet_end = EXCLUDED.quiet_end,
+		    snoozed_until = NULL,
+		    update_time = NOW()
+		`
+	_, err := dao.db.Exec(ctx, sql, reminder.UserId, reminder.ChatId, reminder.Enabled, reminder.RemindAt, reminder.QuietStart, reminder.QuietEnd)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (dao ReminderDAO) UpdateSnoozedUntil(ctx context.Context, userId int64, snoozedUntil time.Time) error {
+	sql := `
+		UPDATE reminder
+		SET snoozed_until = $2, update_time = NOW()
+		WHERE user_id = $1
+		`
+	_, err := dao.db.Exec(ctx, sql, userId, snoozedUntil)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateLastSentOn records the local date the reminder was handled and clears any pending snooze
+func (dao ReminderDAO) UpdateLastSentOn(ctx context.Context, userId int64, lastSentOn time.Time) error {
+	sql := `
+		UPDATE reminder
+		SET last_sent_on = $2::date, snoozed_until = NULL, update_time = NOW()
+		WHERE user_id = $1
+		`
+	_, err := dao.db.Exec(ctx, sql, userId, lastSentOn.Format(time.DateOnly))
+	if err != nil {
+		return err
+	}
+	return nil
+}
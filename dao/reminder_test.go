@@ -0,0 +1,110 @@
+//go:build integration
+
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+func TestReminderDAO_UpsertAndGetByUserId(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+
+	dao := NewReminderDAO(testPool)
+
+	quietStart, quietEnd := 23*60, 7*60
+	err := dao.Upsert(ctx, entity.Reminder{
+		UserId: 100, ChatId: 200, Enabled: true, RemindAt: 21 * 60, QuietStart: &quietStart, QuietEnd: &quietEnd,
+	})
+	if err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	got, err := dao.GetByUserId(ctx, 100)
+	if err != nil {
+		t.Fatalf("GetByUserId: %v", err)
+	}
+	if got == nil {
+		t.Fatal("expected reminder, got nil")
+	}
+	if got.ChatId != 200 || got.RemindAt != 21*60 || !got.Enabled {
+		t.Errorf("unexpected reminder %+v", got)
+	}
+	if got.QuietStart == nil || *got.QuietStart != quietStart {
+		t.Errorf("QuietStart = %v, want %d", got.QuietStart, quietStart)
+	}
+	if got.Timezone != "Asia/Singapore" {
+		t.Errorf("Timezone = %s, want Asia/Singapore (from JOIN)", got.Timezone)
+	}
+
+	// updating the same user replaces the settings
+	err = dao.Upsert(ctx, entity.Reminder{UserId: 100, ChatId: 200, Enabled: false, RemindAt: 20 * 60})
+	if err != nil {
+		t.Fatalf("Upsert again: %v", err)
+	}
+	got, _ = dao.GetByUserId(ctx, 100)
+	if got.Enabled || got.RemindAt != 20*60 || got.QuietStart != nil {
+		t.Errorf("unexpected reminder after update %+v", got)
+	}
+}
+
+func TestReminderDAO_GetByUserId_NotFound(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+
+	got, err := NewReminderDAO(testPool).GetByUserId(ctx, 99999)
+	if err != nil {
+		t.Fatalf("GetByUserId: %v", err)
+	}
+	if got != nil {
+		t.Errorf("expected nil, got %+v", got)
+	}
+}
+
+func TestReminderDAO_SnoozeAndMarkSent(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+	seedUser(t, ctx, 101)
+
+	dao := NewReminderDAO(testPool)
+	if err := dao.Upsert(ctx, entity.Reminder{UserId: 100, ChatId: 100, Enabled: true, RemindAt: 21 * 60}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if err := dao.Upsert(ctx, entity.Reminder{UserId: 101, ChatId: 101, Enabled: false, RemindAt: 21 * 60}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	enabled, err := dao.FindAllEnabled(ctx)
+	if err != nil {
+		t.Fatalf("FindAllEnabled: %v", err)
+	}
+	if len(enabled) != 1 || enabled[0].UserId != 100 {
+		t.Fatalf("expected only user 100 enabled, got %+v", enabled)
+	}
+
+	snoozedUntil := time.Date(2024, 6, 15, 14, 0, 0, 0, time.UTC)
+	if err := dao.UpdateSnoozedUntil(ctx, 100, snoozedUntil); err != nil {
+		t.Fatalf("UpdateSnoozedUntil: %v", err)
+	}
+	got, _ := dao.GetByUserId(ctx, 100)
+	if got.SnoozedUntil == nil || !got.SnoozedUntil.Equal(snoozedUntil) {
+		t.Errorf("SnoozedUntil = %v, want %v", got.SnoozedUntil, snoozedUntil)
+	}
+
+	if err := dao.UpdateLastSentOn(ctx, 100, snoozedUntil); err != nil {
+		t.Fatalf("UpdateLastSentOn: %v", err)
+	}
+	got, _ = dao.GetByUserId(ctx, 100)
+	if got.SnoozedUntil != nil {
+		t.Errorf("expected snooze to be cleared, got %v", got.SnoozedUntil)
+	}
+	if got.LastSentOn == nil || got.LastSentOn.Format(time.DateOnly) != "2024-06-15" {
+		t.Errorf("LastSentOn = %v, want 2024-06-15", got.LastSentOn)
+	}
+}
@@ -3,13 +3,40 @@ package dao
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/aattwwss/telegram-expense-bot/audit"
 	"github.com/aattwwss/telegram-expense-bot/entity"
 	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// transactionAuditValue is the json of the values of the transaction t recorded in the audit log
+const transactionAuditValue = `jsonb_build_object(
+		'datetime', t.datetime, 'category_id', t.category_id,
+		'category_name', (SELECT name FROM category WHERE id = t.category_id),
+		'description', t.description, 'amount', t.amount, 'currency', t.currency, 'account_id', t.account_id,
+		'account_name', (SELECT name FROM account WHERE id = t.account_id))`
+
+// transactionRefunded is the sum of the refunds of the transaction t that are not deleted
+const transactionRefunded = `(SELECT COALESCE(sum(r.amount), 0)
+		FROM transaction r
+		WHERE r.refund_of = t.id AND r.deleted_time IS NULL)`
+
+// transactionsRestoredWith selects the ids of the transactions in the trash restored along with the transaction of the
+// id: the transaction itself and, while its purchase is in the trash, the purchase with the refunds deleted along with
+// it, so a refund never counts without its purchase
+func transactionsRestoredWith(id string) string {
+	return `SELECT g.id
+		FROM transaction x
+		JOIN transaction p ON p.id = COALESCE(x.refund_of, x.id)
+		JOIN transaction g ON g.user_id = x.user_id AND g.deleted_time IS NOT NULL
+		 AND (g.id = x.id OR (p.deleted_time IS NOT NULL
+		                      AND (g.id = p.id OR (g.refund_of = p.id AND g.deleted_time = p.deleted_time))))
+		WHERE x.id = ` + id + ` AND x.deleted_time IS NOT NULL`
+}
+
 type TransactionDAO struct {
 	db *pgxpool.Pool
 }
@@ -21,16 +48,18 @@ func NewTransactionDao(db *pgxpool.Pool) TransactionDAO {
 func (dao TransactionDAO) GetById(ctx context.Context, id int, userId int64) (entity.Transaction, error) {
 	var transactions []*entity.Transaction
 	sql := `
-			SELECT t.id, t.datetime, t.category_id, t.description, t.user_id, t.amount, t.currency, c.name as category_name
+			SELECT t.id, t.datetime, t.category_id, t.description, t.user_id, t.amount, t.currency, c.name as category_name,
+			       t.account_id, a.name as account_name, t.refund_of, ` + transactionRefunded + ` as refunded
 			FROM transaction t JOIN category c on t.category_id = c.id
-			WHERE t.id = $1 and t.user_id = $2
+			LEFT JOIN account a on t.account_id = a.id
+			WHERE t.id = $1 and t.user_id = $2 AND t.deleted_time IS NULL
 			`
 	err := pgxscan.Select(ctx, dao.db, &transactions, sql, id, userId)
 	if err != nil {
 		return entity.Transaction{}, err
 	}
 	if len(transactions) == 0 {
-		return entity.Transaction{}, fmt.Errorf("transaction not found: id=%d userId=%d", id, userId)
+		return entity.Transaction{}, fmt.Errorf("transaction %w: id=%d userId=%d", entity.ErrNotFound, id, userId)
 	}
 	return *transactions[0], nil
 }
@@ -38,9 +67,9 @@ func (dao TransactionDAO) GetById(ctx context.Context, id int, userId int64) (en
 func (dao TransactionDAO) FindLatestByUserId(ctx context.Context, userId int64) (*entity.Transaction, error) {
 	var transactions []*entity.Transaction
 	sql := `
-			SELECT id, datetime, category_id, description, user_id, amount, currency 
+			SELECT id, datetime, category_id, description, user_id, amount, currency, account_id
 			FROM transaction 
-			WHERE user_id = $1
+			WHERE user_id = $1 AND deleted_time IS NULL
 			ORDER BY datetime DESC LIMIT 1;
 			`
 	err := pgxscan.Select(ctx, dao.db, &transactions, sql, userId)
@@ -54,50 +83,303 @@ func (dao TransactionDAO) FindLatestByUserId(ctx context.Context, userId int64)
 
 }
 
-func (dao TransactionDAO) Insert(ctx context.Context, transaction entity.Transaction) error {
+// Insert adds the transaction with its category lines when it is split, and records its creation in the audit log.
+// A refund must be of a purchase of the same user, which the caller checks.
+func (dao TransactionDAO) Insert(ctx context.Context, transaction entity.Transaction) (int, error) {
+	var lastInsertId int
+	origin := audit.FromContext(ctx)
+	splitCategoryIds, splitAmounts := []int{}, []int64{}
+	for _, s := range transaction.Splits {
+		splitCategoryIds = append(splitCategoryIds, s.CategoryId)
+		splitAmounts = append(splitAmounts, s.Amount)
+	}
+	sql := `
+		WITH inserted AS (
+			INSERT INTO transaction AS t (datetime, category_id, description, user_id, amount, currency, account_id, refund_of)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $12)
+			RETURNING t.id, t.user_id, ` + transactionAuditValue + ` AS value
+		), audited AS (
+			INSERT INTO transaction_audit (transaction_id, user_id, action, after, actor, source)
+			SELECT id, user_id, 'create', value, $8, $9 FROM inserted
+		), splits AS (
+			INSERT INTO transaction_split (transaction_id, category_id, amount)
+			SELECT inserted.id, s.category_id, s.amount
+			FROM inserted, unnest($10::integer[], $11::bigint[]) AS s(category_id, amount)
+		)
+		SELECT id FROM inserted
+		`
+	err := dao.db.QueryRow(ctx, sql, transaction.Datetime, transaction.CategoryId, transaction.Description, transaction.UserId, transaction.Amount, transaction.Currency, transaction.AccountId, origin.Actor, string(origin.Source), splitCategoryIds, splitAmounts, transaction.RefundOf).Scan(&lastInsertId)
+	if err != nil {
+		return 0, err
+	}
+	return lastInsertId, nil
+}
+
+// Update changes the transaction and records its values before and after in the audit log. A split transaction whose
+// amount or category is changed is no longer split.
+func (dao TransactionDAO) Update(ctx context.Context, transaction entity.Transaction) error {
+	origin := audit.FromContext(ctx)
 	sql := `
-		INSERT INTO transaction (datetime, category_id, description, user_id, amount, currency)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		WITH before AS (
+			SELECT t.id, ` + transactionAuditValue + ` AS value
+			FROM transaction t
+			WHERE t.id = $1 AND t.user_id = $2 AND t.deleted_time IS NULL
+			FOR UPDATE
+		), updated AS (
+			UPDATE transaction AS t
+			SET datetime = $3, category_id = $4, description = $5, amount = $6, currency = $7, account_id = $8
+			FROM before
+			WHERE t.id = before.id
+			RETURNING t.id, t.user_id, before.value AS before_value, ` + transactionAuditValue + ` AS after_value
+		), unsplit AS (
+			DELETE FROM transaction_split s
+			USING before
+			WHERE s.transaction_id = before.id
+			  AND ((before.value->>'amount')::bigint <> $6 OR (before.value->>'category_id')::integer <> $4)
+		)
+		INSERT INTO transaction_audit (transaction_id, user_id, action, before, after, actor, source)
+		SELECT id, user_id, 'update', before_value, after_value, $9, $10 FROM updated
 		`
-	_, err := dao.db.Exec(ctx, sql, transaction.Datetime, transaction.CategoryId, transaction.Description, transaction.UserId, transaction.Amount, transaction.Currency)
+	tag, err := dao.db.Exec(ctx, sql, transaction.Id, transaction.UserId, transaction.Datetime, transaction.CategoryId, transaction.Description, transaction.Amount, transaction.Currency, transaction.AccountId, origin.Actor, string(origin.Source))
 	if err != nil {
 		return err
 	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("transaction %w: id=%d userId=%d", entity.ErrNotFound, transaction.Id, transaction.UserId)
+	}
 	return nil
 }
 
+// DeleteById moves the transaction to the trash, from which it can be restored until it is purged. The refunds of a
+// purchase are moved along with it, so they are not netted against a purchase that no longer counts.
 func (dao TransactionDAO) DeleteById(ctx context.Context, id int, userId int64) error {
+	origin := audit.FromContext(ctx)
 	sql := `
-			DELETE FROM transaction 
-			WHERE id = $1 AND user_id = $2 ;
+			WITH deleted AS (
+				UPDATE transaction AS t
+				SET deleted_time = NOW()
+				WHERE t.user_id = $2 AND t.deleted_time IS NULL
+				  AND (t.id = $1 OR (t.refund_of = $1 AND EXISTS (SELECT 1
+				                                                  FROM transaction p
+				                                                  WHERE p.id = $1 AND p.deleted_time IS NULL)))
+				RETURNING t.id, t.user_id, ` + transactionAuditValue + ` AS value
+			)
+			INSERT INTO transaction_audit (transaction_id, user_id, action, before, actor, source)
+			SELECT id, user_id, 'delete', value, $3, $4 FROM deleted
 		`
-	_, err := dao.db.Exec(ctx, sql, id, userId)
+	_, err := dao.db.Exec(ctx, sql, id, userId, origin.Actor, string(origin.Source))
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (dao TransactionDAO) GetBreakdownByCategory(ctx context.Context, dateFrom time.Time, dateTo time.Time, userId int64) ([]entity.TransactionBreakdown, error) {
-	var entities []entity.TransactionBreakdown
+// FindDeletedByUserId lists the transactions in the trash of the user, the most recently deleted first
+func (dao TransactionDAO) FindDeletedByUserId(ctx context.Context, userId int64, limit int) ([]entity.Transaction, error) {
+	var entities []entity.Transaction
 	sql := `
-			SELECT c.name as     category_name,
-			       sum(t.amount) amount
+			SELECT t.id, t.datetime, t.category_id, t.description, t.user_id, t.amount, t.currency, c.name as category_name,
+			       t.account_id, a.name as account_name, t.deleted_time
 			FROM transaction t JOIN category c on t.category_id = c.id
-			WHERE datetime >= $1::timestamptz
-			AND datetime < $2::timestamptz
-			AND user_id = $3
+			LEFT JOIN account a on t.account_id = a.id
+			WHERE t.user_id = $1 AND t.deleted_time IS NOT NULL
+			ORDER BY t.deleted_time DESC, t.id DESC
+			LIMIT $2
+			`
+	err := pgxscan.Select(ctx, dao.db, &entities, sql, userId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// Restore takes the transaction out of the trash, along with the refunds deleted with a purchase or the purchase of a
+// refund that is in the trash
+func (dao TransactionDAO) Restore(ctx context.Context, id int, userId int64) error {
+	origin := audit.FromContext(ctx)
+	sql := `
+		WITH restored AS (
+			UPDATE transaction AS t
+			SET deleted_time = NULL
+			WHERE t.user_id = $2 AND t.id IN (` + transactionsRestoredWith("$1") + `)
+			RETURNING t.id, t.user_id, ` + transactionAuditValue + ` AS value
+		)
+		INSERT INTO transaction_audit (transaction_id, user_id, action, after, actor, source)
+		SELECT id, user_id, 'restore', value, $3, $4 FROM restored
+		`
+	tag, err := dao.db.Exec(ctx, sql, id, userId, origin.Actor, string(origin.Source))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("deleted transaction %w: id=%d userId=%d", entity.ErrNotFound, id, userId)
+	}
+	return nil
+}
+
+// RestoreLatest takes the most recently deleted transaction of the user out of the trash as Restore does, and returns
+// its id or 0 when the trash is empty. Of a purchase deleted along with its refunds, the purchase is returned.
+func (dao TransactionDAO) RestoreLatest(ctx context.Context, userId int64) (int, error) {
+	var ids []int
+	origin := audit.FromContext(ctx)
+	sql := `
+		WITH latest AS (
+			SELECT id
+			FROM transaction
+			WHERE user_id = $1 AND deleted_time IS NOT NULL
+			ORDER BY deleted_time DESC, refund_of IS NULL DESC, id DESC
+			LIMIT 1
+		), restored AS (
+			UPDATE transaction AS t
+			SET deleted_time = NULL
+			WHERE t.user_id = $1 AND t.id IN (` + transactionsRestoredWith("(SELECT id FROM latest)") + `)
+			RETURNING t.id, t.user_id, ` + transactionAuditValue + ` AS value
+		), audited AS (
+			INSERT INTO transaction_audit (transaction_id, user_id, action, after, actor, source)
+			SELECT id, user_id, 'restore', value, $2, $3 FROM restored
+		)
+		SELECT id FROM restored WHERE id = (SELECT id FROM latest)
+		`
+	err := pgxscan.Select(ctx, dao.db, &ids, sql, userId, origin.Actor, string(origin.Source))
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return ids[0], nil
+}
+
+// PurgeDeleted deletes for good the transactions moved to the trash before the time, and returns how many it deleted.
+// Their history stays in the audit log. A purchase is only deleted along with its refunds, so it stays in the trash
+// while any of them is kept and no refund loses the purchase it is of.
+func (dao TransactionDAO) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	origin := audit.FromContext(ctx)
+	sql := `
+		WITH purged AS (
+			DELETE FROM transaction AS t
+			WHERE t.deleted_time IS NOT NULL AND t.deleted_time < $1
+			  AND NOT EXISTS (SELECT 1
+			                  FROM transaction r
+			                  WHERE r.refund_of = t.id
+			                    AND (r.deleted_time IS NULL OR r.deleted_time >= $1))
+			RETURNING t.id, t.user_id, ` + transactionAuditValue + ` AS value
+		)
+		INSERT INTO transaction_audit (transaction_id, user_id, action, before, actor, source)
+		SELECT id, user_id, 'purge', value, $2, $3 FROM purged
+		`
+	tag, err := dao.db.Exec(ctx, sql, before, origin.Actor, string(origin.Source))
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// FindSplitsByTransactionIds lists the category lines of the transactions that are split, the largest first
+func (dao TransactionDAO) FindSplitsByTransactionIds(ctx context.Context, ids []int) ([]entity.TransactionSplit, error) {
+	var entities []entity.TransactionSplit
+	sql := `
+			SELECT s.id, s.transaction_id, s.category_id, c.name as category_name, s.amount
+			FROM transaction_split s JOIN category c on s.category_id = c.id
+			WHERE s.transaction_id = ANY($1)
+			ORDER BY s.transaction_id, s.amount DESC, s.id
+			`
+	err := pgxscan.Select(ctx, dao.db, &entities, sql, ids)
+	if err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// FindRefundableByUserId lists up to limit of the latest purchases of the user that are not refunded in full, with the
+// sum of their refunds. A split purchase cannot be refunded, so it is left out.
+func (dao TransactionDAO) FindRefundableByUserId(ctx context.Context, userId int64, limit int) ([]entity.Transaction, error) {
+	var entities []entity.Transaction
+	sql := `
+			SELECT *
+			FROM (SELECT t.id, t.datetime, t.category_id, t.description, t.user_id, t.amount, t.currency,
+			             c.name as category_name, t.account_id, ` + transactionRefunded + ` as refunded
+			      FROM transaction t JOIN category c on t.category_id = c.id
+			      WHERE t.user_id = $1 AND t.deleted_time IS NULL AND t.refund_of IS NULL AND t.amount > 0
+			        AND NOT EXISTS (SELECT 1 FROM transaction_split s WHERE s.transaction_id = t.id)) t
+			WHERE t.amount + t.refunded > 0
+			ORDER BY t.datetime DESC, t.id DESC
+			LIMIT $2
+			`
+	err := pgxscan.Select(ctx, dao.db, &entities, sql, userId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// FindAuditByTransactionId lists the changes to the transaction of the user, the oldest first
+func (dao TransactionDAO) FindAuditByTransactionId(ctx context.Context, id int, userId int64) ([]entity.TransactionAudit, error) {
+	var entities []entity.TransactionAudit
+	sql := `
+			SELECT id, transaction_id, user_id, action, before, after, actor, source, create_time
+			FROM transaction_audit
+			WHERE transaction_id = $1 AND user_id = $2
+			ORDER BY id
+			`
+	err := pgxscan.Select(ctx, dao.db, &entities, sql, id, userId)
+	if err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// FindAuditByUserId lists the changes to the transactions of the user made from dateFrom up to but excluding dateTo,
+// the oldest first
+func (dao TransactionDAO) FindAuditByUserId(ctx context.Context, userId int64, dateFrom time.Time, dateTo time.Time) ([]entity.TransactionAudit, error) {
+	var entities []entity.TransactionAudit
+	sql := `
+			SELECT id, transaction_id, user_id, action, before, after, actor, source, create_time
+			FROM transaction_audit
+			WHERE user_id = $1
+			  AND create_time >= $2::timestamptz
+			  AND create_time < $3::timestamptz
+			ORDER BY id
+			`
+	err := pgxscan.Select(ctx, dao.db, &entities, sql, userId, dateFrom.Format(time.RFC3339), dateTo.Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// GetBreakdownByCategory sums the transactions of each category from dateFrom up to but excluding dateTo. A refund
+// counts under the category of its purchase, in the month of the purchase when refundsByPurchase is true or else in
+// the month it was refunded, and not at all while its purchase is in the trash. A category netted to 0 is left out.
+func (dao TransactionDAO) GetBreakdownByCategory(ctx context.Context, dateFrom time.Time, dateTo time.Time, userId int64, refundsByPurchase bool) ([]entity.TransactionBreakdown, error) {
+	var entities []entity.TransactionBreakdown
+	// a split transaction counts its lines under their own categories instead of its amount under its category
+	sql := `
+			SELECT c.name as                         category_name,
+			       sum(COALESCE(s.amount, t.amount)) amount
+			FROM transaction t
+			LEFT JOIN transaction o on o.id = t.refund_of
+			LEFT JOIN transaction_split s on s.transaction_id = t.id
+			JOIN category c on COALESCE(s.category_id, o.category_id, t.category_id) = c.id
+			WHERE COALESCE(CASE WHEN $4 THEN o.datetime END, t.datetime) >= $1::timestamptz
+			AND COALESCE(CASE WHEN $4 THEN o.datetime END, t.datetime) < $2::timestamptz
+			AND t.user_id = $3
+			AND t.deleted_time IS NULL
+			AND (o.id IS NULL OR o.deleted_time IS NULL)
 			GROUP BY c.name
+			HAVING sum(COALESCE(s.amount, t.amount)) <> 0
 			ORDER BY amount DESC;
 		`
-	err := pgxscan.Select(ctx, dao.db, &entities, sql, dateFrom.Format(time.RFC3339), dateTo.Format(time.RFC3339), userId)
+	err := pgxscan.Select(ctx, dao.db, &entities, sql, dateFrom.Format(time.RFC3339), dateTo.Format(time.RFC3339), userId, refundsByPurchase)
 	if err != nil {
 		return nil, err
 	}
 	return entities, nil
 }
 
-func (dao TransactionDAO) ListByMonthAndYear(ctx context.Context, dateFrom time.Time, dateTo time.Time, offset int, limit int, isAsc bool, userId int64) ([]entity.Transaction, error) {
+// ListByMonthAndYear lists the transactions from dateFrom up to but excluding dateTo, of the account or of all accounts
+// when accountId is 0
+func (dao TransactionDAO) ListByMonthAndYear(ctx context.Context, dateFrom time.Time, dateTo time.Time, offset int, limit int, isAsc bool, userId int64, accountId int) ([]entity.Transaction, error) {
 	sortOrder := "DESC"
 	if isAsc {
 		sortOrder = "ASC"
@@ -105,22 +387,26 @@ func (dao TransactionDAO) ListByMonthAndYear(ctx context.Context, dateFrom time.
 
 	var entities []entity.Transaction
 	sql := `
-			SELECT t.id, t.datetime, t.category_id, t.description, t.user_id, t.amount, t.currency, c.name as category_name
+			SELECT t.id, t.datetime, t.category_id, t.description, t.user_id, t.amount, t.currency, c.name as category_name,
+			       t.account_id, a.name as account_name, t.refund_of
 			FROM transaction t JOIN category c on t.category_id = c.id
+			LEFT JOIN account a on t.account_id = a.id
 		    WHERE t.datetime >= $1::timestamptz
 			  AND t.datetime < $2::timestamptz
 			  AND t.user_id = $3
+			  AND t.deleted_time IS NULL
+			  AND ($6::integer = 0 OR t.account_id = $6::integer)
 		    ORDER BY t.datetime ` + sortOrder + `
 			OFFSET $4 LIMIT $5
 		`
-	err := pgxscan.Select(ctx, dao.db, &entities, sql, dateFrom.Format(time.RFC3339), dateTo.Format(time.RFC3339), userId, offset, limit)
+	err := pgxscan.Select(ctx, dao.db, &entities, sql, dateFrom.Format(time.RFC3339), dateTo.Format(time.RFC3339), userId, offset, limit, accountId)
 	if err != nil {
 		return nil, err
 	}
 	return entities, nil
 }
 
-func (dao TransactionDAO) CountListByMonthAndYear(ctx context.Context, dateFrom time.Time, dateTo time.Time, userId int64) (int, error) {
+func (dao TransactionDAO) CountListByMonthAndYear(ctx context.Context, dateFrom time.Time, dateTo time.Time, userId int64, accountId int) (int, error) {
 	var count int
 	sql := `
 			SELECT COUNT(*) 
@@ -128,8 +414,63 @@ func (dao TransactionDAO) CountListByMonthAndYear(ctx context.Context, dateFrom
 		    WHERE t.datetime >= $1::timestamptz
 			  AND t.datetime < $2::timestamptz
 			  AND t.user_id = $3
+			  AND t.deleted_time IS NULL
+			  AND ($4::integer = 0 OR t.account_id = $4::integer)
 		`
-	err := dao.db.QueryRow(ctx, sql, dateFrom.Format(time.RFC3339), dateTo.Format(time.RFC3339), userId).Scan(&count)
+	err := dao.db.QueryRow(ctx, sql, dateFrom.Format(time.RFC3339), dateTo.Format(time.RFC3339), userId, accountId).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// transactionFilterCondition builds the WHERE clause of the filter, numbering the placeholders from 1
+func transactionFilterCondition(filter entity.TransactionFilter) (string, []any) {
+	conditions := []string{"t.user_id = $1", "t.deleted_time IS NULL"}
+	args := []any{filter.UserId}
+	if !filter.DateFrom.IsZero() {
+		args = append(args, filter.DateFrom.Format(time.RFC3339))
+		conditions = append(conditions, fmt.Sprintf("t.datetime >= $%d::timestamptz", len(args)))
+	}
+	if !filter.DateTo.IsZero() {
+		args = append(args, filter.DateTo.Format(time.RFC3339))
+		conditions = append(conditions, fmt.Sprintf("t.datetime < $%d::timestamptz", len(args)))
+	}
+	if filter.CategoryId != 0 {
+		args = append(args, filter.CategoryId)
+		conditions = append(conditions, fmt.Sprintf("t.category_id = $%d", len(args)))
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+func (dao TransactionDAO) List(ctx context.Context, filter entity.TransactionFilter) ([]entity.Transaction, error) {
+	condition, args := transactionFilterCondition(filter)
+	args = append(args, filter.Offset, filter.Limit)
+
+	var entities []entity.Transaction
+	sql := fmt.Sprintf(`
+			SELECT t.id, t.datetime, t.category_id, t.description, t.user_id, t.amount, t.currency, c.name as category_name
+			FROM transaction t JOIN category c on t.category_id = c.id
+			WHERE %s
+			ORDER BY t.datetime DESC, t.id DESC
+			OFFSET $%d LIMIT $%d
+		`, condition, len(args)-1, len(args))
+	err := pgxscan.Select(ctx, dao.db, &entities, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (dao TransactionDAO) Count(ctx context.Context, filter entity.TransactionFilter) (int, error) {
+	condition, args := transactionFilterCondition(filter)
+
+	var count int
+	sql := `
+			SELECT COUNT(*)
+			FROM transaction t
+			WHERE ` + condition
+	err := dao.db.QueryRow(ctx, sql, args...).Scan(&count)
 	if err != nil {
 		return 0, err
 	}
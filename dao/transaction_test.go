@@ -4,9 +4,12 @@ package dao
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/aattwwss/telegram-expense-bot/audit"
 	"github.com/aattwwss/telegram-expense-bot/entity"
 )
 
@@ -22,7 +25,7 @@ func seedUser(t *testing.T, ctx context.Context, id int64) {
 
 func insertTxn(t *testing.T, ctx context.Context, dao TransactionDAO, dt time.Time, catId int, desc string, userId int64, amount int64, currency string) int {
 	t.Helper()
-	err := dao.Insert(ctx, entity.Transaction{
+	_, err := dao.Insert(ctx, entity.Transaction{
 		Datetime:   dt,
 		CategoryId: catId,
 		Description: desc,
@@ -156,7 +159,7 @@ func TestTransactionDAO_CountAndListByMonthAndYear(t *testing.T) {
 	dateFrom := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
 	dateTo := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
 
-	count, err := dao.CountListByMonthAndYear(ctx, dateFrom, dateTo, 100)
+	count, err := dao.CountListByMonthAndYear(ctx, dateFrom, dateTo, 100, 0)
 	if err != nil {
 		t.Fatalf("CountListByMonthAndYear: %v", err)
 	}
@@ -165,7 +168,7 @@ func TestTransactionDAO_CountAndListByMonthAndYear(t *testing.T) {
 	}
 
 	// Test pagination: offset 0, limit 2
-	results, err := dao.ListByMonthAndYear(ctx, dateFrom, dateTo, 0, 2, false, 100)
+	results, err := dao.ListByMonthAndYear(ctx, dateFrom, dateTo, 0, 2, false, 100, 0)
 	if err != nil {
 		t.Fatalf("ListByMonthAndYear: %v", err)
 	}
@@ -178,7 +181,7 @@ func TestTransactionDAO_CountAndListByMonthAndYear(t *testing.T) {
 	}
 
 	// Test ascending order
-	ascResults, err := dao.ListByMonthAndYear(ctx, dateFrom, dateTo, 0, 10, true, 100)
+	ascResults, err := dao.ListByMonthAndYear(ctx, dateFrom, dateTo, 0, 10, true, 100, 0)
 	if err != nil {
 		t.Fatalf("ListByMonthAndYear asc: %v", err)
 	}
@@ -204,7 +207,7 @@ func TestTransactionDAO_GetBreakdownByCategory(t *testing.T) {
 	dateFrom := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
 	dateTo := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
 
-	breakdowns, err := dao.GetBreakdownByCategory(ctx, dateFrom, dateTo, 100)
+	breakdowns, err := dao.GetBreakdownByCategory(ctx, dateFrom, dateTo, 100, true)
 	if err != nil {
 		t.Fatalf("GetBreakdownByCategory: %v", err)
 	}
@@ -225,3 +228,374 @@ func TestTransactionDAO_GetBreakdownByCategory(t *testing.T) {
 		t.Errorf("Transport amount = %d, want 200", breakdowns[1].Amount)
 	}
 }
+
+func TestTransactionDAO_Update(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+	dao := NewTransactionDao(testPool)
+
+	id := insertTxn(t, ctx, dao, time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC), 4, "lunch", 100, 500, "SGD")
+
+	err := dao.Update(ctx, entity.Transaction{
+		Id: id, UserId: 100, Datetime: time.Date(2024, 6, 2, 10, 0, 0, 0, time.UTC), CategoryId: 13, Description: "bus", Amount: 200, Currency: "SGD",
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	got, _ := dao.GetById(ctx, id, 100)
+	if got.CategoryName != "Transport" || got.Description != "bus" || got.Amount != 200 {
+		t.Errorf("unexpected transaction after update %+v", got)
+	}
+
+	// another user cannot update the transaction
+	err = dao.Update(ctx, entity.Transaction{Id: id, UserId: 999, Datetime: got.Datetime, CategoryId: 4, Amount: 1, Currency: "SGD"})
+	if !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestTransactionDAO_ListAndCount(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+	seedUser(t, ctx, 200)
+	dao := NewTransactionDao(testPool)
+
+	insertTxn(t, ctx, dao, time.Date(2024, 5, 31, 10, 0, 0, 0, time.UTC), 4, "may", 100, 100, "SGD")
+	insertTxn(t, ctx, dao, time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC), 4, "lunch", 100, 500, "SGD")
+	insertTxn(t, ctx, dao, time.Date(2024, 6, 2, 10, 0, 0, 0, time.UTC), 13, "bus", 100, 200, "SGD")
+	insertTxn(t, ctx, dao, time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC), 4, "other user", 200, 300, "SGD")
+
+	filter := entity.TransactionFilter{
+		UserId:   100,
+		DateFrom: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		DateTo:   time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		Limit:    10,
+	}
+	results, err := dao.List(ctx, filter)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(results) != 2 || results[0].Description != "bus" || results[1].Description != "lunch" {
+		t.Errorf("unexpected results %+v", results)
+	}
+
+	filter.CategoryId = 4
+	count, err := dao.Count(ctx, filter)
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("count = %d, want 1", count)
+	}
+
+	all, _ := dao.Count(ctx, entity.TransactionFilter{UserId: 100})
+	if all != 3 {
+		t.Errorf("count without filters = %d, want 3", all)
+	}
+}
+
+func TestTransactionDAO_TrashRestoreAndPurge(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+
+	dao := NewTransactionDao(testPool)
+	dt := time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC)
+	first := insertTxn(t, ctx, dao, dt, 1, "first", 100, 300, "SGD")
+	second := insertTxn(t, ctx, dao, dt.Add(time.Hour), 1, "second", 100, 500, "SGD")
+
+	dao.DeleteById(ctx, second, 100)
+	dao.DeleteById(ctx, first, 100)
+
+	if latest, _ := dao.FindLatestByUserId(ctx, 100); latest != nil {
+		t.Errorf("expected no transaction left to undo, got %+v", latest)
+	}
+	if count, _ := dao.CountListByMonthAndYear(ctx, dt.AddDate(0, 0, -1), dt.AddDate(0, 0, 1), 100, 0); count != 0 {
+		t.Errorf("expected the deleted transactions not counted, got %d", count)
+	}
+	deleted, err := dao.FindDeletedByUserId(ctx, 100, 10)
+	if err != nil || len(deleted) != 2 || deleted[0].Id != first || deleted[0].DeletedTime == nil {
+		t.Fatalf("expected the most recently deleted first, got %+v: %v", deleted, err)
+	}
+
+	if id, err := dao.RestoreLatest(ctx, 100); err != nil || id != first {
+		t.Errorf("expected %d restored, got %d: %v", first, id, err)
+	}
+	if err := dao.Restore(ctx, first, 100); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("expected ErrNotFound restoring a transaction not in the trash, got %v", err)
+	}
+
+	if purged, err := dao.PurgeDeleted(ctx, time.Now().Add(time.Minute)); err != nil || purged != 1 {
+		t.Errorf("expected 1 purged, got %d: %v", purged, err)
+	}
+	if err := dao.Restore(ctx, second, 100); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("expected a purged transaction gone for good, got %v", err)
+	}
+}
+
+func TestTransactionDAO_AuditJob(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+
+	dao := NewTransactionDao(testPool)
+	id := insertTxn(t, audit.WithJob(ctx, "conversation"), dao, time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC), 1, "lunch", 100, 300, "SGD")
+
+	entries, err := dao.FindAuditByTransactionId(ctx, id, 100)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected the creation audited, got %+v, %v", entries, err)
+	}
+	if entries[0].Actor != "job:conversation" || entries[0].Source != "job" {
+		t.Errorf("expected a transaction saved by a timeout audited as the conversation job, got %+v", entries[0])
+	}
+}
+
+func TestTransactionDAO_Audit(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+
+	dao := NewTransactionDao(testPool)
+	dt := time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC)
+	id := insertTxn(t, ctx, dao, dt, 1, "lunch", 100, 300, "SGD")
+
+	apiCtx := audit.WithSource(ctx, audit.SourceApi)
+	err := dao.Update(apiCtx, entity.Transaction{Id: id, Datetime: dt, CategoryId: 1, Description: "dinner", UserId: 100, Amount: 450, Currency: "SGD"})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	dao.DeleteById(ctx, id, 100)
+	dao.RestoreLatest(ctx, 100)
+	dao.DeleteById(ctx, id, 100)
+	if _, err := dao.PurgeDeleted(audit.WithJob(ctx, "trash"), time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+
+	entries, err := dao.FindAuditByTransactionId(ctx, id, 100)
+	if err != nil {
+		t.Fatalf("find audit: %v", err)
+	}
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+	}
+	if strings.Join(actions, ",") != "create,update,delete,restore,delete,purge" {
+		t.Fatalf("expected the history kept after the purge, got %v", actions)
+	}
+
+	created, updated, purged := entries[0], entries[1], entries[5]
+	if created.Before != nil || created.After == nil || created.After.Description != "lunch" || created.After.CategoryName == "" || created.Actor != audit.ActorUser || created.Source != "bot" {
+		t.Errorf("unexpected create %+v", created)
+	}
+	if updated.Before == nil || updated.Before.Amount != 300 || updated.After == nil || updated.After.Amount != 450 || updated.After.Description != "dinner" || updated.Source != "api" {
+		t.Errorf("unexpected update %+v", updated)
+	}
+	if !updated.After.Datetime.Equal(dt) {
+		t.Errorf("expected the datetime kept in the snapshot, got %v", updated.After.Datetime)
+	}
+	if purged.After != nil || purged.Before == nil || purged.Actor != "job:trash" || purged.Source != "job" {
+		t.Errorf("unexpected purge %+v", purged)
+	}
+
+	if others, _ := dao.FindAuditByTransactionId(ctx, id, 200); len(others) != 0 {
+		t.Errorf("expected no history of another user's transaction, got %+v", others)
+	}
+	inPeriod, err := dao.FindAuditByUserId(ctx, 100, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil || len(inPeriod) != 6 {
+		t.Errorf("expected 6 changes in the period, got %d: %v", len(inPeriod), err)
+	}
+}
+
+func TestTransactionDAO_Split(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+	dao := NewTransactionDao(testPool)
+
+	dt := time.Date(2024, 6, 5, 10, 0, 0, 0, time.UTC)
+	id, err := dao.Insert(ctx, entity.Transaction{
+		Datetime:    dt,
+		CategoryId:  4,
+		Description: "NTUC",
+		UserId:      100,
+		Amount:      12000,
+		Currency:    "SGD",
+		Splits:      []entity.TransactionSplit{{CategoryId: 4, Amount: 8000}, {CategoryId: 13, Amount: 4000}},
+	})
+	if err != nil {
+		t.Fatalf("insert split: %v", err)
+	}
+	insertTxn(t, ctx, dao, dt, 13, "bus", 100, 200, "SGD")
+
+	splits, err := dao.FindSplitsByTransactionIds(ctx, []int{id})
+	if err != nil {
+		t.Fatalf("find splits: %v", err)
+	}
+	if len(splits) != 2 || splits[0].CategoryName != "Food" || splits[0].Amount != 8000 || splits[1].CategoryName != "Transport" || splits[1].Amount != 4000 {
+		t.Fatalf("unexpected splits %+v", splits)
+	}
+
+	breakdowns, err := dao.GetBreakdownByCategory(ctx, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), 100, true)
+	if err != nil {
+		t.Fatalf("GetBreakdownByCategory: %v", err)
+	}
+	if len(breakdowns) != 2 || breakdowns[0].CategoryName != "Food" || breakdowns[0].Amount != 8000 || breakdowns[1].CategoryName != "Transport" || breakdowns[1].Amount != 4200 {
+		t.Errorf("expected the split allocated to its categories, got %+v", breakdowns)
+	}
+
+	err = dao.Update(ctx, entity.Transaction{Id: id, Datetime: dt, CategoryId: 4, Description: "NTUC groceries", UserId: 100, Amount: 12000, Currency: "SGD"})
+	if err != nil {
+		t.Fatalf("update description: %v", err)
+	}
+	if splits, _ := dao.FindSplitsByTransactionIds(ctx, []int{id}); len(splits) != 2 {
+		t.Errorf("expected the split kept when only the description changes, got %+v", splits)
+	}
+	err = dao.Update(ctx, entity.Transaction{Id: id, Datetime: dt, CategoryId: 4, Description: "NTUC groceries", UserId: 100, Amount: 9000, Currency: "SGD"})
+	if err != nil {
+		t.Fatalf("update amount: %v", err)
+	}
+	if splits, _ := dao.FindSplitsByTransactionIds(ctx, []int{id}); len(splits) != 0 {
+		t.Errorf("expected the split dropped when the amount changes, got %+v", splits)
+	}
+}
+
+func TestTransactionDAO_Refund(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+	dao := NewTransactionDao(testPool)
+
+	// a purchase of Food in May refunded in part in June, under Transport as the refund takes the category of its purchase
+	purchaseId := insertTxn(t, ctx, dao, time.Date(2024, 5, 30, 10, 0, 0, 0, time.UTC), 4, "shoes", 100, 5000, "SGD")
+	insertTxn(t, ctx, dao, time.Date(2024, 6, 2, 10, 0, 0, 0, time.UTC), 4, "lunch", 100, 800, "SGD")
+	refundId, err := dao.Insert(ctx, entity.Transaction{
+		Datetime:    time.Date(2024, 6, 5, 10, 0, 0, 0, time.UTC),
+		CategoryId:  13,
+		Description: "shoes",
+		UserId:      100,
+		Amount:      -2000,
+		Currency:    "SGD",
+		RefundOf:    &purchaseId,
+	})
+	if err != nil {
+		t.Fatalf("insert refund: %v", err)
+	}
+
+	purchase, err := dao.GetById(ctx, purchaseId, 100)
+	if err != nil || purchase.Refunded != -2000 || purchase.RefundOf != nil {
+		t.Fatalf("expected the purchase read with its refund, got %+v: %v", purchase, err)
+	}
+	refund, _ := dao.GetById(ctx, refundId, 100)
+	if refund.RefundOf == nil || *refund.RefundOf != purchaseId {
+		t.Errorf("expected the refund linked to its purchase, got %+v", refund)
+	}
+
+	refundable, err := dao.FindRefundableByUserId(ctx, 100, 10)
+	if err != nil {
+		t.Fatalf("find refundable: %v", err)
+	}
+	if len(refundable) != 2 || refundable[0].Description != "lunch" || refundable[1].Id != purchaseId || refundable[1].Refunded != -2000 {
+		t.Errorf("expected the purchases with what is refunded of them, got %+v", refundable)
+	}
+
+	breakdowns := func(month time.Month, byPurchase bool) []entity.TransactionBreakdown {
+		from := time.Date(2024, month, 1, 0, 0, 0, 0, time.UTC)
+		got, err := dao.GetBreakdownByCategory(ctx, from, from.AddDate(0, 1, 0), 100, byPurchase)
+		if err != nil {
+			t.Fatalf("GetBreakdownByCategory: %v", err)
+		}
+		return got
+	}
+	if got := breakdowns(time.May, true); len(got) != 1 || got[0].CategoryName != "Food" || got[0].Amount != 3000 {
+		t.Errorf("expected the refund netted in the month of the purchase, got %+v", got)
+	}
+	if got := breakdowns(time.June, true); len(got) != 1 || got[0].Amount != 800 {
+		t.Errorf("expected no refund in the month it was refunded, got %+v", got)
+	}
+	if got := breakdowns(time.May, false); len(got) != 1 || got[0].Amount != 5000 {
+		t.Errorf("expected the purchase in full in its month, got %+v", got)
+	}
+	if got := breakdowns(time.June, false); len(got) != 1 || got[0].CategoryName != "Food" || got[0].Amount != -1200 {
+		t.Errorf("expected the refund netted against Food in the month it was refunded, got %+v", got)
+	}
+
+	dao.Insert(ctx, entity.Transaction{Datetime: time.Now(), CategoryId: 4, Description: "shoes", UserId: 100, Amount: -3000, Currency: "SGD", RefundOf: &purchaseId})
+	if refundable, _ := dao.FindRefundableByUserId(ctx, 100, 10); len(refundable) != 1 {
+		t.Errorf("expected the purchase refunded in full left out, got %+v", refundable)
+	}
+
+	dao.Insert(ctx, entity.Transaction{Datetime: time.Now(), CategoryId: 4, Description: "NTUC", UserId: 100, Amount: 12000, Currency: "SGD",
+		Splits: []entity.TransactionSplit{{CategoryId: 4, Amount: 8000}, {CategoryId: 13, Amount: 4000}}})
+	if refundable, _ := dao.FindRefundableByUserId(ctx, 100, 10); len(refundable) != 1 || refundable[0].Description != "lunch" {
+		t.Errorf("expected a split purchase left out, got %+v", refundable)
+	}
+}
+
+func TestTransactionDAO_TrashWithRefunds(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+	seedUser(t, ctx, 100)
+	dao := NewTransactionDao(testPool)
+
+	purchaseId := insertTxn(t, ctx, dao, time.Date(2024, 5, 30, 10, 0, 0, 0, time.UTC), 4, "shoes", 100, 5000, "SGD")
+	refundId, err := dao.Insert(ctx, entity.Transaction{Datetime: time.Date(2024, 6, 5, 10, 0, 0, 0, time.UTC), CategoryId: 4, Description: "shoes", UserId: 100, Amount: -2000, Currency: "SGD", RefundOf: &purchaseId})
+	if err != nil {
+		t.Fatalf("insert refund: %v", err)
+	}
+	june := func() []entity.TransactionBreakdown {
+		from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		got, err := dao.GetBreakdownByCategory(ctx, from, from.AddDate(0, 1, 0), 100, false)
+		if err != nil {
+			t.Fatalf("GetBreakdownByCategory: %v", err)
+		}
+		return got
+	}
+
+	dao.DeleteById(ctx, purchaseId, 100)
+	if deleted, _ := dao.FindDeletedByUserId(ctx, 100, 10); len(deleted) != 2 {
+		t.Fatalf("expected the refund moved to the trash along with its purchase, got %+v", deleted)
+	}
+	if got := june(); len(got) != 0 {
+		t.Errorf("expected no refund netted against a deleted purchase, got %+v", got)
+	}
+
+	if err := dao.Restore(ctx, refundId, 100); err != nil {
+		t.Fatalf("restore refund: %v", err)
+	}
+	if purchase, err := dao.GetById(ctx, purchaseId, 100); err != nil || purchase.Refunded != -2000 {
+		t.Errorf("expected the purchase restored along with its refund, got %+v: %v", purchase, err)
+	}
+
+	dao.DeleteById(ctx, purchaseId, 100)
+	if id, err := dao.RestoreLatest(ctx, 100); err != nil || id != purchaseId {
+		t.Errorf("expected the purchase restored rather than its refund, got %d: %v", id, err)
+	}
+	if refund, err := dao.GetById(ctx, refundId, 100); err != nil || refund.RefundOf == nil {
+		t.Errorf("expected the refund restored along with its purchase, got %+v: %v", refund, err)
+	}
+
+	// a purchase deleted without its refunds, as before they were moved along with it
+	if _, err := testPool.Exec(ctx, "UPDATE transaction SET deleted_time = NOW() WHERE id = $1", purchaseId); err != nil {
+		t.Fatalf("delete purchase alone: %v", err)
+	}
+	if got := june(); len(got) != 0 {
+		t.Errorf("expected no refund netted against a deleted purchase, got %+v", got)
+	}
+	if purged, err := dao.PurgeDeleted(ctx, time.Now().Add(time.Minute)); err != nil || purged != 0 {
+		t.Fatalf("expected a purchase with a refund kept in the trash, got %d: %v", purged, err)
+	}
+	if refund, _ := dao.GetById(ctx, refundId, 100); refund.RefundOf == nil || *refund.RefundOf != purchaseId {
+		t.Errorf("expected the refund still linked to its purchase, got %+v", refund)
+	}
+	if _, err := testPool.Exec(ctx, "DELETE FROM transaction WHERE id = $1", purchaseId); err == nil {
+		t.Errorf("expected deleting a purchase with a refund refused")
+	}
+
+	dao.DeleteById(ctx, refundId, 100)
+	if purged, err := dao.PurgeDeleted(ctx, time.Now().Add(time.Minute)); err != nil || purged != 2 {
+		t.Errorf("expected the purchase purged along with its refund, got %d: %v", purged, err)
+	}
+}
@@ -0,0 +1,29 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TransferDAO struct {
+	db *pgxpool.Pool
+}
+
+func NewTransferDAO(db *pgxpool.Pool) TransferDAO {
+	return TransferDAO{db: db}
+}
+
+func (dao TransferDAO) Insert(ctx context.Context, transfer entity.Transfer) (int, error) {
+	var lastInsertId int
+	sql := `
+		INSERT INTO transfer (datetime, user_id, from_account_id, to_account_id, amount, currency, description)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id
+		`
+	err := dao.db.QueryRow(ctx, sql, transfer.Datetime, transfer.UserId, transfer.FromAccountId, transfer.ToAccountId, transfer.Amount, transfer.Currency, transfer.Description).Scan(&lastInsertId)
+	if err != nil {
+		return 0, err
+	}
+	return lastInsertId, nil
+}
@@ -17,7 +17,7 @@ func NewUserDao(db *pgxpool.Pool) UserDAO {
 
 func (dao UserDAO) FindUserById(ctx context.Context, id int64) (*entity.User, error) {
 	var users []*entity.User
-	err := pgxscan.Select(ctx, dao.db, &users, `SELECT id, locale, currency, timezone FROM app_user WHERE id = $1`, id)
+	err := pgxscan.Select(ctx, dao.db, &users, `SELECT id, locale, currency, timezone, refund_month FROM app_user WHERE id = $1`, id)
 	if err != nil {
 		return nil, err
 	}
@@ -38,3 +38,29 @@ func (dao UserDAO) Insert(ctx context.Context, user entity.User) error {
 	}
 	return nil
 }
+
+func (dao UserDAO) UpdateLocale(ctx context.Context, id int64, locale string) error {
+	sql := `
+		UPDATE app_user
+		SET locale = $2, update_time = NOW()
+		WHERE id = $1
+		`
+	_, err := dao.db.Exec(ctx, sql, id, locale)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (dao UserDAO) UpdateRefundMonth(ctx context.Context, id int64, refundMonth string) error {
+	sql := `
+		UPDATE app_user
+		SET refund_month = $2, update_time = NOW()
+		WHERE id = $1
+		`
+	_, err := dao.db.Exec(ctx, sql, id, refundMonth)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -44,6 +44,16 @@ func TestUserDAO_InsertAndFindById(t *testing.T) {
 	if user.Timezone != "Asia/Singapore" {
 		t.Errorf("Timezone = %s, want Asia/Singapore", user.Timezone)
 	}
+	if user.RefundMonth != "original" {
+		t.Errorf("RefundMonth = %s, want original", user.RefundMonth)
+	}
+
+	if err := dao.UpdateRefundMonth(ctx, 12345, "refund"); err != nil {
+		t.Fatalf("UpdateRefundMonth: %v", err)
+	}
+	if user, _ := dao.FindUserById(ctx, 12345); user.RefundMonth != "refund" {
+		t.Errorf("RefundMonth = %s, want refund", user.RefundMonth)
+	}
 }
 
 func TestUserDAO_FindById_NotFound(t *testing.T) {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/aattwwss/telegram-expense-bot/config"
+	"github.com/aattwwss/telegram-expense-bot/logging"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -33,5 +34,10 @@ func (c Config) connectionUrl() string {
 
 func LoadDB(ctx context.Context, cfg config.EnvConfig) (*pgxpool.Pool, error) {
 	c := newConfig(cfg)
-	return pgxpool.New(ctx, c.connectionUrl())
+	poolConfig, err := pgxpool.ParseConfig(c.connectionUrl())
+	if err != nil {
+		return nil, err
+	}
+	poolConfig.ConnConfig.Tracer = logging.QueryTracer{}
+	return pgxpool.NewWithConfig(ctx, poolConfig)
 }
@@ -0,0 +1,159 @@
+// Package dispatch hands the updates of a chat to one worker at a time, so each chat is processed in order while
+// different chats are processed in parallel
+package dispatch
+
+import (
+	"sync"
+
+	"github.com/aattwwss/telegram-expense-bot/metrics"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// Dispatcher queues the updates of every chat and hands the chats to a pool of workers in turn. A chat waiting on the
+// rate limit of its replies holds one worker only, so the other chats carry on with the rest of the workers.
+type Dispatcher struct {
+	numWorkers int
+	backlog    int
+	handle     func(tgbotapi.Update)
+	workers    sync.WaitGroup
+
+	mu     sync.Mutex
+	ready  *sync.Cond
+	queues map[int64][]tgbotapi.Update // updates waiting by chat, for the chats queued in chats or being handled
+	chats  []int64                     // chats waiting for a worker, in the order they became ready
+	closed bool
+}
+
+// NewDispatcher returns a dispatcher with numWorkers workers, holding up to backlog updates of each chat waiting to
+// be handled
+func NewDispatcher(numWorkers int, backlog int, handle func(tgbotapi.Update)) *Dispatcher {
+	d := &Dispatcher{
+		numWorkers: max(numWorkers, 1),
+		backlog:    max(backlog, 1),
+		handle:     handle,
+		queues:     map[int64][]tgbotapi.Update{},
+	}
+	d.ready = sync.NewCond(&d.mu)
+	return d
+}
+
+// Start starts the workers
+func (d *Dispatcher) Start() {
+	for i := 0; i < d.numWorkers; i++ {
+		d.workers.Add(1)
+		go func() {
+			defer d.workers.Done()
+			for d.handleNext() {
+			}
+		}()
+	}
+}
+
+// handleNext handles the next update of the chat waiting the longest for a worker, returning false once the dispatcher
+// is closed and no chat is waiting
+func (d *Dispatcher) handleNext() bool {
+	d.mu.Lock()
+	for len(d.chats) == 0 && !d.closed {
+		d.ready.Wait()
+	}
+	if len(d.chats) == 0 {
+		d.mu.Unlock()
+		return false
+	}
+	chatId := d.chats[0]
+	d.chats = d.chats[1:]
+	update := d.queues[chatId][0]
+	d.queues[chatId] = d.queues[chatId][1:]
+	d.mu.Unlock()
+
+	d.handle(update)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.queues[chatId]) == 0 {
+		delete(d.queues, chatId)
+	} else {
+		// the chat goes to the back of the line, so a busy chat takes turns with the others
+		d.chats = append(d.chats, chatId)
+		d.ready.Signal()
+	}
+	return true
+}
+
+// Dispatch queues the update behind the other updates of its chat without waiting. The update is dropped when the
+// backlog of its chat is full, so a chat flooding the bot cannot hold up the updates of the other chats.
+func (d *Dispatcher) Dispatch(update tgbotapi.Update) bool {
+	chatId := shardKey(update)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	queue, active := d.queues[chatId]
+	if len(queue) >= d.backlog {
+		metrics.ObserveUpdateDropped()
+		log.Warn().Int64("chat_id", chatId).Int("update_id", update.UpdateID).Msg("Dropped update as the backlog of its chat is full")
+		return false
+	}
+	d.queues[chatId] = append(queue, update)
+	if !active {
+		d.chats = append(d.chats, chatId)
+		d.ready.Signal()
+	}
+	return true
+}
+
+// Run dispatches the updates until stopping is closed, then dispatches the updates already received and waits for the
+// workers to handle their backlog
+func (d *Dispatcher) Run(updates <-chan tgbotapi.Update, stopping <-chan struct{}) {
+	defer d.close()
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			d.Dispatch(update)
+		case <-stopping:
+			for {
+				select {
+				case update, ok := <-updates:
+					if !ok {
+						return
+					}
+					d.Dispatch(update)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) close() {
+	d.mu.Lock()
+	d.closed = true
+	d.ready.Broadcast()
+	d.mu.Unlock()
+	d.workers.Wait()
+}
+
+// Depth returns the number of updates waiting for a worker
+func (d *Dispatcher) Depth() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	depth := 0
+	for _, queue := range d.queues {
+		depth += len(queue)
+	}
+	return depth
+}
+
+// shardKey returns the chat of the update, or the user for updates without a chat such as inline queries
+func shardKey(update tgbotapi.Update) int64 {
+	if chat := update.FromChat(); chat != nil {
+		return chat.ID
+	}
+	if from := update.SentFrom(); from != nil {
+		return from.ID
+	}
+	return 0
+}
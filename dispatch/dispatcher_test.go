@@ -0,0 +1,192 @@
+package dispatch
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func messageUpdate(updateId int, chatId int64) tgbotapi.Update {
+	return tgbotapi.Update{
+		UpdateID: updateId,
+		Message:  &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: chatId}, From: &tgbotapi.User{ID: chatId}},
+	}
+}
+
+func TestShardKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		update tgbotapi.Update
+		want   int64
+	}{
+		{"message", messageUpdate(1, -100), -100},
+		{"callback", tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{From: &tgbotapi.User{ID: 7}, Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 70}}}}, 70},
+		{"inline query", tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{From: &tgbotapi.User{ID: 7}}}, 7},
+		{"empty", tgbotapi.Update{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shardKey(tt.update); got != tt.want {
+				t.Errorf("shardKey() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatcher_OrderedPerChat(t *testing.T) {
+	var mu sync.Mutex
+	handled := map[int64][]int{}
+	d := NewDispatcher(4, 20, func(update tgbotapi.Update) {
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		chatId := update.Message.Chat.ID
+		handled[chatId] = append(handled[chatId], update.UpdateID)
+	})
+	d.Start()
+
+	updates := make(chan tgbotapi.Update, 100)
+	for i := 0; i < 60; i++ {
+		updates <- messageUpdate(i, int64(i%3))
+	}
+	close(updates)
+	d.Run(updates, make(chan struct{}))
+
+	for chatId, ids := range handled {
+		if len(ids) != 20 {
+			t.Errorf("expected 20 updates of chat %d, got %d", chatId, len(ids))
+		}
+		for i := 1; i < len(ids); i++ {
+			if ids[i] < ids[i-1] {
+				t.Errorf("expected chat %d to be handled in order, got %v", chatId, ids)
+				break
+			}
+		}
+	}
+}
+
+func TestDispatcher_ChatsRunInParallel(t *testing.T) {
+	release := make(chan struct{})
+	otherHandled := make(chan struct{})
+	d := NewDispatcher(2, 10, func(update tgbotapi.Update) {
+		if update.Message.Chat.ID == 0 {
+			<-release
+			return
+		}
+		close(otherHandled)
+	})
+	d.Start()
+
+	updates := make(chan tgbotapi.Update, 2)
+	updates <- messageUpdate(1, 0)
+	updates <- messageUpdate(2, 1)
+	close(updates)
+	go d.Run(updates, make(chan struct{}))
+
+	select {
+	case <-otherHandled:
+	case <-time.After(time.Second):
+		t.Errorf("expected another chat to be handled while the first chat is busy")
+	}
+	close(release)
+}
+
+func TestDispatcher_RunDrainsOnStop(t *testing.T) {
+	var mu sync.Mutex
+	var count int
+	d := NewDispatcher(2, 10, func(update tgbotapi.Update) {
+		mu.Lock()
+		defer mu.Unlock()
+		count++
+	})
+	d.Start()
+
+	updates := make(chan tgbotapi.Update, 10)
+	for i := 0; i < 5; i++ {
+		updates <- messageUpdate(i, int64(i))
+	}
+	stopping := make(chan struct{})
+	close(stopping)
+
+	done := make(chan struct{})
+	go func() {
+		d.Run(updates, stopping)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected Run to return once the queued updates are handled")
+	}
+	if count != 5 {
+		t.Errorf("expected the 5 queued updates to be handled, got %d", count)
+	}
+}
+
+func TestDispatcher_BoundedBacklog(t *testing.T) {
+	release := make(chan struct{})
+	d := NewDispatcher(1, 2, func(update tgbotapi.Update) { <-release })
+	d.Start()
+
+	d.Dispatch(messageUpdate(1, 1)) // taken by the worker
+	time.Sleep(10 * time.Millisecond)
+	d.Dispatch(messageUpdate(2, 1))
+	d.Dispatch(messageUpdate(3, 1))
+	if d.Depth() != 2 {
+		t.Fatalf("expected a backlog of 2, got %d", d.Depth())
+	}
+
+	dispatched := make(chan bool)
+	go func() {
+		dispatched <- d.Dispatch(messageUpdate(4, 1))
+	}()
+	select {
+	case ok := <-dispatched:
+		if ok {
+			t.Errorf("expected the update dropped while the backlog of its chat is full")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected Dispatch not to wait while the backlog is full")
+	}
+	if !d.Dispatch(messageUpdate(5, 2)) {
+		t.Errorf("expected the update of another chat queued while the backlog of the first chat is full")
+	}
+	close(release)
+}
+
+func TestDispatcher_BusyChatHoldsOneWorker(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var handled []int64
+	d := NewDispatcher(2, 10, func(update tgbotapi.Update) {
+		if update.Message.Chat.ID == 0 {
+			<-release
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, update.Message.Chat.ID)
+	})
+	d.Start()
+
+	// chats 0 and 2 fall on the same worker when the chats are sharded by id
+	for i := 0; i < 3; i++ {
+		d.Dispatch(messageUpdate(i, 0))
+		d.Dispatch(messageUpdate(10+i, 2))
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n := len(handled)
+		mu.Unlock()
+		if n == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the updates of chat 2 handled while chat 0 is busy, got %d", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+}
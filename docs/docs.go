@@ -0,0 +1,9 @@
+// Package docs embeds the documents served alongside the bot
+package docs
+
+import _ "embed"
+
+// OpenApi is the OpenAPI document of the REST API under /api/v1
+//
+//go:embed openapi.yaml
+var OpenApi []byte
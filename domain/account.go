@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/message"
+)
+
+const accountBalanceHTMLMsg = "<code>%s%s %s\n</code>" // E.g. DBS card  -$1,234.00
+
+type Account struct {
+	Id             int
+	UserId         int64
+	Name           string
+	OpeningBalance *money.Money
+}
+
+func AccountFromEntity(e entity.Account) Account {
+	return Account{
+		Id:             e.Id,
+		UserId:         e.UserId,
+		Name:           e.Name,
+		OpeningBalance: money.New(e.OpeningBalance, e.Currency),
+	}
+}
+
+type Accounts []Account
+
+// FindByHint returns the account a hint typed as ^hint refers to, which is the account whose name starts with the
+// hint ignoring case and spaces, e.g. ^dbs or ^dbscard for "DBS card". An account named exactly as the hint is
+// preferred over the others starting with it. It returns an entity.ErrNotFound when no account starts with the hint,
+// and an entity.ErrConflict when more than one does.
+func (accounts Accounts) FindByHint(hint string) (Account, error) {
+	return findByHint(accounts, func(a Account) string { return a.Name }, "account", hint)
+}
+
+// Hint returns the shortest hint that refers to the account among the accounts, e.g. dbs for "DBS card"
+func (accounts Accounts) Hint(account Account) string {
+	name := normaliseName(account.Name)
+	for n := 1; n < utf8.RuneCountInString(name); n++ {
+		hint := string([]rune(name)[:n])
+		if found, err := accounts.FindByHint(hint); err == nil && found.Id == account.Id {
+			return hint
+		}
+	}
+	return name
+}
+
+type AccountBalance struct {
+	Name    string
+	Balance *money.Money
+}
+
+func AccountBalanceFromEntity(e entity.AccountBalance) AccountBalance {
+	return AccountBalance{
+		Name:    e.Name,
+		Balance: money.New(e.Balance, e.Currency),
+	}
+}
+
+type AccountBalances []AccountBalance
+
+func (balances AccountBalances) GetFormattedHTMLMsg(locale message.Locale) string {
+	text := ""
+
+	longest := 0
+	for _, b := range balances {
+		length := utf8.RuneCountInString(b.Name)
+		if length > longest {
+			longest = length
+		}
+	}
+
+	for _, b := range balances {
+		spacesToPadAfterName := longest - utf8.RuneCountInString(b.Name)
+		text += fmt.Sprintf(accountBalanceHTMLMsg, html.EscapeString(b.Name), strings.Repeat(" ", spacesToPadAfterName), locale.FormatMoney(b.Balance))
+	}
+	return text
+}
+
+// Transfer moves money from one account of a user to another, which is not spending
+type Transfer struct {
+	Id            int
+	Datetime      time.Time
+	UserId        int64
+	FromAccountId int
+	ToAccountId   int
+	Amount        *money.Money
+	Description   string
+}
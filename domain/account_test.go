@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/message"
+)
+
+func TestAccountsFindByHint(t *testing.T) {
+	accounts := Accounts{
+		{Id: 1, Name: "Cash"},
+		{Id: 2, Name: "Cashback card"},
+		{Id: 3, Name: "DBS card"},
+		{Id: 4, Name: "DBS PayLah"},
+		{Id: 5, Name: "PayNow"},
+	}
+	tests := []struct {
+		hint    string
+		wantId  int
+		wantErr error
+	}{
+		{"cash", 1, nil},
+		{"cashb", 2, nil},
+		{"DBSC", 3, nil},
+		{"dbscard", 3, nil},
+		{"dbsp", 4, nil},
+		{"pay", 5, nil},
+		{"dbs", 0, entity.ErrConflict},
+		{"ocbc", 0, entity.ErrNotFound},
+		{"", 0, entity.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hint, func(t *testing.T) {
+			got, err := accounts.FindByHint(tt.hint)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got.Id != tt.wantId {
+				t.Errorf("expected account %d, got %+v", tt.wantId, got)
+			}
+		})
+	}
+}
+
+func TestAccountsHint(t *testing.T) {
+	accounts := Accounts{{Id: 1, Name: "Cash"}, {Id: 2, Name: "DBS card"}, {Id: 3, Name: "DBS PayLah"}}
+
+	for account, want := range map[int]string{0: "c", 1: "dbsc", 2: "dbsp"} {
+		if got := accounts.Hint(accounts[account]); got != want {
+			t.Errorf("expected the hint %q for %s, got %q", want, accounts[account].Name, got)
+		}
+	}
+}
+
+func TestAccountBalancesGetFormattedHTMLMsg(t *testing.T) {
+	balances := AccountBalances{
+		{Name: "Cash", Balance: money.New(6450, money.SGD)},
+		{Name: "DBS <card>", Balance: money.New(-320000, money.SGD)},
+	}
+
+	got := balances.GetFormattedHTMLMsg(message.GetLocale("en"))
+
+	want := "<code>Cash       $64.50\n</code><code>DBS &lt;card&gt; -$3,200.00\n</code>"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
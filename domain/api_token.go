@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const (
+	ApiTokenPrefix = "teb_"
+	apiTokenBytes  = 32
+)
+
+// NewApiToken generates a random personal access token for the REST API
+func NewApiToken() (string, error) {
+	b := make([]byte, apiTokenBytes)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return ApiTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashApiToken returns the hash of the token that is stored in place of the token
+func HashApiToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewApiToken(t *testing.T) {
+	token, err := NewApiToken()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(token, ApiTokenPrefix) {
+		t.Errorf("expected token to start with %s, got %s", ApiTokenPrefix, token)
+	}
+	if len(token) != len(ApiTokenPrefix)+43 {
+		t.Errorf("expected 32 random bytes in the token, got %s", token)
+	}
+
+	other, _ := NewApiToken()
+	if token == other {
+		t.Errorf("expected tokens to be unique")
+	}
+}
+
+func TestHashApiToken(t *testing.T) {
+	hash := HashApiToken("teb_abc")
+	if len(hash) != 64 {
+		t.Errorf("expected 64 hex characters, got %d", len(hash))
+	}
+	if hash != HashApiToken("teb_abc") {
+		t.Errorf("expected the hash to be deterministic")
+	}
+	if hash == HashApiToken("teb_abd") {
+		t.Errorf("expected different tokens to have different hashes")
+	}
+}
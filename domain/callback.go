@@ -3,8 +3,9 @@ package domain
 import "github.com/aattwwss/telegram-expense-bot/enum"
 
 type Callback struct {
-	Type             enum.CallbackType `json:"t,omitempty"`
-	MessageContextId int               `json:"mc,omitempty"`
+	Type enum.CallbackType `json:"t,omitempty"`
+	// MessageContextId is the id of the message context of the menu, or of the conversation for the menus of a flow
+	MessageContextId int `json:"mc,omitempty"`
 }
 
 type GenericCallback struct {
@@ -32,3 +33,33 @@ type UndoCallback struct {
 	Callback      `json:"c"`
 	TransactionId int `json:"t"`
 }
+
+// RestoreCallback is a transaction in the trash to restore
+type RestoreCallback struct {
+	Callback      `json:"c"`
+	TransactionId int `json:"t"`
+}
+
+// RefundCallback is a purchase to refund, of the amount in the lowest denomination of its currency or of what is left
+// of it when Amount is 0
+type RefundCallback struct {
+	Callback      `json:"c"`
+	TransactionId int   `json:"t"`
+	Amount        int64 `json:"a,omitempty"`
+}
+
+type SnoozeCallback struct {
+	Callback `json:"c"`
+	Minutes  int `json:"m"`
+}
+
+type LanguageCallback struct {
+	Callback `json:"c"`
+	Locale   string `json:"l"`
+}
+
+// AccountCallback is an account tapped in the entry conversation, or no account when AccountId is 0
+type AccountCallback struct {
+	Callback  `json:"c"`
+	AccountId int `json:"id,omitempty"`
+}
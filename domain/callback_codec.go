@@ -0,0 +1,331 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aattwwss/telegram-expense-bot/enum"
+)
+
+// The callback data of a button is encoded as base64 of
+//
+//	version | type | message context id | fields of the type... | truncated hmac
+//
+// with the numbers as varints, so it stays well below the 64 bytes allowed by telegram. The version lets the layout of
+// a type change without breaking the keyboards already sent.
+const (
+	callbackVersion     byte = 1
+	callbackMacLength        = 6
+	maxCallbackDataSize      = 64
+)
+
+var ErrInvalidCallback = errors.New("invalid callback data")
+
+var callbackTypeCodes = map[enum.CallbackType]byte{
+	enum.TransactionType:    1,
+	enum.Category:           2,
+	enum.Pagination:         3,
+	enum.Undo:               4,
+	enum.Cancel:             5,
+	enum.Snooze:             6,
+	enum.LogNow:             7,
+	enum.Language:           8,
+	enum.CancelConversation: 9,
+	enum.Account:            10,
+	enum.Restore:            11,
+	enum.Split:              12,
+	enum.Refund:             13,
+}
+
+// legacyJsonCallbackTypes are the types of the keyboards sent with json callback data before it was signed. Json
+// data of any other type was never sent by the bot.
+var legacyJsonCallbackTypes = map[enum.CallbackType]bool{
+	enum.TransactionType: true,
+	enum.Category:        true,
+	enum.Pagination:      true,
+	enum.Undo:            true,
+	enum.Cancel:          true,
+	enum.Snooze:          true,
+	enum.LogNow:          true,
+	enum.Language:        true,
+}
+
+var paginateActionCodes = map[enum.PaginateAction]byte{
+	"":            0,
+	enum.Next:     1,
+	enum.Previous: 2,
+}
+
+var callbackCodec = struct {
+	sync.RWMutex
+	key        []byte
+	acceptJson bool
+}{}
+
+// SetCallbackKey sets the key that signs the callback data, so data that was not sent by the bot is rejected
+func SetCallbackKey(key []byte) {
+	callbackCodec.Lock()
+	defer callbackCodec.Unlock()
+	callbackCodec.key = key
+}
+
+// SetAcceptJsonCallbacks sets whether the unsigned json callback data of the keyboards sent before the binary encoding
+// is still accepted, for the types of those keyboards only
+func SetAcceptJsonCallbacks(accept bool) {
+	callbackCodec.Lock()
+	defer callbackCodec.Unlock()
+	callbackCodec.acceptJson = accept
+}
+
+// EncodeCallback returns the signed callback data of one of the callback types
+func EncodeCallback(callback any) (string, error) {
+	var base Callback
+	var fields []byte
+	switch c := callback.(type) {
+	case GenericCallback:
+		base = c.Callback
+	case TransactionTypeCallback:
+		base = c.Callback
+		fields = binary.AppendVarint(fields, int64(c.TransactionTypeId))
+	case CategoryCallback:
+		base = c.Callback
+		fields = binary.AppendVarint(fields, int64(c.CategoryId))
+	case AccountCallback:
+		base = c.Callback
+		fields = binary.AppendVarint(fields, int64(c.AccountId))
+	case PaginationCallback:
+		base = c.Callback
+		action, ok := paginateActionCodes[c.Action]
+		if !ok {
+			return "", fmt.Errorf("unknown paginate action %q", c.Action)
+		}
+		fields = append(fields, action)
+		fields = binary.AppendVarint(fields, int64(c.Offset))
+		fields = binary.AppendVarint(fields, int64(c.Limit))
+	case UndoCallback:
+		base = c.Callback
+		fields = binary.AppendVarint(fields, int64(c.TransactionId))
+	case RestoreCallback:
+		base = c.Callback
+		fields = binary.AppendVarint(fields, int64(c.TransactionId))
+	case RefundCallback:
+		base = c.Callback
+		fields = binary.AppendVarint(fields, int64(c.TransactionId))
+		fields = binary.AppendVarint(fields, c.Amount)
+	case SnoozeCallback:
+		base = c.Callback
+		fields = binary.AppendVarint(fields, int64(c.Minutes))
+	case LanguageCallback:
+		base = c.Callback
+		if len(c.Locale) > 16 {
+			return "", fmt.Errorf("locale %q is too long", c.Locale)
+		}
+		fields = append(fields, byte(len(c.Locale)))
+		fields = append(fields, c.Locale...)
+	default:
+		return "", fmt.Errorf("unknown callback %T", callback)
+	}
+
+	typeCode, ok := callbackTypeCodes[base.Type]
+	if !ok {
+		return "", fmt.Errorf("unknown callback type %q", base.Type)
+	}
+	payload := []byte{callbackVersion, typeCode}
+	payload = binary.AppendVarint(payload, int64(base.MessageContextId))
+	payload = append(payload, fields...)
+	payload = append(payload, callbackMac(payload)...)
+
+	data := base64.RawURLEncoding.EncodeToString(payload)
+	if len(data) > maxCallbackDataSize {
+		return "", fmt.Errorf("callback data of %d bytes is over the limit of %d", len(data), maxCallbackDataSize)
+	}
+	return data, nil
+}
+
+// DecodeCallback decodes the callback data into a pointer to one of the callback types. Any callback can be decoded
+// into a GenericCallback to read its type and message context.
+func DecodeCallback(data string, callback any) error {
+	if strings.HasPrefix(data, "{") {
+		return decodeJsonCallback(data, callback)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil || len(payload) < 2+callbackMacLength {
+		return ErrInvalidCallback
+	}
+	signed, mac := payload[:len(payload)-callbackMacLength], payload[len(payload)-callbackMacLength:]
+	if !hmac.Equal(mac, callbackMac(signed)) {
+		return fmt.Errorf("%w: bad signature", ErrInvalidCallback)
+	}
+	if signed[0] != callbackVersion {
+		return fmt.Errorf("%w: unknown version %d", ErrInvalidCallback, signed[0])
+	}
+
+	r := callbackReader{buf: signed[2:]}
+	var base Callback
+	base.Type, err = callbackTypeOf(signed[1])
+	if err != nil {
+		return err
+	}
+	base.MessageContextId = r.int()
+
+	var expected enum.CallbackType
+	switch c := callback.(type) {
+	case *GenericCallback:
+		c.Callback = base
+	case *TransactionTypeCallback:
+		expected = enum.TransactionType
+		c.Callback = base
+		c.TransactionTypeId = r.int()
+	case *CategoryCallback:
+		expected = enum.Category
+		c.Callback = base
+		c.CategoryId = r.int()
+	case *AccountCallback:
+		expected = enum.Account
+		c.Callback = base
+		c.AccountId = r.int()
+	case *PaginationCallback:
+		expected = enum.Pagination
+		c.Callback = base
+		c.Action = r.action()
+		c.Offset = r.int()
+		c.Limit = r.int()
+	case *UndoCallback:
+		expected = enum.Undo
+		c.Callback = base
+		c.TransactionId = r.int()
+	case *RestoreCallback:
+		expected = enum.Restore
+		c.Callback = base
+		c.TransactionId = r.int()
+	case *RefundCallback:
+		expected = enum.Refund
+		c.Callback = base
+		c.TransactionId = r.int()
+		c.Amount = r.int64()
+	case *SnoozeCallback:
+		expected = enum.Snooze
+		c.Callback = base
+		c.Minutes = r.int()
+	case *LanguageCallback:
+		expected = enum.Language
+		c.Callback = base
+		c.Locale = r.string()
+	default:
+		return fmt.Errorf("unknown callback %T", callback)
+	}
+	if expected != "" && expected != base.Type {
+		return fmt.Errorf("%w: %s callback decoded as %s", ErrInvalidCallback, base.Type, expected)
+	}
+	// a generic callback only reads the type and the message context of any callback
+	if r.err != nil || (expected != "" && len(r.buf) != 0) {
+		return fmt.Errorf("%w: malformed %s callback", ErrInvalidCallback, base.Type)
+	}
+	return nil
+}
+
+func decodeJsonCallback(data string, callback any) error {
+	callbackCodec.RLock()
+	acceptJson := callbackCodec.acceptJson
+	callbackCodec.RUnlock()
+	if !acceptJson {
+		return fmt.Errorf("%w: json callbacks are no longer accepted", ErrInvalidCallback)
+	}
+	var generic GenericCallback
+	err := json.Unmarshal([]byte(data), &generic)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCallback, err)
+	}
+	if !legacyJsonCallbackTypes[generic.Type] {
+		return fmt.Errorf("%w: %s callbacks are never json", ErrInvalidCallback, generic.Type)
+	}
+	err = json.Unmarshal([]byte(data), callback)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCallback, err)
+	}
+	return nil
+}
+
+func callbackMac(payload []byte) []byte {
+	callbackCodec.RLock()
+	mac := hmac.New(sha256.New, callbackCodec.key)
+	callbackCodec.RUnlock()
+	mac.Write(payload)
+	return mac.Sum(nil)[:callbackMacLength]
+}
+
+func callbackTypeOf(code byte) (enum.CallbackType, error) {
+	for callbackType, c := range callbackTypeCodes {
+		if c == code {
+			return callbackType, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown type %d", ErrInvalidCallback, code)
+}
+
+// callbackReader reads the fields of a callback, keeping the first error
+type callbackReader struct {
+	buf []byte
+	err error
+}
+
+func (r *callbackReader) int() int {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.buf)
+	if n <= 0 || v != int64(int32(v)) {
+		r.err = ErrInvalidCallback
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return int(v)
+}
+
+func (r *callbackReader) int64() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.buf)
+	if n <= 0 {
+		r.err = ErrInvalidCallback
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *callbackReader) action() enum.PaginateAction {
+	if r.err != nil || len(r.buf) == 0 {
+		r.err = ErrInvalidCallback
+		return ""
+	}
+	code := r.buf[0]
+	r.buf = r.buf[1:]
+	for action, c := range paginateActionCodes {
+		if c == code {
+			return action
+		}
+	}
+	r.err = ErrInvalidCallback
+	return ""
+}
+
+func (r *callbackReader) string() string {
+	if r.err != nil || len(r.buf) == 0 || int(r.buf[0]) > len(r.buf)-1 {
+		r.err = ErrInvalidCallback
+		return ""
+	}
+	n := int(r.buf[0])
+	s := string(r.buf[1 : 1+n])
+	r.buf = r.buf[1+n:]
+	return s
+}
@@ -0,0 +1,218 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aattwwss/telegram-expense-bot/enum"
+)
+
+func TestCallbackCodec_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		callback any
+		decoded  any
+	}{
+		{"generic", GenericCallback{Callback{Type: enum.Cancel, MessageContextId: 3}}, &GenericCallback{}},
+		{"transaction type", TransactionTypeCallback{Callback{Type: enum.TransactionType, MessageContextId: 1}, 5}, &TransactionTypeCallback{}},
+		{"category", CategoryCallback{Callback{Type: enum.Category, MessageContextId: 123456}, 7}, &CategoryCallback{}},
+		{"pagination", PaginationCallback{Callback{Type: enum.Pagination, MessageContextId: 123}, enum.Next, 30, 10}, &PaginationCallback{}},
+		{"undo", UndoCallback{Callback{Type: enum.Undo, MessageContextId: 5}, 2147483647}, &UndoCallback{}},
+		{"snooze", SnoozeCallback{Callback{Type: enum.Snooze}, 60}, &SnoozeCallback{}},
+		{"log now", GenericCallback{Callback{Type: enum.LogNow}}, &GenericCallback{}},
+		{"language", LanguageCallback{Callback{Type: enum.Language}, "zh"}, &LanguageCallback{}},
+		{"account", AccountCallback{Callback{Type: enum.Account, MessageContextId: 42}, 3}, &AccountCallback{}},
+		{"restore", RestoreCallback{Callback{Type: enum.Restore}, 2147483647}, &RestoreCallback{}},
+		{"refund", RefundCallback{Callback{Type: enum.Refund}, 2147483647, 99999999999}, &RefundCallback{}},
+		{"refund in full", RefundCallback{Callback{Type: enum.Refund}, 12, 0}, &RefundCallback{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := EncodeCallback(tt.callback)
+			if err != nil {
+				t.Fatalf("encode error: %v", err)
+			}
+			if len(data) > 32 {
+				t.Errorf("expected compact callback data, got %d bytes: %s", len(data), data)
+			}
+			err = DecodeCallback(data, tt.decoded)
+			if err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if got := reflect.ValueOf(tt.decoded).Elem().Interface(); !reflect.DeepEqual(got, tt.callback) {
+				t.Errorf("expected %+v, got %+v", tt.callback, got)
+			}
+		})
+	}
+}
+
+func TestCallbackCodec_Generic(t *testing.T) {
+	data, _ := EncodeCallback(PaginationCallback{Callback{Type: enum.Pagination, MessageContextId: 9}, enum.Previous, 10, 10})
+
+	var generic GenericCallback
+	err := DecodeCallback(data, &generic)
+
+	if err != nil || generic.Type != enum.Pagination || generic.MessageContextId != 9 {
+		t.Errorf("expected the type and message context of any callback, got %+v: %v", generic, err)
+	}
+}
+
+func TestCallbackCodec_Tampered(t *testing.T) {
+	data, _ := EncodeCallback(UndoCallback{Callback{Type: enum.Undo, MessageContextId: 5}, 99})
+	payload, _ := base64.RawURLEncoding.DecodeString(data)
+	payload[3] ^= 1
+	tampered := base64.RawURLEncoding.EncodeToString(payload)
+
+	var undo UndoCallback
+	if err := DecodeCallback(tampered, &undo); !errors.Is(err, ErrInvalidCallback) {
+		t.Errorf("expected tampered data to be rejected, got %v", err)
+	}
+}
+
+func TestCallbackCodec_OtherKey(t *testing.T) {
+	defer SetCallbackKey(nil)
+	SetCallbackKey([]byte("old key"))
+	data, _ := EncodeCallback(GenericCallback{Callback{Type: enum.Cancel}})
+	SetCallbackKey([]byte("new key"))
+
+	var generic GenericCallback
+	if err := DecodeCallback(data, &generic); !errors.Is(err, ErrInvalidCallback) {
+		t.Errorf("expected data signed with another key to be rejected, got %v", err)
+	}
+}
+
+func TestCallbackCodec_WrongType(t *testing.T) {
+	data, _ := EncodeCallback(CategoryCallback{Callback{Type: enum.Category, MessageContextId: 1}, 7})
+
+	var undo UndoCallback
+	if err := DecodeCallback(data, &undo); !errors.Is(err, ErrInvalidCallback) {
+		t.Errorf("expected a category callback not to decode as undo, got %v", err)
+	}
+}
+
+func TestCallbackCodec_UnknownVersion(t *testing.T) {
+	payload := []byte{2, 5, 0}
+	payload = append(payload, callbackMac(payload)...)
+
+	var generic GenericCallback
+	err := DecodeCallback(base64.RawURLEncoding.EncodeToString(payload), &generic)
+	if err == nil || !strings.Contains(err.Error(), "unknown version") {
+		t.Errorf("expected an unknown version error, got %v", err)
+	}
+}
+
+func TestCallbackCodec_LegacyJson(t *testing.T) {
+	data := `{"c":{"t":"Pagination","mc":123},"a":"Next","o":30,"l":10}`
+
+	var pagination PaginationCallback
+	if err := DecodeCallback(data, &pagination); !errors.Is(err, ErrInvalidCallback) {
+		t.Errorf("expected the json callback rejected by default, got %v", err)
+	}
+
+	SetAcceptJsonCallbacks(true)
+	defer SetAcceptJsonCallbacks(false)
+	err := DecodeCallback(data, &pagination)
+	if err != nil || pagination.MessageContextId != 123 || pagination.Offset != 30 || pagination.Action != enum.Next {
+		t.Errorf("expected the json callback to be accepted, got %+v: %v", pagination, err)
+	}
+
+	var refund RefundCallback
+	err = DecodeCallback(`{"c":{"t":"Refund","mc":123},"id":5,"amount":-100000}`, &refund)
+	if !errors.Is(err, ErrInvalidCallback) {
+		t.Errorf("expected a json callback of a type added since the signing rejected, got %+v: %v", refund, err)
+	}
+
+	SetAcceptJsonCallbacks(false)
+	if err := DecodeCallback(data, &pagination); !errors.Is(err, ErrInvalidCallback) {
+		t.Errorf("expected the json callback to be rejected, got %v", err)
+	}
+}
+
+func TestCallbackCodec_EncodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		callback any
+	}{
+		{"unknown struct", Callback{Type: enum.Cancel}},
+		{"unknown type", GenericCallback{Callback{Type: "Other"}}},
+		{"unknown action", PaginationCallback{Callback{Type: enum.Pagination}, "Jump", 0, 10}},
+		{"long locale", LanguageCallback{Callback{Type: enum.Language}, strings.Repeat("x", 17)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EncodeCallback(tt.callback); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func FuzzDecodeCallback(f *testing.F) {
+	for _, c := range []any{
+		GenericCallback{Callback{Type: enum.Cancel, MessageContextId: 3}},
+		PaginationCallback{Callback{Type: enum.Pagination, MessageContextId: 123}, enum.Next, 30, 10},
+		LanguageCallback{Callback{Type: enum.Language}, "ms"},
+	} {
+		data, _ := EncodeCallback(c)
+		f.Add(data)
+	}
+	f.Add(`{"c":{"t":"Undo","mc":5},"t":99}`)
+	f.Add("")
+	f.Add("AQ")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		targets := []any{&GenericCallback{}, &TransactionTypeCallback{}, &CategoryCallback{}, &PaginationCallback{},
+			&UndoCallback{}, &SnoozeCallback{}, &LanguageCallback{}}
+		for _, target := range targets {
+			if DecodeCallback(data, target) != nil || strings.HasPrefix(data, "{") {
+				continue
+			}
+			// whatever decodes must encode back to data that decodes the same
+			decoded := reflect.ValueOf(target).Elem().Interface()
+			encoded, err := EncodeCallback(decoded)
+			if err != nil {
+				t.Fatalf("decoded %+v from %q but cannot encode it: %v", decoded, data, err)
+			}
+			again := reflect.New(reflect.TypeOf(decoded)).Interface()
+			if err := DecodeCallback(encoded, again); err != nil {
+				t.Fatalf("cannot decode %q encoded from %+v: %v", encoded, decoded, err)
+			}
+			if got := reflect.ValueOf(again).Elem().Interface(); !reflect.DeepEqual(got, decoded) {
+				t.Fatalf("expected %+v, got %+v", decoded, got)
+			}
+		}
+	})
+}
+
+func FuzzCallbackRoundTrip(f *testing.F) {
+	f.Add(123, 1, 30, 10, "en")
+	f.Add(0, 0, -1, 0, "")
+	f.Fuzz(func(t *testing.T, messageContextId int, id int, offset int, limit int, locale string) {
+		messageContextId, id, offset, limit = int(int32(messageContextId)), int(int32(id)), int(int32(offset)), int(int32(limit))
+		callbacks := []any{
+			CategoryCallback{Callback{Type: enum.Category, MessageContextId: messageContextId}, id},
+			UndoCallback{Callback{Type: enum.Undo, MessageContextId: messageContextId}, id},
+			PaginationCallback{Callback{Type: enum.Pagination, MessageContextId: messageContextId}, enum.Previous, offset, limit},
+			LanguageCallback{Callback{Type: enum.Language, MessageContextId: messageContextId}, locale},
+		}
+		for _, c := range callbacks {
+			data, err := EncodeCallback(c)
+			if err != nil {
+				if _, ok := c.(LanguageCallback); ok && len(locale) > 16 {
+					continue
+				}
+				t.Fatalf("encode %+v: %v", c, err)
+			}
+			decoded := reflect.New(reflect.TypeOf(c)).Interface()
+			if err := DecodeCallback(data, decoded); err != nil {
+				t.Fatalf("decode %+v: %v", c, err)
+			}
+			if got := reflect.ValueOf(decoded).Elem().Interface(); !reflect.DeepEqual(got, c) {
+				t.Fatalf("expected %+v, got %+v", c, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"fmt"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+// maxMinorUnits is the most decimal places of an ISO 4217 currency, e.g. 4 for CLF
+const maxMinorUnits = 4
+
+// MinorUnits returns the decimal places of a currency with the minor units in one major unit, e.g. 2 for 100
+func MinorUnits(denominator int) (int, error) {
+	minorUnits := 0
+	for d := denominator; d > 1 && d%10 == 0; d /= 10 {
+		minorUnits++
+	}
+	if denominator < 1 || pow10(minorUnits) != denominator || minorUnits > maxMinorUnits {
+		return 0, fmt.Errorf("denominator %d is not a power of 10 up to %d", denominator, pow10(maxMinorUnits))
+	}
+	return minorUnits, nil
+}
+
+// RegisterCurrencies makes money parse and format the currencies in the decimal places of their denominators in the
+// currency table. Currencies money does not know are shown with their code.
+func RegisterCurrencies(currencies []entity.Currency) error {
+	for _, c := range currencies {
+		minorUnits, err := MinorUnits(c.Denominator)
+		if err != nil {
+			return fmt.Errorf("currency %s: %w", c.Code, err)
+		}
+		known := money.GetCurrency(c.Code)
+		if known == nil {
+			money.AddCurrency(c.Code, c.Code, "1 $", ".", ",", minorUnits)
+			continue
+		}
+		if known.Fraction == minorUnits {
+			continue
+		}
+		money.AddCurrency(known.Code, known.Grapheme, known.Template, known.Decimal, known.Thousand, minorUnits)
+	}
+	return nil
+}
+
+func pow10(n int) int {
+	p := 1
+	for range n {
+		p *= 10
+	}
+	return p
+}
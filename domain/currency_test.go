@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+func TestMinorUnits(t *testing.T) {
+	tests := []struct {
+		denominator int
+		want        int
+		wantErr     bool
+	}{
+		{1, 0, false},
+		{100, 2, false},
+		{1000, 3, false},
+		{10000, 4, false},
+		{0, 0, true},
+		{-100, 0, true},
+		{50, 0, true},
+		{100000, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := MinorUnits(tt.denominator)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("MinorUnits(%d) = %d, %v, want %d, wantErr %v", tt.denominator, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRegisterCurrencies(t *testing.T) {
+	err := RegisterCurrencies([]entity.Currency{{Code: "SGD", Denominator: 100}, {Code: "JPY", Denominator: 1}, {Code: "KWD", Denominator: 1000}, {Code: "CLF", Denominator: 10000}, {Code: "XCG", Denominator: 100}})
+	if err != nil {
+		t.Fatalf("RegisterCurrencies() error = %v", err)
+	}
+
+	for code, want := range map[string]int{"SGD": 2, "JPY": 0, "KWD": 3, "CLF": 4, "XCG": 2} {
+		got := money.GetCurrency(code)
+		if got == nil || got.Fraction != want {
+			t.Errorf("expected %s in %d decimal places, got %+v", code, want, got)
+		}
+	}
+	if got := money.GetCurrency("SGD"); got.Grapheme != "$" {
+		t.Errorf("expected the symbol of a known currency kept, got %+v", got)
+	}
+
+	err = RegisterCurrencies([]entity.Currency{{Code: "SGD", Denominator: 50}})
+	if err == nil {
+		t.Errorf("expected a denominator that is not a power of 10 rejected")
+	}
+}
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/message"
+)
+
+const (
+	goalHeaderHTMLMsg   = "<b>%s</b>\n"
+	goalProgressHTMLMsg = "<code>%s %3d%%</code> %s / %s\n" // E.g. ██████░░░░  60% $1,800.00 / $3,000.00
+
+	goalProgressWidth = 10
+)
+
+// Goal is an amount a user saves towards, optionally by the month of Deadline and with an amount contributed
+// automatically every month
+type Goal struct {
+	Id            int
+	UserId        int64
+	ChatId        int64
+	Name          string
+	Target        *money.Money
+	Saved         *money.Money
+	Deadline      *time.Time
+	MonthlyAmount *money.Money
+	ReachedTime   *time.Time
+	CreateTime    time.Time
+}
+
+func GoalFromEntity(e entity.Goal) Goal {
+	return Goal{
+		Id:            e.Id,
+		UserId:        e.UserId,
+		ChatId:        e.ChatId,
+		Name:          e.Name,
+		Target:        money.New(e.Target, e.Currency),
+		Saved:         money.New(e.Saved, e.Currency),
+		Deadline:      e.Deadline,
+		MonthlyAmount: money.New(e.MonthlyAmount, e.Currency),
+		ReachedTime:   e.ReachedTime,
+		CreateTime:    e.CreateTime,
+	}
+}
+
+// IsReached returns true when the contributions add up to the target
+func (g Goal) IsReached() bool {
+	return g.Saved.Amount() >= g.Target.Amount()
+}
+
+// Progress returns the part of the target saved, which is 0 when nothing is saved and over 1 once it is exceeded
+func (g Goal) Progress() float64 {
+	if g.Saved.Amount() <= 0 {
+		return 0
+	}
+	return float64(g.Saved.Amount()) / float64(g.Target.Amount())
+}
+
+// RequiredPace returns the amount to save every month from the month of now to reach the target in the month of the
+// deadline, or false when there is no deadline or it has passed
+func (g Goal) RequiredPace(now time.Time) (*money.Money, bool) {
+	if g.Deadline == nil {
+		return nil, false
+	}
+	monthsLeft := monthsBetween(now, *g.Deadline) + 1
+	if monthsLeft <= 0 {
+		return nil, false
+	}
+	remaining := g.Target.Amount() - g.Saved.Amount()
+	if remaining < 0 {
+		remaining = 0
+	}
+	// round up so that saving the pace every month reaches the target
+	pace := (remaining + int64(monthsLeft) - 1) / int64(monthsLeft)
+	return money.New(pace, g.Target.Currency().Code), true
+}
+
+// ActualPace returns the amount saved a month on average, from the month the goal was added to the month of now
+func (g Goal) ActualPace(now time.Time) *money.Money {
+	months := monthsBetween(g.CreateTime.In(now.Location()), now) + 1
+	if months < 1 {
+		months = 1
+	}
+	return money.New(g.Saved.Amount()/int64(months), g.Saved.Currency().Code)
+}
+
+// DeadlineLabel returns the month of the deadline in the locale, e.g. "December 2024"
+func (g Goal) DeadlineLabel(locale message.Locale) string {
+	if g.Deadline == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %d", locale.MonthName(g.Deadline.Month()), g.Deadline.Year())
+}
+
+// GetFormattedHTMLMsg returns the name of the goal with a progress bar, and its required pace against its actual pace
+func (g Goal) GetFormattedHTMLMsg(locale message.Locale, now time.Time) string {
+	text := fmt.Sprintf(goalHeaderHTMLMsg, html.EscapeString(g.Name))
+	text += fmt.Sprintf(goalProgressHTMLMsg, progressBar(g.Progress(), goalProgressWidth), int(g.Progress()*100), locale.FormatMoney(g.Saved), locale.FormatMoney(g.Target))
+
+	actual := locale.FormatMoney(g.ActualPace(now))
+	if g.IsReached() {
+		return text + locale.Get(message.GoalReachedLabel)
+	}
+	if required, ok := g.RequiredPace(now); ok {
+		text += locale.Get(message.GoalPaceMsg, locale.FormatMoney(required), g.DeadlineLabel(locale), actual)
+	} else if g.Deadline != nil {
+		text += locale.Get(message.GoalOverdueMsg, g.DeadlineLabel(locale), actual)
+	} else {
+		text += locale.Get(message.GoalActualPaceMsg, actual)
+	}
+	if g.MonthlyAmount.IsPositive() {
+		text += locale.Get(message.GoalMonthlyLabel, locale.FormatMoney(g.MonthlyAmount))
+	}
+	return text
+}
+
+type Goals []Goal
+
+// FindByHint returns the goal whose name starts with the hint ignoring case and spaces, e.g. japan for "Japan trip",
+// with the errors of the hints of accounts
+func (goals Goals) FindByHint(hint string) (Goal, error) {
+	return findByHint(goals, func(g Goal) string { return g.Name }, "goal", hint)
+}
+
+func (goals Goals) GetFormattedHTMLMsg(locale message.Locale, now time.Time) string {
+	var texts []string
+	for _, g := range goals {
+		texts = append(texts, g.GetFormattedHTMLMsg(locale, now))
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// progressBar draws the part of a bar of the width that is done, e.g. ██████░░░░ for 0.6
+func progressBar(done float64, width int) string {
+	filled := int(done * float64(width))
+	if filled > width {
+		filled = width
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+func monthsBetween(from time.Time, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
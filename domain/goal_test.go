@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/message"
+)
+
+func newTestGoal(saved int64, deadline *time.Time) Goal {
+	return Goal{
+		Name:          "Japan <trip>",
+		Target:        money.New(300000, money.SGD),
+		Saved:         money.New(saved, money.SGD),
+		Deadline:      deadline,
+		MonthlyAmount: money.New(0, money.SGD),
+		CreateTime:    time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestGoalRequiredPace(t *testing.T) {
+	deadline := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	// 7 months from June to December inclusive, rounded up
+	pace, ok := newTestGoal(100000, &deadline).RequiredPace(now)
+	if !ok || pace.Amount() != 28572 {
+		t.Errorf("expected 28572 a month, got %v, %v", pace, ok)
+	}
+	if _, ok := newTestGoal(100000, &deadline).RequiredPace(now.AddDate(1, 0, 0)); ok {
+		t.Errorf("expected no pace once the deadline has passed")
+	}
+	if _, ok := newTestGoal(100000, nil).RequiredPace(now); ok {
+		t.Errorf("expected no pace without a deadline")
+	}
+}
+
+func TestGoalActualPace(t *testing.T) {
+	// 6 months from January to June inclusive
+	got := newTestGoal(120000, nil).ActualPace(time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC))
+	if got.Amount() != 20000 {
+		t.Errorf("expected 20000 a month, got %d", got.Amount())
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	tests := map[float64]string{0: "░░░░░░░░░░", 0.6: "██████░░░░", 1: "██████████", 1.5: "██████████"}
+	for done, want := range tests {
+		if got := progressBar(done, 10); got != want {
+			t.Errorf("progressBar(%v) = %q, want %q", done, got, want)
+		}
+	}
+}
+
+func TestGoalGetFormattedHTMLMsg(t *testing.T) {
+	deadline := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	goal := newTestGoal(180000, &deadline)
+	goal.MonthlyAmount = money.New(25000, money.SGD)
+
+	got := goal.GetFormattedHTMLMsg(message.GetLocale("en"), time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC))
+
+	want := "<b>Japan &lt;trip&gt;</b>\n<code>██████░░░░  60%</code> $1,800.00 / $3,000.00\n" +
+		"Needs $171.43 a month until December 2024, saving $300.00 a month\n+$250.00 on the 1st of every month"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	reached := newTestGoal(300000, &deadline)
+	if got := reached.GetFormattedHTMLMsg(message.GetLocale("en"), time.Now()); got != "<b>Japan &lt;trip&gt;</b>\n<code>██████████ 100%</code> $3,000.00 / $3,000.00\n🎉 Reached!" {
+		t.Errorf("unexpected reached goal %q", got)
+	}
+}
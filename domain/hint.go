@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+// findByHint returns the item whose name starts with the hint ignoring case and spaces, preferring an item named
+// exactly as the hint over the others starting with it. It returns an entity.ErrNotFound when no item starts with the
+// hint, and an entity.ErrConflict when more than one does.
+func findByHint[T any](items []T, name func(T) string, noun string, hint string) (T, error) {
+	var zero T
+	hint = normaliseName(hint)
+	var found []T
+	for _, item := range items {
+		normalised := normaliseName(name(item))
+		if normalised == hint {
+			return item, nil
+		}
+		if hint != "" && strings.HasPrefix(normalised, hint) {
+			found = append(found, item)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return zero, fmt.Errorf("%s %w: none starts with %q", noun, entity.ErrNotFound, hint)
+	case 1:
+		return found[0], nil
+	}
+	return zero, fmt.Errorf("%s %w: %d start with %q", noun, entity.ErrConflict, len(found), hint)
+}
+
+func normaliseName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), ""))
+}
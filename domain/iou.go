@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"html"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/message"
+)
+
+// Iou is money lent to a person when Amount is positive, or borrowed from them when it is negative. It is kept apart
+// from the transactions, so it never counts as spending.
+type Iou struct {
+	Id          int
+	UserId      int64
+	Person      string
+	Amount      *money.Money
+	Description string
+	Datetime    time.Time
+}
+
+// IouBalance is what a person owes the user when Balance is positive, or what the user owes them when it is negative,
+// outstanding since OpenSince
+type IouBalance struct {
+	Person    string
+	Balance   *money.Money
+	OpenSince time.Time
+}
+
+func IouBalanceFromEntity(e entity.IouBalance) IouBalance {
+	return IouBalance{
+		Person:    e.Person,
+		Balance:   money.New(e.Balance, e.Currency),
+		OpenSince: e.OpenSince,
+	}
+}
+
+// GetFormattedMsg returns who owes whom, e.g. "Alice owes you $50.00", in the locale
+func (b IouBalance) GetFormattedMsg(locale message.Locale) string {
+	if b.Balance.IsNegative() {
+		return locale.Get(message.IouYouOweMsg, b.Person, locale.FormatMoney(b.Balance.Absolute()))
+	}
+	return locale.Get(message.IouOwesYouMsg, b.Person, locale.FormatMoney(b.Balance))
+}
+
+// GetFormattedHTMLMsg returns who owes whom and since when, e.g. "Alice owes you $50.00 since 03 Jun 2024"
+func (b IouBalance) GetFormattedHTMLMsg(locale message.Locale, loc *time.Location) string {
+	since := locale.FormatDate(b.OpenSince.In(loc))
+	if b.Balance.IsNegative() {
+		return locale.Get(message.IouYouOweLine, html.EscapeString(b.Person), locale.FormatMoney(b.Balance.Absolute()), since)
+	}
+	return locale.Get(message.IouOwesYouLine, html.EscapeString(b.Person), locale.FormatMoney(b.Balance), since)
+}
+
+type IouBalances []IouBalance
+
+func (balances IouBalances) GetFormattedHTMLMsg(locale message.Locale, loc *time.Location) string {
+	text := ""
+	for _, b := range balances {
+		text += b.GetFormattedHTMLMsg(locale, loc)
+	}
+	return text
+}
+
+// OpenSince returns the balances outstanding since the time or earlier
+func (balances IouBalances) OpenSince(t time.Time) IouBalances {
+	old := IouBalances{}
+	for _, b := range balances {
+		if !b.OpenSince.After(t) {
+			old = append(old, b)
+		}
+	}
+	return old
+}
+
+// IouReminder reminds the user of the debts outstanding for Days or longer, at most once every Days
+type IouReminder struct {
+	UserId   int64
+	ChatId   int64
+	Days     int
+	Location *time.Location
+	Locale   string
+}
+
+func IouReminderFromEntity(e entity.IouReminder) (*IouReminder, error) {
+	loc, err := time.LoadLocation(e.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	return &IouReminder{
+		UserId:   e.UserId,
+		ChatId:   e.ChatId,
+		Days:     e.Days,
+		Location: loc,
+		Locale:   e.Locale,
+	}, nil
+}
+
+func (r IouReminder) GetLocale() message.Locale {
+	return message.GetLocale(r.Locale)
+}
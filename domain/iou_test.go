@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/message"
+)
+
+func TestIouBalancesGetFormattedHTMLMsg(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Singapore")
+	balances := IouBalances{
+		{Person: "Alice <3", Balance: money.New(5000, money.SGD), OpenSince: time.Date(2024, 6, 2, 20, 0, 0, 0, time.UTC)},
+		{Person: "Bob", Balance: money.New(-2000, money.SGD), OpenSince: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)},
+	}
+
+	got := balances.GetFormattedHTMLMsg(message.GetLocale("en"), loc)
+
+	want := "Alice &lt;3 owes you $50.00 since 03/06/24\nYou owe Bob $20.00 since 10/06/24\n"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestIouBalancesOpenSince(t *testing.T) {
+	cutoff := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	balances := IouBalances{
+		{Person: "Alice", OpenSince: cutoff.AddDate(0, 0, -1)},
+		{Person: "Bob", OpenSince: cutoff},
+		{Person: "Carol", OpenSince: cutoff.AddDate(0, 0, 1)},
+	}
+
+	old := balances.OpenSince(cutoff)
+
+	if len(old) != 2 || old[0].Person != "Alice" || old[1].Person != "Bob" {
+		t.Errorf("expected Alice and Bob, got %+v", old)
+	}
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/message"
+)
+
+const minutesInDay = 24 * 60
+
+type Reminder struct {
+	UserId       int64
+	ChatId       int64
+	Enabled      bool
+	RemindAt     int // minutes after local midnight
+	QuietStart   *int
+	QuietEnd     *int
+	SnoozedUntil *time.Time
+	LastSentOn   *time.Time
+	Location     *time.Location
+	Locale       string
+}
+
+func ReminderFromEntity(e entity.Reminder) (*Reminder, error) {
+	loc, err := time.LoadLocation(e.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	return &Reminder{
+		UserId:       e.UserId,
+		ChatId:       e.ChatId,
+		Enabled:      e.Enabled,
+		RemindAt:     e.RemindAt,
+		QuietStart:   e.QuietStart,
+		QuietEnd:     e.QuietEnd,
+		SnoozedUntil: e.SnoozedUntil,
+		LastSentOn:   e.LastSentOn,
+		Location:     loc,
+		Locale:       e.Locale,
+	}, nil
+}
+
+// IsDue returns true if the reminder should be sent at the given time. A snoozed reminder is due once the snooze
+// has passed, otherwise it is due once a day after the configured time. Nothing is due during quiet hours.
+func (r Reminder) IsDue(now time.Time) bool {
+	if !r.Enabled {
+		return false
+	}
+
+	local := now.In(r.Location)
+	if r.IsQuiet(local.Hour()*60 + local.Minute()) {
+		return false
+	}
+
+	if r.SnoozedUntil != nil {
+		return !now.Before(*r.SnoozedUntil)
+	}
+
+	if r.LastSentOn != nil && r.LastSentOn.Format(time.DateOnly) == local.Format(time.DateOnly) {
+		return false
+	}
+
+	return local.Hour()*60+local.Minute() >= r.RemindAt
+}
+
+// IsQuiet returns true if the minute of the day falls within the quiet hours, which may wrap past midnight.
+func (r Reminder) IsQuiet(minute int) bool {
+	if r.QuietStart == nil || r.QuietEnd == nil {
+		return false
+	}
+	start, end := *r.QuietStart, *r.QuietEnd
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+func (r Reminder) GetFormattedMsg(locale message.Locale) string {
+	if !r.Enabled {
+		return locale.Get(message.ReminderOffMsg)
+	}
+	text := locale.Get(message.ReminderOnMsg, FormatMinuteOfDay(r.RemindAt))
+	if r.QuietStart != nil && r.QuietEnd != nil {
+		text += locale.Get(message.ReminderQuietHoursMsg, FormatMinuteOfDay(*r.QuietStart), FormatMinuteOfDay(*r.QuietEnd))
+	}
+	if r.SnoozedUntil != nil {
+		text += locale.Get(message.ReminderSnoozedMsg, locale.FormatTime(r.SnoozedUntil.In(r.Location)))
+	}
+	return text
+}
+
+func (r Reminder) GetLocale() message.Locale {
+	return message.GetLocale(r.Locale)
+}
+
+// ParseMinuteOfDay parses a "HH:MM" 24-hour string into the number of minutes after midnight
+func ParseMinuteOfDay(s string) (int, error) {
+	hourString, minuteString, found := strings.Cut(strings.TrimSpace(s), ":")
+	if !found {
+		return 0, fmt.Errorf("invalid time of day: %s", s)
+	}
+	hour, err := strconv.Atoi(hourString)
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, fmt.Errorf("invalid hour: %s", s)
+	}
+	minute, err := strconv.Atoi(minuteString)
+	if err != nil || len(minuteString) != 2 || minute < 0 || minute > 59 {
+		return 0, fmt.Errorf("invalid minute: %s", s)
+	}
+	return hour*60 + minute, nil
+}
+
+// FormatMinuteOfDay formats minutes after midnight as "HH:MM"
+func FormatMinuteOfDay(minute int) string {
+	minute = ((minute % minutesInDay) + minutesInDay) % minutesInDay
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
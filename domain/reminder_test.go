@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReminderIsDue(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Singapore")
+	quietStart, quietEnd := 23*60, 7*60
+	yesterday := time.Date(2024, 6, 14, 0, 0, 0, 0, time.UTC)
+	today := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	snoozedUntil := time.Date(2024, 6, 15, 22, 0, 0, 0, loc)
+
+	tests := []struct {
+		name     string
+		reminder Reminder
+		now      time.Time
+		want     bool
+	}{
+		{"disabled", Reminder{Enabled: false, RemindAt: 21 * 60, Location: loc}, time.Date(2024, 6, 15, 21, 30, 0, 0, loc), false},
+		{"before remind time", Reminder{Enabled: true, RemindAt: 21 * 60, Location: loc}, time.Date(2024, 6, 15, 20, 59, 0, 0, loc), false},
+		{"at remind time", Reminder{Enabled: true, RemindAt: 21 * 60, Location: loc}, time.Date(2024, 6, 15, 21, 0, 0, 0, loc), true},
+		{"remind time in user timezone", Reminder{Enabled: true, RemindAt: 21 * 60, Location: loc}, time.Date(2024, 6, 15, 13, 0, 0, 0, time.UTC), true},
+		{"sent yesterday", Reminder{Enabled: true, RemindAt: 21 * 60, LastSentOn: &yesterday, Location: loc}, time.Date(2024, 6, 15, 21, 0, 0, 0, loc), true},
+		{"already sent today", Reminder{Enabled: true, RemindAt: 21 * 60, LastSentOn: &today, Location: loc}, time.Date(2024, 6, 15, 21, 30, 0, 0, loc), false},
+		{"quiet hours", Reminder{Enabled: true, RemindAt: 21 * 60, QuietStart: &quietStart, QuietEnd: &quietEnd, Location: loc}, time.Date(2024, 6, 15, 23, 30, 0, 0, loc), false},
+		{"snoozed", Reminder{Enabled: true, RemindAt: 21 * 60, LastSentOn: &today, SnoozedUntil: &snoozedUntil, Location: loc}, time.Date(2024, 6, 15, 21, 30, 0, 0, loc), false},
+		{"snooze passed", Reminder{Enabled: true, RemindAt: 21 * 60, LastSentOn: &today, SnoozedUntil: &snoozedUntil, Location: loc}, time.Date(2024, 6, 15, 22, 0, 0, 0, loc), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.reminder.IsDue(tt.now)
+			if got != tt.want {
+				t.Errorf("IsDue(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReminderIsQuiet(t *testing.T) {
+	overnightStart, overnightEnd := 23*60, 7*60
+	afternoonStart, afternoonEnd := 13*60, 14*60
+
+	overnight := Reminder{QuietStart: &overnightStart, QuietEnd: &overnightEnd}
+	afternoon := Reminder{QuietStart: &afternoonStart, QuietEnd: &afternoonEnd}
+
+	tests := []struct {
+		name     string
+		reminder Reminder
+		minute   int
+		want     bool
+	}{
+		{"no quiet hours", Reminder{}, 0, false},
+		{"overnight before midnight", overnight, 23*60 + 30, true},
+		{"overnight after midnight", overnight, 6 * 60, true},
+		{"overnight end is exclusive", overnight, 7 * 60, false},
+		{"overnight daytime", overnight, 12 * 60, false},
+		{"afternoon inside", afternoon, 13*60 + 30, true},
+		{"afternoon outside", afternoon, 15 * 60, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.reminder.IsQuiet(tt.minute)
+			if got != tt.want {
+				t.Errorf("IsQuiet(%d) = %v, want %v", tt.minute, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMinuteOfDay(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{"midnight", "00:00", 0, false},
+		{"evening", "21:30", 21*60 + 30, false},
+		{"single digit hour", "7:05", 7*60 + 5, false},
+		{"hour out of range", "24:00", 0, true},
+		{"minute out of range", "12:60", 0, true},
+		{"single digit minute", "12:5", 0, true},
+		{"no colon", "2100", 0, true},
+		{"not a number", "ab:cd", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMinuteOfDay(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseMinuteOfDay(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseMinuteOfDay(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatMinuteOfDay(t *testing.T) {
+	if got := FormatMinuteOfDay(21*60 + 5); got != "21:05" {
+		t.Errorf("FormatMinuteOfDay() = %q, want 21:05", got)
+	}
+	if got := FormatMinuteOfDay(0); got != "00:00" {
+		t.Errorf("FormatMinuteOfDay() = %q, want 00:00", got)
+	}
+}
@@ -3,6 +3,7 @@ package domain
 import (
 	"fmt"
 	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/message"
 	"strings"
 	"time"
 )
@@ -10,7 +11,7 @@ import (
 const (
 	monthYearHeaderHTMLMsg    = "<code>\n%v %v\n</code>"
 	transactionSummaryHTMLMsg = "<code>%v:%s %v\n</code>"
-	transactionTotalHTMLMsg   = "<code>🟡 Total: %v\n</code>"
+	transactionTotalHTMLMsg   = "<code>🟡 %s: %v\n</code>"
 )
 
 type MonthlySummaries []MonthlySummary
@@ -26,7 +27,7 @@ func (s MonthlySummaries) GetLongestLabelLength() int {
 	return longestLabel
 }
 
-func (s MonthlySummaries) GenerateReportText(currencyCode string) string {
+func (s MonthlySummaries) GenerateReportText(currencyCode string, locale message.Locale) string {
 	currMonth := ""
 	var totalAmountForTheMonth int64
 	var msg string
@@ -34,7 +35,7 @@ func (s MonthlySummaries) GenerateReportText(currencyCode string) string {
 	longestLabel := s.GetLongestLabelLength()
 
 	for i, summary := range s {
-		month := summary.Month.String()[:3]
+		month := locale.MonthName(summary.Month)
 		if currMonth != month {
 			msg += fmt.Sprintf(monthYearHeaderHTMLMsg, month, summary.Year)
 			currMonth = month
@@ -43,10 +44,10 @@ func (s MonthlySummaries) GenerateReportText(currencyCode string) string {
 
 		totalAmountForTheMonth += summary.Amount * summary.Multiplier
 		moneyAmount := money.New(summary.Amount, currencyCode)
-		msg += fmt.Sprintf(transactionSummaryHTMLMsg, summary.TransactionTypeLabel, summary.GetPaddedSpacesForLabel(longestLabel), moneyAmount.Display())
+		msg += fmt.Sprintf(transactionSummaryHTMLMsg, summary.TransactionTypeLabel, summary.GetPaddedSpacesForLabel(longestLabel), locale.FormatMoney(moneyAmount))
 
-		if i == len(s)-1 || s[i+1].Month.String()[:3] != currMonth {
-			msg += fmt.Sprintf(transactionTotalHTMLMsg, money.New(totalAmountForTheMonth, currencyCode).Display())
+		if i == len(s)-1 || locale.MonthName(s[i+1].Month) != currMonth {
+			msg += fmt.Sprintf(transactionTotalHTMLMsg, locale.Get(message.StatsTotalLabel), locale.FormatMoney(money.New(totalAmountForTheMonth, currencyCode)))
 		}
 	}
 	return msg
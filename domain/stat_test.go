@@ -3,6 +3,8 @@ package domain
 import (
 	"testing"
 	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/message"
 )
 
 func TestMonthlySummariesGetLongestLabelLength(t *testing.T) {
@@ -44,7 +46,7 @@ func TestMonthlySummariesGenerateReportText(t *testing.T) {
 		},
 	}
 
-	text := s.GenerateReportText("SGD")
+	text := s.GenerateReportText("SGD", message.GetLocale("en"))
 	if len(text) == 0 {
 		t.Error("expected non-empty report text")
 	}
@@ -2,18 +2,26 @@ package domain
 
 import (
 	"fmt"
+	"html"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/Rhymond/go-money"
 	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/message"
 )
 
-const PercentCategoryAmountMsg = "<code>%s%.1f%% %s %s%s\n</code>" // E.g. 82.8% Taxes    $1,234.00
-const ListTransactionHeader = "<b>%s %v</b>\n\n"                   // E.g. January 2023
-const ListTransactionBody = "<code>%s\n%s %s %s%s\n\n</code>"
+const PercentCategoryAmountMsg = "<code>%s%s%% %s %s%s\n</code>" // E.g. 82.8% Taxes    $1,234.00
+const ListTransactionHeader = "<b>%s</b>\n\n"                    // E.g. January 2023
+const ListTransactionBody = "<code>%s #%d%s\n%s %s %s%s\n\n</code>"
 const ListTransactionFooter = "<code>[%v/%v]</code>" //E.g. [1/3]
 
+const ListTrashBody = "<code>%d. %s #%d\n%s %s %s\n\n</code>" // E.g. 1. 03/06/24 12:30 #12\nFood Chicken Rice $5.50
+
+// TrashRetentionDays is how long a deleted transaction stays in the trash before it is purged
+const TrashRetentionDays = 30
+
 type Transaction struct {
 	Id           int
 	Datetime     time.Time
@@ -22,10 +30,84 @@ type Transaction struct {
 	Description  string
 	UserId       int64
 	Amount       *money.Money
+	// AccountId is the account the transaction was paid with, or 0 when none was chosen
+	AccountId   int
+	AccountName string
+	// DeletedTime is when the transaction was moved to the trash, or nil when it is not deleted
+	DeletedTime *time.Time
+	// RefundOf is the purchase the transaction is a refund of, or 0 when it is not a refund
+	RefundOf int
+	// Refunded is the sum of the refunds of the purchase, which are negative, or nil when it is not read with them
+	Refunded *money.Money
+	// Splits are the category lines of a transaction split across categories, which add up to its amount, or nil
+	// when it is not split
+	Splits []TransactionSplit
+}
+
+// TransactionSplit is the part of a transaction allocated to a category
+type TransactionSplit struct {
+	CategoryId   int
+	CategoryName string
+	Amount       *money.Money
+}
+
+func TransactionSplitFromEntity(e entity.TransactionSplit, currency string) TransactionSplit {
+	return TransactionSplit{
+		CategoryId:   e.CategoryId,
+		CategoryName: e.CategoryName,
+		Amount:       money.New(e.Amount, currency),
+	}
+}
+
+// IsSplit returns whether the transaction is split across categories
+func (t Transaction) IsSplit() bool {
+	return len(t.Splits) > 0
+}
+
+// Allocations returns the amounts of the transaction by category, which is its whole amount under its category when
+// it is not split
+func (t Transaction) Allocations() []TransactionSplit {
+	if t.IsSplit() {
+		return t.Splits
+	}
+	return []TransactionSplit{{CategoryId: t.CategoryId, CategoryName: t.CategoryName, Amount: t.Amount}}
+}
+
+// IsRefund returns whether the transaction is a refund of a purchase
+func (t Transaction) IsRefund() bool {
+	return t.RefundOf != 0
+}
+
+// Refundable returns what is left to refund of the purchase, which is its amount less the refunds read with it
+func (t Transaction) Refundable() *money.Money {
+	if t.Refunded == nil {
+		return t.Amount
+	}
+	left, err := t.Amount.Add(t.Refunded)
+	if err != nil {
+		return t.Amount
+	}
+	return left
+}
+
+// RefundLabel returns the link of a refund to its purchase in the locale, or nothing when it is not a refund
+func (t Transaction) RefundLabel(locale message.Locale) string {
+	if !t.IsRefund() {
+		return ""
+	}
+	return locale.Get(message.TransactionRefundLink, t.RefundOf)
+}
+
+// CategoryLabel returns the category of the transaction in the locale, or the split marker when it is split
+func (t Transaction) CategoryLabel(locale message.Locale) string {
+	if t.IsSplit() {
+		return locale.Get(message.TransactionSplitMarker)
+	}
+	return locale.CategoryName(t.CategoryName)
 }
 
 func TransactionFromEntity(e entity.Transaction) Transaction {
-	return Transaction{
+	t := Transaction{
 		Id:           e.Id,
 		Datetime:     e.Datetime,
 		CategoryId:   e.CategoryId,
@@ -33,26 +115,45 @@ func TransactionFromEntity(e entity.Transaction) Transaction {
 		Description:  e.Description,
 		UserId:       e.UserId,
 		Amount:       money.New(e.Amount, e.Currency),
+		DeletedTime:  e.DeletedTime,
+	}
+	if e.AccountId != nil {
+		t.AccountId = *e.AccountId
 	}
+	if e.AccountName != nil {
+		t.AccountName = *e.AccountName
+	}
+	if e.RefundOf != nil {
+		t.RefundOf = *e.RefundOf
+	}
+	if e.Refunded != 0 {
+		t.Refunded = money.New(e.Refunded, e.Currency)
+	}
+	for _, s := range e.Splits {
+		t.Splits = append(t.Splits, TransactionSplitFromEntity(s, e.Currency))
+	}
+	return t
 }
 
 type Transactions []Transaction
 
-func (trxs Transactions) GetFormattedHTMLMsg(searchedMonth time.Month, searchedYear int, loc *time.Location, totalCount int, currentOffset int, pageSize int) string {
-	text := fmt.Sprintf(ListTransactionHeader, searchedMonth.String(), searchedYear)
+// GetFormattedHTMLMsg lists the page of transactions under the name of the period searched, e.g. January 2023
+func (trxs Transactions) GetFormattedHTMLMsg(period string, loc *time.Location, locale message.Locale, totalCount int, currentOffset int, pageSize int) string {
+	text := fmt.Sprintf(ListTransactionHeader, period)
 	longest := 0
 
 	for _, t := range trxs {
-		length := len(t.CategoryName) + len(t.Description)
+		length := utf8.RuneCountInString(t.CategoryLabel(locale)) + utf8.RuneCountInString(t.Description)
 		if length > longest {
 			longest = length
 		}
 	}
 
 	for _, t := range trxs {
-		dtString := t.Datetime.In(loc).Format("02/01/06 15:04")
-		spacesToPadAfterDesc := longest - len(t.CategoryName) - len(t.Description)
-		text += fmt.Sprintf(ListTransactionBody, dtString, t.CategoryName, t.Description, strings.Repeat(" ", spacesToPadAfterDesc), t.Amount.Display())
+		dtString := locale.FormatDateTime(t.Datetime.In(loc))
+		categoryName := t.CategoryLabel(locale)
+		spacesToPadAfterDesc := longest - utf8.RuneCountInString(categoryName) - utf8.RuneCountInString(t.Description)
+		text += fmt.Sprintf(ListTransactionBody, dtString, t.Id, t.RefundLabel(locale), html.EscapeString(categoryName), html.EscapeString(t.Description), strings.Repeat(" ", spacesToPadAfterDesc), locale.FormatMoney(t.Amount))
 	}
 
 	numOfPages := (totalCount-1)/pageSize + 1
@@ -61,6 +162,16 @@ func (trxs Transactions) GetFormattedHTMLMsg(searchedMonth time.Month, searchedY
 	return text
 }
 
+// GetFormattedTrashHTMLMsg lists the transactions in the trash, numbered as the buttons that restore them
+func (trxs Transactions) GetFormattedTrashHTMLMsg(loc *time.Location, locale message.Locale) string {
+	text := ""
+	for i, t := range trxs {
+		dtString := locale.FormatDateTime(t.Datetime.In(loc))
+		text += fmt.Sprintf(ListTrashBody, i+1, dtString, t.Id, html.EscapeString(locale.CategoryName(t.CategoryName)), html.EscapeString(t.Description), locale.FormatMoney(t.Amount))
+	}
+	return text
+}
+
 type Breakdown struct {
 	CategoryName string
 	Amount       *money.Money
@@ -69,12 +180,12 @@ type Breakdown struct {
 
 type Breakdowns []Breakdown
 
-func (bds Breakdowns) GetFormattedHTMLMsg() string {
+func (bds Breakdowns) GetFormattedHTMLMsg(locale message.Locale) string {
 	text := ""
 
 	longest := 0
 	for _, b := range bds {
-		length := len(b.CategoryName)
+		length := utf8.RuneCountInString(locale.CategoryName(b.CategoryName))
 		if length > longest {
 			longest = length
 		}
@@ -85,8 +196,9 @@ func (bds Breakdowns) GetFormattedHTMLMsg() string {
 		if b.Percent < 10 {
 			spacesToPadBeforePercent = " "
 		}
-		spacesToPadAfterCategory := longest - len(b.CategoryName)
-		text += fmt.Sprintf(PercentCategoryAmountMsg, spacesToPadBeforePercent, b.Percent, b.CategoryName, strings.Repeat(" ", spacesToPadAfterCategory), b.Amount.Display())
+		categoryName := locale.CategoryName(b.CategoryName)
+		spacesToPadAfterCategory := longest - utf8.RuneCountInString(categoryName)
+		text += fmt.Sprintf(PercentCategoryAmountMsg, spacesToPadBeforePercent, locale.FormatNumber(b.Percent, 1), html.EscapeString(categoryName), strings.Repeat(" ", spacesToPadAfterCategory), locale.FormatMoney(b.Amount))
 	}
 	return text
 }
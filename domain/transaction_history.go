@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/audit"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/message"
+)
+
+// The changes to a transaction recorded in its history
+const (
+	ChangeCreate  = "create"
+	ChangeUpdate  = "update"
+	ChangeDelete  = "delete"
+	ChangeRestore = "restore"
+	ChangePurge   = "purge"
+)
+
+const transactionValuesBody = "%s %s %s %s" // E.g. 03/06/24 12:30 Food Chicken Rice $5.50
+
+var changeKeys = map[string]message.Key{
+	ChangeCreate:  message.HistoryActionCreate,
+	ChangeUpdate:  message.HistoryActionUpdate,
+	ChangeDelete:  message.HistoryActionDelete,
+	ChangeRestore: message.HistoryActionRestore,
+	ChangePurge:   message.HistoryActionPurge,
+}
+
+var sourceKeys = map[audit.Source]message.Key{
+	audit.SourceBot:       message.HistorySourceBot,
+	audit.SourceApi:       message.HistorySourceApi,
+	audit.SourceApp:       message.HistorySourceApp,
+	audit.SourceImport:    message.HistorySourceImport,
+	audit.SourceRecurring: message.HistorySourceRecurring,
+}
+
+// TransactionValues is what a transaction was at a point in its history
+type TransactionValues struct {
+	Datetime     time.Time
+	CategoryName string
+	Description  string
+	Amount       *money.Money
+	// AccountId is the account the transaction was paid with, or 0 when none was chosen
+	AccountId   int
+	AccountName string
+}
+
+func transactionValuesFromEntity(e *entity.TransactionSnapshot) *TransactionValues {
+	if e == nil {
+		return nil
+	}
+	v := TransactionValues{
+		Datetime:     e.Datetime,
+		CategoryName: e.CategoryName,
+		Description:  e.Description,
+		Amount:       money.New(e.Amount, e.Currency),
+	}
+	if e.AccountId != nil {
+		v.AccountId = *e.AccountId
+	}
+	if e.AccountName != nil {
+		v.AccountName = *e.AccountName
+	}
+	return &v
+}
+
+// GetFormattedMsg returns the values on one line, e.g. 03/06/24 12:30 Food Chicken Rice $5.50 (Cash)
+func (v TransactionValues) GetFormattedMsg(locale message.Locale, loc *time.Location) string {
+	text := fmt.Sprintf(transactionValuesBody, locale.FormatDateTime(v.Datetime.In(loc)), locale.CategoryName(v.CategoryName), v.Description, locale.FormatMoney(v.Amount))
+	if v.AccountName != "" {
+		text += " (" + v.AccountName + ")"
+	}
+	return text
+}
+
+// TransactionChange is a change to a transaction with its values before and after it. Before is nil when the
+// transaction was created or restored, and After is nil when it was deleted or purged.
+type TransactionChange struct {
+	Id            int64
+	TransactionId int
+	Action        string
+	Before        *TransactionValues
+	After         *TransactionValues
+	Origin        audit.Origin
+	Time          time.Time
+}
+
+func TransactionChangeFromEntity(e entity.TransactionAudit) TransactionChange {
+	return TransactionChange{
+		Id:            e.Id,
+		TransactionId: e.TransactionId,
+		Action:        e.Action,
+		Before:        transactionValuesFromEntity(e.Before),
+		After:         transactionValuesFromEntity(e.After),
+		Origin:        audit.Origin{Actor: e.Actor, Source: audit.Source(e.Source)},
+		Time:          e.CreateTime,
+	}
+}
+
+// ActionName returns what the change was in the locale, e.g. Edited
+func (c TransactionChange) ActionName(locale message.Locale) string {
+	key, ok := changeKeys[c.Action]
+	if !ok {
+		return c.Action
+	}
+	return locale.Get(key)
+}
+
+// OriginName returns who made the change and where from in the locale, e.g. API or trash job
+func (c TransactionChange) OriginName(locale message.Locale) string {
+	if c.Origin.IsJob() {
+		return locale.Get(message.HistorySourceJob, c.Origin.JobName())
+	}
+	key, ok := sourceKeys[c.Origin.Source]
+	if !ok {
+		return string(c.Origin.Source)
+	}
+	return locale.Get(key)
+}
+
+// Diff returns a line for each value the change updated, e.g. Amount: $5.50 → $6.00
+func (c TransactionChange) Diff(locale message.Locale, loc *time.Location) []string {
+	if c.Before == nil || c.After == nil {
+		return nil
+	}
+	var lines []string
+	add := func(key message.Key, before string, after string) {
+		if before != after {
+			lines = append(lines, locale.Get(message.HistoryDiffLine, locale.Get(key), before, after))
+		}
+	}
+	add(message.ExportDateHeader, locale.FormatDateTime(c.Before.Datetime.In(loc)), locale.FormatDateTime(c.After.Datetime.In(loc)))
+	add(message.ExportCatHeader, locale.CategoryName(c.Before.CategoryName), locale.CategoryName(c.After.CategoryName))
+	add(message.ExportDescHeader, c.Before.Description, c.After.Description)
+	add(message.ExportAmtHeader, locale.FormatMoney(c.Before.Amount), locale.FormatMoney(c.After.Amount))
+	add(message.ExportAccountHeader, accountNameOrNone(c.Before.AccountName), accountNameOrNone(c.After.AccountName))
+	return lines
+}
+
+func accountNameOrNone(name string) string {
+	if name == "" {
+		return "-"
+	}
+	return name
+}
+
+// GetFormattedHTMLMsg returns when and how the transaction changed, followed by what was updated or the values it was
+// created, deleted or restored with
+func (c TransactionChange) GetFormattedHTMLMsg(locale message.Locale, loc *time.Location) string {
+	var details string
+	switch {
+	case c.Before != nil && c.After != nil:
+		details = strings.Join(c.Diff(locale, loc), "\n")
+		if details == "" {
+			details = locale.Get(message.HistoryNoChangeMsg)
+		}
+	case c.After != nil:
+		details = c.After.GetFormattedMsg(locale, loc)
+	case c.Before != nil:
+		details = c.Before.GetFormattedMsg(locale, loc)
+	}
+	return locale.Get(message.HistoryEntryLine, locale.FormatDateTime(c.Time.In(loc)), c.ActionName(locale), c.OriginName(locale), html.EscapeString(details))
+}
+
+// TransactionHistory is the changes to transactions, the oldest first
+type TransactionHistory []TransactionChange
+
+func (history TransactionHistory) GetFormattedHTMLMsg(locale message.Locale, loc *time.Location) string {
+	text := ""
+	for _, c := range history {
+		text += c.GetFormattedHTMLMsg(locale, loc)
+	}
+	return text
+}
+
+// ForAccount returns the changes to the transactions that were paid with the account before or after the change
+func (history TransactionHistory) ForAccount(accountId int) TransactionHistory {
+	filtered := TransactionHistory{}
+	for _, c := range history {
+		if (c.Before != nil && c.Before.AccountId == accountId) || (c.After != nil && c.After.AccountId == accountId) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/audit"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/message"
+)
+
+func TestTransactionChangeFromEntity(t *testing.T) {
+	accountId, accountName := 3, "Cash"
+	e := entity.TransactionAudit{
+		Id:            1,
+		TransactionId: 12,
+		Action:        ChangeDelete,
+		Before:        &entity.TransactionSnapshot{CategoryName: "Food", Description: "Lunch", Amount: 550, Currency: "SGD", AccountId: &accountId, AccountName: &accountName},
+		Actor:         "job:trash",
+		Source:        "job",
+	}
+
+	c := TransactionChangeFromEntity(e)
+	if c.After != nil || c.Before == nil || c.Before.AccountId != 3 || c.Before.AccountName != "Cash" || c.Before.Amount.Amount() != 550 {
+		t.Errorf("unexpected values %+v", c)
+	}
+	if !c.Origin.IsJob() || c.Origin.JobName() != "trash" {
+		t.Errorf("expected the trash job, got %+v", c.Origin)
+	}
+}
+
+func TestTransactionHistory_GetFormattedHTMLMsg(t *testing.T) {
+	dt := time.Date(2024, 6, 3, 12, 30, 0, 0, time.UTC)
+	before := &TransactionValues{Datetime: dt, CategoryName: "Food", Description: "Lunch", Amount: money.New(550, "SGD")}
+	after := &TransactionValues{Datetime: dt, CategoryName: "Food", Description: "Fish & Chips", Amount: money.New(600, "SGD"), AccountName: "Cash"}
+	history := TransactionHistory{
+		{Action: ChangeCreate, After: before, Origin: audit.Origin{Actor: audit.ActorUser, Source: audit.SourceBot}, Time: dt},
+		{Action: ChangeUpdate, Before: before, After: after, Origin: audit.Origin{Actor: audit.ActorUser, Source: audit.SourceApi}, Time: dt.Add(time.Hour)},
+		{Action: ChangePurge, Before: after, Origin: audit.Origin{Actor: "job:trash", Source: audit.SourceJob}, Time: dt.Add(2 * time.Hour)},
+	}
+
+	text := history.GetFormattedHTMLMsg(message.GetLocale("en"), time.UTC)
+	for _, want := range []string{
+		"<b>Created</b> · bot\n03/06/24 12:30 Food Lunch $5.50",
+		"<b>Edited</b> · API\nDescription: Lunch → Fish &amp; Chips\nAmount: $5.50 → $6.00\nAccount: - → Cash",
+		"<b>Purged</b> · trash job\n03/06/24 12:30 Food Fish &amp; Chips $6.00 (Cash)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in %q", want, text)
+		}
+	}
+	if strings.Contains(text, "Date:") || strings.Contains(text, "Category:") {
+		t.Errorf("expected only the values that changed, got %q", text)
+	}
+}
+
+func TestTransactionHistory_ForAccount(t *testing.T) {
+	history := TransactionHistory{
+		{TransactionId: 1, After: &TransactionValues{AccountId: 3}},
+		{TransactionId: 2, Before: &TransactionValues{AccountId: 3}, After: &TransactionValues{AccountId: 4}},
+		{TransactionId: 3, After: &TransactionValues{}},
+	}
+
+	filtered := history.ForAccount(3)
+	if len(filtered) != 2 || filtered[0].TransactionId != 1 || filtered[1].TransactionId != 2 {
+		t.Errorf("expected the changes to or from the account, got %+v", filtered)
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/Rhymond/go-money"
 	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/message"
 )
 
 func TestTransactionsGetFormattedHTMLMsg(t *testing.T) {
@@ -29,7 +30,7 @@ func TestTransactionsGetFormattedHTMLMsg(t *testing.T) {
 		},
 	}
 
-	html := trxs.GetFormattedHTMLMsg(time.January, 2023, loc, 5, 0, 10)
+	html := trxs.GetFormattedHTMLMsg("January 2023", loc, message.GetLocale("en"), 5, 0, 10)
 
 	if len(html) == 0 {
 		t.Error("expected non-empty HTML message")
@@ -55,7 +56,7 @@ func TestBreakdownsGetFormattedHTMLMsg(t *testing.T) {
 		{CategoryName: "Shopping", Amount: money.New(2000, "SGD"), Percent: 20.0},
 	}
 
-	html := bds.GetFormattedHTMLMsg()
+	html := bds.GetFormattedHTMLMsg(message.GetLocale("en"))
 	if len(html) == 0 {
 		t.Error("expected non-empty HTML message")
 	}
@@ -73,12 +74,46 @@ func TestBreakdownsGetFormattedHTMLMsg(t *testing.T) {
 
 func TestEmptyBreakdowns(t *testing.T) {
 	bds := Breakdowns{}
-	html := bds.GetFormattedHTMLMsg()
+	html := bds.GetFormattedHTMLMsg(message.GetLocale("en"))
 	if html != "" {
 		t.Errorf("expected empty HTML for empty breakdowns, got %q", html)
 	}
 }
 
+func TestTransactionsGetFormattedHTMLMsg_Localized(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	dt, _ := time.ParseInLocation("2006-01-02 15:04", "2023-03-15 12:30", loc)
+
+	trxs := Transactions{
+		{Id: 1, Datetime: dt, CategoryName: "Food", Description: "Nasi Goreng", Amount: money.New(123456, "SGD")},
+	}
+
+	html := trxs.GetFormattedHTMLMsg("Maret 2023", loc, message.GetLocale("id"), 1, 0, 10)
+	if !contains(html, "<b>Maret 2023</b>") {
+		t.Errorf("expected the period as the header, got %q", html)
+	}
+	if !contains(html, "Makanan") {
+		t.Errorf("expected localized category name, got %q", html)
+	}
+	if !contains(html, "$1.234,56") {
+		t.Errorf("expected localized amount, got %q", html)
+	}
+	if !contains(html, "15/03/23 12.30") {
+		t.Errorf("expected localized date, got %q", html)
+	}
+}
+
+func TestBreakdownsGetFormattedHTMLMsg_Localized(t *testing.T) {
+	bds := Breakdowns{
+		{CategoryName: "Transport", Amount: money.New(300050, "SGD"), Percent: 62.5},
+	}
+
+	html := bds.GetFormattedHTMLMsg(message.GetLocale("id"))
+	if !contains(html, "62,5%") || !contains(html, "Transportasi") || !contains(html, "$3.000,50") {
+		t.Errorf("expected localized breakdown, got %q", html)
+	}
+}
+
 func TestTransactionFromEntity(t *testing.T) {
 	e := entity.Transaction{
 		Id:           1,
@@ -106,6 +141,82 @@ func TestTransactionFromEntity(t *testing.T) {
 	}
 }
 
+func TestTransactionsGetFormattedHTMLMsg_Split(t *testing.T) {
+	trxs := Transactions{
+		{
+			Id:           1,
+			CategoryName: "Grocery",
+			Description:  "NTUC",
+			Amount:       money.New(12000, "SGD"),
+			Splits: []TransactionSplit{
+				{CategoryId: 5, CategoryName: "Grocery", Amount: money.New(8000, "SGD")},
+				{CategoryId: 6, CategoryName: "Housing", Amount: money.New(4000, "SGD")},
+			},
+		},
+	}
+
+	html := trxs.GetFormattedHTMLMsg("January 2023", time.UTC, message.GetLocale("en"), 1, 0, 10)
+
+	if !contains(html, "✂️ Split NTUC $120.00") {
+		t.Errorf("expected one line with the split marker, got %q", html)
+	}
+	if contains(html, "Housing") {
+		t.Errorf("expected the parts left out of the list, got %q", html)
+	}
+}
+
+func TestTransactionsGetFormattedTrashHTMLMsg_EscapesHTML(t *testing.T) {
+	trxs := Transactions{
+		{Id: 12, CategoryName: "Food & Drinks", Description: "<3 Lunch", Amount: money.New(550, "SGD")},
+	}
+
+	html := trxs.GetFormattedTrashHTMLMsg(time.UTC, message.GetLocale("en"))
+
+	if !contains(html, "Food &amp; Drinks &lt;3 Lunch $5.50") {
+		t.Errorf("expected the category and description escaped, got %q", html)
+	}
+}
+
+func TestTransactionsGetFormattedHTMLMsg_Refund(t *testing.T) {
+	trxs := Transactions{
+		{Id: 15, CategoryName: "Food", Description: "Chicken Rice", Amount: money.New(-550, "SGD"), RefundOf: 12},
+		{Id: 12, CategoryName: "Food", Description: "Chicken Rice", Amount: money.New(550, "SGD")},
+	}
+
+	html := trxs.GetFormattedHTMLMsg("January 2023", time.UTC, message.GetLocale("en"), 2, 0, 10)
+
+	if !contains(html, "#15 ↩️ #12\n") || !contains(html, "#12\n") {
+		t.Errorf("expected the refund linked to its purchase, got %q", html)
+	}
+}
+
+func TestTransactionRefundable(t *testing.T) {
+	purchase := Transaction{Amount: money.New(550, "SGD")}
+	if got := purchase.Refundable().Amount(); got != 550 {
+		t.Errorf("expected the whole amount left to refund, got %d", got)
+	}
+	purchase.Refunded = money.New(-200, "SGD")
+	if got := purchase.Refundable().Amount(); got != 350 {
+		t.Errorf("expected 350 left to refund, got %d", got)
+	}
+}
+
+func TestTransactionAllocations(t *testing.T) {
+	whole := Transaction{CategoryId: 3, CategoryName: "Food", Amount: money.New(550, "SGD")}
+	if got := whole.Allocations(); len(got) != 1 || got[0].CategoryName != "Food" || got[0].Amount.Amount() != 550 {
+		t.Errorf("expected the whole amount under its category, got %+v", got)
+	}
+
+	split := Transaction{CategoryId: 5, CategoryName: "Grocery", Amount: money.New(12000, "SGD")}
+	split.Splits = []TransactionSplit{
+		{CategoryId: 5, CategoryName: "Grocery", Amount: money.New(8000, "SGD")},
+		{CategoryId: 6, CategoryName: "Housing", Amount: money.New(4000, "SGD")},
+	}
+	if got := split.Allocations(); len(got) != 2 || got[1].CategoryName != "Housing" || got[1].Amount.Amount() != 4000 {
+		t.Errorf("expected the parts of the split, got %+v", got)
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) > 0 && len(substr) > 0 && len(s) >= len(substr) && searchSubstring(s, substr)
 }
@@ -5,6 +5,15 @@ import (
 
 	"github.com/Rhymond/go-money"
 	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/message"
+)
+
+// The months stats can count a refund in
+const (
+	// RefundMonthOriginal counts a refund in the month of its purchase, so the purchase is netted where it was counted
+	RefundMonthOriginal = "original"
+	// RefundMonthRefund counts a refund in the month it was refunded
+	RefundMonthRefund = "refund"
 )
 
 type User struct {
@@ -12,6 +21,8 @@ type User struct {
 	Locale   string
 	Currency *money.Currency
 	Location *time.Location
+	// RefundMonth is the month stats count a refund in, RefundMonthOriginal or RefundMonthRefund
+	RefundMonth string
 }
 
 func UserFromEntity(e entity.User) (*User, error) {
@@ -20,9 +31,19 @@ func UserFromEntity(e entity.User) (*User, error) {
 		return nil, err
 	}
 	return &User{
-		Id:       e.Id,
-		Locale:   e.Locale,
-		Currency: money.GetCurrency(e.Currency),
-		Location: loc,
+		Id:          e.Id,
+		Locale:      e.Locale,
+		Currency:    money.GetCurrency(e.Currency),
+		Location:    loc,
+		RefundMonth: e.RefundMonth,
 	}, nil
 }
+
+// RefundsByPurchase returns whether stats count a refund in the month of its purchase
+func (u User) RefundsByPurchase() bool {
+	return u.RefundMonth != RefundMonthRefund
+}
+
+func (u User) GetLocale() message.Locale {
+	return message.GetLocale(u.Locale)
+}
@@ -44,3 +44,19 @@ func TestUserFromEntity_BadTimezone(t *testing.T) {
 		t.Error("expected error for invalid timezone")
 	}
 }
+
+func TestUserRefundsByPurchase(t *testing.T) {
+	tests := []struct {
+		refundMonth string
+		want        bool
+	}{
+		{RefundMonthOriginal, true},
+		{RefundMonthRefund, false},
+		{"", true},
+	}
+	for _, tt := range tests {
+		if got := (User{RefundMonth: tt.refundMonth}).RefundsByPurchase(); got != tt.want {
+			t.Errorf("RefundsByPurchase() with %q = %v, want %v", tt.refundMonth, got, tt.want)
+		}
+	}
+}
@@ -5,10 +5,11 @@ import (
 )
 
 type User struct {
-	Id       int64
-	Locale   string
-	Currency string
-	Timezone string
+	Id          int64
+	Locale      string
+	Currency    string
+	Timezone    string
+	RefundMonth string
 }
 
 type Transaction struct {
@@ -20,12 +21,138 @@ type Transaction struct {
 	UserId       int64
 	Amount       int64
 	Currency     string
+	AccountId    *int
+	AccountName  *string
+	DeletedTime  *time.Time
+	// RefundOf is the purchase the transaction is a refund of, or nil when it is not a refund
+	RefundOf *int
+	// Refunded is the sum of the refunds of the transaction, negative like their amounts, when it is read with it
+	Refunded int64
+	// Splits are the category lines of a transaction split across categories, which are not read with it
+	Splits []TransactionSplit `db:"-"`
+}
+
+// TransactionSplit is the part of a transaction allocated to a category
+type TransactionSplit struct {
+	Id            int
+	TransactionId int
+	CategoryId    int
+	CategoryName  string
+	Amount        int64
+}
+
+// TransactionAudit is a change to a transaction, with the values of the transaction before and after it
+type TransactionAudit struct {
+	Id            int64
+	TransactionId int
+	UserId        int64
+	Action        string
+	Before        *TransactionSnapshot
+	After         *TransactionSnapshot
+	Actor         string
+	Source        string
+	CreateTime    time.Time
+}
+
+// TransactionSnapshot is the values of a transaction at a point in time, stored as json in the audit log
+type TransactionSnapshot struct {
+	Datetime     time.Time `json:"datetime"`
+	CategoryId   int       `json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	Description  string    `json:"description"`
+	Amount       int64     `json:"amount"`
+	Currency     string    `json:"currency"`
+	AccountId    *int      `json:"account_id"`
+	AccountName  *string   `json:"account_name"`
 }
 
 type Category struct {
 	Id                int
 	Name              string
 	TransactionTypeId int
+	UserId            *int64
+}
+
+type Account struct {
+	Id             int
+	UserId         int64
+	Name           string
+	OpeningBalance int64
+	Currency       string
+}
+
+// AccountBalance is an account with its transactions and transfers applied to its opening balance
+type AccountBalance struct {
+	Id       int
+	Name     string
+	Balance  int64
+	Currency string
+}
+
+type Transfer struct {
+	Id            int
+	Datetime      time.Time
+	UserId        int64
+	FromAccountId int
+	ToAccountId   int
+	Amount        int64
+	Currency      string
+	Description   string
+}
+
+// Goal is an amount a user saves towards, with Saved being the sum of its contributions
+type Goal struct {
+	Id                   int
+	UserId               int64
+	ChatId               int64
+	Name                 string
+	Target               int64
+	Saved                int64
+	Currency             string
+	Deadline             *time.Time
+	MonthlyAmount        int64
+	NextContributionTime *time.Time
+	ReachedTime          *time.Time
+	CreateTime           time.Time
+}
+
+type GoalContribution struct {
+	Id        int
+	GoalId    int
+	Datetime  time.Time
+	Amount    int64
+	Currency  string
+	Automatic bool
+}
+
+// Iou is money lent to a person when Amount is positive, or borrowed from them when it is negative
+type Iou struct {
+	Id          int
+	UserId      int64
+	Person      string
+	Amount      int64
+	Currency    string
+	Description string
+	Datetime    time.Time
+	SettledTime *time.Time
+}
+
+// IouBalance is what a person owes the user when Balance is positive, or what the user owes them when it is negative,
+// outstanding since OpenSince
+type IouBalance struct {
+	Person    string
+	Balance   int64
+	Currency  string
+	OpenSince time.Time
+}
+
+type IouReminder struct {
+	UserId       int64
+	ChatId       int64
+	Days         int
+	LastSentTime *time.Time
+	Timezone     string
+	Locale       string
 }
 
 type MonthlySummary struct {
@@ -35,6 +162,12 @@ type MonthlySummary struct {
 	Multiplier           int64
 }
 
+// Currency is a row of the currency table, with the minor units in one major unit of the currency, e.g. 100 for SGD
+type Currency struct {
+	Code        string
+	Denominator int
+}
+
 type TransactionType struct {
 	Id         int
 	Name       string
@@ -48,9 +181,44 @@ type MessageContext struct {
 	MessageId int
 	Message   string
 	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Conversation is a step by step flow with a user in a chat, Data holds the json of what the flow has collected
+type Conversation struct {
+	Id           int
+	ChatId       int64
+	UserId       int64
+	Flow         string
+	State        string
+	Data         []byte
+	AwaitingText bool
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
 }
 
 type TransactionBreakdown struct {
 	CategoryName string
 	Amount       int64
 }
+
+type Reminder struct {
+	UserId       int64
+	ChatId       int64
+	Enabled      bool
+	RemindAt     int
+	QuietStart   *int
+	QuietEnd     *int
+	SnoozedUntil *time.Time
+	LastSentOn   *time.Time
+	Timezone     string
+	Locale       string
+}
+
+type ApiToken struct {
+	Id           int
+	UserId       int64
+	TokenHash    string
+	LastUsedTime *time.Time
+	CreateTime   time.Time
+}
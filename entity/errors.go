@@ -0,0 +1,10 @@
+package entity
+
+import "errors"
+
+var (
+	// ErrNotFound is wrapped by lookups that find no matching row
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is wrapped by writes that clash with existing rows, such as a duplicate name or a row still in use
+	ErrConflict = errors.New("conflict")
+)
@@ -2,12 +2,24 @@ package entity
 
 import "time"
 
+// TransactionListQuery pages through the transactions of a user from DateFrom up to but excluding DateTo, of the
+// account AccountId or of all accounts when it is 0
 type TransactionListQuery struct {
-	Month    time.Month
-	Year     int
-	Offset   int
-	Limit    int
-	Asc      bool
-	UserId   int64
-	Location *time.Location
+	DateFrom  time.Time
+	DateTo    time.Time
+	Offset    int
+	Limit     int
+	Asc       bool
+	UserId    int64
+	AccountId int
+}
+
+// TransactionFilter narrows down the transactions of a user, a zero value field does not filter
+type TransactionFilter struct {
+	UserId     int64
+	DateFrom   time.Time
+	DateTo     time.Time
+	CategoryId int
+	Offset     int
+	Limit      int
 }
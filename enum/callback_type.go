@@ -4,11 +4,19 @@ type CallbackType string
 type PaginateAction string
 
 const (
-	TransactionType CallbackType = "TransactionType"
-	Category        CallbackType = "Category"
-	Pagination      CallbackType = "Pagination"
-	Undo            CallbackType = "Undo"
-	Cancel          CallbackType = "Cancel"
+	TransactionType    CallbackType = "TransactionType"
+	Category           CallbackType = "Category"
+	Pagination         CallbackType = "Pagination"
+	Undo               CallbackType = "Undo"
+	Cancel             CallbackType = "Cancel"
+	Snooze             CallbackType = "Snooze"
+	LogNow             CallbackType = "LogNow"
+	Language           CallbackType = "Language"
+	CancelConversation CallbackType = "CancelConversation"
+	Account            CallbackType = "Account"
+	Restore            CallbackType = "Restore"
+	Split              CallbackType = "Split"
+	Refund             CallbackType = "Refund"
 
 	Next     PaginateAction = "Next"
 	Previous PaginateAction = "Prev"
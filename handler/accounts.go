@@ -0,0 +1,343 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	"github.com/aattwwss/telegram-expense-bot/util"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const accountNameLimit = 50
+
+var errTooManyAccounts = errors.New("more than one account hinted")
+
+// accountHintError is a hint typed as ^hint that does not refer to exactly one account of the user
+type accountHintError struct {
+	hint string
+	err  error
+}
+
+func (e accountHintError) Error() string {
+	return e.err.Error()
+}
+
+func (e accountHintError) Unwrap() error {
+	return e.err
+}
+
+func (e accountHintError) reply(locale message.Locale) string {
+	if errors.Is(e.err, entity.ErrConflict) {
+		return locale.Get(message.AccountAmbiguousMsg, e.hint)
+	}
+	return locale.Get(message.AccountUnknownMsg, e.hint)
+}
+
+// findAccountsByHint returns the account of the user each hint refers to, in the order of the hints. It returns an
+// accountHintError for the first hint that does not refer to exactly one account.
+func findAccountsByHint(ctx context.Context, accountRepo AccountRepo, userId int64, hints []string) (domain.Accounts, error) {
+	accounts, err := accountRepo.FindAllByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var found domain.Accounts
+	for _, hint := range hints {
+		account, err := accounts.FindByHint(hint)
+		if err != nil {
+			return nil, accountHintError{hint: hint, err: err}
+		}
+		found = append(found, account)
+	}
+	return found, nil
+}
+
+// listArgs are the arguments of /list and /export, which are a period and optionally an account, e.g. last month ^dbs
+type listArgs struct {
+	period  util.Period
+	account *domain.Account
+}
+
+// parseListArgs parses the arguments of /list and /export. It returns util.ErrInvalidPeriod for a period it does not
+// understand, errTooManyAccounts for more than one hint and an accountHintError for a hint that cannot be used.
+func parseListArgs(ctx context.Context, accountRepo AccountRepo, s string, user domain.User) (listArgs, error) {
+	rest, hints := parseAccountHints(s)
+	period, err := util.ParsePeriod(rest, time.Now().In(user.Location))
+	if err != nil {
+		return listArgs{}, err
+	}
+	if len(hints) == 0 {
+		return listArgs{period: period}, nil
+	}
+	if len(hints) > 1 {
+		return listArgs{}, errTooManyAccounts
+	}
+
+	accounts, err := findAccountsByHint(ctx, accountRepo, user.Id, hints)
+	if err != nil {
+		return listArgs{}, err
+	}
+	return listArgs{period: period, account: &accounts[0]}, nil
+}
+
+// label returns the period, with the name of the account when the list is filtered by one
+func (args listArgs) label(locale message.Locale) string {
+	if args.account == nil {
+		return args.period.Label(locale)
+	}
+	return locale.Get(message.AccountFilterLabel, args.period.Label(locale), args.account.Name)
+}
+
+// accountId returns the id of the account the list is filtered by, or 0 for all accounts
+func (args listArgs) accountId() int {
+	if args.account == nil {
+		return 0
+	}
+	return args.account.Id
+}
+
+// readListArgs parses the arguments of /list and /export, or replies why they cannot be used and returns false
+func (handler CommandHandler) readListArgs(ctx context.Context, bot *sender.Sender, chatId int64, user domain.User, s string) (listArgs, bool) {
+	locale := user.GetLocale()
+	args, err := parseListArgs(ctx, handler.accountRepo, s, user)
+	var hintErr accountHintError
+	switch {
+	case err == nil:
+		return args, true
+	case errors.Is(err, util.ErrInvalidPeriod):
+		util.BotSendMessage(bot, chatId, locale.Get(message.PeriodInvalidMsg))
+	case errors.Is(err, errTooManyAccounts):
+		util.BotSendMessage(bot, chatId, locale.Get(message.AccountTooManyMsg))
+	case errors.As(err, &hintErr):
+		util.BotSendMessage(bot, chatId, hintErr.reply(locale))
+	default:
+		log.Ctx(ctx).Error().Msgf("Error parsing list arguments: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+	}
+	return listArgs{}, false
+}
+
+// findAccounts returns the account of the user each hint refers to, or replies why a hint cannot be used and returns
+// false
+func (handler CommandHandler) findAccounts(ctx context.Context, bot *sender.Sender, chatId int64, user domain.User, hints []string) (domain.Accounts, bool) {
+	locale := user.GetLocale()
+	accounts, err := findAccountsByHint(ctx, handler.accountRepo, user.Id, hints)
+	var hintErr accountHintError
+	if errors.As(err, &hintErr) {
+		util.BotSendMessage(bot, chatId, hintErr.reply(locale))
+		return nil, false
+	}
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error finding accounts by hint: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return nil, false
+	}
+	return accounts, true
+}
+
+// Account lists the accounts of the user, or adds an account, sets its opening balance or deletes it, e.g.
+// /account add DBS card, /account opening ^dbs 1200.50 and /account delete ^dbs
+func (handler CommandHandler) Account(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	user := userFromContext(ctx)
+	chatId := update.Message.Chat.ID
+
+	action, rest, _ := strings.Cut(strings.TrimSpace(update.Message.CommandArguments()), " ")
+	rest = strings.TrimSpace(rest)
+	switch strings.ToLower(action) {
+	case "add":
+		handler.addAccount(ctx, bot, chatId, *user, rest)
+	case "opening":
+		handler.setOpeningBalance(ctx, bot, chatId, *user, rest)
+	case "delete":
+		handler.deleteAccount(ctx, bot, chatId, *user, rest)
+	default:
+		handler.listAccounts(ctx, bot, chatId, *user)
+	}
+}
+
+func (handler CommandHandler) listAccounts(ctx context.Context, bot *sender.Sender, chatId int64, user domain.User) {
+	locale := user.GetLocale()
+	accounts, err := handler.accountRepo.FindAllByUserId(ctx, user.Id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("FindAllByUserId accounts error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	if len(accounts) == 0 {
+		util.BotSendMessage(bot, chatId, locale.Get(message.AccountNoneMsg)+locale.Get(message.AccountUsageMsg))
+		return
+	}
+
+	lines := ""
+	for _, account := range accounts {
+		lines += fmt.Sprintf("^%s %s\n", accounts.Hint(account), account.Name)
+	}
+	util.BotSendMessage(bot, chatId, locale.Get(message.AccountListMsg, lines)+locale.Get(message.AccountUsageMsg))
+}
+
+func (handler CommandHandler) addAccount(ctx context.Context, bot *sender.Sender, chatId int64, user domain.User, name string) {
+	locale := user.GetLocale()
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || utf8.RuneCountInString(name) > accountNameLimit || strings.Contains(name, "^") {
+		util.BotSendMessage(bot, chatId, locale.Get(message.AccountNameInvalidMsg, accountNameLimit))
+		return
+	}
+
+	id, err := handler.accountRepo.Add(ctx, domain.Account{
+		UserId:         user.Id,
+		Name:           name,
+		OpeningBalance: money.New(0, user.Currency.Code),
+	})
+	if errors.Is(err, entity.ErrConflict) {
+		util.BotSendMessage(bot, chatId, locale.Get(message.AccountExistsMsg))
+		return
+	}
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Add account error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+
+	accounts, err := handler.accountRepo.FindAllByUserId(ctx, user.Id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("FindAllByUserId accounts error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	util.BotSendMessage(bot, chatId, locale.Get(message.AccountAddedMsg, name, accounts.Hint(domain.Account{Id: id, Name: name})))
+}
+
+func (handler CommandHandler) setOpeningBalance(ctx context.Context, bot *sender.Sender, chatId int64, user domain.User, args string) {
+	locale := user.GetLocale()
+	text, hints := parseAccountHints(args)
+	if len(hints) != 1 {
+		util.BotSendMessage(bot, chatId, locale.Get(message.AccountUsageMsg))
+		return
+	}
+	amount, rest, err := parseAmount(text, *user.Currency)
+	if err != nil || rest != "" {
+		util.BotSendMessage(bot, chatId, locale.Get(message.AccountUsageMsg))
+		return
+	}
+
+	accounts, ok := handler.findAccounts(ctx, bot, chatId, user, hints)
+	if !ok {
+		return
+	}
+	account := accounts[0]
+
+	err = handler.accountRepo.SetOpeningBalance(ctx, account.Id, user.Id, amount)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("SetOpeningBalance error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	util.BotSendMessage(bot, chatId, locale.Get(message.AccountOpeningSetMsg, account.Name, locale.FormatMoney(amount)))
+}
+
+func (handler CommandHandler) deleteAccount(ctx context.Context, bot *sender.Sender, chatId int64, user domain.User, args string) {
+	locale := user.GetLocale()
+	text, hints := parseAccountHints(args)
+	if len(hints) != 1 || text != "" {
+		util.BotSendMessage(bot, chatId, locale.Get(message.AccountUsageMsg))
+		return
+	}
+
+	accounts, ok := handler.findAccounts(ctx, bot, chatId, user, hints)
+	if !ok {
+		return
+	}
+	account := accounts[0]
+
+	err := handler.accountRepo.DeleteById(ctx, account.Id, user.Id)
+	if errors.Is(err, entity.ErrConflict) {
+		util.BotSendMessage(bot, chatId, locale.Get(message.AccountInUseMsg, account.Name))
+		return
+	}
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Delete account error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	util.BotSendMessage(bot, chatId, locale.Get(message.AccountDeletedMsg, account.Name))
+}
+
+// Transfer moves an amount from one account of the user to another, e.g. /transfer 100 ^dbs ^cash ATM, which is not
+// counted as spending
+func (handler CommandHandler) Transfer(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	user := userFromContext(ctx)
+	locale := user.GetLocale()
+	chatId := update.Message.Chat.ID
+
+	text, hints := parseAccountHints(update.Message.CommandArguments())
+	if len(hints) != 2 {
+		util.BotSendMessage(bot, chatId, locale.Get(message.TransferUsageMsg))
+		return
+	}
+	amount, description, err := parseAmount(text, *user.Currency)
+	if err != nil || !amount.IsPositive() {
+		util.BotSendMessage(bot, chatId, locale.Get(message.TransferUsageMsg))
+		return
+	}
+	if len(description) > descLengthLimit {
+		util.BotSendMessage(bot, chatId, locale.Get(message.DescriptionTooLongMsg, descLengthLimit))
+		return
+	}
+
+	accounts, ok := handler.findAccounts(ctx, bot, chatId, *user, hints)
+	if !ok {
+		return
+	}
+	from, to := accounts[0], accounts[1]
+	if from.Id == to.Id {
+		util.BotSendMessage(bot, chatId, locale.Get(message.TransferUsageMsg))
+		return
+	}
+
+	_, err = handler.accountRepo.Transfer(ctx, domain.Transfer{
+		Datetime:      time.Now(),
+		UserId:        user.Id,
+		FromAccountId: from.Id,
+		ToAccountId:   to.Id,
+		Amount:        amount,
+		Description:   description,
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Transfer error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	util.BotSendMessage(bot, chatId, locale.Get(message.TransferReplyMsg, locale.FormatMoney(amount), from.Name, to.Name))
+}
+
+// Balances replies with the balance of each account of the user
+func (handler CommandHandler) Balances(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	user := userFromContext(ctx)
+	locale := user.GetLocale()
+	chatId := update.Message.Chat.ID
+
+	balances, err := handler.accountRepo.GetBalances(ctx, user.Id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("GetBalances error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	if len(balances) == 0 {
+		util.BotSendMessage(bot, chatId, locale.Get(message.AccountNoneMsg)+locale.Get(message.AccountUsageMsg))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatId, locale.Get(message.BalancesHeader)+balances.GetFormattedHTMLMsg(locale))
+	msg.ParseMode = tgbotapi.ModeHTML
+	util.BotSendWrapper(bot, msg)
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/util"
+)
+
+func accountsTestContext() context.Context {
+	user := &domain.User{Id: 1, Currency: money.GetCurrency("SGD"), Location: time.UTC, Locale: "en"}
+	return context.WithValue(context.Background(), userKey{}, user)
+}
+
+func withAccounts(accounts domain.Accounts) mockAccountRepo {
+	return mockAccountRepo{
+		findAllByUserIdFn: func(ctx context.Context, userId int64) (domain.Accounts, error) {
+			return accounts, nil
+		},
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	var transferred domain.Transfer
+	ar := withAccounts(domain.Accounts{{Id: 7, Name: "Cash"}, {Id: 8, Name: "DBS card"}})
+	ar.transferFn = func(ctx context.Context, transfer domain.Transfer) (int, error) {
+		transferred = transfer
+		return 1, nil
+	}
+	handler := CommandHandler{accountRepo: ar}
+	bot, client := newRecordingSender()
+
+	handler.Transfer(accountsTestContext(), bot, commandUpdate("/transfer 100 ^dbs ^cash ATM"))
+
+	if transferred.FromAccountId != 8 || transferred.ToAccountId != 7 || transferred.Amount.Amount() != 10000 || transferred.Description != "ATM" {
+		t.Errorf("unexpected transfer %+v", transferred)
+	}
+	if len(client.requests) != 1 || !strings.Contains(client.requests[0], "from+DBS+card+to+Cash") {
+		t.Errorf("expected the transfer to be confirmed, got %v", client.requests)
+	}
+}
+
+func TestTransfer_Invalid(t *testing.T) {
+	ar := withAccounts(domain.Accounts{{Id: 7, Name: "Cash"}, {Id: 8, Name: "DBS card"}})
+	ar.transferFn = func(ctx context.Context, transfer domain.Transfer) (int, error) {
+		t.Errorf("expected no transfer, got %+v", transfer)
+		return 0, nil
+	}
+	handler := CommandHandler{accountRepo: ar}
+
+	for text, want := range map[string]string{
+		"/transfer 100 ^dbs":        "Type+%2Ftransfer",
+		"/transfer 0 ^dbs ^cash":    "Type+%2Ftransfer",
+		"/transfer 100 ^cash ^cash": "Type+%2Ftransfer",
+		"/transfer 100 ^dbs ^ocbc":  "no+account+starting+with+%5Eocbc",
+	} {
+		bot, client := newRecordingSender()
+		handler.Transfer(accountsTestContext(), bot, commandUpdate(text))
+		if len(client.requests) != 1 || !strings.Contains(client.requests[0], want) {
+			t.Errorf("%s: expected a reply with %s, got %v", text, want, client.requests)
+		}
+	}
+}
+
+func TestAccount_Add(t *testing.T) {
+	var added domain.Account
+	ar := withAccounts(domain.Accounts{{Id: 7, Name: "DBS PayLah"}, {Id: 8, Name: "DBS card"}})
+	ar.addFn = func(ctx context.Context, account domain.Account) (int, error) {
+		added = account
+		return 8, nil
+	}
+	handler := CommandHandler{accountRepo: ar}
+	bot, client := newRecordingSender()
+
+	handler.Account(accountsTestContext(), bot, commandUpdate("/account add  DBS   card"))
+
+	if added.Name != "DBS card" || added.UserId != 1 || !added.OpeningBalance.IsZero() {
+		t.Errorf("unexpected account %+v", added)
+	}
+	if len(client.requests) != 1 || !strings.Contains(client.requests[0], "Add+%5Edbsc+to") {
+		t.Errorf("expected the hint of the account in the reply, got %v", client.requests)
+	}
+}
+
+func TestAccount_AddExists(t *testing.T) {
+	ar := mockAccountRepo{
+		addFn: func(ctx context.Context, account domain.Account) (int, error) {
+			return 0, entity.ErrConflict
+		},
+	}
+	handler := CommandHandler{accountRepo: ar}
+	bot, client := newRecordingSender()
+
+	handler.Account(accountsTestContext(), bot, commandUpdate("/account add Cash"))
+
+	if len(client.requests) != 1 || !strings.Contains(client.requests[0], "already+have+an+account") {
+		t.Errorf("expected the account to exist, got %v", client.requests)
+	}
+}
+
+func TestParseListArgs(t *testing.T) {
+	ar := withAccounts(domain.Accounts{{Id: 7, Name: "Cash"}, {Id: 8, Name: "DBS card"}})
+	user := domain.User{Id: 1, Location: time.UTC}
+
+	args, err := parseListArgs(context.Background(), ar, "jan 2024 ^dbs", user)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if args.accountId() != 8 || args.period.From.Format("2006-01-02") != "2024-01-01" {
+		t.Errorf("unexpected arguments %+v", args)
+	}
+
+	args, err = parseListArgs(context.Background(), mockAccountRepo{}, "jan 2024", user)
+	if err != nil || args.accountId() != 0 {
+		t.Errorf("expected all accounts, got %+v, %v", args, err)
+	}
+
+	var hintErr accountHintError
+	if _, err := parseListArgs(context.Background(), ar, "^ocbc", user); !errors.As(err, &hintErr) || hintErr.hint != "ocbc" {
+		t.Errorf("expected an unknown account, got %v", err)
+	}
+	if _, err := parseListArgs(context.Background(), ar, "^dbs ^cash", user); !errors.Is(err, errTooManyAccounts) {
+		t.Errorf("expected too many accounts, got %v", err)
+	}
+	if _, err := parseListArgs(context.Background(), ar, "someday ^dbs", user); !errors.Is(err, util.ErrInvalidPeriod) {
+		t.Errorf("expected an invalid period, got %v", err)
+	}
+}
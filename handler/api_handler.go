@@ -0,0 +1,650 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/audit"
+	"github.com/aattwwss/telegram-expense-bot/docs"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/util"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	ApiPrefix = "/api/v1/"
+
+	categoryNameLengthLimit  = 50
+	defaultTransactionTypeId = 1 // custom categories are all spending for now
+
+	apiDefaultPageSize = 50
+	apiMaxPageSize     = 1000
+	apiMaxBodyBytes    = 1 << 16
+)
+
+type ApiHandler struct {
+	userRepo        UserRepo
+	transactionRepo TransactionRepo
+	categoryRepo    CategoryRepo
+	apiTokenRepo    ApiTokenRepo
+}
+
+func NewApiHandler(userRepo UserRepo, transactionRepo TransactionRepo, categoryRepo CategoryRepo, apiTokenRepo ApiTokenRepo) ApiHandler {
+	return ApiHandler{
+		userRepo:        userRepo,
+		transactionRepo: transactionRepo,
+		categoryRepo:    categoryRepo,
+		apiTokenRepo:    apiTokenRepo,
+	}
+}
+
+// apiHandlerFunc serves a request of an authenticated user
+type apiHandlerFunc func(w http.ResponseWriter, r *http.Request, user domain.User)
+
+// Routes returns the handler of all the endpoints under ApiPrefix
+func (handler ApiHandler) Routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+ApiPrefix+"openapi.yaml", handler.OpenApi)
+	handler.register(mux, ApiPrefix, handler.authenticate)
+	return mux
+}
+
+// register adds the endpoints under the prefix, with auth resolving the user of each request
+func (handler ApiHandler) register(mux *http.ServeMux, prefix string, auth func(apiHandlerFunc) http.HandlerFunc) {
+	mux.HandleFunc("GET "+prefix+"transactions", auth(handler.ListTransactions))
+	mux.HandleFunc("POST "+prefix+"transactions", auth(handler.CreateTransaction))
+	mux.HandleFunc("GET "+prefix+"transactions/{id}", auth(handler.GetTransaction))
+	mux.HandleFunc("PATCH "+prefix+"transactions/{id}", auth(handler.UpdateTransaction))
+	mux.HandleFunc("DELETE "+prefix+"transactions/{id}", auth(handler.DeleteTransaction))
+	mux.HandleFunc("GET "+prefix+"categories", auth(handler.ListCategories))
+	mux.HandleFunc("POST "+prefix+"categories", auth(handler.CreateCategory))
+	mux.HandleFunc("PATCH "+prefix+"categories/{id}", auth(handler.RenameCategory))
+	mux.HandleFunc("DELETE "+prefix+"categories/{id}", auth(handler.DeleteCategory))
+	mux.HandleFunc("GET "+prefix+"stats", auth(handler.Stats))
+	mux.HandleFunc(prefix, func(w http.ResponseWriter, r *http.Request) {
+		writeApiError(w, http.StatusNotFound, "not found")
+	})
+}
+
+// authenticate resolves the user of the bearer token before calling next
+func (handler ApiHandler) authenticate(next apiHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || strings.TrimSpace(token) == "" {
+			writeApiError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		userId, err := handler.apiTokenRepo.Authenticate(r.Context(), strings.TrimSpace(token))
+		if errors.Is(err, entity.ErrNotFound) {
+			writeApiError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		if err != nil {
+			log.Ctx(r.Context()).Error().Msgf("Error authenticating api token: %v", err)
+			writeApiError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		user, err := handler.userRepo.FindUserById(r.Context(), userId)
+		if err != nil || user == nil {
+			log.Ctx(r.Context()).Error().Msgf("Error finding user for api: %v", err)
+			writeApiError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		next(w, r.WithContext(audit.WithSource(r.Context(), audit.SourceApi)), *user)
+	}
+}
+
+func (handler ApiHandler) OpenApi(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(docs.OpenApi)
+}
+
+func (handler ApiHandler) ListTransactions(w http.ResponseWriter, r *http.Request, user domain.User) {
+	filter, err := parseTransactionFilter(r, user)
+	if err != nil {
+		writeApiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	transactions, totalCount, err := handler.transactionRepo.List(r.Context(), filter)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Msgf("Error listing transactions for api: %v", err)
+		writeApiError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	res := apiTransactionList{
+		Transactions: []apiTransaction{},
+		TotalCount:   totalCount,
+		Offset:       filter.Offset,
+		Limit:        filter.Limit,
+	}
+	for _, t := range transactions {
+		res.Transactions = append(res.Transactions, newApiTransaction(t))
+	}
+	writeApiJson(w, http.StatusOK, res)
+}
+
+func (handler ApiHandler) CreateTransaction(w http.ResponseWriter, r *http.Request, user domain.User) {
+	var req apiTransactionRequest
+	err := decodeApiRequest(w, r, &req)
+	if err != nil {
+		writeApiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Amount == nil || req.CategoryId == nil {
+		writeApiError(w, http.StatusBadRequest, "amount and category_id are required")
+		return
+	}
+
+	transaction := domain.Transaction{
+		Datetime: time.Now(),
+		UserId:   user.Id,
+	}
+	status, err := handler.applyTransactionRequest(r.Context(), &transaction, req, user)
+	if err != nil {
+		writeApiError(w, status, err.Error())
+		return
+	}
+
+	id, err := handler.transactionRepo.Add(r.Context(), transaction)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Msgf("Error adding transaction for api: %v", err)
+		writeApiError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	handler.writeTransaction(w, r, http.StatusCreated, id, user)
+}
+
+func (handler ApiHandler) GetTransaction(w http.ResponseWriter, r *http.Request, user domain.User) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeApiError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	handler.writeTransaction(w, r, http.StatusOK, id, user)
+}
+
+// UpdateTransaction only changes the fields present in the request
+func (handler ApiHandler) UpdateTransaction(w http.ResponseWriter, r *http.Request, user domain.User) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeApiError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var req apiTransactionRequest
+	err = decodeApiRequest(w, r, &req)
+	if err != nil {
+		writeApiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	transaction, err := handler.transactionRepo.GetById(r.Context(), id, user.Id)
+	if errors.Is(err, entity.ErrNotFound) {
+		writeApiError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Msgf("Error getting transaction for api: %v", err)
+		writeApiError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	status, err := handler.applyTransactionRequest(r.Context(), &transaction, req, user)
+	if err != nil {
+		writeApiError(w, status, err.Error())
+		return
+	}
+
+	err = handler.transactionRepo.Update(r.Context(), transaction)
+	if errors.Is(err, entity.ErrNotFound) {
+		writeApiError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Msgf("Error updating transaction for api: %v", err)
+		writeApiError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	handler.writeTransaction(w, r, http.StatusOK, id, user)
+}
+
+func (handler ApiHandler) DeleteTransaction(w http.ResponseWriter, r *http.Request, user domain.User) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeApiError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	_, err = handler.transactionRepo.GetById(r.Context(), id, user.Id)
+	if errors.Is(err, entity.ErrNotFound) {
+		writeApiError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Msgf("Error getting transaction for api: %v", err)
+		writeApiError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	err = handler.transactionRepo.DeleteById(r.Context(), id, user.Id)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Msgf("Error deleting transaction for api: %v", err)
+		writeApiError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler ApiHandler) ListCategories(w http.ResponseWriter, r *http.Request, user domain.User) {
+	categories, err := handler.categoryRepo.FindAllByUserId(r.Context(), user.Id)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Msgf("Error finding categories for api: %v", err)
+		writeApiError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	res := []apiCategory{}
+	for _, c := range categories {
+		res = append(res, newApiCategory(*c))
+	}
+	writeApiJson(w, http.StatusOK, res)
+}
+
+// CreateCategory adds a custom category that only the user can see
+func (handler ApiHandler) CreateCategory(w http.ResponseWriter, r *http.Request, user domain.User) {
+	var req apiCategoryRequest
+	err := decodeApiRequest(w, r, &req)
+	if err != nil {
+		writeApiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	name, err := validateCategoryName(req.Name)
+	if err != nil {
+		writeApiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	category := entity.Category{
+		Name:              name,
+		TransactionTypeId: defaultTransactionTypeId,
+		UserId:            &user.Id,
+	}
+	category.Id, err = handler.categoryRepo.Add(r.Context(), category)
+	if errors.Is(err, entity.ErrConflict) {
+		writeApiError(w, http.StatusConflict, "category already exists")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Msgf("Error adding category for api: %v", err)
+		writeApiError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	writeApiJson(w, http.StatusCreated, newApiCategory(category))
+}
+
+func (handler ApiHandler) RenameCategory(w http.ResponseWriter, r *http.Request, user domain.User) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeApiError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req apiCategoryRequest
+	err = decodeApiRequest(w, r, &req)
+	if err != nil {
+		writeApiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	name, err := validateCategoryName(req.Name)
+	if err != nil {
+		writeApiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = handler.categoryRepo.Rename(r.Context(), id, user.Id, name)
+	if errors.Is(err, entity.ErrNotFound) {
+		writeApiError(w, http.StatusNotFound, "custom category not found")
+		return
+	}
+	if errors.Is(err, entity.ErrConflict) {
+		writeApiError(w, http.StatusConflict, "category already exists")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Msgf("Error renaming category for api: %v", err)
+		writeApiError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	category, err := handler.categoryRepo.GetById(r.Context(), id)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Msgf("Error getting category for api: %v", err)
+		writeApiError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	writeApiJson(w, http.StatusOK, newApiCategory(*category))
+}
+
+// DeleteCategory deletes a custom category of the user that has no transactions
+func (handler ApiHandler) DeleteCategory(w http.ResponseWriter, r *http.Request, user domain.User) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeApiError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	err = handler.categoryRepo.DeleteById(r.Context(), id, user.Id)
+	if errors.Is(err, entity.ErrNotFound) {
+		writeApiError(w, http.StatusNotFound, "custom category not found")
+		return
+	}
+	if errors.Is(err, entity.ErrConflict) {
+		writeApiError(w, http.StatusConflict, "category still has transactions")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Msgf("Error deleting category for api: %v", err)
+		writeApiError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Stats returns the breakdown by category of the month and year in the query, defaulting to the current month
+func (handler ApiHandler) Stats(w http.ResponseWriter, r *http.Request, user domain.User) {
+	now := time.Now().In(user.Location)
+	month, year := now.Month(), now.Year()
+	if s := r.URL.Query().Get("month"); s != "" {
+		month = util.ParseMonthFromString(s)
+	}
+	if s := r.URL.Query().Get("year"); s != "" {
+		y, err := strconv.Atoi(s)
+		if err != nil {
+			writeApiError(w, http.StatusBadRequest, "invalid year")
+			return
+		}
+		year = y
+	}
+
+	period := util.MonthPeriod(month, year, user.Location)
+	breakdowns, total, err := handler.transactionRepo.GetTransactionBreakdownByCategory(r.Context(), period.From, period.To, user)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Msgf("Error getting breakdowns for api: %v", err)
+		writeApiError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	res := apiStats{
+		Month:      int(month),
+		Year:       year,
+		Total:      apiAmount(total),
+		Currency:   total.Currency().Code,
+		Categories: []apiCategoryStat{},
+	}
+	for _, b := range breakdowns {
+		res.Categories = append(res.Categories, apiCategoryStat{Category: b.CategoryName, Amount: apiAmount(b.Amount), Percent: b.Percent})
+	}
+	writeApiJson(w, http.StatusOK, res)
+}
+
+// applyTransactionRequest copies the fields present in the request onto the transaction, returning the http status
+// of any validation error. A refund keeps the category of its purchase and an amount within what is left of it, and a
+// purchase cannot be less than what is refunded of it, as with /refund.
+func (handler ApiHandler) applyTransactionRequest(ctx context.Context, transaction *domain.Transaction, req apiTransactionRequest, user domain.User) (int, error) {
+	if req.Amount != nil {
+		decimal, err := util.ExpandExponent(req.Amount.String())
+		if err != nil {
+			return http.StatusBadRequest, errors.New("invalid amount")
+		}
+		amount, err := util.ParseMinorUnits(decimal, user.Currency.Fraction)
+		if err != nil || amount == 0 {
+			return http.StatusBadRequest, errors.New("invalid amount")
+		}
+		status, err := handler.checkRefundedAmount(ctx, *transaction, amount, user)
+		if err != nil {
+			return status, err
+		}
+		transaction.Amount = money.New(amount, user.Currency.Code)
+	}
+
+	if req.Description != nil {
+		description := strings.TrimSpace(*req.Description)
+		if utf8.RuneCountInString(description) > descLengthLimit {
+			return http.StatusBadRequest, fmt.Errorf("description is longer than %d characters", descLengthLimit)
+		}
+		transaction.Description = description
+	}
+
+	if req.CategoryId != nil {
+		category, err := handler.categoryRepo.GetById(ctx, *req.CategoryId)
+		if errors.Is(err, entity.ErrNotFound) || (err == nil && !isCategoryVisible(*category, user.Id)) {
+			return http.StatusBadRequest, errors.New("unknown category_id")
+		}
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("Error getting category for api: %v", err)
+			return http.StatusInternalServerError, errors.New("internal error")
+		}
+		if transaction.IsRefund() && category.Id != transaction.CategoryId {
+			return http.StatusBadRequest, errors.New("a refund keeps the category of its purchase")
+		}
+		transaction.CategoryId = category.Id
+		transaction.CategoryName = category.Name
+	}
+
+	if req.Datetime != nil {
+		transaction.Datetime = *req.Datetime
+	}
+	return http.StatusOK, nil
+}
+
+// checkRefundedAmount returns the http status and the reason the amount cannot be set on a refund or on a purchase
+// with refunds, or nil when it can
+func (handler ApiHandler) checkRefundedAmount(ctx context.Context, transaction domain.Transaction, amount int64, user domain.User) (int, error) {
+	fraction := user.Currency.Fraction
+	if transaction.IsRefund() {
+		purchase, err := handler.transactionRepo.GetById(ctx, transaction.RefundOf, user.Id)
+		if errors.Is(err, entity.ErrNotFound) {
+			return http.StatusBadRequest, errors.New("the purchase of the refund is deleted")
+		}
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("Error getting purchase for api: %v", err)
+			return http.StatusInternalServerError, errors.New("internal error")
+		}
+		// what is left of the purchase without this refund
+		left := purchase.Refundable().Amount() - transaction.Amount.Amount()
+		if amount > 0 || -amount > left {
+			return http.StatusBadRequest, fmt.Errorf("a refund must be negative and at most the %s left of its purchase", util.FormatMinorUnits(left, fraction))
+		}
+		return http.StatusOK, nil
+	}
+	if transaction.Refunded != nil && amount < -transaction.Refunded.Amount() {
+		return http.StatusBadRequest, fmt.Errorf("a purchase cannot be less than the %s refunded of it", util.FormatMinorUnits(-transaction.Refunded.Amount(), fraction))
+	}
+	return http.StatusOK, nil
+}
+
+func (handler ApiHandler) writeTransaction(w http.ResponseWriter, r *http.Request, status int, id int, user domain.User) {
+	transaction, err := handler.transactionRepo.GetById(r.Context(), id, user.Id)
+	if errors.Is(err, entity.ErrNotFound) {
+		writeApiError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Msgf("Error getting transaction for api: %v", err)
+		writeApiError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	writeApiJson(w, status, newApiTransaction(transaction))
+}
+
+// parseTransactionFilter reads the from, to, category_id, offset and limit query parameters. Dates without a time
+// are in the timezone of the user, and to is exclusive.
+func parseTransactionFilter(r *http.Request, user domain.User) (entity.TransactionFilter, error) {
+	query := r.URL.Query()
+	filter := entity.TransactionFilter{
+		UserId: user.Id,
+		Limit:  apiDefaultPageSize,
+	}
+
+	var err error
+	if s := query.Get("from"); s != "" {
+		filter.DateFrom, err = parseApiTime(s, user.Location)
+		if err != nil {
+			return filter, errors.New("invalid from")
+		}
+	}
+	if s := query.Get("to"); s != "" {
+		filter.DateTo, err = parseApiTime(s, user.Location)
+		if err != nil {
+			return filter, errors.New("invalid to")
+		}
+	}
+	if s := query.Get("category_id"); s != "" {
+		filter.CategoryId, err = strconv.Atoi(s)
+		if err != nil {
+			return filter, errors.New("invalid category_id")
+		}
+	}
+	if s := query.Get("offset"); s != "" {
+		filter.Offset, err = strconv.Atoi(s)
+		if err != nil || filter.Offset < 0 {
+			return filter, errors.New("invalid offset")
+		}
+	}
+	if s := query.Get("limit"); s != "" {
+		filter.Limit, err = strconv.Atoi(s)
+		if err != nil || filter.Limit < 1 || filter.Limit > apiMaxPageSize {
+			return filter, fmt.Errorf("limit must be between 1 and %d", apiMaxPageSize)
+		}
+	}
+	return filter, nil
+}
+
+func validateCategoryName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > categoryNameLengthLimit {
+		return "", fmt.Errorf("name must be between 1 and %d characters", categoryNameLengthLimit)
+	}
+	return name, nil
+}
+
+func parseApiTime(s string, loc *time.Location) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation(time.DateOnly, s, loc)
+}
+
+func decodeApiRequest(w http.ResponseWriter, r *http.Request, v any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiMaxBodyBytes))
+	decoder.DisallowUnknownFields()
+	decoder.UseNumber()
+	err := decoder.Decode(v)
+	if err != nil {
+		return fmt.Errorf("invalid request body: %v", err)
+	}
+	return nil
+}
+
+func writeApiJson(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		log.Error().Msgf("Error writing api response: %v", err)
+	}
+}
+
+func writeApiError(w http.ResponseWriter, status int, msg string) {
+	writeApiJson(w, status, apiError{Error: msg})
+}
+
+// apiAmount formats the money in major units without any symbol or separator, e.g. 1234.50
+func apiAmount(m *money.Money) json.Number {
+	return json.Number(util.FormatMinorUnits(m.Amount(), m.Currency().Fraction))
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+type apiTransactionRequest struct {
+	Amount      *json.Number `json:"amount"`
+	Description *string      `json:"description"`
+	CategoryId  *int         `json:"category_id"`
+	Datetime    *time.Time   `json:"datetime"`
+}
+
+type apiTransaction struct {
+	Id          int         `json:"id"`
+	Datetime    time.Time   `json:"datetime"`
+	CategoryId  int         `json:"category_id"`
+	Category    string      `json:"category"`
+	Description string      `json:"description"`
+	Amount      json.Number `json:"amount"`
+	Currency    string      `json:"currency"`
+}
+
+func newApiTransaction(t domain.Transaction) apiTransaction {
+	return apiTransaction{
+		Id:          t.Id,
+		Datetime:    t.Datetime,
+		CategoryId:  t.CategoryId,
+		Category:    t.CategoryName,
+		Description: t.Description,
+		Amount:      apiAmount(t.Amount),
+		Currency:    t.Amount.Currency().Code,
+	}
+}
+
+type apiTransactionList struct {
+	Transactions []apiTransaction `json:"transactions"`
+	TotalCount   int              `json:"total_count"`
+	Offset       int              `json:"offset"`
+	Limit        int              `json:"limit"`
+}
+
+type apiCategoryRequest struct {
+	Name string `json:"name"`
+}
+
+type apiCategory struct {
+	Id                int    `json:"id"`
+	Name              string `json:"name"`
+	TransactionTypeId int    `json:"transaction_type_id"`
+	Custom            bool   `json:"custom"`
+}
+
+func newApiCategory(c entity.Category) apiCategory {
+	return apiCategory{Id: c.Id, Name: c.Name, TransactionTypeId: c.TransactionTypeId, Custom: c.UserId != nil}
+}
+
+type apiStats struct {
+	Month      int               `json:"month"`
+	Year       int               `json:"year"`
+	Total      json.Number       `json:"total"`
+	Currency   string            `json:"currency"`
+	Categories []apiCategoryStat `json:"categories"`
+}
+
+type apiCategoryStat struct {
+	Category string      `json:"category"`
+	Amount   json.Number `json:"amount"`
+	Percent  float64     `json:"percent"`
+}
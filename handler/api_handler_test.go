@@ -0,0 +1,513 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+const testApiToken = "teb_secret"
+
+func newTestApiHandler(tr mockTransactionRepo, cr mockCategoryRepo) http.Handler {
+	ur := mockUserRepo{
+		findByIdFn: func(ctx context.Context, id int64) (*domain.User, error) {
+			return &domain.User{Id: id, Locale: "en", Currency: money.GetCurrency("SGD"), Location: time.UTC}, nil
+		},
+	}
+	atr := mockApiTokenRepo{
+		authenticateFn: func(ctx context.Context, token string) (int64, error) {
+			if token != testApiToken {
+				return 0, fmt.Errorf("api token %w", entity.ErrNotFound)
+			}
+			return 7, nil
+		},
+	}
+	return NewApiHandler(ur, tr, cr, atr).Routes()
+}
+
+func doApiRequest(h http.Handler, method, target, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func testTransaction(id int, userId int64) domain.Transaction {
+	return domain.Transaction{
+		Id:           id,
+		Datetime:     time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC),
+		CategoryId:   4,
+		CategoryName: "Food",
+		Description:  "Lunch",
+		UserId:       userId,
+		Amount:       money.New(550, money.SGD),
+	}
+}
+
+func TestApi_Unauthorized(t *testing.T) {
+	h := newTestApiHandler(mockTransactionRepo{}, mockCategoryRepo{})
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"missing token", ""},
+		{"invalid token", "teb_wrong"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doApiRequest(h, http.MethodGet, "/api/v1/transactions", "", tt.token)
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("expected 401, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestApi_OpenApiIsPublic(t *testing.T) {
+	h := newTestApiHandler(mockTransactionRepo{}, mockCategoryRepo{})
+
+	rec := doApiRequest(h, http.MethodGet, "/api/v1/openapi.yaml", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "openapi: 3") {
+		t.Errorf("expected the OpenAPI document")
+	}
+}
+
+func TestApi_ListTransactions(t *testing.T) {
+	var gotFilter entity.TransactionFilter
+	tr := mockTransactionRepo{
+		listFn: func(ctx context.Context, filter entity.TransactionFilter) (domain.Transactions, int, error) {
+			gotFilter = filter
+			return domain.Transactions{testTransaction(1, filter.UserId)}, 11, nil
+		},
+	}
+	h := newTestApiHandler(tr, mockCategoryRepo{})
+
+	rec := doApiRequest(h, http.MethodGet, "/api/v1/transactions?from=2023-03-01&to=2023-04-01T00:00:00Z&category_id=4&offset=10&limit=10", "", testApiToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if gotFilter.UserId != 7 || gotFilter.CategoryId != 4 || gotFilter.Offset != 10 || gotFilter.Limit != 10 {
+		t.Errorf("unexpected filter %+v", gotFilter)
+	}
+	if !gotFilter.DateFrom.Equal(time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)) || !gotFilter.DateTo.Equal(time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected date range %v - %v", gotFilter.DateFrom, gotFilter.DateTo)
+	}
+
+	var res apiTransactionList
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("unexpected body: %v", err)
+	}
+	if res.TotalCount != 11 || len(res.Transactions) != 1 || res.Transactions[0].Amount != "5.50" {
+		t.Errorf("unexpected response %+v", res)
+	}
+}
+
+func TestApi_ListTransactions_InvalidLimit(t *testing.T) {
+	h := newTestApiHandler(mockTransactionRepo{}, mockCategoryRepo{})
+
+	rec := doApiRequest(h, http.MethodGet, "/api/v1/transactions?limit=5000", "", testApiToken)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestApi_CreateTransaction(t *testing.T) {
+	var added domain.Transaction
+	tr := mockTransactionRepo{
+		addFn: func(ctx context.Context, t domain.Transaction) (int, error) {
+			added = t
+			return 42, nil
+		},
+		getByIdFn: func(ctx context.Context, id int, userId int64) (domain.Transaction, error) {
+			return testTransaction(id, userId), nil
+		},
+	}
+	cr := mockCategoryRepo{
+		getByIdFn: func(ctx context.Context, id int) (*entity.Category, error) {
+			return &entity.Category{Id: id, Name: "Food", TransactionTypeId: 1}, nil
+		},
+	}
+	h := newTestApiHandler(tr, cr)
+
+	rec := doApiRequest(h, http.MethodPost, "/api/v1/transactions", `{"amount": 5.5, "description": " Lunch ", "category_id": 4}`, testApiToken)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if added.UserId != 7 || added.Amount.Amount() != 550 || added.Description != "Lunch" || added.CategoryId != 4 {
+		t.Errorf("unexpected transaction added %+v", added)
+	}
+
+	var res apiTransaction
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("unexpected body: %v", err)
+	}
+	if res.Id != 42 {
+		t.Errorf("expected id 42, got %d", res.Id)
+	}
+}
+
+func TestApi_CreateTransaction_ExponentAmount(t *testing.T) {
+	tests := []struct {
+		amount string
+		want   int64
+	}{
+		{"1e2", 10000},
+		{"1E2", 10000},
+		{"55e-1", 550},
+		{"0.055e+2", 550},
+	}
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			var added domain.Transaction
+			tr := mockTransactionRepo{
+				addFn: func(ctx context.Context, t domain.Transaction) (int, error) {
+					added = t
+					return 42, nil
+				},
+				getByIdFn: func(ctx context.Context, id int, userId int64) (domain.Transaction, error) {
+					return testTransaction(id, userId), nil
+				},
+			}
+			cr := mockCategoryRepo{
+				getByIdFn: func(ctx context.Context, id int) (*entity.Category, error) {
+					return &entity.Category{Id: id, Name: "Food", TransactionTypeId: 1}, nil
+				},
+			}
+			h := newTestApiHandler(tr, cr)
+
+			rec := doApiRequest(h, http.MethodPost, "/api/v1/transactions", `{"amount": `+tt.amount+`, "category_id": 4}`, testApiToken)
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if added.Amount.Amount() != tt.want {
+				t.Errorf("expected %d minor units, got %d", tt.want, added.Amount.Amount())
+			}
+		})
+	}
+}
+
+func TestApi_CreateTransaction_Invalid(t *testing.T) {
+	cr := mockCategoryRepo{
+		getByIdFn: func(ctx context.Context, id int) (*entity.Category, error) {
+			return nil, fmt.Errorf("category %w: id=%d", entity.ErrNotFound, id)
+		},
+	}
+	h := newTestApiHandler(mockTransactionRepo{}, cr)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"missing amount", `{"category_id": 4}`},
+		{"zero amount", `{"amount": 0, "category_id": 4}`},
+		{"too many decimals", `{"amount": 1.005, "category_id": 4}`},
+		{"too many decimals in exponent form", `{"amount": 1e-3, "category_id": 4}`},
+		{"unknown field", `{"amount": 1, "category_id": 4, "user_id": 8}`},
+		{"unknown category", `{"amount": 1, "category_id": 99}`},
+		{"description too long", `{"amount": 1, "category_id": 4, "description": "` + strings.Repeat("a", descLengthLimit+1) + `"}`},
+		{"not json", `5.50 lunch`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doApiRequest(h, http.MethodPost, "/api/v1/transactions", tt.body, testApiToken)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d: %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestApi_UpdateTransaction(t *testing.T) {
+	var updated domain.Transaction
+	tr := mockTransactionRepo{
+		getByIdFn: func(ctx context.Context, id int, userId int64) (domain.Transaction, error) {
+			return testTransaction(id, userId), nil
+		},
+		updateFn: func(ctx context.Context, t domain.Transaction) error {
+			updated = t
+			return nil
+		},
+	}
+	h := newTestApiHandler(tr, mockCategoryRepo{})
+
+	rec := doApiRequest(h, http.MethodPatch, "/api/v1/transactions/3", `{"description": "Dinner"}`, testApiToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if updated.Id != 3 || updated.UserId != 7 || updated.Description != "Dinner" || updated.Amount.Amount() != 550 {
+		t.Errorf("expected only the description to change, got %+v", updated)
+	}
+}
+
+func TestApi_UpdateTransaction_Refunds(t *testing.T) {
+	// purchase #1 of $50.00 with refund #2 of $20.00
+	purchase := testTransaction(1, 7)
+	purchase.Amount = money.New(5000, money.SGD)
+	purchase.Refunded = money.New(-2000, money.SGD)
+	refund := testTransaction(2, 7)
+	refund.Amount = money.New(-2000, money.SGD)
+	refund.RefundOf = 1
+
+	tests := []struct {
+		name string
+		id   int
+		body string
+		want int
+	}{
+		{"refund within the purchase", 2, `{"amount": -50}`, http.StatusOK},
+		{"refund made positive", 2, `{"amount": 20}`, http.StatusBadRequest},
+		{"refund beyond the purchase", 2, `{"amount": -50.01}`, http.StatusBadRequest},
+		{"refund under its category", 2, `{"category_id": 4}`, http.StatusOK},
+		{"refund under another category", 2, `{"category_id": 13}`, http.StatusBadRequest},
+		{"purchase down to its refunds", 1, `{"amount": 20}`, http.StatusOK},
+		{"purchase below its refunds", 1, `{"amount": 19.99}`, http.StatusBadRequest},
+		{"purchase under another category", 1, `{"category_id": 13}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := false
+			tr := mockTransactionRepo{
+				getByIdFn: func(ctx context.Context, id int, userId int64) (domain.Transaction, error) {
+					if id == 1 {
+						return purchase, nil
+					}
+					return refund, nil
+				},
+				updateFn: func(ctx context.Context, t domain.Transaction) error {
+					updated = true
+					return nil
+				},
+			}
+			cr := mockCategoryRepo{
+				getByIdFn: func(ctx context.Context, id int) (*entity.Category, error) {
+					return &entity.Category{Id: id, Name: "Food", TransactionTypeId: 1}, nil
+				},
+			}
+			h := newTestApiHandler(tr, cr)
+
+			rec := doApiRequest(h, http.MethodPatch, fmt.Sprintf("/api/v1/transactions/%d", tt.id), tt.body, testApiToken)
+			if rec.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+			if updated != (tt.want == http.StatusOK) {
+				t.Errorf("expected updated %v, got %v", tt.want == http.StatusOK, updated)
+			}
+		})
+	}
+}
+
+func TestApi_TransactionNotFound(t *testing.T) {
+	tr := mockTransactionRepo{
+		getByIdFn: func(ctx context.Context, id int, userId int64) (domain.Transaction, error) {
+			return domain.Transaction{}, fmt.Errorf("transaction %w: id=%d userId=%d", entity.ErrNotFound, id, userId)
+		},
+	}
+	h := newTestApiHandler(tr, mockCategoryRepo{})
+
+	tests := []struct {
+		method string
+		body   string
+	}{
+		{http.MethodGet, ""},
+		{http.MethodPatch, `{"description": "Dinner"}`},
+		{http.MethodDelete, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			rec := doApiRequest(h, tt.method, "/api/v1/transactions/3", tt.body, testApiToken)
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("expected 404, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestApi_DeleteTransaction(t *testing.T) {
+	var deletedId int
+	var deletedUserId int64
+	tr := mockTransactionRepo{
+		getByIdFn: func(ctx context.Context, id int, userId int64) (domain.Transaction, error) {
+			return testTransaction(id, userId), nil
+		},
+		deleteByIdFn: func(ctx context.Context, id int, userId int64) error {
+			deletedId, deletedUserId = id, userId
+			return nil
+		},
+	}
+	h := newTestApiHandler(tr, mockCategoryRepo{})
+
+	rec := doApiRequest(h, http.MethodDelete, "/api/v1/transactions/3", "", testApiToken)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	if deletedId != 3 || deletedUserId != 7 {
+		t.Errorf("expected transaction 3 of user 7 to be deleted, got %d of %d", deletedId, deletedUserId)
+	}
+}
+
+func TestApi_Stats(t *testing.T) {
+	var gotFrom, gotTo time.Time
+	tr := mockTransactionRepo{
+		getTransactionBreakdownByCatFn: func(ctx context.Context, dateFrom time.Time, dateTo time.Time, user domain.User) (domain.Breakdowns, *money.Money, error) {
+			gotFrom, gotTo = dateFrom, dateTo
+			breakdowns := domain.Breakdowns{{CategoryName: "Food", Amount: money.New(123450, money.SGD), Percent: 100}}
+			return breakdowns, money.New(123450, money.SGD), nil
+		},
+	}
+	h := newTestApiHandler(tr, mockCategoryRepo{})
+
+	rec := doApiRequest(h, http.MethodGet, "/api/v1/stats?month=mar&year=2023", "", testApiToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if gotFrom.Format("2006-01-02") != "2023-03-01" || gotTo.Format("2006-01-02") != "2023-04-01" {
+		t.Errorf("expected March 2023, got %v to %v", gotFrom, gotTo)
+	}
+	if !strings.Contains(rec.Body.String(), `"total":1234.50`) {
+		t.Errorf("expected exact total in major units, got %s", rec.Body.String())
+	}
+}
+
+func TestApi_CreateTransaction_OtherUsersCategory(t *testing.T) {
+	otherUserId := int64(8)
+	cr := mockCategoryRepo{
+		getByIdFn: func(ctx context.Context, id int) (*entity.Category, error) {
+			return &entity.Category{Id: id, Name: "Secret", TransactionTypeId: 1, UserId: &otherUserId}, nil
+		},
+	}
+	h := newTestApiHandler(mockTransactionRepo{}, cr)
+
+	rec := doApiRequest(h, http.MethodPost, "/api/v1/transactions", `{"amount": 1, "category_id": 40}`, testApiToken)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestApi_ListCategories(t *testing.T) {
+	userId := int64(7)
+	var gotUserId int64
+	cr := mockCategoryRepo{
+		findAllByUserIdFn: func(ctx context.Context, id int64) ([]*entity.Category, error) {
+			gotUserId = id
+			return []*entity.Category{
+				{Id: 1, Name: "Food", TransactionTypeId: 1},
+				{Id: 40, Name: "Pets", TransactionTypeId: 1, UserId: &userId},
+			}, nil
+		},
+	}
+	h := newTestApiHandler(mockTransactionRepo{}, cr)
+
+	rec := doApiRequest(h, http.MethodGet, "/api/v1/categories", "", testApiToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if gotUserId != 7 {
+		t.Errorf("expected categories of user 7, got %d", gotUserId)
+	}
+	var res []apiCategory
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("unexpected body: %v", err)
+	}
+	if len(res) != 2 || res[0].Custom || !res[1].Custom {
+		t.Errorf("expected only the second category to be custom, got %+v", res)
+	}
+}
+
+func TestApi_CreateCategory(t *testing.T) {
+	var added entity.Category
+	cr := mockCategoryRepo{
+		addFn: func(ctx context.Context, category entity.Category) (int, error) {
+			if category.Name == "Food" {
+				return 0, fmt.Errorf("category %w", entity.ErrConflict)
+			}
+			added = category
+			return 40, nil
+		},
+	}
+	h := newTestApiHandler(mockTransactionRepo{}, cr)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{"created", `{"name": " Pets "}`, http.StatusCreated},
+		{"already exists", `{"name": "Food"}`, http.StatusConflict},
+		{"empty name", `{"name": "  "}`, http.StatusBadRequest},
+		{"name too long", `{"name": "` + strings.Repeat("a", categoryNameLengthLimit+1) + `"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doApiRequest(h, http.MethodPost, "/api/v1/categories", tt.body, testApiToken)
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+			}
+		})
+	}
+	if added.Name != "Pets" || added.UserId == nil || *added.UserId != 7 {
+		t.Errorf("expected custom category Pets of user 7, got %+v", added)
+	}
+}
+
+func TestApi_RenameAndDeleteCategory(t *testing.T) {
+	cr := mockCategoryRepo{
+		renameFn: func(ctx context.Context, id int, userId int64, name string) error {
+			if id == 1 {
+				return fmt.Errorf("category %w", entity.ErrNotFound)
+			}
+			return nil
+		},
+		deleteByIdFn: func(ctx context.Context, id int, userId int64) error {
+			switch id {
+			case 1:
+				return fmt.Errorf("category %w", entity.ErrNotFound)
+			case 41:
+				return fmt.Errorf("category %w", entity.ErrConflict)
+			}
+			return nil
+		},
+		getByIdFn: func(ctx context.Context, id int) (*entity.Category, error) {
+			userId := int64(7)
+			return &entity.Category{Id: id, Name: "Pets", TransactionTypeId: 1, UserId: &userId}, nil
+		},
+	}
+	h := newTestApiHandler(mockTransactionRepo{}, cr)
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		wantCode int
+	}{
+		{"rename", http.MethodPatch, "/api/v1/categories/40", `{"name": "Pets"}`, http.StatusOK},
+		{"rename shared", http.MethodPatch, "/api/v1/categories/1", `{"name": "Pets"}`, http.StatusNotFound},
+		{"delete", http.MethodDelete, "/api/v1/categories/40", "", http.StatusNoContent},
+		{"delete shared", http.MethodDelete, "/api/v1/categories/1", "", http.StatusNotFound},
+		{"delete with transactions", http.MethodDelete, "/api/v1/categories/41", "", http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doApiRequest(h, tt.method, tt.target, tt.body, testApiToken)
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
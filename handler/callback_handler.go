@@ -2,17 +2,19 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"strconv"
+	"html"
 	"strings"
 	"time"
 
 	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/conversation"
 	"github.com/aattwwss/telegram-expense-bot/domain"
 	"github.com/aattwwss/telegram-expense-bot/entity"
 	"github.com/aattwwss/telegram-expense-bot/enum"
 	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/sender"
 	"github.com/aattwwss/telegram-expense-bot/util"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/rs/zerolog/log"
@@ -28,258 +30,427 @@ type CallbackHandler struct {
 	messageContextRepo  MessageContextRepo
 	transactionTypeRepo TransactionTypeRepo
 	categoryRepo        CategoryRepo
+	accountRepo         AccountRepo
+	reminderRepo        ReminderRepo
+	conversations       *conversation.Manager
 }
 
-func NewCallbackHandler(userRepo UserRepo, transactionRepo TransactionRepo, messageContextRepo MessageContextRepo, transactionTypeRepo TransactionTypeRepo, categoryRepo CategoryRepo) CallbackHandler {
+func NewCallbackHandler(userRepo UserRepo, transactionRepo TransactionRepo, messageContextRepo MessageContextRepo, transactionTypeRepo TransactionTypeRepo, categoryRepo CategoryRepo, accountRepo AccountRepo, reminderRepo ReminderRepo, conversations *conversation.Manager) CallbackHandler {
 	return CallbackHandler{
 		userRepo:            userRepo,
 		transactionRepo:     transactionRepo,
 		messageContextRepo:  messageContextRepo,
 		transactionTypeRepo: transactionTypeRepo,
 		categoryRepo:        categoryRepo,
+		accountRepo:         accountRepo,
+		reminderRepo:        reminderRepo,
+		conversations:       conversations,
 	}
 }
 
-func (handler CallbackHandler) FromCategory(ctx context.Context, bot *tgbotapi.BotAPI, callbackQuery *tgbotapi.CallbackQuery) {
+func (handler CallbackHandler) FromCategory(ctx context.Context, bot *sender.Sender, callbackQuery *tgbotapi.CallbackQuery) {
+	handler.fromConversation(ctx, bot, callbackQuery)
+}
+
+func (handler CallbackHandler) FromAccount(ctx context.Context, bot *sender.Sender, callbackQuery *tgbotapi.CallbackQuery) {
+	handler.fromConversation(ctx, bot, callbackQuery)
+}
+
+func (handler CallbackHandler) FromSplit(ctx context.Context, bot *sender.Sender, callbackQuery *tgbotapi.CallbackQuery) {
+	handler.fromConversation(ctx, bot, callbackQuery)
+}
+
+// FromCancelConversation ends the conversation of the menu, such as an amount waiting for its category
+func (handler CallbackHandler) FromCancelConversation(ctx context.Context, bot *sender.Sender, callbackQuery *tgbotapi.CallbackQuery) {
+	defer util.NewCallbackAnswer(bot, callbackQuery.ID).Send()
 	defer util.BotDeleteMessage(bot, callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID)
 
-	user, err := handler.userRepo.FindUserById(ctx, callbackQuery.From.ID)
+	var genericCallback domain.GenericCallback
+	err := domain.DecodeCallback(callbackQuery.Data, &genericCallback)
 	if err != nil {
-		log.Error().Msgf("Error finding user for category: %v", err)
-		util.BotSendMessage(bot, callbackQuery.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("FromCancelConversation unmarshall error: %v", err)
 		return
 	}
 
-	var categoryCallback domain.CategoryCallback
-	err = json.Unmarshal([]byte(callbackQuery.Data), &categoryCallback)
-	if err != nil {
-		log.Error().Msgf("FromCategory unmarshall error: %v", err)
-		util.BotSendMessage(bot, callbackQuery.Message.Chat.ID, message.GenericErrReplyMsg)
+	err = handler.conversations.CancelById(ctx, bot, genericCallback.MessageContextId, callbackQuery)
+	if errors.Is(err, entity.ErrNotFound) {
 		return
 	}
-
-	defer handler.deleteMessageContext(ctx, categoryCallback.MessageContextId)
-
-	category, err := handler.categoryRepo.GetById(ctx, categoryCallback.CategoryId)
 	if err != nil {
-		log.Error().Msgf("Get category by id error: %v", err)
-		util.BotSendMessage(bot, callbackQuery.Message.Chat.ID, message.GenericErrReplyMsg)
-		return
+		log.Ctx(ctx).Error().Msgf("Cancel conversation error: %v", err)
 	}
+}
+
+// fromConversation passes a button tapped on the menu of a flow to its conversation, whose steps edit the menu
+func (handler CallbackHandler) fromConversation(ctx context.Context, bot *sender.Sender, callbackQuery *tgbotapi.CallbackQuery) {
+	answer := util.NewCallbackAnswer(bot, callbackQuery.ID)
+	defer answer.Send()
 
-	messageContext, err := handler.messageContextRepo.GetMessageById(ctx, categoryCallback.Callback.MessageContextId)
+	chatId, messageId := callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID
+	locale := findLocale(ctx, handler.userRepo, callbackQuery.From)
+	var genericCallback domain.GenericCallback
+	err := domain.DecodeCallback(callbackQuery.Data, &genericCallback)
 	if err != nil {
-		log.Error().Msgf("Get message context by id error: %v", err)
-		util.BotSendMessage(bot, callbackQuery.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("fromConversation unmarshall error: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 
-	amountString, err := parseFloatStringFromString(messageContext)
-	if err != nil {
-		log.Error().Msgf("Parsing float string from mesage context error: %v", err)
-		util.BotSendMessage(bot, callbackQuery.Message.Chat.ID, message.GenericErrReplyMsg)
+	err = handler.conversations.HandleCallback(ctx, bot, genericCallback.MessageContextId, callbackQuery)
+	if errors.Is(err, entity.ErrNotFound) {
+		answer.Alert(locale.Get(message.MenuExpiredMsg))
 		return
 	}
-
-	amountFloat, err := strconv.ParseFloat(amountString, 64)
 	if err != nil {
-		log.Error().Msgf("Parsing amountString to amountFloat error: %v", err)
-		util.BotSendMessage(bot, callbackQuery.Message.Chat.ID, message.GenericErrReplyMsg)
-		return
+		log.Ctx(ctx).Error().Msgf("Handle conversation callback error: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
 	}
+}
 
-	amountInt, err := decimalise(amountFloat, *user.Currency)
+// addTransaction adds the amount and description typed by the user under the category, or split across the categories
+// of the parts when there is more than one, paid with the account unless it is nil, and returns the reply
+func (handler CallbackHandler) addTransaction(ctx context.Context, user domain.User, category entity.Category, account *domain.Account, typed string, splits []entrySplit, datetime time.Time) (string, error) {
+	locale := user.GetLocale()
+	moneyTransacted, description, err := parseAmount(typed, *user.Currency)
 	if err != nil {
-		util.BotSendMessage(bot, callbackQuery.Message.Chat.ID, message.GenericErrReplyMsg)
-		return
+		return "", fmt.Errorf("parsing amount from conversation error: %w", err)
 	}
-	stringAfter := util.After(messageContext, amountString)
-	description := strings.TrimSpace(stringAfter)
-
-	moneyTransacted := money.New(amountInt, user.Currency.Code)
 
 	transaction := domain.Transaction{
-		Datetime:    time.Now(),
+		Datetime:    datetime,
 		CategoryId:  category.Id,
 		Description: description,
-		UserId:      callbackQuery.From.ID,
+		UserId:      user.Id,
 		Amount:      moneyTransacted,
 	}
+	if account != nil {
+		transaction.AccountId = account.Id
+	}
+	categoryName := locale.CategoryName(category.Name)
+	if len(splits) > 1 {
+		var names []string
+		for _, s := range splits {
+			transaction.Splits = append(transaction.Splits, domain.TransactionSplit{
+				CategoryId:   s.CategoryId,
+				CategoryName: s.CategoryName,
+				Amount:       money.New(s.Amount, moneyTransacted.Currency().Code),
+			})
+			names = append(names, locale.CategoryName(s.CategoryName))
+		}
+		categoryName = strings.Join(names, ", ")
+	}
 
-	err = handler.transactionRepo.Add(ctx, transaction)
+	id, err := handler.transactionRepo.Add(ctx, transaction)
 	if err != nil {
-		log.Error().Msgf("FromCategory error: %v", err)
-		util.BotSendMessage(bot, callbackQuery.Message.Chat.ID, message.GenericErrReplyMsg)
-		return
+		return "", err
 	}
 
 	transactionType, err := handler.transactionTypeRepo.GetById(ctx, category.TransactionTypeId)
 	if err != nil {
-		log.Error().Msgf("FromCategory error: %v", err)
-		util.BotSendMessage(bot, callbackQuery.Message.Chat.ID, message.GenericErrReplyMsg)
-		return
+		return "", err
 	}
 
-	text := fmt.Sprintf(transactionType.ReplyText, moneyTransacted.Display(), category.Name)
-	text += fmt.Sprintf(message.TransactionEndReplyMsg, description)
-	msg := tgbotapi.NewMessage(callbackQuery.Message.Chat.ID, text)
-	msg.ParseMode = tgbotapi.ModeHTML
-	util.BotSendWrapper(bot, msg)
+	replyText := locale.GetOrDefault(message.TransactionTypeReplyKey(transactionType.Id), transactionType.ReplyText)
+	text := fmt.Sprintf(replyText, locale.FormatMoney(moneyTransacted), html.EscapeString(categoryName))
+	for _, s := range transaction.Splits {
+		text += locale.Get(message.TransactionSplitReplyLine, html.EscapeString(locale.CategoryName(s.CategoryName)), locale.FormatMoney(s.Amount))
+	}
+	text += locale.Get(message.TransactionEndReplyMsg, html.EscapeString(description))
+	if account != nil {
+		text += locale.Get(message.TransactionAccountReplyMsg, html.EscapeString(account.Name))
+	}
+	// the id lets a reply to the confirmation refer to the transaction, e.g. to refund it
+	text += locale.Get(message.TransactionIdReplyMsg, id)
+	return text, nil
 }
 
-func (handler CallbackHandler) FromPagination(ctx context.Context, bot *tgbotapi.BotAPI, callbackQuery *tgbotapi.CallbackQuery) {
-	defer util.BotDeleteMessage(bot, callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID)
+// FromPagination shows the page tapped in place of the page listed
+func (handler CallbackHandler) FromPagination(ctx context.Context, bot *sender.Sender, callbackQuery *tgbotapi.CallbackQuery) {
+	answer := util.NewCallbackAnswer(bot, callbackQuery.ID)
+	defer answer.Send()
 
 	// TODO Find a way to handle the persisting context when paginating
+	chatId, messageId := callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID
 	userId := callbackQuery.From.ID
+	locale := clientLocale(callbackQuery.From)
 	user, err := handler.userRepo.FindUserById(ctx, userId)
 	if err != nil {
-		log.Error().Msgf("Error finding user for stats: %v", err)
-		util.BotSendMessage(bot, callbackQuery.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("Error finding user for stats: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
+	locale = user.GetLocale()
 
 	var paginationCallback domain.PaginationCallback
-	err = json.Unmarshal([]byte(callbackQuery.Data), &paginationCallback)
+	err = domain.DecodeCallback(callbackQuery.Data, &paginationCallback)
 	if err != nil {
-		log.Error().Msgf("FromPagination unmarshall error: %v", err)
-		util.BotSendMessage(bot, callbackQuery.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("FromPagination unmarshall error: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 
 	messageContext, err := handler.messageContextRepo.GetMessageById(ctx, paginationCallback.Callback.MessageContextId)
+	if errors.Is(err, entity.ErrNotFound) {
+		answer.Alert(locale.Get(message.MenuExpiredMsg))
+		return
+	}
 	if err != nil {
-		log.Error().Msgf("Get message context by id error: %v", err)
-		util.BotSendMessage(bot, callbackQuery.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("Get message context by id error: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 
-	month, year := util.ParseMonthYearFromMessage(messageContext)
+	// the message context is the /list command, whose arguments were valid when it was sent
+	_, args, _ := strings.Cut(messageContext, " ")
+	list, err := parseListArgs(ctx, handler.accountRepo, args, *user)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error parsing list arguments for pagination: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
 
 	offset, limit := paginationCallback.Offset, paginationCallback.Limit
 	q := entity.TransactionListQuery{
-		Month:    month,
-		Year:     year,
-		Offset:   offset,
-		Limit:    limit,
-		Asc:      false,
-		UserId:   user.Id,
-		Location: user.Location,
+		DateFrom:  list.period.From,
+		DateTo:    list.period.To,
+		Offset:    offset,
+		Limit:     limit,
+		Asc:       false,
+		UserId:    user.Id,
+		AccountId: list.accountId(),
 	}
-	transactions, totalCount, err := handler.transactionRepo.ListByMonthAndYear(ctx, q)
+	transactions, totalCount, err := handler.transactionRepo.ListByDateRange(ctx, q)
 
-	inlineKeyboard, err := util.NewPaginationKeyboard(totalCount, offset, limit, paginationCallback.MessageContextId, 2)
+	inlineKeyboard, err := util.NewPaginationKeyboard(totalCount, offset, limit, paginationCallback.MessageContextId, 2, locale)
 	if err != nil {
-		log.Error().Msgf("Error generating keyboard for transaction pagination: %v", err)
-		util.BotSendMessage(bot, callbackQuery.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("Error generating keyboard for transaction pagination: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 
-	text := transactions.GetFormattedHTMLMsg(month, year, user.Location, totalCount, offset, limit)
-	msg := tgbotapi.NewMessage(callbackQuery.Message.Chat.ID, text)
-	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: inlineKeyboard}
-	msg.ParseMode = tgbotapi.ModeHTML
-	util.BotSendWrapper(bot, msg)
+	text := transactions.GetFormattedHTMLMsg(html.EscapeString(list.label(locale)), user.Location, locale, totalCount, offset, limit)
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatId, messageId, text, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: inlineKeyboard})
+	edit.ParseMode = tgbotapi.ModeHTML
+	util.BotEditWrapper(bot, edit)
 }
 
-func (handler CallbackHandler) FromUndo(ctx context.Context, bot *tgbotapi.BotAPI, callbackQuery *tgbotapi.CallbackQuery) {
-	defer util.BotDeleteMessage(bot, callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID)
+func (handler CallbackHandler) FromUndo(ctx context.Context, bot *sender.Sender, callbackQuery *tgbotapi.CallbackQuery) {
+	answer := util.NewCallbackAnswer(bot, callbackQuery.ID)
+	defer answer.Send()
 
+	chatId, messageId := callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID
 	userId := callbackQuery.From.ID
+	locale := findLocale(ctx, handler.userRepo, callbackQuery.From)
 	var undoCallback domain.UndoCallback
 
-	err := json.Unmarshal([]byte(callbackQuery.Data), &undoCallback)
+	err := domain.DecodeCallback(callbackQuery.Data, &undoCallback)
 	if err != nil {
-		log.Error().Msgf("FromUndo unmarshall error: %v", err)
+		log.Ctx(ctx).Error().Msgf("FromUndo unmarshall error: %v", err)
+		util.BotRemoveKeyboard(bot, chatId, messageId)
 		return
 	}
-	log.Info().Msgf("transaction: %v", undoCallback.TransactionId)
+	log.Ctx(ctx).Info().Msgf("transaction: %v", undoCallback.TransactionId)
+
+	_, err = handler.messageContextRepo.GetMessageById(ctx, undoCallback.MessageContextId)
+	if errors.Is(err, entity.ErrNotFound) {
+		answer.Alert(locale.Get(message.MenuExpiredMsg))
+		return
+	}
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Get message context by id error: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	defer handler.deleteMessageContext(ctx, undoCallback.MessageContextId)
 
 	transaction, err := handler.transactionRepo.GetById(ctx, undoCallback.TransactionId, userId)
 	if err != nil {
-		log.Error().Msgf("FromUndo cannot find transaction error: %v", err)
-		util.BotSendMessage(bot, callbackQuery.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("FromUndo cannot find transaction error: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 
 	err = handler.transactionRepo.DeleteById(ctx, undoCallback.TransactionId, userId)
 	if err != nil {
-		log.Error().Msgf("Error deleting latest transaction: %v", err)
-		util.BotSendMessage(bot, callbackQuery.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("Error deleting latest transaction: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 
-	text := fmt.Sprintf(message.TransactionDeletedReplyMsg, transaction.Amount.Display(), transaction.Description)
-	msg := tgbotapi.NewMessage(callbackQuery.Message.Chat.ID, text)
-	util.BotSendWrapper(bot, msg)
+	text := locale.Get(message.TransactionDeletedReplyMsg, locale.FormatMoney(transaction.Amount), transaction.Description) + locale.Get(message.TransactionUndoMoreHint)
+	util.BotEditMessage(bot, chatId, messageId, text)
 }
 
-func (handler CallbackHandler) FromCancel(ctx context.Context, bot *tgbotapi.BotAPI, callbackQuery *tgbotapi.CallbackQuery) {
+func (handler CallbackHandler) FromCancel(ctx context.Context, bot *sender.Sender, callbackQuery *tgbotapi.CallbackQuery) {
+	defer util.NewCallbackAnswer(bot, callbackQuery.ID).Send()
 	defer util.BotDeleteMessage(bot, callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID)
 
 	var genericCallback domain.GenericCallback
-	err := json.Unmarshal([]byte(callbackQuery.Data), &genericCallback)
+	err := domain.DecodeCallback(callbackQuery.Data, &genericCallback)
 	if err != nil {
-		log.Error().Msgf("FromCancel unmarshall error: %v", err)
+		log.Ctx(ctx).Error().Msgf("FromCancel unmarshall error: %v", err)
 		return
 	}
 
 	handler.deleteMessageContext(ctx, genericCallback.MessageContextId)
 }
 
-func newCategoriesKeyboard(categories []*entity.Category, messageContextId int, colSize int) ([][]tgbotapi.InlineKeyboardButton, error) {
+func (handler CallbackHandler) FromSnooze(ctx context.Context, bot *sender.Sender, callbackQuery *tgbotapi.CallbackQuery) {
+	defer util.NewCallbackAnswer(bot, callbackQuery.ID).Send()
+
+	chatId, messageId := callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID
+
+	locale := findLocale(ctx, handler.userRepo, callbackQuery.From)
+	var snoozeCallback domain.SnoozeCallback
+	err := domain.DecodeCallback(callbackQuery.Data, &snoozeCallback)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("FromSnooze unmarshall error: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+
+	reminder, err := handler.reminderRepo.GetByUserId(ctx, callbackQuery.From.ID)
+	if err != nil || reminder == nil {
+		log.Ctx(ctx).Error().Msgf("FromSnooze cannot find reminder error: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+
+	snoozedUntil := time.Now().Add(time.Duration(snoozeCallback.Minutes) * time.Minute)
+	err = handler.reminderRepo.Snooze(ctx, reminder.UserId, snoozedUntil)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error snoozing reminder: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+
+	text := locale.Get(message.ReminderSnoozedReplyMsg, locale.FormatTime(snoozedUntil.In(reminder.Location)))
+	util.BotEditMessage(bot, chatId, messageId, text)
+}
+
+// FromLogNow removes the buttons of the reminder and asks for the amount in a reply, which a message cannot be edited
+// into
+func (handler CallbackHandler) FromLogNow(ctx context.Context, bot *sender.Sender, callbackQuery *tgbotapi.CallbackQuery) {
+	defer util.NewCallbackAnswer(bot, callbackQuery.ID).Send()
+	util.BotRemoveKeyboard(bot, callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID)
+
+	locale := findLocale(ctx, handler.userRepo, callbackQuery.From)
+	msg := tgbotapi.NewMessage(callbackQuery.Message.Chat.ID, locale.Get(message.ReminderLogNowPromptMsg))
+	msg.ReplyMarkup = tgbotapi.ForceReply{
+		ForceReply:            true,
+		InputFieldPlaceholder: locale.Get(message.ReminderLogNowPlaceholder),
+	}
+	util.BotSendWrapper(bot, msg)
+}
+
+func (handler CallbackHandler) FromLanguage(ctx context.Context, bot *sender.Sender, callbackQuery *tgbotapi.CallbackQuery) {
+	defer util.NewCallbackAnswer(bot, callbackQuery.ID).Send()
+
+	chatId, messageId := callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID
+
+	locale := findLocale(ctx, handler.userRepo, callbackQuery.From)
+	var languageCallback domain.LanguageCallback
+	err := domain.DecodeCallback(callbackQuery.Data, &languageCallback)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("FromLanguage unmarshall error: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+
+	newLocale, ok := message.MatchLocale(languageCallback.Locale)
+	if !ok {
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.LanguageUnsupportedMsg))
+		return
+	}
+
+	err = handler.userRepo.UpdateLocale(ctx, callbackQuery.From.ID, newLocale.Code)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error updating locale: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	util.BotEditMessage(bot, chatId, messageId, newLocale.Get(message.LanguageUpdatedMsg))
+}
+
+// newCategoriesKeyboard returns the categories to choose from in the entry conversation, and a button to cancel it
+func newCategoriesKeyboard(categories []*entity.Category, conversationId int, colSize int, locale message.Locale) ([][]tgbotapi.InlineKeyboardButton, error) {
 	var configs []util.InlineKeyboardConfig
 	for _, category := range categories {
 		data := domain.CategoryCallback{
 			Callback: domain.Callback{
 				Type:             enum.Category,
-				MessageContextId: messageContextId,
+				MessageContextId: conversationId,
 			},
 			CategoryId: category.Id,
 		}
 
-		dataJson, err := util.ToJson(data)
+		dataJson, err := domain.EncodeCallback(data)
+		if err != nil {
+			return nil, err
+		}
+
+		config := util.NewInlineKeyboardConfig(locale.CategoryName(category.Name), dataJson)
+		configs = append(configs, config)
+	}
+
+	cancelRow, err := util.NewCancelConversationRow(conversationId, locale)
+	if err != nil {
+		return nil, err
+	}
+	return append(util.NewInlineKeyboard(configs, conversationId, colSize, false, locale), cancelRow), nil
+}
+
+// newAccountsKeyboard returns the accounts to choose from in the entry conversation, a button to add the amount without
+// an account and a button to cancel it
+func newAccountsKeyboard(accounts domain.Accounts, conversationId int, colSize int, locale message.Locale) ([][]tgbotapi.InlineKeyboardButton, error) {
+	var configs []util.InlineKeyboardConfig
+	for _, account := range append(accounts, domain.Account{Name: locale.Get(message.AccountNoneButton)}) {
+		data := domain.AccountCallback{
+			Callback: domain.Callback{
+				Type:             enum.Account,
+				MessageContextId: conversationId,
+			},
+			AccountId: account.Id,
+		}
+
+		dataJson, err := domain.EncodeCallback(data)
 		if err != nil {
 			return nil, err
 		}
 
-		config := util.NewInlineKeyboardConfig(category.Name, dataJson)
+		config := util.NewInlineKeyboardConfig(account.Name, dataJson)
 		configs = append(configs, config)
 	}
 
-	return util.NewInlineKeyboard(configs, messageContextId, colSize, true), nil
+	cancelRow, err := util.NewCancelConversationRow(conversationId, locale)
+	if err != nil {
+		return nil, err
+	}
+	return append(util.NewInlineKeyboard(configs, conversationId, colSize, false, locale), cancelRow), nil
+}
 
+// isCategoryVisible returns true for the shared categories and the custom categories of the user
+func isCategoryVisible(category entity.Category, userId int64) bool {
+	return category.UserId == nil || *category.UserId == userId
 }
 
 func (handler CallbackHandler) deleteMessageContext(ctx context.Context, id int) {
 	err := handler.messageContextRepo.DeleteById(ctx, id)
 	if err != nil {
-		log.Error().Msgf("deleteMessageContext error: %v", err)
+		log.Ctx(ctx).Error().Msgf("deleteMessageContext error: %v", err)
 	}
 }
 
-// decimalise decimalise the value of a currency to its lowest denomination
-func decimalise(value float64, currency money.Currency) (int64, error) {
-	formatString := fmt.Sprintf("%%.%df", currency.Fraction)
-	formatted := fmt.Sprintf(formatString, value)
-	intString := removeNonNumeric(formatted)
-	res, err := strconv.ParseInt(intString, 10, 64)
+// parseAmount parses the amount at the start of a text in the currency, and returns it with the rest of the text
+func parseAmount(s string, currency money.Currency) (*money.Money, string, error) {
+	amountString, err := parseFloatStringFromString(s)
 	if err != nil {
-		return 0, err
+		return nil, "", err
 	}
-	return res, err
-}
 
-// removeNonNumeric removes all non-numeric characters from a string, preserving a leading minus sign
-func removeNonNumeric(s string) string {
-	var sb strings.Builder
-	for i, ch := range s {
-		if ch >= '0' && ch <= '9' {
-			sb.WriteRune(ch)
-		}
-		if ch == '-' && i == 0 {
-			sb.WriteRune(ch)
-		}
+	amount, err := util.ParseMinorUnits(amountString, currency.Fraction)
+	if err != nil {
+		return nil, "", err
 	}
-	return sb.String()
+	return money.New(amount, currency.Code), strings.TrimSpace(util.After(s, amountString)), nil
 }
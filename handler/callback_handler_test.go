@@ -1,62 +1,454 @@
 package handler
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"path"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/audit"
+	"github.com/aattwwss/telegram-expense-bot/conversation"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/enum"
+	"github.com/aattwwss/telegram-expense-bot/job"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func TestDecimalise(t *testing.T) {
+// recordingClient records the telegram methods called with their form and replies ok
+type recordingClient struct {
+	requests []string
+}
+
+func (c *recordingClient) Do(req *http.Request) (*http.Response, error) {
+	body, _ := io.ReadAll(req.Body)
+	c.requests = append(c.requests, path.Base(req.URL.Path)+" "+string(body))
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"ok":true,"result":true}`))}, nil
+}
+
+func newRecordingSender() (*sender.Sender, *recordingClient) {
+	client := &recordingClient{}
+	bot := &tgbotapi.BotAPI{Token: "dummy", Client: client}
+	bot.SetAPIEndpoint(tgbotapi.APIEndpoint)
+	return sender.New(bot, sender.Options{GlobalRate: 1000, GlobalBurst: 1000, ChatRate: 1000, ChatBurst: 1000}), client
+}
+
+// newEntryTestHandler returns a callback handler with the entry flow registered on an in-memory store, which adds any
+// amount under the category "Food" of the transaction type 1 for a user without accounts
+func newEntryTestHandler(tr mockTransactionRepo, defaultCategoryId int) (CallbackHandler, *conversation.Manager, *memoryConversationStore) {
+	return newEntryTestHandlerWithAccounts(tr, nil, defaultCategoryId)
+}
+
+// newEntryTestHandlerWithAccounts returns the handler of newEntryTestHandler for a user with the accounts
+func newEntryTestHandlerWithAccounts(tr mockTransactionRepo, accounts domain.Accounts, defaultCategoryId int) (CallbackHandler, *conversation.Manager, *memoryConversationStore) {
+	ur := mockUserRepo{
+		findByIdFn: func(ctx context.Context, id int64) (*domain.User, error) {
+			return &domain.User{Id: id, Currency: money.GetCurrency("SGD"), Location: time.UTC, Locale: "en"}, nil
+		},
+	}
+	ttr := mockTransactionTypeRepo{
+		getByIdFn: func(ctx context.Context, id int) (*entity.TransactionType, error) {
+			return &entity.TransactionType{Id: id, ReplyText: "Spent %s on %s"}, nil
+		},
+	}
+	cr := mockCategoryRepo{
+		getByIdFn: func(ctx context.Context, id int) (*entity.Category, error) {
+			return &entity.Category{Id: id, Name: "Food", TransactionTypeId: 1}, nil
+		},
+	}
+	ar := mockAccountRepo{
+		findAllByUserIdFn: func(ctx context.Context, userId int64) (domain.Accounts, error) {
+			return accounts, nil
+		},
+		getByIdFn: func(ctx context.Context, id int, userId int64) (domain.Account, error) {
+			for _, account := range accounts {
+				if account.Id == id {
+					return account, nil
+				}
+			}
+			return domain.Account{}, entity.ErrNotFound
+		},
+	}
+	store := newMemoryConversationStore()
+	manager := conversation.NewManager(store)
+	handler := NewCallbackHandler(ur, tr, mockMessageContextRepo{}, ttr, cr, ar, nil, manager)
+	RegisterFlows(manager, handler, 15*time.Minute, defaultCategoryId)
+	return handler, manager, store
+}
+
+func categoryCallbackQuery(conversationId int, categoryId int) *tgbotapi.CallbackQuery {
+	data, _ := domain.EncodeCallback(domain.CategoryCallback{
+		Callback:   domain.Callback{Type: enum.Category, MessageContextId: conversationId},
+		CategoryId: categoryId,
+	})
+	return &tgbotapi.CallbackQuery{
+		ID:      "q1",
+		From:    &tgbotapi.User{ID: 1},
+		Message: &tgbotapi.Message{MessageID: 2, Chat: &tgbotapi.Chat{ID: 3}},
+		Data:    data,
+	}
+}
+
+func TestFromCategory(t *testing.T) {
+	var added domain.Transaction
+	tr := mockTransactionRepo{
+		addFn: func(ctx context.Context, tr domain.Transaction) (int, error) {
+			added = tr
+			return 1, nil
+		},
+	}
+	handler, manager, store := newEntryTestHandler(tr, 0)
+	id, err := conversation.Start(context.Background(), manager, entryFlow, 3, 1, entryData{Text: "5.50 chicken rice", TypedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bot, client := newRecordingSender()
+
+	handler.FromCategory(context.Background(), bot, categoryCallbackQuery(id, 4))
+
+	if added.CategoryId != 4 || added.UserId != 1 || added.Amount.Amount() != 550 || added.Description != "chicken rice" {
+		t.Errorf("unexpected transaction %+v", added)
+	}
+	if len(store.conversations) != 0 {
+		t.Errorf("expected the conversation to end, got %v", store.conversations)
+	}
+	if len(client.requests) != 2 || !strings.HasPrefix(client.requests[0], "editMessageText") || !strings.Contains(client.requests[0], "message_id=2") {
+		t.Errorf("expected the menu replaced with the reply, got %v", client.requests)
+	}
+	if len(client.requests) == 2 && (!strings.HasPrefix(client.requests[1], "answerCallbackQuery") || strings.Contains(client.requests[1], "show_alert=true")) {
+		t.Errorf("expected the callback to be answered, got %v", client.requests)
+	}
+}
+
+func TestAddTransaction_EscapesHTML(t *testing.T) {
+	tr := mockTransactionRepo{
+		addFn: func(ctx context.Context, tr domain.Transaction) (int, error) {
+			return 1, nil
+		},
+	}
+	handler, _, _ := newEntryTestHandler(tr, 0)
+	user := domain.User{Id: 1, Currency: money.GetCurrency("SGD"), Location: time.UTC, Locale: "en"}
+	category := entity.Category{Id: 40, Name: "Food & Drinks", TransactionTypeId: 1}
+
+	text, err := handler.addTransaction(context.Background(), user, category, nil, "5.50 <3 lunch", nil, time.Now())
+
+	if err != nil || !strings.Contains(text, "Spent $5.50 on Food &amp; Drinks") || !strings.Contains(text, "<i>&lt;3 lunch</i>") {
+		t.Errorf("expected the category and description escaped, got %q, %v", text, err)
+	}
+}
+
+func TestFromCategory_MenuExpired(t *testing.T) {
+	tr := mockTransactionRepo{
+		addFn: func(ctx context.Context, tr domain.Transaction) (int, error) {
+			t.Error("expected no transaction to be added")
+			return 0, nil
+		},
+	}
+	handler, _, _ := newEntryTestHandler(tr, 0)
+	bot, client := newRecordingSender()
+
+	handler.FromCategory(context.Background(), bot, categoryCallbackQuery(9, 4))
+
+	if len(client.requests) != 1 || !strings.HasPrefix(client.requests[0], "answerCallbackQuery") ||
+		!strings.Contains(client.requests[0], "callback_query_id=q1") || !strings.Contains(client.requests[0], "show_alert=true") {
+		t.Errorf("expected only the callback to be answered with an alert, got %v", client.requests)
+	}
+}
+
+func TestFromCategory_OtherUser(t *testing.T) {
+	tr := mockTransactionRepo{
+		addFn: func(ctx context.Context, tr domain.Transaction) (int, error) {
+			t.Error("expected no transaction to be added")
+			return 0, nil
+		},
+	}
+	handler, manager, store := newEntryTestHandler(tr, 0)
+	id, _ := conversation.Start(context.Background(), manager, entryFlow, 3, 7, entryData{Text: "5.50 chicken rice", TypedAt: time.Now()})
+	bot, client := newRecordingSender()
+
+	handler.FromCategory(context.Background(), bot, categoryCallbackQuery(id, 4))
+
+	if len(store.conversations) != 1 {
+		t.Errorf("expected the conversation of the other user to be kept")
+	}
+	if len(client.requests) != 1 || !strings.HasPrefix(client.requests[0], "answerCallbackQuery") {
+		t.Errorf("expected the callback to be answered as expired and the menu left, got %v", client.requests)
+	}
+}
+
+func TestFromCancelConversation(t *testing.T) {
+	handler, manager, store := newEntryTestHandler(mockTransactionRepo{}, 4)
+	id, _ := conversation.Start(context.Background(), manager, entryFlow, 3, 1, entryData{Text: "5.50 chicken rice", TypedAt: time.Now()})
+	data, _ := domain.EncodeCallback(domain.GenericCallback{
+		Callback: domain.Callback{Type: enum.CancelConversation, MessageContextId: id},
+	})
+	bot, client := newRecordingSender()
+
+	handler.FromCancelConversation(context.Background(), bot, &tgbotapi.CallbackQuery{
+		ID:      "q1",
+		From:    &tgbotapi.User{ID: 1},
+		Message: &tgbotapi.Message{MessageID: 2, Chat: &tgbotapi.Chat{ID: 3}},
+		Data:    data,
+	})
+
+	if len(store.conversations) != 0 {
+		t.Errorf("expected the conversation to be cancelled, got %v", store.conversations)
+	}
+	if len(client.requests) != 2 || !strings.HasPrefix(client.requests[0], "deleteMessage") || !strings.HasPrefix(client.requests[1], "answerCallbackQuery") {
+		t.Errorf("expected the menu to be deleted and the callback answered, got %v", client.requests)
+	}
+}
+
+func TestFromPagination(t *testing.T) {
+	var listed entity.TransactionListQuery
+	tr := mockTransactionRepo{
+		listByDateRangeFn: func(ctx context.Context, q entity.TransactionListQuery) (domain.Transactions, int, error) {
+			listed = q
+			return domain.Transactions{}, 25, nil
+		},
+	}
+	ur := mockUserRepo{
+		findByIdFn: func(ctx context.Context, id int64) (*domain.User, error) {
+			return &domain.User{Id: id, Currency: money.GetCurrency("SGD"), Location: time.UTC, Locale: "en"}, nil
+		},
+	}
+	mr := mockMessageContextRepo{
+		getMsgByIdFn: func(ctx context.Context, id int) (string, error) {
+			return "/list feb 2024", nil
+		},
+	}
+	handler := NewCallbackHandler(ur, tr, mr, mockTransactionTypeRepo{}, mockCategoryRepo{}, nil, nil, nil)
+	data, _ := domain.EncodeCallback(domain.PaginationCallback{
+		Callback: domain.Callback{Type: enum.Pagination, MessageContextId: 5},
+		Action:   enum.Previous,
+		Offset:   10,
+		Limit:    10,
+	})
+	bot, client := newRecordingSender()
+
+	handler.FromPagination(context.Background(), bot, &tgbotapi.CallbackQuery{
+		ID:      "q1",
+		From:    &tgbotapi.User{ID: 1},
+		Message: &tgbotapi.Message{MessageID: 2, Chat: &tgbotapi.Chat{ID: 3}},
+		Data:    data,
+	})
+
+	if listed.Offset != 10 || listed.Limit != 10 || listed.DateFrom.Format("2006-01-02") != "2024-02-01" || listed.DateTo.Format("2006-01-02") != "2024-03-01" {
+		t.Errorf("unexpected query %+v", listed)
+	}
+	if len(client.requests) != 2 || !strings.HasPrefix(client.requests[0], "editMessageText") ||
+		!strings.Contains(client.requests[0], "message_id=2") || !strings.Contains(client.requests[0], "reply_markup") {
+		t.Fatalf("expected the page edited in place with its buttons, got %v", client.requests)
+	}
+	if !strings.HasPrefix(client.requests[1], "answerCallbackQuery") {
+		t.Errorf("expected the callback to be answered, got %v", client.requests)
+	}
+}
+
+func TestEntryFlow_DefaultCategoryOnTimeout(t *testing.T) {
+	typedAt := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	var added domain.Transaction
+	tr := mockTransactionRepo{
+		addFn: func(ctx context.Context, tr domain.Transaction) (int, error) {
+			added = tr
+			return 1, nil
+		},
+	}
+	_, manager, store := newEntryTestHandler(tr, 4)
+	_, err := conversation.Start(context.Background(), manager, entryFlow, 3, 1, entryData{Text: "5.50 chicken rice", TypedAt: typedAt})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bot, client := newRecordingSender()
+
+	expired, err := manager.Expire(context.Background(), bot, time.Now().Add(time.Hour))
+
+	if err != nil || expired != 1 || len(store.conversations) != 0 {
+		t.Fatalf("expected the conversation to expire, got %d, %v", expired, err)
+	}
+	if added.CategoryId != 4 || added.UserId != 1 || added.Amount.Amount() != 550 || added.Description != "chicken rice" {
+		t.Errorf("unexpected transaction %+v", added)
+	}
+	if !added.Datetime.Equal(typedAt) {
+		t.Errorf("expected the transaction at the time it was typed, got %v", added.Datetime)
+	}
+	if len(client.requests) != 1 || !strings.HasPrefix(client.requests[0], "sendMessage") || !strings.Contains(client.requests[0], "default+category") {
+		t.Errorf("expected a reply about the default category, got %v", client.requests)
+	}
+}
+
+func TestEntryFlow_DefaultCategoryOnTimeout_Audit(t *testing.T) {
+	var origin audit.Origin
+	tr := mockTransactionRepo{
+		addFn: func(ctx context.Context, tr domain.Transaction) (int, error) {
+			origin = audit.FromContext(ctx)
+			return 1, nil
+		},
+	}
+	_, manager, _ := newEntryTestHandler(tr, 4)
+	_, err := conversation.Start(context.Background(), manager, entryFlow, 3, 1, entryData{Text: "5.50 chicken rice", TypedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bot, _ := newRecordingSender()
+
+	job.NewConversationJob(manager).Run(context.Background(), bot, time.Now().Add(time.Hour))
+
+	if origin.Actor != "job:conversation" || origin.Source != audit.SourceJob {
+		t.Errorf("expected the transaction saved by the conversation job, got %+v", origin)
+	}
+}
+
+func TestEntryFlow_NoDefaultCategory(t *testing.T) {
+	tr := mockTransactionRepo{
+		addFn: func(ctx context.Context, tr domain.Transaction) (int, error) {
+			t.Error("expected no transaction to be added")
+			return 0, nil
+		},
+	}
+	_, manager, _ := newEntryTestHandler(tr, 0)
+	conversation.Start(context.Background(), manager, entryFlow, 3, 1, entryData{Text: "5.50 chicken rice", TypedAt: time.Now()})
+	bot, client := newRecordingSender()
+
+	expired, err := manager.Expire(context.Background(), bot, time.Now().Add(time.Hour))
+
+	if err != nil || expired != 1 || len(client.requests) != 0 {
+		t.Errorf("expected the conversation to expire silently, got %d, %v, %v", expired, err, client.requests)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
 	tests := []struct {
 		name     string
-		value    float64
+		s        string
 		currency money.Currency
 		want     int64
+		wantRest string
 		wantErr  bool
 	}{
-		{"SGD 5.50", 5.50, *money.GetCurrency("SGD"), 550, false},
-		{"SGD 100", 100, *money.GetCurrency("SGD"), 10000, false},
-		{"SGD 0.01", 0.01, *money.GetCurrency("SGD"), 1, false},
-		{"SGD 0", 0, *money.GetCurrency("SGD"), 0, false},
-		{"JPY 100", 100, *money.GetCurrency("JPY"), 100, false},
-		{"SGD -5.50", -5.50, *money.GetCurrency("SGD"), -550, false},
+		{"SGD 5.50", "5.50 Chicken Rice", *money.GetCurrency("SGD"), 550, "Chicken Rice", false},
+		{"SGD 100", "100", *money.GetCurrency("SGD"), 10000, "", false},
+		{"SGD 0.01", "0.01", *money.GetCurrency("SGD"), 1, "", false},
+		{"SGD 0", "0", *money.GetCurrency("SGD"), 0, "", false},
+		{"SGD 0.29", "0.29", *money.GetCurrency("SGD"), 29, "", false},
+		{"SGD -5.50", "-5.50 Refund", *money.GetCurrency("SGD"), -550, "Refund", false},
+		{"SGD 5.505", "5.505 Coffee", *money.GetCurrency("SGD"), 0, "", true},
+		{"JPY 100", "100 Ramen", *money.GetCurrency("JPY"), 100, "Ramen", false},
+		{"JPY 100.5", "100.5 Ramen", *money.GetCurrency("JPY"), 0, "", true},
+		{"KWD 1.250", "1.250 Tea", *money.GetCurrency("KWD"), 1250, "Tea", false},
+		{"no amount", "Chicken Rice", *money.GetCurrency("SGD"), 0, "", true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := decimalise(tt.value, tt.currency)
+			got, rest, err := parseAmount(tt.s, tt.currency)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("decimalise(%v, %v) error = %v, wantErr %v", tt.value, tt.currency, err, tt.wantErr)
+				t.Fatalf("parseAmount(%q, %v) error = %v, wantErr %v", tt.s, tt.currency.Code, err, tt.wantErr)
+			}
+			if err != nil {
 				return
 			}
-			if got != tt.want {
-				t.Errorf("decimalise(%v, %v) = %d, want %d", tt.value, tt.currency, got, tt.want)
+			if got.Amount() != tt.want || got.Currency().Code != tt.currency.Code || rest != tt.wantRest {
+				t.Errorf("parseAmount(%q, %v) = %v %q, want %d %q", tt.s, tt.currency.Code, got, rest, tt.want, tt.wantRest)
 			}
 		})
 	}
 }
 
-func TestRemoveNonNumeric(t *testing.T) {
-	tests := []struct {
-		name  string
-		input string
-		want  string
-	}{
-		{"digits only", "12345", "12345"},
-		{"with dots", "12.34", "1234"},
-		{"with text", "abc123def", "123"},
-		{"negative at start", "-100", "-100"},
-		{"negative mid-string", "abc-100", "100"},
-		{"empty", "", ""},
-		{"all non-numeric", "abc", ""},
+func accountCallbackQuery(conversationId int, accountId int) *tgbotapi.CallbackQuery {
+	query := categoryCallbackQuery(conversationId, 0)
+	query.Data, _ = domain.EncodeCallback(domain.AccountCallback{
+		Callback:  domain.Callback{Type: enum.Account, MessageContextId: conversationId},
+		AccountId: accountId,
+	})
+	return query
+}
+
+func TestEntryFlow_ChooseAccount(t *testing.T) {
+	var added domain.Transaction
+	tr := mockTransactionRepo{
+		addFn: func(ctx context.Context, tr domain.Transaction) (int, error) {
+			added = tr
+			return 1, nil
+		},
 	}
+	accounts := domain.Accounts{{Id: 7, Name: "Cash"}, {Id: 8, Name: "DBS card"}}
+	handler, manager, store := newEntryTestHandlerWithAccounts(tr, accounts, 0)
+	id, _ := conversation.Start(context.Background(), manager, entryFlow, 3, 1, entryData{Text: "5.50 chicken rice", TypedAt: time.Now()})
+	bot, client := newRecordingSender()
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := removeNonNumeric(tt.input)
-			if got != tt.want {
-				t.Errorf("removeNonNumeric(%q) = %q, want %q", tt.input, got, tt.want)
-			}
-		})
+	handler.FromCategory(context.Background(), bot, categoryCallbackQuery(id, 4))
+
+	if added.UserId != 0 {
+		t.Fatalf("expected the account to be asked before adding, got %+v", added)
+	}
+	if len(client.requests) != 2 || !strings.HasPrefix(client.requests[0], "editMessageText") || !strings.Contains(client.requests[0], "DBS+card") {
+		t.Fatalf("expected the menu replaced with the accounts, got %v", client.requests)
+	}
+
+	handler.FromAccount(context.Background(), bot, accountCallbackQuery(id, 8))
+
+	if added.CategoryId != 4 || added.AccountId != 8 || added.Amount.Amount() != 550 {
+		t.Errorf("unexpected transaction %+v", added)
+	}
+	if len(store.conversations) != 0 {
+		t.Errorf("expected the conversation to end, got %v", store.conversations)
+	}
+	if len(client.requests) != 4 || !strings.Contains(client.requests[2], "Paid+with") {
+		t.Errorf("expected the reply to name the account, got %v", client.requests)
+	}
+}
+
+func TestEntryFlow_HintedAccount(t *testing.T) {
+	var added domain.Transaction
+	tr := mockTransactionRepo{
+		addFn: func(ctx context.Context, tr domain.Transaction) (int, error) {
+			added = tr
+			return 1, nil
+		},
+	}
+	accounts := domain.Accounts{{Id: 7, Name: "Cash"}, {Id: 8, Name: "DBS card"}}
+	handler, manager, store := newEntryTestHandlerWithAccounts(tr, accounts, 0)
+	id, _ := conversation.Start(context.Background(), manager, entryFlow, 3, 1, entryData{Text: "5.50 chicken rice", TypedAt: time.Now(), AccountId: 7})
+	bot, _ := newRecordingSender()
+
+	handler.FromCategory(context.Background(), bot, categoryCallbackQuery(id, 4))
+
+	if added.CategoryId != 4 || added.AccountId != 7 {
+		t.Errorf("expected the amount added with the hinted account, got %+v", added)
+	}
+	if len(store.conversations) != 0 {
+		t.Errorf("expected the conversation to end, got %v", store.conversations)
+	}
+}
+
+func TestEntryFlow_NoAccountOnTimeout(t *testing.T) {
+	var added domain.Transaction
+	tr := mockTransactionRepo{
+		addFn: func(ctx context.Context, tr domain.Transaction) (int, error) {
+			added = tr
+			return 1, nil
+		},
+	}
+	handler, manager, _ := newEntryTestHandlerWithAccounts(tr, domain.Accounts{{Id: 7, Name: "Cash"}}, 0)
+	id, _ := conversation.Start(context.Background(), manager, entryFlow, 3, 1, entryData{Text: "5.50 chicken rice", TypedAt: time.Now()})
+	bot, client := newRecordingSender()
+	handler.FromCategory(context.Background(), bot, categoryCallbackQuery(id, 4))
+
+	expired, err := manager.Expire(context.Background(), bot, time.Now().Add(time.Hour))
+
+	if err != nil || expired != 1 {
+		t.Fatalf("expected the conversation to expire, got %d, %v", expired, err)
+	}
+	if added.CategoryId != 4 || added.AccountId != 0 {
+		t.Errorf("expected the amount added under the category chosen without an account, got %+v", added)
+	}
+	if last := client.requests[len(client.requests)-1]; !strings.HasPrefix(last, "sendMessage") || !strings.Contains(last, "No+account") {
+		t.Errorf("expected a reply about the account, got %v", client.requests)
 	}
 }
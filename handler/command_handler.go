@@ -2,17 +2,22 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"html"
 	"os"
 	"strings"
 	"time"
 	"unicode/utf8"
 
 	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/conversation"
 	"github.com/aattwwss/telegram-expense-bot/domain"
 	"github.com/aattwwss/telegram-expense-bot/entity"
 	"github.com/aattwwss/telegram-expense-bot/enum"
 	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/router"
+	"github.com/aattwwss/telegram-expense-bot/sender"
 	"github.com/aattwwss/telegram-expense-bot/util"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/rs/zerolog/log"
@@ -20,15 +25,7 @@ import (
 )
 
 const (
-	userExistsMsg            = "Welcome back! These are the summary of your transactions: \n"
-	errorFindingUserMsg      = "Sorry there is a problem fetching your information.\n"
-	errorCreatingUserMsg     = "Sorry there is a problem signing you up.\n"
-	signUpSuccessMsg         = "Congratulations!\nWelcome to your expense tracker!\nType /help to learn how you can start using this bot right away!"
-	cannotRecogniseAmountMsg = "I don't recognise that amount of money :(\nType /help to learn how you can start tracking your expenses!"
-	descriptionTooLong       = "Sorry, your description (max 20 characters) is too long :( \n"
-	transactionListEmptyMsg  = "You have no transactions this month."
-
-	statsHeaderHTMLMsg = "<b>%s %v\n</b>%s\n\n" // E.g. November 2022
+	statsHeaderHTMLMsg = "<b>%s\n</b>%s\n\n" // E.g. November 2022
 
 	transactionTypeInlineColSize = 2
 
@@ -36,40 +33,60 @@ const (
 	exportDefaultPageSize = 1000
 
 	descLengthLimit = 50
+
+	defaultRemindAt = 21 * 60 // 9pm
 )
 
+// CommandHandler handles the commands registered by NewCommandRouter. The handlers of the commands that need a
+// registered user expect the router to have loaded it into the context with LoadUser and RequireUser.
 type CommandHandler struct {
 	transactionRepo     TransactionRepo
 	messageContextRepo  MessageContextRepo
 	transactionTypeRepo TransactionTypeRepo
 	categoryRepo        CategoryRepo
+	accountRepo         AccountRepo
+	goalRepo            GoalRepo
+	iouRepo             IouRepo
+	reminderRepo        ReminderRepo
+	apiTokenRepo        ApiTokenRepo
 	userRepo            UserRepo
+	conversations       *conversation.Manager
+	commands            *router.Router
+	webAppUrl           string
 }
 
-func NewCommandHandler(userRepo UserRepo, transactionRepo TransactionRepo, messageContextRepo MessageContextRepo, transactionTypeRepo TransactionTypeRepo, categoryRepo CategoryRepo) CommandHandler {
+func NewCommandHandler(userRepo UserRepo, transactionRepo TransactionRepo, messageContextRepo MessageContextRepo, transactionTypeRepo TransactionTypeRepo, categoryRepo CategoryRepo, accountRepo AccountRepo, goalRepo GoalRepo, iouRepo IouRepo, reminderRepo ReminderRepo, apiTokenRepo ApiTokenRepo, conversations *conversation.Manager, webAppUrl string) CommandHandler {
 	return CommandHandler{
 		userRepo:            userRepo,
 		transactionRepo:     transactionRepo,
 		messageContextRepo:  messageContextRepo,
 		transactionTypeRepo: transactionTypeRepo,
 		categoryRepo:        categoryRepo,
+		accountRepo:         accountRepo,
+		goalRepo:            goalRepo,
+		iouRepo:             iouRepo,
+		reminderRepo:        reminderRepo,
+		apiTokenRepo:        apiTokenRepo,
+		conversations:       conversations,
+		webAppUrl:           webAppUrl,
 	}
 }
 
-func (handler CommandHandler) Start(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+func (handler CommandHandler) Start(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
 
 	teleUser := update.SentFrom()
+	locale := clientLocale(teleUser)
 
 	dbUser, err := handler.userRepo.FindUserById(ctx, teleUser.ID)
 	if err != nil {
-		log.Error().Msgf("error finding user: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, errorFindingUserMsg)
+		log.Ctx(ctx).Error().Msgf("error finding user: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.ErrorFindingUserMsg))
 		return
 	}
 
 	if dbUser != nil {
-		log.Info().Msgf("User already exists. id: %v", dbUser.Id)
-		util.BotSendMessage(bot, update.Message.Chat.ID, userExistsMsg)
+		log.Ctx(ctx).Info().Msgf("User already exists. id: %v", dbUser.Id)
+		util.BotSendMessage(bot, update.Message.Chat.ID, dbUser.GetLocale().Get(message.UserExistsMsg))
 		return
 	}
 
@@ -78,210 +95,255 @@ func (handler CommandHandler) Start(ctx context.Context, bot *tgbotapi.BotAPI, u
 
 	user := domain.User{
 		Id:       teleUser.ID,
-		Locale:   "en",
+		Locale:   locale.Code,
 		Currency: defaultCurrency,
 		Location: defaultLocation,
 	}
 
 	err = handler.userRepo.Add(ctx, user)
 	if err != nil {
-		log.Error().Msgf("error adding user: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, errorCreatingUserMsg)
+		log.Ctx(ctx).Error().Msgf("error adding user: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.ErrorCreatingUserMsg))
 		return
 	}
 
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID, signUpSuccessMsg)
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, locale.Get(message.SignUpSuccessMsg))
 	util.BotSendWrapper(bot, msg)
 }
 
-func (handler CommandHandler) Help(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID, message.HelpMsg)
+// Help explains how to add an amount and lists the commands registered with the router
+func (handler CommandHandler) Help(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	locale := contextLocale(ctx, update.SentFrom())
+	text := locale.Get(message.HelpMsg)
+	if handler.commands != nil {
+		text += handler.commands.Usage(locale)
+	}
+	text += locale.Get(message.HelpExamplesMsg)
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
 	util.BotSendWrapper(bot, msg)
 }
 
-func (handler CommandHandler) Undo(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+func (handler CommandHandler) Undo(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
 	userId := update.Message.From.ID
+	locale := contextLocale(ctx, update.SentFrom())
 	latestTransaction, err := handler.transactionRepo.FindLastestByUserId(ctx, userId)
 	if err != nil {
-		log.Error().Msgf("Error finding latest transaction: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("Error finding latest transaction: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 
 	if latestTransaction == nil {
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.TransactionLatestNotFound)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.TransactionLatestNotFound))
 		return
 	}
 
 	contextId, err := handler.messageContextRepo.Add(ctx, update.Message.Chat.ID, update.Message.MessageID, update.Message.Text)
 	if err != nil {
-		log.Error().Msgf("Add message context error: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("Add message context error: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 
-	inlineKeyboard, err := util.NewUndoConfirmationKeyboard(latestTransaction.Id, contextId, 1)
+	inlineKeyboard, err := util.NewUndoConfirmationKeyboard(latestTransaction.Id, contextId, 1, locale)
 	if err != nil {
-		log.Error().Msgf("NewUndoConfirmationKeyboard error: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("NewUndoConfirmationKeyboard error: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 
-	text := fmt.Sprintf(message.TransactionDeleteConfirmationMsg, latestTransaction.Amount.Display(), latestTransaction.Description)
+	text := locale.Get(message.TransactionDeleteConfirmationMsg, locale.FormatMoney(latestTransaction.Amount), latestTransaction.Description)
 	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
 	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: inlineKeyboard}
 	util.BotSendWrapper(bot, msg)
 }
 
-func (handler CommandHandler) StartTransaction(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
-	userId := update.SentFrom().ID
-	user, err := handler.userRepo.FindUserById(ctx, userId)
+// FromText passes a text that is not a command to the conversation waiting for it, or else starts adding an amount
+func (handler CommandHandler) FromText(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	handled, err := handler.conversations.HandleText(ctx, bot, update.Message)
+	if err != nil {
+		locale := contextLocale(ctx, update.SentFrom())
+		log.Ctx(ctx).Error().Msgf("Handle conversation text error: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	if handled {
+		return
+	}
+	handler.StartTransaction(ctx, bot, update)
+}
+
+// Cancel ends the conversations of the user in the chat, such as an amount waiting for its category
+func (handler CommandHandler) Cancel(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	locale := contextLocale(ctx, update.SentFrom())
+	cancelled, err := handler.conversations.Cancel(ctx, bot, update.Message.Chat.ID, update.SentFrom().ID)
 	if err != nil {
-		log.Error().Msgf("Error finding user for transact: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("Cancel conversations error: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
-	if user == nil {
-		log.Error().Msgf("User not found for transact: %v", userId)
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+	if cancelled == 0 {
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.NoConversationMsg))
 		return
 	}
+	util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.ConversationCancelledMsg))
+}
+
+func (handler CommandHandler) StartTransaction(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	user := userFromContext(ctx)
+	locale := user.GetLocale()
 
-	floatString, err := parseFloatStringFromString(update.Message.Text)
+	text, hints := parseAccountHints(update.Message.Text)
+	amount, description, err := parseAmount(text, *user.Currency)
+	if errors.Is(err, util.ErrTooManyDecimals) {
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.AmountPrecisionMsg, user.Currency.Code, user.Currency.Fraction))
+		return
+	}
 	if err != nil {
-		log.Error().Msgf("%v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, cannotRecogniseAmountMsg)
+		log.Ctx(ctx).Error().Msgf("%v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.CannotRecogniseAmountMsg))
 		return
 	}
 
-	stringAfter := util.After(update.Message.Text, floatString)
-	if len(strings.TrimSpace(stringAfter)) > descLengthLimit {
-		util.BotSendMessage(bot, update.Message.Chat.ID, descriptionTooLong)
+	if len(description) > descLengthLimit {
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.DescriptionTooLongMsg, descLengthLimit))
 		return
 	}
 
-	contextId, err := handler.messageContextRepo.Add(ctx, update.Message.Chat.ID, update.Message.MessageID, update.Message.Text)
+	data := entryData{
+		Text:    text,
+		TypedAt: time.Now(),
+	}
+	if len(hints) > 1 {
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.AccountTooManyMsg))
+		return
+	}
+	if len(hints) == 1 {
+		accounts, ok := handler.findAccounts(ctx, bot, update.Message.Chat.ID, *user, hints)
+		if !ok {
+			return
+		}
+		data.AccountId = accounts[0].Id
+	}
+
+	conversationId, err := conversation.Start(ctx, handler.conversations, entryFlow, update.Message.Chat.ID, user.Id, data)
 	if err != nil {
-		log.Error().Msgf("Add message context error: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("Start entry conversation error: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 
-	categories, err := handler.categoryRepo.FindAll(ctx)
+	categories, err := handler.categoryRepo.FindAllByUserId(ctx, user.Id)
 	if err != nil {
-		log.Error().Msgf("FindAll categories error: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("FindAllByUserId categories error: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 
-	inlineKeyboard, err := newCategoriesKeyboard(categories, contextId, categoriesInlineColSize)
+	inlineKeyboard, err := newEntryCategoriesKeyboard(categories, conversationId, categoriesInlineColSize, amount, locale)
 	if err != nil {
-		log.Error().Msgf("newCategoriesKeyboard error: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("newCategoriesKeyboard error: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID, message.TransactionTypeReplyMsg)
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, locale.Get(message.TransactionTypeReplyMsg))
 	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: inlineKeyboard}
 	util.BotSendWrapper(bot, msg)
 }
 
-func (handler CommandHandler) Stats(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
-	userId := update.SentFrom().ID
-	user, err := handler.userRepo.FindUserById(ctx, userId)
+func (handler CommandHandler) Stats(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	user := userFromContext(ctx)
+	locale := user.GetLocale()
+
+	period, err := util.ParsePeriod(update.Message.CommandArguments(), time.Now().In(user.Location))
 	if err != nil {
-		log.Error().Msgf("Error finding user for stats: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.PeriodInvalidMsg))
 		return
 	}
 
-	month, year := util.ParseMonthYearFromMessage(update.Message.Text)
-
-	breakdowns, total, err := handler.transactionRepo.GetTransactionBreakdownByCategory(ctx, month, year, *user)
+	breakdowns, total, err := handler.transactionRepo.GetTransactionBreakdownByCategory(ctx, period.From, period.To, *user)
 
 	if err != nil {
-		log.Error().Msgf("Error getting breakdowns: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("Error getting breakdowns: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 
-	header := fmt.Sprintf(statsHeaderHTMLMsg, month.String(), year, total.Display())
-	text := header + breakdowns.GetFormattedHTMLMsg()
+	header := fmt.Sprintf(statsHeaderHTMLMsg, period.Label(locale), locale.FormatMoney(total))
+	text := header + breakdowns.GetFormattedHTMLMsg(locale)
 	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
 	msg.ParseMode = tgbotapi.ModeHTML
 	util.BotSendWrapper(bot, msg)
 }
 
-func (handler CommandHandler) List(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+func (handler CommandHandler) List(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
 	pageSize := listDefaultPageSize
-	userId := update.SentFrom().ID
-	user, err := handler.userRepo.FindUserById(ctx, userId)
-	if err != nil {
-		log.Error().Msgf("Error finding user for stats: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+	user := userFromContext(ctx)
+	locale := user.GetLocale()
+
+	list, ok := handler.readListArgs(ctx, bot, update.Message.Chat.ID, *user, update.Message.CommandArguments())
+	if !ok {
 		return
 	}
 
 	contextId, err := handler.messageContextRepo.Add(ctx, update.Message.Chat.ID, update.Message.MessageID, update.Message.Text)
 	if err != nil {
-		log.Error().Msgf("Add message context error: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("Add message context error: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 
-	month, year := util.ParseMonthYearFromMessage(update.Message.Text)
-
 	q := entity.TransactionListQuery{
-		Month:    month,
-		Year:     year,
-		Offset:   0,
-		Limit:    pageSize,
-		Asc:      false,
-		UserId:   user.Id,
-		Location: user.Location,
-	}
-	transactions, totalCount, err := handler.transactionRepo.ListByMonthAndYear(ctx, q)
+		DateFrom:  list.period.From,
+		DateTo:    list.period.To,
+		Offset:    0,
+		Limit:     pageSize,
+		Asc:       false,
+		UserId:    user.Id,
+		AccountId: list.accountId(),
+	}
+	transactions, totalCount, err := handler.transactionRepo.ListByDateRange(ctx, q)
 	if err != nil {
-		log.Error().Msgf("Error getting list of transactions: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("Error getting list of transactions: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 	if totalCount == 0 {
-		util.BotSendMessage(bot, update.Message.Chat.ID, transactionListEmptyMsg)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.TransactionListEmptyMsg, list.label(locale)))
 		return
 	}
 
-	inlineKeyboard, err := util.NewPaginationKeyboard(totalCount, 0, pageSize, contextId, 2)
+	inlineKeyboard, err := util.NewPaginationKeyboard(totalCount, 0, pageSize, contextId, 2, locale)
 	if err != nil {
-		log.Error().Msgf("Error generating keyboard for transaction pagination: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("Error generating keyboard for transaction pagination: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 
-	text := transactions.GetFormattedHTMLMsg(month, year, user.Location, totalCount, 0, pageSize)
+	text := transactions.GetFormattedHTMLMsg(html.EscapeString(list.label(locale)), user.Location, locale, totalCount, 0, pageSize)
 	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
 	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: inlineKeyboard}
 	msg.ParseMode = tgbotapi.ModeHTML
 	util.BotSendWrapper(bot, msg)
 }
 
-func (handler CommandHandler) Export(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+func (handler CommandHandler) Export(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
 	pageSize := exportDefaultPageSize
 
-	userId := update.SentFrom().ID
-	user, err := handler.userRepo.FindUserById(ctx, userId)
-	if err != nil {
-		log.Error().Msgf("Error finding user for stats: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+	user := userFromContext(ctx)
+	locale := user.GetLocale()
+
+	list, ok := handler.readListArgs(ctx, bot, update.Message.Chat.ID, *user, update.Message.CommandArguments())
+	if !ok {
 		return
 	}
 
-	month, year := util.ParseMonthYearFromMessage(update.Message.Text)
-	fileName := fmt.Sprintf("expenses_%02d_%v_*.xlsx", int(month), year)
+	fileName := fmt.Sprintf("expenses_%s_*.xlsx", list.period.Slug())
 	f, err := os.CreateTemp("", fileName)
 	if err != nil {
-		log.Error().Msgf("Error creating temp file: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("Error creating temp file: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 	defer os.Remove(f.Name())
@@ -289,81 +351,306 @@ func (handler CommandHandler) Export(ctx context.Context, bot *tgbotapi.BotAPI,
 	excel := excelize.NewFile()
 	defer excel.Close()
 
+	sheetName := locale.Get(message.ExportSheetName)
+	excel.SetSheetName("Sheet1", sheetName)
+
+	// format the date and amount columns before the header row so that the header keeps its own style
+	dateFmt := locale.ExcelDateFmt
+	dateStyleId, _ := excel.NewStyle(&excelize.Style{CustomNumFmt: &dateFmt})
+	excel.SetColStyle(sheetName, "A", dateStyleId)
+	amountFmt := excelAmountFmt(user.Currency.Fraction)
+	amountStyleId, _ := excel.NewStyle(&excelize.Style{CustomNumFmt: &amountFmt})
+	excel.SetColStyle(sheetName, "C", amountStyleId)
+
 	// add header row
 	headers := []string{
-		"Date",
-		"Description",
-		"Amount",
-		"Category",
-		"Currency",
-	}
-	excel.SetSheetRow("Sheet1", "A1", &headers)
+		locale.Get(message.ExportDateHeader),
+		locale.Get(message.ExportDescHeader),
+		locale.Get(message.ExportAmtHeader),
+		locale.Get(message.ExportCatHeader),
+		locale.Get(message.ExportCurrHeader),
+		locale.Get(message.ExportAccountHeader),
+	}
+	excel.SetSheetRow(sheetName, "A1", &headers)
 	style := excelize.Style{
 		Font: &excelize.Font{
 			Bold: true,
 		},
 	}
 	styleId, _ := excel.NewStyle(&style)
-	excel.SetRowStyle("Sheet1", 1, 1, styleId)
+	excel.SetRowStyle(sheetName, 1, 1, styleId)
 
 	offset := 0
+	// a split transaction takes a row for each of its categories
+	row := 2
 	for {
 		q := entity.TransactionListQuery{
-			Month:    month,
-			Year:     year,
-			Offset:   offset,
-			Limit:    pageSize,
-			Asc:      true,
-			UserId:   user.Id,
-			Location: user.Location,
+			DateFrom:  list.period.From,
+			DateTo:    list.period.To,
+			Offset:    offset,
+			Limit:     pageSize,
+			Asc:       true,
+			UserId:    user.Id,
+			AccountId: list.accountId(),
 		}
-		transactions, totalCount, err := handler.transactionRepo.ListByMonthAndYear(ctx, q)
+		transactions, totalCount, err := handler.transactionRepo.ListByDateRange(ctx, q)
 		if totalCount == 0 {
-			util.BotSendMessage(bot, update.Message.Chat.ID, transactionListEmptyMsg)
+			util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.TransactionListEmptyMsg, list.label(locale)))
 			return
 		}
 		if offset > totalCount {
 			break
 		}
 		if err != nil {
-			log.Error().Msgf("Error finding listing transactions for export: %v", err)
-			util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+			log.Ctx(ctx).Error().Msgf("Error finding listing transactions for export: %v", err)
+			util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
 			return
 		}
-		for i, t := range transactions {
-			data := []interface{}{
-				t.Datetime.In(user.Location),
-				t.Description,
-				t.Amount.AsMajorUnits(),
-				t.CategoryName,
-				t.Amount.Currency().Code,
+		for _, t := range transactions {
+			for _, a := range t.Allocations() {
+				data := []interface{}{
+					t.Datetime.In(user.Location),
+					t.Description,
+					a.Amount.AsMajorUnits(),
+					locale.CategoryName(a.CategoryName),
+					a.Amount.Currency().Code,
+					t.AccountName,
+				}
+
+				cellName, _ := excelize.CoordinatesToCellName(1, row)
+				excel.SetSheetRow(sheetName, cellName, &data)
+				row++
 			}
-
-			cellName, _ := excelize.CoordinatesToCellName(1, offset+i+2)
-			excel.SetSheetRow("Sheet1", cellName, &data)
 		}
 		offset += pageSize
 	}
 
-	err = autoFitColumnWidth(excel, "Sheet1")
+	err = autoFitColumnWidth(excel, sheetName)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error auto fitting column width: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+
+	// the changes made during the period go in a sheet of their own
+	history, err := handler.transactionRepo.ListHistory(ctx, user.Id, list.period.From, list.period.To)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error listing transaction history for export: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	if list.account != nil {
+		history = history.ForAccount(list.account.Id)
+	}
+	err = writeHistorySheet(excel, history, *user)
 	if err != nil {
-		log.Error().Msgf("Error auto fitting column width: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("Error writing history sheet: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 
 	err = excel.SaveAs(f.Name())
 	if err != nil {
-		log.Error().Msgf("Error saving export excel file: %v", err)
-		util.BotSendMessage(bot, update.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msgf("Error saving export excel file: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
 		return
 	}
 
 	docMsg := tgbotapi.NewDocument(update.Message.Chat.ID, tgbotapi.FilePath(f.Name()))
-	docMsg.Caption = fmt.Sprintf("Exported expenses for %s %v", month.String(), year)
+	docMsg.Caption = locale.Get(message.ExportCaptionMsg, list.label(locale))
 	util.BotSendWrapper(bot, docMsg)
 }
 
+func (handler CommandHandler) Remind(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	userId := update.SentFrom().ID
+	locale := contextLocale(ctx, update.SentFrom())
+	reminder, err := handler.reminderRepo.GetByUserId(ctx, userId)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error finding reminder: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		text := locale.Get(message.ReminderNotFoundMsg)
+		if reminder != nil {
+			text = reminder.GetFormattedMsg(locale)
+		}
+		util.BotSendMessage(bot, update.Message.Chat.ID, text+"\n"+locale.Get(message.ReminderUsageMsg))
+		return
+	}
+
+	if reminder == nil {
+		user := userFromContext(ctx)
+		// a reminder set up with only quiet hours is on at the default time
+		reminder = &domain.Reminder{
+			UserId:   userId,
+			Enabled:  true,
+			RemindAt: defaultRemindAt,
+			Location: user.Location,
+			Locale:   user.Locale,
+		}
+	}
+	reminder.ChatId = update.Message.Chat.ID
+
+	err = applyReminderArgs(reminder, args)
+	if errors.Is(err, errQuietReminder) {
+		text := locale.Get(message.ReminderQuietCoversMsg, domain.FormatMinuteOfDay(reminder.RemindAt))
+		util.BotSendMessage(bot, update.Message.Chat.ID, text+locale.Get(message.ReminderUsageMsg))
+		return
+	}
+	if err != nil {
+		log.Ctx(ctx).Info().Msgf("Invalid reminder arguments: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.ReminderInvalidMsg)+locale.Get(message.ReminderUsageMsg))
+		return
+	}
+
+	err = handler.reminderRepo.Save(ctx, *reminder)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error saving reminder: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+
+	reminder.SnoozedUntil = nil // saving the reminder clears any pending snooze
+	util.BotSendMessage(bot, update.Message.Chat.ID, reminder.GetFormattedMsg(locale))
+}
+
+func (handler CommandHandler) Language(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	user := userFromContext(ctx)
+	locale := user.GetLocale()
+
+	arg := strings.TrimSpace(update.Message.CommandArguments())
+	if arg == "" {
+		inlineKeyboard, err := newLanguagesKeyboard(message.SupportedLocales(), 2, locale)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("newLanguagesKeyboard error: %v", err)
+			util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
+			return
+		}
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, locale.Get(message.LanguageSelectMsg))
+		msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: inlineKeyboard}
+		util.BotSendWrapper(bot, msg)
+		return
+	}
+
+	newLocale, ok := message.MatchLocale(arg)
+	if !ok {
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.LanguageUnsupportedMsg))
+		return
+	}
+
+	err := handler.userRepo.UpdateLocale(ctx, user.Id, newLocale.Code)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error updating locale: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	util.BotSendMessage(bot, update.Message.Chat.ID, newLocale.Get(message.LanguageUpdatedMsg))
+}
+
+// Token issues a personal access token for the REST API, or revokes all of them with "/token revoke"
+func (handler CommandHandler) Token(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	user := userFromContext(ctx)
+	locale := user.GetLocale()
+
+	if !update.Message.Chat.IsPrivate() {
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.TokenPrivateChatMsg))
+		return
+	}
+
+	if strings.EqualFold(strings.TrimSpace(update.Message.CommandArguments()), "revoke") {
+		err := handler.apiTokenRepo.RevokeAll(ctx, user.Id)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("Error revoking api tokens: %v", err)
+			util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
+			return
+		}
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.TokenRevokedMsg))
+		return
+	}
+
+	token, err := handler.apiTokenRepo.Issue(ctx, user.Id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error issuing api token: %v", err)
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, locale.Get(message.TokenIssuedMsg, token))
+	msg.ParseMode = tgbotapi.ModeHTML
+	util.BotSendWrapper(bot, msg)
+}
+
+// App replies with a button that opens the mini app, which telegram only allows in private chats
+func (handler CommandHandler) App(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	locale := contextLocale(ctx, update.SentFrom())
+
+	if handler.webAppUrl == "" {
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.WebAppNotConfiguredMsg))
+		return
+	}
+	if !update.Message.Chat.IsPrivate() {
+		util.BotSendMessage(bot, update.Message.Chat.ID, locale.Get(message.WebAppPrivateChatMsg))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, locale.Get(message.WebAppOpenMsg))
+	msg.ReplyMarkup = util.NewWebAppKeyboard(locale.Get(message.WebAppButton), handler.webAppUrl)
+	util.BotSendWrapper(bot, msg)
+}
+
+// errQuietReminder is returned for quiet hours covering the time of the reminder, which would never be sent if they
+// last past midnight
+var errQuietReminder = errors.New("quiet hours cover the reminder")
+
+// applyReminderArgs updates the reminder according to the arguments of the /remind command, returning
+// errQuietReminder if the quiet hours would cover the time of the reminder
+func applyReminderArgs(reminder *domain.Reminder, args []string) error {
+	switch {
+	case len(args) == 1 && strings.EqualFold(args[0], "off"):
+		reminder.Enabled = false
+	case len(args) == 1 && strings.EqualFold(args[0], "on"):
+		reminder.Enabled = true
+	case len(args) == 2 && strings.EqualFold(args[0], "quiet") && strings.EqualFold(args[1], "off"):
+		reminder.QuietStart = nil
+		reminder.QuietEnd = nil
+	case len(args) == 3 && strings.EqualFold(args[0], "quiet"):
+		quietStart, err := domain.ParseMinuteOfDay(args[1])
+		if err != nil {
+			return err
+		}
+		quietEnd, err := domain.ParseMinuteOfDay(args[2])
+		if err != nil {
+			return err
+		}
+		reminder.QuietStart = &quietStart
+		reminder.QuietEnd = &quietEnd
+	case len(args) == 1:
+		remindAt, err := domain.ParseMinuteOfDay(args[0])
+		if err != nil {
+			return err
+		}
+		reminder.RemindAt = remindAt
+		reminder.Enabled = true
+	default:
+		return fmt.Errorf("unrecognised reminder arguments: %v", args)
+	}
+	if reminder.IsQuiet(reminder.RemindAt) {
+		return errQuietReminder
+	}
+	return nil
+}
+
+// excelAmountFmt returns the excel number format of an amount with the decimal places of the currency,
+// the separators are applied by excel according to the locale of the viewer
+func excelAmountFmt(fraction int) string {
+	if fraction <= 0 {
+		return "#,##0"
+	}
+	return "#,##0." + strings.Repeat("0", fraction)
+}
+
 // autofit all columns according to their text content
 func autoFitColumnWidth(excel *excelize.File, sheetName string) error {
 	cols, err := excel.GetCols(sheetName)
@@ -387,7 +674,7 @@ func autoFitColumnWidth(excel *excelize.File, sheetName string) error {
 	return nil
 }
 
-func newTransactionTypesKeyboard(transactionTypes []*entity.TransactionType, messageContextId int, colSize int) ([][]tgbotapi.InlineKeyboardButton, error) {
+func newTransactionTypesKeyboard(transactionTypes []*entity.TransactionType, messageContextId int, colSize int, locale message.Locale) ([][]tgbotapi.InlineKeyboardButton, error) {
 	var configs []util.InlineKeyboardConfig
 	for _, transactionType := range transactionTypes {
 		data := domain.TransactionTypeCallback{
@@ -398,7 +685,7 @@ func newTransactionTypesKeyboard(transactionTypes []*entity.TransactionType, mes
 			TransactionTypeId: transactionType.Id,
 		}
 
-		dataJson, err := util.ToJson(data)
+		dataJson, err := domain.EncodeCallback(data)
 		if err != nil {
 			return nil, err
 		}
@@ -407,5 +694,27 @@ func newTransactionTypesKeyboard(transactionTypes []*entity.TransactionType, mes
 		configs = append(configs, config)
 	}
 
-	return util.NewInlineKeyboard(configs, messageContextId, colSize, true), nil
+	return util.NewInlineKeyboard(configs, messageContextId, colSize, true, locale), nil
+}
+
+func newLanguagesKeyboard(locales []message.Locale, colSize int, locale message.Locale) ([][]tgbotapi.InlineKeyboardButton, error) {
+	var configs []util.InlineKeyboardConfig
+	for _, l := range locales {
+		data := domain.LanguageCallback{
+			Callback: domain.Callback{
+				Type: enum.Language,
+			},
+			Locale: l.Code,
+		}
+
+		dataJson, err := domain.EncodeCallback(data)
+		if err != nil {
+			return nil, err
+		}
+
+		config := util.NewInlineKeyboardConfig(l.Name, dataJson)
+		configs = append(configs, config)
+	}
+
+	return util.NewInlineKeyboard(configs, 0, colSize, true, locale), nil
 }
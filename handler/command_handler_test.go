@@ -4,16 +4,21 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/conversation"
 	"github.com/aattwwss/telegram-expense-bot/domain"
 	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/enum"
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/sender"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func newTestCommandHandler(ur mockUserRepo, tr mockTransactionRepo, mr mockMessageContextRepo, ttr mockTransactionTypeRepo, cr mockCategoryRepo) (CommandHandler, *tgbotapi.BotAPI) {
+func newTestCommandHandler(ur mockUserRepo, tr mockTransactionRepo, mr mockMessageContextRepo, ttr mockTransactionTypeRepo, cr mockCategoryRepo) (CommandHandler, *sender.Sender) {
 	bot := &tgbotapi.BotAPI{
 		Token:  "dummy",
 		Client: &http.Client{},
@@ -26,7 +31,7 @@ func newTestCommandHandler(ur mockUserRepo, tr mockTransactionRepo, mr mockMessa
 		messageContextRepo:  mr,
 		transactionTypeRepo: ttr,
 		categoryRepo:        cr,
-	}, bot
+	}, sender.New(bot, sender.Options{GlobalRate: 1000, GlobalBurst: 1000, ChatRate: 1000, ChatBurst: 1000})
 }
 
 func TestStart_UserExists(t *testing.T) {
@@ -182,13 +187,38 @@ func TestUndo_FindLatestError(t *testing.T) {
 	handler.Undo(context.Background(), bot, update)
 }
 
+func TestCancel(t *testing.T) {
+	callbackHandler, manager, store := newEntryTestHandler(mockTransactionRepo{}, 4)
+	conversation.Start(context.Background(), manager, entryFlow, 456, 1, entryData{Text: "5 coffee"})
+	conversation.Start(context.Background(), manager, entryFlow, 789, 1, entryData{Text: "6 tea"})
+	handler := CommandHandler{userRepo: callbackHandler.userRepo, conversations: manager}
+	bot, client := newRecordingSender()
+	update := tgbotapi.Update{
+		Message: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: 1},
+			Chat: &tgbotapi.Chat{ID: 456},
+			Text: "/cancel",
+		},
+	}
+
+	handler.Cancel(context.Background(), bot, update)
+	handler.Cancel(context.Background(), bot, update)
+
+	if len(store.conversations) != 1 {
+		t.Errorf("expected only the conversation in the chat to be cancelled, got %v", store.conversations)
+	}
+	if len(client.requests) != 2 || !strings.Contains(client.requests[0], "Cancelled") || !strings.Contains(client.requests[1], "nothing+to+cancel") {
+		t.Errorf("expected cancelled then nothing to cancel, got %v", client.requests)
+	}
+}
+
 func TestNewCategoriesKeyboard(t *testing.T) {
 	categories := []*entity.Category{
 		{Id: 1, Name: "Food", TransactionTypeId: 1},
 		{Id: 2, Name: "Transport", TransactionTypeId: 1},
 	}
 
-	kb, err := newCategoriesKeyboard(categories, 42, 2)
+	kb, err := newCategoriesKeyboard(categories, 42, 2, message.GetLocale("en"))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -198,11 +228,16 @@ func TestNewCategoriesKeyboard(t *testing.T) {
 	if kb[0][0].Text != "Food" || kb[0][1].Text != "Transport" {
 		t.Errorf("expected Food and Transport buttons")
 	}
+	var cancel domain.GenericCallback
+	err = domain.DecodeCallback(*kb[1][0].CallbackData, &cancel)
+	if err != nil || cancel.Type != enum.CancelConversation || cancel.MessageContextId != 42 {
+		t.Errorf("expected a button cancelling conversation 42, got %+v, %v", cancel, err)
+	}
 }
 
 func TestNewCategoriesKeyboard_Empty(t *testing.T) {
 	categories := []*entity.Category{}
-	kb, err := newCategoriesKeyboard(categories, 42, 2)
+	kb, err := newCategoriesKeyboard(categories, 42, 2, message.GetLocale("en"))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -211,13 +246,44 @@ func TestNewCategoriesKeyboard_Empty(t *testing.T) {
 	}
 }
 
+func TestNewCategoriesKeyboard_Localized(t *testing.T) {
+	categories := []*entity.Category{
+		{Id: 1, Name: "Food", TransactionTypeId: 1},
+		{Id: 2, Name: "Unknown", TransactionTypeId: 1},
+	}
+
+	kb, err := newCategoriesKeyboard(categories, 42, 2, message.GetLocale("ms"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if kb[0][0].Text != "Makanan" || kb[0][1].Text != "Unknown" {
+		t.Errorf("expected Makanan and Unknown buttons, got %s and %s", kb[0][0].Text, kb[0][1].Text)
+	}
+	if kb[1][0].Text != "Batal" {
+		t.Errorf("expected Batal button, got %s", kb[1][0].Text)
+	}
+}
+
+func TestNewLanguagesKeyboard(t *testing.T) {
+	kb, err := newLanguagesKeyboard(message.SupportedLocales(), 2, message.GetLocale("en"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(kb) != 3 { // 2 data rows + cancel
+		t.Fatalf("expected 3 rows, got %d", len(kb))
+	}
+	if kb[0][0].Text != "English" || kb[0][1].Text != "中文" {
+		t.Errorf("expected English and 中文 buttons, got %s and %s", kb[0][0].Text, kb[0][1].Text)
+	}
+}
+
 func TestNewTransactionTypesKeyboard(t *testing.T) {
 	types := []*entity.TransactionType{
 		{Id: 1, Name: "Spent", Multiplier: -1, ReplyText: "Spent %s"},
 		{Id: 2, Name: "Received", Multiplier: 1, ReplyText: "Received %s"},
 	}
 
-	kb, err := newTransactionTypesKeyboard(types, 42, 2)
+	kb, err := newTransactionTypesKeyboard(types, 42, 2, message.GetLocale("en"))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -228,3 +294,119 @@ func TestNewTransactionTypesKeyboard(t *testing.T) {
 		t.Errorf("expected Spent and Received buttons")
 	}
 }
+
+func TestApplyReminderArgs(t *testing.T) {
+	reminder := domain.Reminder{RemindAt: defaultRemindAt}
+
+	if err := applyReminderArgs(&reminder, []string{"20:15"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reminder.Enabled || reminder.RemindAt != 20*60+15 {
+		t.Errorf("expected enabled reminder at 20:15, got %+v", reminder)
+	}
+
+	if err := applyReminderArgs(&reminder, []string{"quiet", "23:00", "07:00"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reminder.QuietStart == nil || *reminder.QuietStart != 23*60 || reminder.QuietEnd == nil || *reminder.QuietEnd != 7*60 {
+		t.Errorf("expected quiet hours 23:00 - 07:00, got %+v", reminder)
+	}
+
+	if err := applyReminderArgs(&reminder, []string{"quiet", "off"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reminder.QuietStart != nil || reminder.QuietEnd != nil {
+		t.Errorf("expected quiet hours to be removed, got %+v", reminder)
+	}
+
+	if err := applyReminderArgs(&reminder, []string{"OFF"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reminder.Enabled {
+		t.Errorf("expected reminder to be disabled")
+	}
+
+	if err := applyReminderArgs(&reminder, []string{"quiet", "20:00", "07:00"}); !errors.Is(err, errQuietReminder) {
+		t.Errorf("expected quiet hours past midnight covering the reminder rejected, got %v", err)
+	}
+	if err := applyReminderArgs(&reminder, []string{"quiet", "20:00", "21:00"}); !errors.Is(err, errQuietReminder) {
+		t.Errorf("expected quiet hours covering the reminder rejected, got %v", err)
+	}
+	if err := applyReminderArgs(&reminder, []string{"quiet", "22:00", "07:00"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := applyReminderArgs(&reminder, []string{"23:00"}); !errors.Is(err, errQuietReminder) {
+		t.Errorf("expected a reminder during the quiet hours rejected, got %v", err)
+	}
+
+	if err := applyReminderArgs(&reminder, []string{"quiet", "23:00"}); err == nil {
+		t.Errorf("expected error for incomplete quiet hours")
+	}
+	if err := applyReminderArgs(&reminder, []string{"9pm"}); err == nil {
+		t.Errorf("expected error for invalid time")
+	}
+}
+
+func TestRemind(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		existing  *domain.Reminder
+		wantSaved bool
+		wantReply string
+	}{
+		{"quiet hours first", "/remind quiet 23:00 07:00", nil, true, "remind+you+at+21%3A00"},
+		{"off first", "/remind off", nil, true, "Reminders+are+off"},
+		{"quiet hours past midnight covering the reminder", "/remind quiet 20:00 07:00", nil, false, "cannot+cover+the+time+of+the+reminder+at+21%3A00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *domain.Reminder
+			rr := mockReminderRepo{
+				getByUserIdFn: func(ctx context.Context, userId int64) (*domain.Reminder, error) {
+					return tt.existing, nil
+				},
+				saveFn: func(ctx context.Context, reminder domain.Reminder) error {
+					saved = &reminder
+					return nil
+				},
+			}
+			handler := CommandHandler{reminderRepo: rr}
+			bot, client := newRecordingSender()
+
+			handler.Remind(accountsTestContext(), bot, commandUpdate(tt.text))
+
+			if (saved != nil) != tt.wantSaved {
+				t.Errorf("expected saved %v, got %+v", tt.wantSaved, saved)
+			}
+			if len(client.requests) != 1 || !strings.Contains(client.requests[0], tt.wantReply) {
+				t.Errorf("expected a reply with %s, got %v", tt.wantReply, client.requests)
+			}
+		})
+	}
+}
+
+func TestStartTransaction_AmountPrecision(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"too many decimals", "5.505 Coffee", "Amounts+in+SGD+can+have+up+to+2+decimal+places"},
+		{"too large", "99999999999999999999 Coffee", "don%27t+recognise+that+amount"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, client := newRecordingSender()
+			update := commandUpdate(tt.text)
+			update.Message.Entities = nil
+
+			CommandHandler{}.StartTransaction(accountsTestContext(), bot, update)
+
+			if len(client.requests) != 1 || !strings.Contains(client.requests[0], tt.want) {
+				t.Errorf("expected %q, got %v", tt.want, client.requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/router"
+)
+
+// NewCommandRouter returns the registry of the commands of the bot, with the texts that are not commands handled as
+// amounts. The middleware given runs first for every message, e.g. to recover from panics.
+func NewCommandRouter(handler CommandHandler, middleware ...router.Middleware) *router.Router {
+	r := router.New(middleware...)
+	handler.commands = r
+
+	loadUser := LoadUser(handler.userRepo)
+	registered := []router.Middleware{loadUser, RequireUser()}
+
+	r.Handle(router.Command{Name: "start", Description: message.CommandStartDesc, Handler: handler.Start})
+	r.Handle(router.Command{Name: "help", Description: message.CommandHelpDesc, Handler: handler.Help, Middleware: []router.Middleware{loadUser}})
+	r.Handle(router.Command{Name: "stats", Args: "[period]", Description: message.CommandStatsDesc, Handler: handler.Stats, Middleware: registered})
+	r.Handle(router.Command{Name: "summary", Description: message.CommandSummaryDesc, Handler: handler.Summary, Middleware: registered})
+	r.Handle(router.Command{Name: "list", Args: "[period] [^account]", Description: message.CommandListDesc, Handler: handler.List, Middleware: registered})
+	r.Handle(router.Command{Name: "export", Args: "[period] [^account]", Description: message.CommandExportDesc, Handler: handler.Export, Middleware: registered})
+	r.Handle(router.Command{Name: "balances", Description: message.CommandBalancesDesc, Handler: handler.Balances, Middleware: registered})
+	r.Handle(router.Command{Name: "transfer", Args: "<amount> ^from ^to", Description: message.CommandTransferDesc, Handler: handler.Transfer, Middleware: registered})
+	r.Handle(router.Command{Name: "account", Args: "[add|opening|delete]", Description: message.CommandAccountDesc, Handler: handler.Account, Middleware: registered})
+	r.Handle(router.Command{Name: "goal", Args: "[add|monthly|delete]", Description: message.CommandGoalDesc, Handler: handler.Goal, Middleware: registered})
+	r.Handle(router.Command{Name: "lent", Args: "<amount> <person> [description]", Description: message.CommandLentDesc, Handler: handler.Lent, Middleware: registered})
+	r.Handle(router.Command{Name: "borrowed", Args: "<amount> <person> [description]", Description: message.CommandBorrowedDesc, Handler: handler.Borrowed, Middleware: registered})
+	r.Handle(router.Command{Name: "repaid", Args: "<amount> <person>", Description: message.CommandRepaidDesc, Handler: handler.Repaid, Middleware: registered})
+	r.Handle(router.Command{Name: "owed", Args: "[remind <days|off>]", Description: message.CommandOwedDesc, Handler: handler.Owed, Middleware: registered})
+	r.Handle(router.Command{Name: "undo", Description: message.CommandUndoDesc, Handler: handler.Undo, Middleware: registered})
+	r.Handle(router.Command{Name: "redo", Description: message.CommandRedoDesc, Handler: handler.Redo, Middleware: registered})
+	r.Handle(router.Command{Name: "trash", Description: message.CommandTrashDesc, Handler: handler.Trash, Middleware: registered})
+	r.Handle(router.Command{Name: "history", Args: "<id>", Description: message.CommandHistoryDesc, Handler: handler.History, Middleware: registered})
+	r.Handle(router.Command{Name: "refund", Args: "[amount] | month <original|refund>", Description: message.CommandRefundDesc, Handler: handler.Refund, Middleware: registered})
+	r.Handle(router.Command{Name: "remind", Args: "[HH:MM]", Description: message.CommandRemindDesc, Handler: handler.Remind, Middleware: registered})
+	r.Handle(router.Command{Name: "language", Args: "[language]", Description: message.CommandLanguageDesc, Handler: handler.Language, Middleware: registered})
+	r.Handle(router.Command{Name: "token", Args: "[revoke]", Description: message.CommandTokenDesc, Handler: handler.Token, Middleware: registered})
+	r.Handle(router.Command{Name: "app", Description: message.CommandAppDesc, Handler: handler.App, Middleware: registered, Hidden: handler.webAppUrl == ""})
+	r.Handle(router.Command{Name: "cancel", Description: message.CommandCancelDesc, Handler: handler.Cancel, Middleware: []router.Middleware{loadUser}})
+	r.HandleText(handler.FromText, registered...)
+	r.Fallback("help")
+	return r
+}
@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/conversation"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/enum"
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	"github.com/aattwwss/telegram-expense-bot/util"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// entryFlow asks for the category of an amount typed by the user, or for the categories and amounts of its parts
+	// when it is split, then for the account it was paid with when the user has accounts and did not hint one
+	entryFlow = "entry"
+
+	entryCategoryState      conversation.State = "category"
+	entryAccountState       conversation.State = "account"
+	entrySplitCategoryState conversation.State = "split_category"
+	entrySplitAmountState   conversation.State = "split_amount"
+
+	accountsInlineColSize = 2
+)
+
+// entryData is the amount and description typed by the user, with the category and account chosen so far. The
+// category of a split amount is only chosen once its parts add up to it.
+type entryData struct {
+	Text       string       `json:"text"`
+	TypedAt    time.Time    `json:"typed_at"`
+	CategoryId int          `json:"category_id,omitempty"`
+	AccountId  int          `json:"account_id,omitempty"`
+	Splits     []entrySplit `json:"splits,omitempty"`
+	// SplitCategoryId is the category of the part whose amount is asked
+	SplitCategoryId int `json:"split_category_id,omitempty"`
+}
+
+// RegisterFlows registers the conversation flows started by the handlers. An amount whose category is not chosen
+// within entryTimeout is added under defaultCategoryId, or dropped when it is 0. An amount whose account is not chosen
+// in time is added without one.
+func RegisterFlows(m *conversation.Manager, callbackHandler CallbackHandler, entryTimeout time.Duration, defaultCategoryId int) {
+	conversation.Register(m, callbackHandler.newEntryFlow(entryTimeout, defaultCategoryId))
+}
+
+func (handler CallbackHandler) newEntryFlow(timeout time.Duration, defaultCategoryId int) conversation.Flow[entryData] {
+	return conversation.Flow[entryData]{
+		Name:    entryFlow,
+		Initial: entryCategoryState,
+		Timeout: timeout,
+		Steps: map[conversation.State]conversation.Step[entryData]{
+			entryCategoryState:      {OnCallback: handler.chooseEntryCategory},
+			entryAccountState:       {OnCallback: handler.chooseEntryAccount},
+			entrySplitCategoryState: {OnCallback: handler.chooseSplitCategory},
+			entrySplitAmountState:   {OnText: handler.readSplitAmount, OnCallback: handler.chooseSplitCategory},
+		},
+		OnTimeout: func(ctx context.Context, bot *sender.Sender, c conversation.Conversation[entryData]) {
+			handler.applyDefaultCategory(ctx, bot, c, defaultCategoryId)
+		},
+	}
+}
+
+// chooseEntryCategory takes the category tapped, then asks for the account when the user has accounts and did not
+// hint one, or adds the amount and replaces the menu with the reply. The flow ends even when the amount cannot be
+// added, as its menu is replaced with the error.
+func (handler CallbackHandler) chooseEntryCategory(ctx context.Context, bot *sender.Sender, c *conversation.Conversation[entryData], callbackQuery *tgbotapi.CallbackQuery) (conversation.Transition, error) {
+	messageId := callbackQuery.Message.MessageID
+	locale := clientLocale(callbackQuery.From)
+	user, err := handler.findEntryUser(ctx, c.UserId)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error finding user for category: %v", err)
+		util.BotEditMessage(bot, c.ChatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return conversation.End(), nil
+	}
+	locale = user.GetLocale()
+
+	var genericCallback domain.GenericCallback
+	err = domain.DecodeCallback(callbackQuery.Data, &genericCallback)
+	if err == nil && genericCallback.Type == enum.Split {
+		return handler.startSplit(ctx, bot, c, callbackQuery.Message, *user), nil
+	}
+
+	var categoryCallback domain.CategoryCallback
+	err = domain.DecodeCallback(callbackQuery.Data, &categoryCallback)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("FromCategory unmarshall error: %v", err)
+		util.BotEditMessage(bot, c.ChatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return conversation.End(), nil
+	}
+
+	category, err := handler.findEntryCategory(ctx, categoryCallback.CategoryId, user.Id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Get category by id error: %v", err)
+		util.BotEditMessage(bot, c.ChatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return conversation.End(), nil
+	}
+
+	var account *domain.Account
+	if c.Data.AccountId != 0 {
+		hinted, err := handler.accountRepo.GetById(ctx, c.Data.AccountId, user.Id)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("Get hinted account by id error: %v", err)
+			util.BotEditMessage(bot, c.ChatId, messageId, locale.Get(message.GenericErrReplyMsg))
+			return conversation.End(), nil
+		}
+		account = &hinted
+	} else {
+		accounts, err := handler.accountRepo.FindAllByUserId(ctx, user.Id)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("FindAllByUserId accounts error: %v", err)
+			util.BotEditMessage(bot, c.ChatId, messageId, locale.Get(message.GenericErrReplyMsg))
+			return conversation.End(), nil
+		}
+		if len(accounts) > 0 {
+			inlineKeyboard, err := newAccountsKeyboard(accounts, c.Id, accountsInlineColSize, locale)
+			if err != nil {
+				log.Ctx(ctx).Error().Msgf("newAccountsKeyboard error: %v", err)
+				util.BotEditMessage(bot, c.ChatId, messageId, locale.Get(message.GenericErrReplyMsg))
+				return conversation.End(), nil
+			}
+			c.Data.CategoryId = category.Id
+			edit := tgbotapi.NewEditMessageTextAndMarkup(c.ChatId, messageId, locale.Get(message.AccountSelectMsg), tgbotapi.InlineKeyboardMarkup{InlineKeyboard: inlineKeyboard})
+			util.BotEditWrapper(bot, edit)
+			return conversation.Goto(entryAccountState), nil
+		}
+	}
+
+	return handler.finishEntry(ctx, bot, c, messageId, *user, *category, account), nil
+}
+
+// chooseEntryAccount adds the amount under the category chosen before, paid with the account tapped, and replaces
+// the menu with the reply
+func (handler CallbackHandler) chooseEntryAccount(ctx context.Context, bot *sender.Sender, c *conversation.Conversation[entryData], callbackQuery *tgbotapi.CallbackQuery) (conversation.Transition, error) {
+	messageId := callbackQuery.Message.MessageID
+	locale := clientLocale(callbackQuery.From)
+	user, err := handler.findEntryUser(ctx, c.UserId)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error finding user for account: %v", err)
+		util.BotEditMessage(bot, c.ChatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return conversation.End(), nil
+	}
+	locale = user.GetLocale()
+
+	var accountCallback domain.AccountCallback
+	err = domain.DecodeCallback(callbackQuery.Data, &accountCallback)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("FromAccount unmarshall error: %v", err)
+		util.BotEditMessage(bot, c.ChatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return conversation.End(), nil
+	}
+
+	var account *domain.Account
+	if accountCallback.AccountId != 0 {
+		chosen, err := handler.accountRepo.GetById(ctx, accountCallback.AccountId, user.Id)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("Get account by id error: %v", err)
+			util.BotEditMessage(bot, c.ChatId, messageId, locale.Get(message.GenericErrReplyMsg))
+			return conversation.End(), nil
+		}
+		account = &chosen
+	}
+
+	category, err := handler.findEntryCategory(ctx, c.Data.CategoryId, user.Id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Get chosen category by id error: %v", err)
+		util.BotEditMessage(bot, c.ChatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return conversation.End(), nil
+	}
+
+	return handler.finishEntry(ctx, bot, c, messageId, *user, *category, account), nil
+}
+
+// finishEntry adds the amount under the category, paid with the account unless it is nil, replaces the menu with the
+// reply and ends the flow
+func (handler CallbackHandler) finishEntry(ctx context.Context, bot *sender.Sender, c *conversation.Conversation[entryData], messageId int, user domain.User, category entity.Category, account *domain.Account) conversation.Transition {
+	locale := user.GetLocale()
+	text, err := handler.addTransaction(ctx, user, category, account, c.Data.Text, c.Data.Splits, time.Now())
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("finishEntry error: %v", err)
+		util.BotEditMessage(bot, c.ChatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return conversation.End()
+	}
+
+	edit := tgbotapi.NewEditMessageText(c.ChatId, messageId, text)
+	edit.ParseMode = tgbotapi.ModeHTML
+	util.BotEditWrapper(bot, edit)
+	return conversation.End()
+}
+
+// applyDefaultCategory adds an amount that was not given a category or an account in time. An amount without a
+// category is added under the default category, or dropped when it is 0, and one without an account is added
+// without one under the category chosen.
+func (handler CallbackHandler) applyDefaultCategory(ctx context.Context, bot *sender.Sender, c conversation.Conversation[entryData], defaultCategoryId int) {
+	// the parts of an amount whose split was not finished are dropped along with it
+	categoryId, splits, reason := c.Data.CategoryId, c.Data.Splits, message.NoAccountAppliedMsg
+	if categoryId == 0 {
+		categoryId, splits, reason = defaultCategoryId, nil, message.DefaultCategoryAppliedMsg
+	}
+	if categoryId == 0 {
+		return
+	}
+
+	user, err := handler.findEntryUser(ctx, c.UserId)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error finding user for default category: %v", err)
+		return
+	}
+	locale := user.GetLocale()
+
+	category, err := handler.findEntryCategory(ctx, categoryId, user.Id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Get default category by id error: %v", err)
+		util.BotSendMessage(bot, c.ChatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+
+	var account *domain.Account
+	if c.Data.AccountId != 0 {
+		hinted, err := handler.accountRepo.GetById(ctx, c.Data.AccountId, user.Id)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("Get hinted account by id error: %v", err)
+			util.BotSendMessage(bot, c.ChatId, locale.Get(message.GenericErrReplyMsg))
+			return
+		}
+		account = &hinted
+	}
+
+	text, err := handler.addTransaction(ctx, *user, *category, account, c.Data.Text, splits, c.Data.TypedAt)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("applyDefaultCategory error: %v", err)
+		util.BotSendMessage(bot, c.ChatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(c.ChatId, locale.Get(reason)+text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	util.BotSendWrapper(bot, msg)
+}
+
+// findEntryUser returns the user of an entry conversation
+func (handler CallbackHandler) findEntryUser(ctx context.Context, userId int64) (*domain.User, error) {
+	user, err := handler.userRepo.FindUserById(ctx, userId)
+	if err == nil && user == nil {
+		err = fmt.Errorf("user %d not found", userId)
+	}
+	return user, err
+}
+
+// findEntryCategory returns a category the user can add an amount under
+func (handler CallbackHandler) findEntryCategory(ctx context.Context, categoryId int, userId int64) (*entity.Category, error) {
+	category, err := handler.categoryRepo.GetById(ctx, categoryId)
+	if err == nil && !isCategoryVisible(*category, userId) {
+		err = fmt.Errorf("category %d does not belong to user %d", category.Id, userId)
+	}
+	return category, err
+}
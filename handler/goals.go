@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	"github.com/aattwwss/telegram-expense-bot/util"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const goalNameLimit = 50
+
+// Goal lists the savings goals of the user, or adds a goal, puts money towards one, sets its monthly amount or deletes
+// it, e.g. /goal add Japan trip 3000 by 2024-12, /goal add 200 Japan, /goal monthly 250 Japan and /goal delete Japan
+func (handler CommandHandler) Goal(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	user := userFromContext(ctx)
+	chatId := update.Message.Chat.ID
+
+	action, rest, _ := strings.Cut(strings.TrimSpace(update.Message.CommandArguments()), " ")
+	rest = strings.TrimSpace(rest)
+	switch strings.ToLower(action) {
+	case "add":
+		// an amount first puts money towards a goal, a name first adds one
+		if _, err := parseFloatStringFromString(rest); err == nil {
+			handler.contributeToGoal(ctx, bot, chatId, *user, rest)
+		} else {
+			handler.addGoal(ctx, bot, chatId, *user, rest)
+		}
+	case "monthly":
+		handler.setGoalMonthlyAmount(ctx, bot, chatId, *user, rest)
+	case "delete":
+		handler.deleteGoal(ctx, bot, chatId, *user, rest)
+	default:
+		handler.listGoals(ctx, bot, chatId, *user)
+	}
+}
+
+func (handler CommandHandler) listGoals(ctx context.Context, bot *sender.Sender, chatId int64, user domain.User) {
+	locale := user.GetLocale()
+	goals, err := handler.goalRepo.FindAllByUserId(ctx, user.Id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("FindAllByUserId goals error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	if len(goals) == 0 {
+		util.BotSendMessage(bot, chatId, locale.Get(message.GoalNoneMsg)+locale.Get(message.GoalUsageMsg))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatId, locale.Get(message.GoalListHeader)+goals.GetFormattedHTMLMsg(locale, time.Now().In(user.Location)))
+	msg.ParseMode = tgbotapi.ModeHTML
+	util.BotSendWrapper(bot, msg)
+}
+
+// addGoal adds a goal typed as its name and target, optionally followed by the month to reach it by, e.g.
+// Japan trip 3000 by 2024-12
+func (handler CommandHandler) addGoal(ctx context.Context, bot *sender.Sender, chatId int64, user domain.User, args string) {
+	locale := user.GetLocale()
+	now := time.Now().In(user.Location)
+
+	var deadline *time.Time
+	if i := strings.LastIndex(strings.ToLower(args), " by "); i >= 0 {
+		period, err := util.ParsePeriod(args[i+len(" by "):], now)
+		if err != nil {
+			util.BotSendMessage(bot, chatId, locale.Get(message.PeriodInvalidMsg))
+			return
+		}
+		// the goal is due by the last month of the period, e.g. December for 2024
+		last := period.To.AddDate(0, 0, -1)
+		month := time.Date(last.Year(), last.Month(), 1, 0, 0, 0, 0, time.UTC)
+		deadline = &month
+		args = args[:i]
+	}
+
+	fields := strings.Fields(args)
+	if len(fields) < 2 {
+		util.BotSendMessage(bot, chatId, locale.Get(message.GoalUsageMsg))
+		return
+	}
+	target, rest, err := parseAmount(fields[len(fields)-1], *user.Currency)
+	if err != nil || rest != "" || !target.IsPositive() {
+		util.BotSendMessage(bot, chatId, locale.Get(message.GoalUsageMsg))
+		return
+	}
+	name := strings.Join(fields[:len(fields)-1], " ")
+	if utf8.RuneCountInString(name) > goalNameLimit {
+		util.BotSendMessage(bot, chatId, locale.Get(message.GoalNameInvalidMsg, goalNameLimit))
+		return
+	}
+
+	goal := domain.Goal{
+		UserId:     user.Id,
+		ChatId:     chatId,
+		Name:       name,
+		Target:     target,
+		Saved:      money.New(0, target.Currency().Code),
+		Deadline:   deadline,
+		CreateTime: now,
+	}
+	_, err = handler.goalRepo.Add(ctx, goal)
+	if errors.Is(err, entity.ErrConflict) {
+		util.BotSendMessage(bot, chatId, locale.Get(message.GoalExistsMsg))
+		return
+	}
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Add goal error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+
+	if pace, ok := goal.RequiredPace(now); ok {
+		util.BotSendMessage(bot, chatId, locale.Get(message.GoalAddedByMsg, name, locale.FormatMoney(target), goal.DeadlineLabel(locale), locale.FormatMoney(pace)))
+		return
+	}
+	util.BotSendMessage(bot, chatId, locale.Get(message.GoalAddedMsg, name, locale.FormatMoney(target)))
+}
+
+// contributeToGoal puts an amount towards a goal typed as the amount and the start of its name, e.g. 200 Japan, and
+// celebrates the goal when it is reached
+func (handler CommandHandler) contributeToGoal(ctx context.Context, bot *sender.Sender, chatId int64, user domain.User, args string) {
+	locale := user.GetLocale()
+	amount, hint, err := parseAmount(args, *user.Currency)
+	if err != nil || amount.IsZero() || hint == "" {
+		util.BotSendMessage(bot, chatId, locale.Get(message.GoalUsageMsg))
+		return
+	}
+
+	goal, ok := handler.findGoal(ctx, bot, chatId, user, hint)
+	if !ok {
+		return
+	}
+
+	now := time.Now().In(user.Location)
+	goal, reached, err := handler.goalRepo.Contribute(ctx, goal.Id, user.Id, amount, now)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Contribute to goal error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+
+	text := locale.Get(message.GoalContributedMsg, locale.FormatMoney(amount), html.EscapeString(goal.Name)) + goal.GetFormattedHTMLMsg(locale, now)
+	msg := tgbotapi.NewMessage(chatId, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	util.BotSendWrapper(bot, msg)
+
+	if reached {
+		util.BotSendMessage(bot, chatId, locale.Get(message.GoalReachedMsg, goal.Name, locale.FormatMoney(goal.Target)))
+	}
+}
+
+// setGoalMonthlyAmount puts an amount towards a goal on the 1st of every month, or stops when it is 0, e.g.
+// 250 Japan
+func (handler CommandHandler) setGoalMonthlyAmount(ctx context.Context, bot *sender.Sender, chatId int64, user domain.User, args string) {
+	locale := user.GetLocale()
+	amount, hint, err := parseAmount(args, *user.Currency)
+	if err != nil || amount.IsNegative() || hint == "" {
+		util.BotSendMessage(bot, chatId, locale.Get(message.GoalUsageMsg))
+		return
+	}
+
+	goal, ok := handler.findGoal(ctx, bot, chatId, user, hint)
+	if !ok {
+		return
+	}
+
+	now := time.Now().In(user.Location)
+	next := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, user.Location)
+	err = handler.goalRepo.SetMonthlyAmount(ctx, goal.Id, user.Id, amount, next)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("SetMonthlyAmount error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+
+	if amount.IsZero() {
+		util.BotSendMessage(bot, chatId, locale.Get(message.GoalMonthlyStoppedMsg, goal.Name))
+		return
+	}
+	util.BotSendMessage(bot, chatId, locale.Get(message.GoalMonthlySetMsg, locale.FormatMoney(amount), goal.Name))
+}
+
+func (handler CommandHandler) deleteGoal(ctx context.Context, bot *sender.Sender, chatId int64, user domain.User, hint string) {
+	locale := user.GetLocale()
+	if hint == "" {
+		util.BotSendMessage(bot, chatId, locale.Get(message.GoalUsageMsg))
+		return
+	}
+
+	goal, ok := handler.findGoal(ctx, bot, chatId, user, hint)
+	if !ok {
+		return
+	}
+
+	err := handler.goalRepo.DeleteById(ctx, goal.Id, user.Id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Delete goal error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	util.BotSendMessage(bot, chatId, locale.Get(message.GoalDeletedMsg, goal.Name))
+}
+
+// findGoal returns the goal of the user whose name starts with the hint, or replies why there is none and returns
+// false
+func (handler CommandHandler) findGoal(ctx context.Context, bot *sender.Sender, chatId int64, user domain.User, hint string) (domain.Goal, bool) {
+	locale := user.GetLocale()
+	goals, err := handler.goalRepo.FindAllByUserId(ctx, user.Id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("FindAllByUserId goals error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return domain.Goal{}, false
+	}
+
+	goal, err := goals.FindByHint(hint)
+	if errors.Is(err, entity.ErrConflict) {
+		util.BotSendMessage(bot, chatId, locale.Get(message.GoalAmbiguousMsg, hint))
+		return domain.Goal{}, false
+	}
+	if err != nil {
+		util.BotSendMessage(bot, chatId, locale.Get(message.GoalUnknownMsg, hint))
+		return domain.Goal{}, false
+	}
+	return goal, true
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+)
+
+func TestGoal_Add(t *testing.T) {
+	var added domain.Goal
+	gr := mockGoalRepo{
+		addFn: func(ctx context.Context, goal domain.Goal) (int, error) {
+			added = goal
+			return 1, nil
+		},
+	}
+	handler := CommandHandler{goalRepo: gr}
+	bot, client := newRecordingSender()
+
+	handler.Goal(accountsTestContext(), bot, commandUpdate("/goal add Japan trip 3000 by dec 2099"))
+
+	if added.Name != "Japan trip" || added.Target.Amount() != 300000 || added.UserId != 1 || added.ChatId != 456 {
+		t.Errorf("unexpected goal %+v", added)
+	}
+	if added.Deadline == nil || added.Deadline.Format("2006-01-02") != "2099-12-01" {
+		t.Errorf("expected the goal due by December 2099, got %v", added.Deadline)
+	}
+	if len(client.requests) != 1 || !strings.Contains(client.requests[0], "by+December+2099") {
+		t.Errorf("expected the deadline and pace in the reply, got %v", client.requests)
+	}
+}
+
+func TestGoal_AddInvalid(t *testing.T) {
+	gr := mockGoalRepo{
+		addFn: func(ctx context.Context, goal domain.Goal) (int, error) {
+			t.Errorf("expected no goal, got %+v", goal)
+			return 0, nil
+		},
+	}
+	handler := CommandHandler{goalRepo: gr}
+
+	for text, want := range map[string]string{
+		"/goal add Japan trip":          "Type+%2Fgoal",
+		"/goal add Japan trip 0":        "Type+%2Fgoal",
+		"/goal add Japan 3000 by never": "understand+that+period",
+	} {
+		bot, client := newRecordingSender()
+		handler.Goal(accountsTestContext(), bot, commandUpdate(text))
+		if len(client.requests) != 1 || !strings.Contains(client.requests[0], want) {
+			t.Errorf("%s: expected a reply with %s, got %v", text, want, client.requests)
+		}
+	}
+}
+
+func TestGoal_ContributeReached(t *testing.T) {
+	goals := domain.Goals{{Id: 3, Name: "Japan trip"}, {Id: 4, Name: "Laptop"}}
+	var contributed *money.Money
+	gr := mockGoalRepo{
+		findAllByUserIdFn: func(ctx context.Context, userId int64) (domain.Goals, error) {
+			return goals, nil
+		},
+		contributeFn: func(ctx context.Context, id int, userId int64, amount *money.Money, now time.Time) (domain.Goal, bool, error) {
+			if id != 3 {
+				t.Errorf("expected the contribution to Japan trip, got %d", id)
+			}
+			contributed = amount
+			return domain.Goal{Id: 3, Name: "Japan trip", Target: money.New(300000, money.SGD), Saved: money.New(300000, money.SGD), MonthlyAmount: money.New(0, money.SGD)}, true, nil
+		},
+	}
+	handler := CommandHandler{goalRepo: gr}
+	bot, client := newRecordingSender()
+
+	handler.Goal(accountsTestContext(), bot, commandUpdate("/goal add 200 japan"))
+
+	if contributed == nil || contributed.Amount() != 20000 {
+		t.Errorf("unexpected contribution %v", contributed)
+	}
+	if len(client.requests) != 2 || !strings.Contains(client.requests[0], "Reached") || !strings.Contains(client.requests[1], "You+reached+your+goal+Japan+trip") {
+		t.Errorf("expected the progress then the celebration, got %v", client.requests)
+	}
+}
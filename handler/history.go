@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	"github.com/aattwwss/telegram-expense-bot/util"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/xuri/excelize/v2"
+)
+
+// historyShownLimit is how many of the latest changes /history shows, which keeps the message within the size limit
+// of telegram
+const historyShownLimit = 20
+
+// History shows when a transaction was created, edited, deleted and restored, e.g. /history 12 for the transaction
+// shown as #12 in /list or /trash
+func (handler CommandHandler) History(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	user := userFromContext(ctx)
+	locale := user.GetLocale()
+	chatId := update.Message.Chat.ID
+
+	args := strings.TrimPrefix(strings.TrimSpace(update.Message.CommandArguments()), "#")
+	id, err := strconv.Atoi(args)
+	if err != nil || id < 1 {
+		util.BotSendMessage(bot, chatId, locale.Get(message.HistoryUsageMsg))
+		return
+	}
+
+	history, err := handler.transactionRepo.GetHistory(ctx, id, user.Id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("GetHistory error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	if len(history) == 0 {
+		util.BotSendMessage(bot, chatId, locale.Get(message.HistoryNotFoundMsg, id))
+		return
+	}
+	if len(history) > historyShownLimit {
+		history = history[len(history)-historyShownLimit:]
+	}
+
+	msg := tgbotapi.NewMessage(chatId, locale.Get(message.HistoryHeader, id)+history.GetFormattedHTMLMsg(locale, user.Location))
+	msg.ParseMode = tgbotapi.ModeHTML
+	util.BotSendWrapper(bot, msg)
+}
+
+// writeHistorySheet adds a sheet listing the changes to the transactions with their values before and after
+func writeHistorySheet(excel *excelize.File, history domain.TransactionHistory, user domain.User) error {
+	locale := user.GetLocale()
+	sheetName := locale.Get(message.HistorySheetName)
+	_, err := excel.NewSheet(sheetName)
+	if err != nil {
+		return err
+	}
+
+	dateFmt := locale.ExcelDateFmt
+	dateStyleId, _ := excel.NewStyle(&excelize.Style{CustomNumFmt: &dateFmt})
+	excel.SetColStyle(sheetName, "A", dateStyleId)
+
+	headers := []string{
+		locale.Get(message.HistoryTimeHeader),
+		locale.Get(message.HistoryTransactionHeader),
+		locale.Get(message.HistoryActionHeader),
+		locale.Get(message.HistorySourceHeader),
+		locale.Get(message.HistoryBeforeHeader),
+		locale.Get(message.HistoryAfterHeader),
+	}
+	excel.SetSheetRow(sheetName, "A1", &headers)
+	styleId, _ := excel.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	excel.SetRowStyle(sheetName, 1, 1, styleId)
+
+	values := func(v *domain.TransactionValues) string {
+		if v == nil {
+			return ""
+		}
+		return v.GetFormattedMsg(locale, user.Location)
+	}
+	for i, c := range history {
+		data := []interface{}{
+			c.Time.In(user.Location),
+			c.TransactionId,
+			c.ActionName(locale),
+			c.OriginName(locale),
+			values(c.Before),
+			values(c.After),
+		}
+		cellName, _ := excelize.CoordinatesToCellName(1, i+2)
+		excel.SetSheetRow(sheetName, cellName, &data)
+	}
+	return autoFitColumnWidth(excel, sheetName)
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/audit"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+)
+
+func TestHistory(t *testing.T) {
+	dt := time.Date(2024, 6, 3, 12, 30, 0, 0, time.UTC)
+	values := &domain.TransactionValues{Datetime: dt, CategoryName: "Food", Description: "Chicken Rice", Amount: money.New(550, money.SGD)}
+	history := domain.TransactionHistory{
+		{TransactionId: 12, Action: domain.ChangeCreate, After: values, Origin: audit.Origin{Actor: audit.ActorUser, Source: audit.SourceBot}, Time: dt},
+		{TransactionId: 12, Action: domain.ChangeDelete, Before: values, Origin: audit.Origin{Actor: audit.ActorUser, Source: audit.SourceApi}, Time: dt},
+	}
+
+	tests := []struct {
+		name    string
+		args    string
+		history domain.TransactionHistory
+		err     error
+		wantId  int
+		want    string
+	}{
+		{"timeline", "12", history, nil, 12, "History+of+%2312"},
+		{"with the hash", "#12", history, nil, 12, "Deleted"},
+		{"no history", "99", domain.TransactionHistory{}, nil, 99, "no+history+of+%2399"},
+		{"missing id", "", nil, nil, 0, "Send+%2Fhistory"},
+		{"not a number", "abc", nil, nil, 0, "Send+%2Fhistory"},
+		{"repo error", "12", nil, errors.New("db down"), 12, "Something+went+wrong"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotId int
+			tr := mockTransactionRepo{
+				getHistoryFn: func(ctx context.Context, id int, userId int64) (domain.TransactionHistory, error) {
+					gotId = id
+					return tt.history, tt.err
+				},
+			}
+			handler := CommandHandler{transactionRepo: tr}
+			bot, client := newRecordingSender()
+
+			handler.History(accountsTestContext(), bot, commandUpdate("/history "+tt.args))
+
+			if gotId != tt.wantId {
+				t.Errorf("expected the history of %d, got %d", tt.wantId, gotId)
+			}
+			if len(client.requests) != 1 || !strings.Contains(client.requests[0], tt.want) {
+				t.Errorf("expected %q, got %v", tt.want, client.requests)
+			}
+		})
+	}
+}
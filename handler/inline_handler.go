@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	"github.com/aattwwss/telegram-expense-bot/util"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	inlineCacheTime      = 30 * time.Second
+	inlineTopCategories  = 5
+	inlineRecentPageSize = 5
+
+	inlineStatsQuery = "stats"
+	inlineListQuery  = "list"
+)
+
+type InlineHandler struct {
+	userRepo        UserRepo
+	transactionRepo TransactionRepo
+	cache           *inlineResultCache
+}
+
+func NewInlineHandler(userRepo UserRepo, transactionRepo TransactionRepo) InlineHandler {
+	return InlineHandler{
+		userRepo:        userRepo,
+		transactionRepo: transactionRepo,
+		cache:           newInlineResultCache(inlineCacheTime),
+	}
+}
+
+// FromInlineQuery answers "@bot stats [period]" and "@bot list [period]" with articles built from the
+// transactions of the user who typed the query. A query without stats or list answers with all the articles.
+func (handler InlineHandler) FromInlineQuery(ctx context.Context, bot *sender.Sender, inlineQuery *tgbotapi.InlineQuery) {
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: inlineQuery.ID,
+		CacheTime:     int(inlineCacheTime.Seconds()),
+		IsPersonal:    true,
+		Results:       []interface{}{},
+	}
+
+	locale := clientLocale(inlineQuery.From)
+	user, err := handler.userRepo.FindUserById(ctx, inlineQuery.From.ID)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error finding user for inline query: %v", err)
+		util.BotSendWrapper(bot, answer)
+		return
+	}
+	if user == nil {
+		answer.SwitchPMText = locale.Get(message.InlineSignUpMsg)
+		answer.SwitchPMParameter = "start"
+		util.BotSendWrapper(bot, answer)
+		return
+	}
+
+	cacheKey := fmt.Sprintf("%d:%s", user.Id, normaliseInlineQuery(inlineQuery.Query))
+	results, ok := handler.cache.get(cacheKey, time.Now())
+	if !ok {
+		results, err = handler.buildInlineResults(ctx, *user, inlineQuery.Query)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("Error building inline results: %v", err)
+			util.BotSendWrapper(bot, answer)
+			return
+		}
+		handler.cache.put(cacheKey, results, time.Now())
+	}
+
+	answer.Results = results
+	util.BotSendWrapper(bot, answer)
+}
+
+// buildInlineResults only ever queries the transactions of the given user
+func (handler InlineHandler) buildInlineResults(ctx context.Context, user domain.User, query string) ([]interface{}, error) {
+	locale := user.GetLocale()
+	args := strings.Fields(normaliseInlineQuery(query))
+	kind := ""
+	if len(args) > 0 && (args[0] == inlineStatsQuery || args[0] == inlineListQuery) {
+		kind, args = args[0], args[1:]
+	}
+	period, err := util.ParsePeriod(strings.Join(args, " "), time.Now().In(user.Location))
+	if err != nil {
+		// the period may still be being typed
+		return []interface{}{}, nil
+	}
+	label := period.Label(locale)
+
+	results := []interface{}{}
+	if kind != inlineListQuery {
+		breakdowns, total, err := handler.transactionRepo.GetTransactionBreakdownByCategory(ctx, period.From, period.To, user)
+		if err != nil {
+			return nil, err
+		}
+
+		header := fmt.Sprintf(statsHeaderHTMLMsg, label, locale.FormatMoney(total))
+		totalArticle := tgbotapi.NewInlineQueryResultArticleHTML("total-"+period.Slug(), locale.Get(message.InlineTotalTitle, label), header)
+		totalArticle.Description = locale.FormatMoney(total)
+		results = append(results, totalArticle)
+
+		if len(breakdowns) > 0 {
+			if len(breakdowns) > inlineTopCategories {
+				breakdowns = breakdowns[:inlineTopCategories]
+			}
+			var names []string
+			for _, b := range breakdowns {
+				names = append(names, locale.CategoryName(b.CategoryName))
+			}
+			topArticle := tgbotapi.NewInlineQueryResultArticleHTML("top-"+period.Slug(), locale.Get(message.InlineTopCategoriesTitle, label), header+breakdowns.GetFormattedHTMLMsg(locale))
+			topArticle.Description = strings.Join(names, ", ")
+			results = append(results, topArticle)
+		}
+	}
+
+	if kind != inlineStatsQuery {
+		q := entity.TransactionListQuery{
+			DateFrom: period.From,
+			DateTo:   period.To,
+			Offset:   0,
+			Limit:    inlineRecentPageSize,
+			Asc:      false,
+			UserId:   user.Id,
+		}
+		transactions, totalCount, err := handler.transactionRepo.ListByDateRange(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+
+		if totalCount > 0 {
+			text := transactions.GetFormattedHTMLMsg(label, user.Location, locale, totalCount, 0, inlineRecentPageSize)
+			recentArticle := tgbotapi.NewInlineQueryResultArticleHTML("recent-"+period.Slug(), locale.Get(message.InlineRecentTitle, label), text)
+			recentArticle.Description = locale.Get(message.InlineRecentDescription, totalCount)
+			results = append(results, recentArticle)
+		}
+	}
+
+	return results, nil
+}
+
+// normaliseInlineQuery lowercases the query and collapses the spaces so similar queries share the same cache entry
+func normaliseInlineQuery(query string) string {
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
+
+type inlineResultCacheEntry struct {
+	results   []interface{}
+	expiresAt time.Time
+}
+
+// inlineResultCache keeps the results of recent inline queries, since Telegram sends a query for every keystroke
+type inlineResultCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]inlineResultCacheEntry
+}
+
+func newInlineResultCache(ttl time.Duration) *inlineResultCache {
+	return &inlineResultCache{
+		ttl:     ttl,
+		entries: map[string]inlineResultCacheEntry{},
+	}
+}
+
+func (c *inlineResultCache) get(key string, now time.Time) ([]interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.results, true
+}
+
+func (c *inlineResultCache) put(key string, results []interface{}, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = inlineResultCacheEntry{results: results, expiresAt: now.Add(c.ttl)}
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func newTestInlineRepo(queriedUserIds *[]int64) mockTransactionRepo {
+	return mockTransactionRepo{
+		getTransactionBreakdownByCatFn: func(ctx context.Context, dateFrom time.Time, dateTo time.Time, user domain.User) (domain.Breakdowns, *money.Money, error) {
+			*queriedUserIds = append(*queriedUserIds, user.Id)
+			breakdowns := domain.Breakdowns{
+				{CategoryName: "Food", Amount: money.New(750, money.SGD), Percent: 75},
+				{CategoryName: "Transport", Amount: money.New(250, money.SGD), Percent: 25},
+			}
+			return breakdowns, money.New(1000, money.SGD), nil
+		},
+		listByDateRangeFn: func(ctx context.Context, q entity.TransactionListQuery) (domain.Transactions, int, error) {
+			*queriedUserIds = append(*queriedUserIds, q.UserId)
+			transactions := domain.Transactions{
+				{Datetime: time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC), CategoryName: "Food", Description: "Lunch", Amount: money.New(750, money.SGD)},
+			}
+			return transactions, 1, nil
+		},
+	}
+}
+
+func TestBuildInlineResults(t *testing.T) {
+	user := domain.User{Id: 7, Locale: "en", Currency: money.GetCurrency("SGD"), Location: time.UTC}
+
+	tests := []struct {
+		query   string
+		wantIds []string
+	}{
+		{"", []string{"total-", "top-", "recent-"}},
+		{"stats mar 2023", []string{"total-2023-03-01_2023-03-31", "top-2023-03-01_2023-03-31"}},
+		{"LIST  Mar 2023", []string{"recent-2023-03-01_2023-03-31"}},
+		{"stats 2023-q1", []string{"total-2023-01-01_2023-03-31", "top-2023-01-01_2023-03-31"}},
+		{"stats 31 feb", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var queriedUserIds []int64
+			handler := NewInlineHandler(mockUserRepo{}, newTestInlineRepo(&queriedUserIds))
+
+			results, err := handler.buildInlineResults(context.Background(), user, tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(results) != len(tt.wantIds) {
+				t.Fatalf("expected %d results, got %d", len(tt.wantIds), len(results))
+			}
+			for i, r := range results {
+				article := r.(tgbotapi.InlineQueryResultArticle)
+				if !strings.HasPrefix(article.ID, tt.wantIds[i]) {
+					t.Errorf("expected result %d to be %s, got %s", i, tt.wantIds[i], article.ID)
+				}
+			}
+			for _, id := range queriedUserIds {
+				if id != user.Id {
+					t.Errorf("expected only user %d to be queried, got %d", user.Id, id)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildInlineResults_Descriptions(t *testing.T) {
+	var queriedUserIds []int64
+	handler := NewInlineHandler(mockUserRepo{}, newTestInlineRepo(&queriedUserIds))
+	user := domain.User{Id: 7, Locale: "en", Currency: money.GetCurrency("SGD"), Location: time.UTC}
+
+	results, err := handler.buildInlineResults(context.Background(), user, "mar 2023")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	total := results[0].(tgbotapi.InlineQueryResultArticle)
+	if total.Title != "Total spent in March 2023" || total.Description != "$10.00" {
+		t.Errorf("unexpected total article: %s / %s", total.Title, total.Description)
+	}
+	top := results[1].(tgbotapi.InlineQueryResultArticle)
+	if top.Description != "Food, Transport" {
+		t.Errorf("unexpected top categories description: %s", top.Description)
+	}
+	recent := results[2].(tgbotapi.InlineQueryResultArticle)
+	if recent.Description != "1 expenses" {
+		t.Errorf("unexpected recent description: %s", recent.Description)
+	}
+}
+
+func TestBuildInlineResults_EscapesHTML(t *testing.T) {
+	tr := mockTransactionRepo{
+		getTransactionBreakdownByCatFn: func(ctx context.Context, dateFrom time.Time, dateTo time.Time, user domain.User) (domain.Breakdowns, *money.Money, error) {
+			return domain.Breakdowns{{CategoryName: "Food & Drinks", Amount: money.New(750, money.SGD), Percent: 100}}, money.New(750, money.SGD), nil
+		},
+		listByDateRangeFn: func(ctx context.Context, q entity.TransactionListQuery) (domain.Transactions, int, error) {
+			transactions := domain.Transactions{
+				{Datetime: time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC), CategoryName: "Food & Drinks", Description: "<3 Lunch", Amount: money.New(750, money.SGD)},
+			}
+			return transactions, 1, nil
+		},
+	}
+	handler := NewInlineHandler(mockUserRepo{}, tr)
+	user := domain.User{Id: 7, Locale: "en", Currency: money.GetCurrency("SGD"), Location: time.UTC}
+
+	results, err := handler.buildInlineResults(context.Background(), user, "mar 2023")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	top := results[1].(tgbotapi.InlineQueryResultArticle).InputMessageContent.(tgbotapi.InputTextMessageContent).Text
+	if !strings.Contains(top, "Food &amp; Drinks") {
+		t.Errorf("expected the category escaped, got %s", top)
+	}
+	recent := results[2].(tgbotapi.InlineQueryResultArticle).InputMessageContent.(tgbotapi.InputTextMessageContent).Text
+	if !strings.Contains(recent, "Food &amp; Drinks &lt;3 Lunch") {
+		t.Errorf("expected the category and description escaped, got %s", recent)
+	}
+}
+
+func TestNormaliseInlineQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", ""},
+		{"  Stats   MAR ", "stats mar"},
+		{"list\t2 2023", "list 2 2023"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := normaliseInlineQuery(tt.query); got != tt.want {
+				t.Errorf("normaliseInlineQuery(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInlineResultCache(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache := newInlineResultCache(30 * time.Second)
+	cache.put("1:stats", []interface{}{"a"}, now)
+
+	if _, ok := cache.get("2:stats", now); ok {
+		t.Errorf("expected no results for another user")
+	}
+	if results, ok := cache.get("1:stats", now.Add(29*time.Second)); !ok || len(results) != 1 {
+		t.Errorf("expected cached results before expiry")
+	}
+	if _, ok := cache.get("1:stats", now.Add(30*time.Second)); ok {
+		t.Errorf("expected cached results to expire")
+	}
+
+	cache.put("2:stats", []interface{}{"b"}, now.Add(time.Minute))
+	if len(cache.entries) != 1 {
+		t.Errorf("expected expired entries to be evicted, got %d entries", len(cache.entries))
+	}
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	"github.com/aattwwss/telegram-expense-bot/util"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	iouPersonLimit       = 50
+	iouReminderDaysLimit = 365
+)
+
+// Lent records money the user lent a person, e.g. /lent 50 Alice concert
+func (handler CommandHandler) Lent(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	handler.addIou(ctx, bot, update, false)
+}
+
+// Borrowed records money the user borrowed from a person, e.g. /borrowed 20 Bob taxi
+func (handler CommandHandler) Borrowed(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	handler.addIou(ctx, bot, update, true)
+}
+
+func (handler CommandHandler) addIou(ctx context.Context, bot *sender.Sender, update tgbotapi.Update, borrowed bool) {
+	user := userFromContext(ctx)
+	locale := user.GetLocale()
+	chatId := update.Message.Chat.ID
+
+	amount, person, description, ok := parseIouArgs(bot, chatId, *user, update.Message.CommandArguments())
+	if !ok {
+		return
+	}
+	if utf8.RuneCountInString(description) > descLengthLimit {
+		util.BotSendMessage(bot, chatId, locale.Get(message.DescriptionTooLongMsg, descLengthLimit))
+		return
+	}
+
+	text := locale.Get(message.IouLentMsg, locale.FormatMoney(amount), person)
+	if borrowed {
+		text = locale.Get(message.IouBorrowedMsg, locale.FormatMoney(amount), person)
+		amount = amount.Negative()
+	}
+	handler.recordIou(ctx, bot, chatId, *user, domain.Iou{
+		UserId:      user.Id,
+		Person:      person,
+		Amount:      amount,
+		Description: description,
+		Datetime:    time.Now(),
+	}, text)
+}
+
+// Repaid records a repayment between the user and a person, e.g. /repaid 20 Alice, which brings what is owed either
+// way closer to nothing
+func (handler CommandHandler) Repaid(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	user := userFromContext(ctx)
+	locale := user.GetLocale()
+	chatId := update.Message.Chat.ID
+
+	amount, person, description, ok := parseIouArgs(bot, chatId, *user, update.Message.CommandArguments())
+	if !ok {
+		return
+	}
+
+	balance, err := handler.iouRepo.GetBalance(ctx, user.Id, person, amount.Currency().Code)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("GetBalance iou error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	if balance.IsZero() {
+		util.BotSendMessage(bot, chatId, locale.Get(message.IouNothingOwedMsg, person))
+		return
+	}
+	if amount.Amount() > balance.Absolute().Amount() {
+		util.BotSendMessage(bot, chatId, locale.Get(message.IouRepaidTooMuchMsg, locale.FormatMoney(balance.Absolute()), person))
+		return
+	}
+
+	// a repayment moves the balance towards nothing, whichever way it is owed
+	text := locale.Get(message.IouRepaidYouMsg, person, locale.FormatMoney(amount))
+	if balance.IsPositive() {
+		amount = amount.Negative()
+	} else {
+		text = locale.Get(message.IouYouRepaidMsg, person, locale.FormatMoney(amount))
+	}
+	handler.recordIou(ctx, bot, chatId, *user, domain.Iou{
+		UserId:      user.Id,
+		Person:      person,
+		Amount:      amount,
+		Description: description,
+		Datetime:    time.Now(),
+	}, text)
+}
+
+// Owed lists what each person owes the user and what the user owes them, or turns the reminders of old debts on or off,
+// e.g. /owed remind 30 and /owed remind off
+func (handler CommandHandler) Owed(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	user := userFromContext(ctx)
+	locale := user.GetLocale()
+	chatId := update.Message.Chat.ID
+
+	action, rest, _ := strings.Cut(strings.TrimSpace(update.Message.CommandArguments()), " ")
+	if strings.ToLower(action) == "remind" {
+		handler.setIouReminder(ctx, bot, chatId, *user, strings.TrimSpace(rest))
+		return
+	}
+
+	balances, err := handler.iouRepo.GetBalances(ctx, user.Id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("GetBalances iou error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	if len(balances) == 0 {
+		util.BotSendMessage(bot, chatId, locale.Get(message.IouNoneMsg)+locale.Get(message.IouUsageMsg))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatId, locale.Get(message.IouListHeader)+balances.GetFormattedHTMLMsg(locale, user.Location))
+	msg.ParseMode = tgbotapi.ModeHTML
+	util.BotSendWrapper(bot, msg)
+}
+
+func (handler CommandHandler) setIouReminder(ctx context.Context, bot *sender.Sender, chatId int64, user domain.User, args string) {
+	locale := user.GetLocale()
+	if strings.ToLower(args) == "off" {
+		err := handler.iouRepo.DeleteReminder(ctx, user.Id)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("DeleteReminder iou error: %v", err)
+			util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+			return
+		}
+		util.BotSendMessage(bot, chatId, locale.Get(message.IouReminderOffMsg))
+		return
+	}
+
+	days, err := strconv.Atoi(args)
+	if err != nil || days < 1 || days > iouReminderDaysLimit {
+		util.BotSendMessage(bot, chatId, locale.Get(message.IouUsageMsg))
+		return
+	}
+	err = handler.iouRepo.SetReminder(ctx, user.Id, chatId, days)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("SetReminder iou error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	util.BotSendMessage(bot, chatId, locale.Get(message.IouReminderSetMsg, days))
+}
+
+// recordIou adds the iou and replies the text followed by what is owed with the person afterwards
+func (handler CommandHandler) recordIou(ctx context.Context, bot *sender.Sender, chatId int64, user domain.User, iou domain.Iou, text string) {
+	locale := user.GetLocale()
+	balance, err := handler.iouRepo.Add(ctx, iou)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Add iou error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+
+	if balance.IsZero() {
+		text += locale.Get(message.IouSettledMsg, iou.Person)
+	} else {
+		text += domain.IouBalance{Person: iou.Person, Balance: balance}.GetFormattedMsg(locale)
+	}
+	util.BotSendMessage(bot, chatId, text)
+}
+
+// parseIouArgs parses a positive amount followed by the name of a person and an optional description, e.g.
+// 50 Alice concert, or replies how to use the command and returns false
+func parseIouArgs(bot *sender.Sender, chatId int64, user domain.User, args string) (*money.Money, string, string, bool) {
+	locale := user.GetLocale()
+	amount, rest, err := parseAmount(strings.TrimSpace(args), *user.Currency)
+	if err != nil || !amount.IsPositive() || rest == "" {
+		util.BotSendMessage(bot, chatId, locale.Get(message.IouUsageMsg))
+		return nil, "", "", false
+	}
+	person, description, _ := strings.Cut(rest, " ")
+	if utf8.RuneCountInString(person) > iouPersonLimit {
+		util.BotSendMessage(bot, chatId, locale.Get(message.IouPersonInvalidMsg, iouPersonLimit))
+		return nil, "", "", false
+	}
+	return amount, person, strings.TrimSpace(description), true
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+)
+
+func TestLent(t *testing.T) {
+	var added domain.Iou
+	ir := mockIouRepo{
+		addFn: func(ctx context.Context, iou domain.Iou) (*money.Money, error) {
+			added = iou
+			return money.New(5000, money.SGD), nil
+		},
+	}
+	handler := CommandHandler{iouRepo: ir}
+	bot, client := newRecordingSender()
+
+	handler.Lent(accountsTestContext(), bot, commandUpdate("/lent 50 Alice concert"))
+
+	if added.Person != "Alice" || added.Description != "concert" || added.Amount.Amount() != 5000 || added.UserId != 1 {
+		t.Errorf("unexpected iou %+v", added)
+	}
+	if len(client.requests) != 1 || !strings.Contains(client.requests[0], "Alice+owes+you+%2450.00") {
+		t.Errorf("expected the balance in the reply, got %v", client.requests)
+	}
+}
+
+func TestBorrowed(t *testing.T) {
+	var added domain.Iou
+	ir := mockIouRepo{
+		addFn: func(ctx context.Context, iou domain.Iou) (*money.Money, error) {
+			added = iou
+			return money.New(-2000, money.SGD), nil
+		},
+	}
+	handler := CommandHandler{iouRepo: ir}
+	bot, client := newRecordingSender()
+
+	handler.Borrowed(accountsTestContext(), bot, commandUpdate("/borrowed 20 Bob taxi"))
+
+	if added.Person != "Bob" || added.Amount.Amount() != -2000 {
+		t.Errorf("expected -2000 borrowed from Bob, got %+v", added)
+	}
+	if len(client.requests) != 1 || !strings.Contains(client.requests[0], "You+owe+Bob+%2420.00") {
+		t.Errorf("expected the balance in the reply, got %v", client.requests)
+	}
+}
+
+func TestRepaid(t *testing.T) {
+	tests := []struct {
+		name       string
+		balance    int64
+		text       string
+		wantAmount int64
+		wantReply  string
+	}{
+		{"partial repayment to the user", 5000, "/repaid 20 alice", -2000, "alice+owes+you+%2430.00"},
+		{"repayment by the user settles", -2000, "/repaid 20 alice", 2000, "all+square"},
+		{"more than owed", 5000, "/repaid 80 alice", 0, "more+than+the+%2450.00"},
+		{"nothing owed", 0, "/repaid 20 alice", 0, "owe+each+other+nothing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var added *domain.Iou
+			ir := mockIouRepo{
+				getBalanceFn: func(ctx context.Context, userId int64, person string, currency string) (*money.Money, error) {
+					return money.New(tt.balance, currency), nil
+				},
+				addFn: func(ctx context.Context, iou domain.Iou) (*money.Money, error) {
+					added = &iou
+					return money.New(tt.balance+iou.Amount.Amount(), money.SGD), nil
+				},
+			}
+			handler := CommandHandler{iouRepo: ir}
+			bot, client := newRecordingSender()
+
+			handler.Repaid(accountsTestContext(), bot, commandUpdate(tt.text))
+
+			if tt.wantAmount == 0 && added != nil {
+				t.Errorf("expected nothing recorded, got %+v", added)
+			}
+			if tt.wantAmount != 0 && (added == nil || added.Amount.Amount() != tt.wantAmount) {
+				t.Errorf("expected %d recorded, got %+v", tt.wantAmount, added)
+			}
+			if len(client.requests) != 1 || !strings.Contains(client.requests[0], tt.wantReply) {
+				t.Errorf("expected a reply with %s, got %v", tt.wantReply, client.requests)
+			}
+		})
+	}
+}
+
+func TestOwed_Remind(t *testing.T) {
+	var days int
+	deleted := false
+	ir := mockIouRepo{
+		setReminderFn: func(ctx context.Context, userId int64, chatId int64, d int) error {
+			days = d
+			return nil
+		},
+		deleteReminderFn: func(ctx context.Context, userId int64) error {
+			deleted = true
+			return nil
+		},
+	}
+	handler := CommandHandler{iouRepo: ir}
+
+	bot, _ := newRecordingSender()
+	handler.Owed(accountsTestContext(), bot, commandUpdate("/owed remind 30"))
+	if days != 30 {
+		t.Errorf("expected a reminder after 30 days, got %d", days)
+	}
+
+	bot, _ = newRecordingSender()
+	handler.Owed(accountsTestContext(), bot, commandUpdate("/owed remind off"))
+	if !deleted {
+		t.Errorf("expected the reminder to be deleted")
+	}
+
+	bot, client := newRecordingSender()
+	handler.Owed(accountsTestContext(), bot, commandUpdate("/owed remind 0"))
+	if len(client.requests) != 1 || !strings.Contains(client.requests[0], "Type+%2Flent") {
+		t.Errorf("expected the usage, got %v", client.requests)
+	}
+}
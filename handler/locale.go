@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/aattwwss/telegram-expense-bot/message"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// clientLocale returns the locale of the telegram client, used until the user is loaded from the database
+func clientLocale(from *tgbotapi.User) message.Locale {
+	if from == nil {
+		return message.GetLocale(message.DefaultLocale)
+	}
+	return message.GetLocale(from.LanguageCode)
+}
+
+// findLocale returns the locale chosen by the user, falling back to the locale of the telegram client
+func findLocale(ctx context.Context, userRepo UserRepo, from *tgbotapi.User) message.Locale {
+	if from == nil {
+		return clientLocale(from)
+	}
+	user, err := userRepo.FindUserById(ctx, from.ID)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error finding user for locale: %v", err)
+		return clientLocale(from)
+	}
+	if user == nil {
+		return clientLocale(from)
+	}
+	return user.GetLocale()
+}
+
+// contextLocale returns the locale of the user loaded by LoadUser, falling back to the locale of the telegram client
+func contextLocale(ctx context.Context, from *tgbotapi.User) message.Locale {
+	if user := userFromContext(ctx); user != nil {
+		return user.GetLocale()
+	}
+	return clientLocale(from)
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/router"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	"github.com/aattwwss/telegram-expense-bot/util"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+type userKey struct{}
+
+// LoadUser loads the user who sent the update into the context, or replies with the generic error when it cannot be
+// loaded. The user is nil when they have not signed up.
+func LoadUser(userRepo UserRepo) router.Middleware {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+			from := update.SentFrom()
+			if from == nil {
+				next(ctx, bot, update)
+				return
+			}
+			user, err := userRepo.FindUserById(ctx, from.ID)
+			if err != nil {
+				log.Ctx(ctx).Error().Msgf("Error loading user: %v", err)
+				util.BotSendMessage(bot, update.FromChat().ID, clientLocale(from).Get(message.ErrorFindingUserMsg))
+				return
+			}
+			next(context.WithValue(ctx, userKey{}, user), bot, update)
+		}
+	}
+}
+
+// RequireUser asks the users who have not signed up to send /start, it runs after LoadUser
+func RequireUser() router.Middleware {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+			if userFromContext(ctx) == nil {
+				util.BotSendMessage(bot, update.FromChat().ID, clientLocale(update.SentFrom()).Get(message.NotRegisteredMsg))
+				return
+			}
+			next(ctx, bot, update)
+		}
+	}
+}
+
+// userFromContext returns the user loaded by LoadUser, or nil
+func userFromContext(ctx context.Context) *domain.User {
+	user, _ := ctx.Value(userKey{}).(*domain.User)
+	return user
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func commandUpdate(text string) tgbotapi.Update {
+	command := strings.SplitN(text, " ", 2)[0]
+	return tgbotapi.Update{
+		Message: &tgbotapi.Message{
+			From:     &tgbotapi.User{ID: 1},
+			Chat:     &tgbotapi.Chat{ID: 456},
+			Text:     text,
+			Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}},
+		},
+	}
+}
+
+func TestLoadUser(t *testing.T) {
+	tests := []struct {
+		name      string
+		user      *domain.User
+		err       error
+		wantNext  bool
+		wantUser  bool
+		wantReply string
+	}{
+		{name: "registered", user: &domain.User{Id: 1}, wantNext: true, wantUser: true},
+		{name: "not registered", wantNext: true},
+		{name: "error", err: errors.New("db error"), wantReply: "problem+fetching"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ur := mockUserRepo{findByIdFn: func(ctx context.Context, id int64) (*domain.User, error) {
+				return tt.user, tt.err
+			}}
+			bot, client := newRecordingSender()
+			called := false
+			var got *domain.User
+			next := func(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+				called = true
+				got = userFromContext(ctx)
+			}
+
+			LoadUser(ur)(next)(context.Background(), bot, commandUpdate("/stats"))
+
+			if called != tt.wantNext {
+				t.Errorf("expected next called %v, got %v", tt.wantNext, called)
+			}
+			if (got != nil) != tt.wantUser {
+				t.Errorf("expected the user in the context %v, got %v", tt.wantUser, got)
+			}
+			if tt.wantReply == "" && len(client.requests) != 0 {
+				t.Errorf("expected no reply, got %v", client.requests)
+			}
+			if tt.wantReply != "" && (len(client.requests) != 1 || !strings.Contains(client.requests[0], tt.wantReply)) {
+				t.Errorf("expected a reply containing %q, got %v", tt.wantReply, client.requests)
+			}
+		})
+	}
+}
+
+func TestRequireUser(t *testing.T) {
+	bot, client := newRecordingSender()
+	called := false
+	next := RequireUser()(func(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+		called = true
+	})
+
+	next(context.Background(), bot, commandUpdate("/stats"))
+
+	if called {
+		t.Error("expected the handler not to run without a user")
+	}
+	if len(client.requests) != 1 || !strings.Contains(client.requests[0], "send+%2Fstart") {
+		t.Errorf("expected the user asked to sign up, got %v", client.requests)
+	}
+
+	next(context.WithValue(context.Background(), userKey{}, &domain.User{Id: 1}), bot, commandUpdate("/stats"))
+
+	if !called {
+		t.Error("expected the handler to run with a user")
+	}
+}
+
+func TestNewCommandRouter_Help(t *testing.T) {
+	ur := mockUserRepo{findByIdFn: func(ctx context.Context, id int64) (*domain.User, error) {
+		return nil, nil
+	}}
+	handler, _ := newTestCommandHandler(ur, mockTransactionRepo{}, mockMessageContextRepo{}, mockTransactionTypeRepo{}, mockCategoryRepo{})
+	r := NewCommandRouter(handler)
+	bot, client := newRecordingSender()
+
+	r.Dispatch(context.Background(), bot, commandUpdate("/unknown"))
+
+	if len(client.requests) != 1 {
+		t.Fatalf("expected the help replied, got %v", client.requests)
+	}
+	for _, want := range []string{"%2Fstats+%5Bperiod%5D", "%2Fcancel", "%2Fstart"} {
+		if !strings.Contains(client.requests[0], want) {
+			t.Errorf("expected the help to list %s, got %s", want, client.requests[0])
+		}
+	}
+	if strings.Contains(client.requests[0], "%2Fapp") {
+		t.Errorf("expected /app hidden without a web app, got %s", client.requests[0])
+	}
+}
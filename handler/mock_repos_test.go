@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/Rhymond/go-money"
@@ -10,8 +11,10 @@ import (
 )
 
 type mockUserRepo struct {
-	findByIdFn func(ctx context.Context, id int64) (*domain.User, error)
-	addFn      func(ctx context.Context, user domain.User) error
+	findByIdFn     func(ctx context.Context, id int64) (*domain.User, error)
+	addFn          func(ctx context.Context, user domain.User) error
+	updateLocaleFn func(ctx context.Context, id int64, locale string) error
+	updateRefundFn func(ctx context.Context, id int64, refundMonth string) error
 }
 
 func (m mockUserRepo) FindUserById(ctx context.Context, id int64) (*domain.User, error) {
@@ -22,19 +25,39 @@ func (m mockUserRepo) Add(ctx context.Context, user domain.User) error {
 	return m.addFn(ctx, user)
 }
 
+func (m mockUserRepo) UpdateLocale(ctx context.Context, id int64, locale string) error {
+	return m.updateLocaleFn(ctx, id, locale)
+}
+
+func (m mockUserRepo) UpdateRefundMonth(ctx context.Context, id int64, refundMonth string) error {
+	return m.updateRefundFn(ctx, id, refundMonth)
+}
+
 type mockTransactionRepo struct {
-	addFn                          func(ctx context.Context, t domain.Transaction) error
+	addFn                          func(ctx context.Context, t domain.Transaction) (int, error)
+	updateFn                       func(ctx context.Context, t domain.Transaction) error
 	getByIdFn                      func(ctx context.Context, id int, userId int64) (domain.Transaction, error)
 	findLatestByUserIdFn           func(ctx context.Context, userId int64) (*domain.Transaction, error)
 	deleteByIdFn                   func(ctx context.Context, id int, userId int64) error
-	getTransactionBreakdownByCatFn func(ctx context.Context, month time.Month, year int, user domain.User) (domain.Breakdowns, *money.Money, error)
-	listByMonthAndYearFn           func(ctx context.Context, q entity.TransactionListQuery) (domain.Transactions, int, error)
+	findDeletedFn                  func(ctx context.Context, userId int64, limit int) (domain.Transactions, error)
+	findRefundableFn               func(ctx context.Context, userId int64, limit int) (domain.Transactions, error)
+	restoreFn                      func(ctx context.Context, id int, userId int64) (domain.Transaction, error)
+	restoreLatestFn                func(ctx context.Context, userId int64) (*domain.Transaction, error)
+	getHistoryFn                   func(ctx context.Context, id int, userId int64) (domain.TransactionHistory, error)
+	listHistoryFn                  func(ctx context.Context, userId int64, dateFrom time.Time, dateTo time.Time) (domain.TransactionHistory, error)
+	getTransactionBreakdownByCatFn func(ctx context.Context, dateFrom time.Time, dateTo time.Time, user domain.User) (domain.Breakdowns, *money.Money, error)
+	listByDateRangeFn              func(ctx context.Context, q entity.TransactionListQuery) (domain.Transactions, int, error)
+	listFn                         func(ctx context.Context, filter entity.TransactionFilter) (domain.Transactions, int, error)
 }
 
-func (m mockTransactionRepo) Add(ctx context.Context, t domain.Transaction) error {
+func (m mockTransactionRepo) Add(ctx context.Context, t domain.Transaction) (int, error) {
 	return m.addFn(ctx, t)
 }
 
+func (m mockTransactionRepo) Update(ctx context.Context, t domain.Transaction) error {
+	return m.updateFn(ctx, t)
+}
+
 func (m mockTransactionRepo) GetById(ctx context.Context, id int, userId int64) (domain.Transaction, error) {
 	return m.getByIdFn(ctx, id, userId)
 }
@@ -47,12 +70,40 @@ func (m mockTransactionRepo) DeleteById(ctx context.Context, id int, userId int6
 	return m.deleteByIdFn(ctx, id, userId)
 }
 
-func (m mockTransactionRepo) GetTransactionBreakdownByCategory(ctx context.Context, month time.Month, year int, user domain.User) (domain.Breakdowns, *money.Money, error) {
-	return m.getTransactionBreakdownByCatFn(ctx, month, year, user)
+func (m mockTransactionRepo) FindDeleted(ctx context.Context, userId int64, limit int) (domain.Transactions, error) {
+	return m.findDeletedFn(ctx, userId, limit)
+}
+
+func (m mockTransactionRepo) FindRefundable(ctx context.Context, userId int64, limit int) (domain.Transactions, error) {
+	return m.findRefundableFn(ctx, userId, limit)
+}
+
+func (m mockTransactionRepo) Restore(ctx context.Context, id int, userId int64) (domain.Transaction, error) {
+	return m.restoreFn(ctx, id, userId)
+}
+
+func (m mockTransactionRepo) RestoreLatest(ctx context.Context, userId int64) (*domain.Transaction, error) {
+	return m.restoreLatestFn(ctx, userId)
+}
+
+func (m mockTransactionRepo) GetHistory(ctx context.Context, id int, userId int64) (domain.TransactionHistory, error) {
+	return m.getHistoryFn(ctx, id, userId)
 }
 
-func (m mockTransactionRepo) ListByMonthAndYear(ctx context.Context, q entity.TransactionListQuery) (domain.Transactions, int, error) {
-	return m.listByMonthAndYearFn(ctx, q)
+func (m mockTransactionRepo) ListHistory(ctx context.Context, userId int64, dateFrom time.Time, dateTo time.Time) (domain.TransactionHistory, error) {
+	return m.listHistoryFn(ctx, userId, dateFrom, dateTo)
+}
+
+func (m mockTransactionRepo) GetTransactionBreakdownByCategory(ctx context.Context, dateFrom time.Time, dateTo time.Time, user domain.User) (domain.Breakdowns, *money.Money, error) {
+	return m.getTransactionBreakdownByCatFn(ctx, dateFrom, dateTo, user)
+}
+
+func (m mockTransactionRepo) ListByDateRange(ctx context.Context, q entity.TransactionListQuery) (domain.Transactions, int, error) {
+	return m.listByDateRangeFn(ctx, q)
+}
+
+func (m mockTransactionRepo) List(ctx context.Context, filter entity.TransactionFilter) (domain.Transactions, int, error) {
+	return m.listFn(ctx, filter)
 }
 
 type mockMessageContextRepo struct {
@@ -82,14 +133,242 @@ func (m mockTransactionTypeRepo) GetById(ctx context.Context, id int) (*entity.T
 }
 
 type mockCategoryRepo struct {
-	findAllFn func(ctx context.Context) ([]*entity.Category, error)
-	getByIdFn func(ctx context.Context, id int) (*entity.Category, error)
+	findAllByUserIdFn func(ctx context.Context, userId int64) ([]*entity.Category, error)
+	getByIdFn         func(ctx context.Context, id int) (*entity.Category, error)
+	addFn             func(ctx context.Context, category entity.Category) (int, error)
+	renameFn          func(ctx context.Context, id int, userId int64, name string) error
+	deleteByIdFn      func(ctx context.Context, id int, userId int64) error
 }
 
-func (m mockCategoryRepo) FindAll(ctx context.Context) ([]*entity.Category, error) {
-	return m.findAllFn(ctx)
+func (m mockCategoryRepo) FindAllByUserId(ctx context.Context, userId int64) ([]*entity.Category, error) {
+	return m.findAllByUserIdFn(ctx, userId)
 }
 
 func (m mockCategoryRepo) GetById(ctx context.Context, id int) (*entity.Category, error) {
 	return m.getByIdFn(ctx, id)
 }
+
+func (m mockCategoryRepo) Add(ctx context.Context, category entity.Category) (int, error) {
+	return m.addFn(ctx, category)
+}
+
+func (m mockCategoryRepo) Rename(ctx context.Context, id int, userId int64, name string) error {
+	return m.renameFn(ctx, id, userId, name)
+}
+
+func (m mockCategoryRepo) DeleteById(ctx context.Context, id int, userId int64) error {
+	return m.deleteByIdFn(ctx, id, userId)
+}
+
+type mockAccountRepo struct {
+	findAllByUserIdFn   func(ctx context.Context, userId int64) (domain.Accounts, error)
+	getByIdFn           func(ctx context.Context, id int, userId int64) (domain.Account, error)
+	addFn               func(ctx context.Context, account domain.Account) (int, error)
+	setOpeningBalanceFn func(ctx context.Context, id int, userId int64, openingBalance *money.Money) error
+	deleteByIdFn        func(ctx context.Context, id int, userId int64) error
+	getBalancesFn       func(ctx context.Context, userId int64) (domain.AccountBalances, error)
+	transferFn          func(ctx context.Context, transfer domain.Transfer) (int, error)
+}
+
+func (m mockAccountRepo) FindAllByUserId(ctx context.Context, userId int64) (domain.Accounts, error) {
+	return m.findAllByUserIdFn(ctx, userId)
+}
+
+func (m mockAccountRepo) GetById(ctx context.Context, id int, userId int64) (domain.Account, error) {
+	return m.getByIdFn(ctx, id, userId)
+}
+
+func (m mockAccountRepo) Add(ctx context.Context, account domain.Account) (int, error) {
+	return m.addFn(ctx, account)
+}
+
+func (m mockAccountRepo) SetOpeningBalance(ctx context.Context, id int, userId int64, openingBalance *money.Money) error {
+	return m.setOpeningBalanceFn(ctx, id, userId, openingBalance)
+}
+
+func (m mockAccountRepo) DeleteById(ctx context.Context, id int, userId int64) error {
+	return m.deleteByIdFn(ctx, id, userId)
+}
+
+func (m mockAccountRepo) GetBalances(ctx context.Context, userId int64) (domain.AccountBalances, error) {
+	return m.getBalancesFn(ctx, userId)
+}
+
+func (m mockAccountRepo) Transfer(ctx context.Context, transfer domain.Transfer) (int, error) {
+	return m.transferFn(ctx, transfer)
+}
+
+type mockGoalRepo struct {
+	findAllByUserIdFn  func(ctx context.Context, userId int64) (domain.Goals, error)
+	addFn              func(ctx context.Context, goal domain.Goal) (int, error)
+	deleteByIdFn       func(ctx context.Context, id int, userId int64) error
+	setMonthlyAmountFn func(ctx context.Context, id int, userId int64, amount *money.Money, next time.Time) error
+	contributeFn       func(ctx context.Context, id int, userId int64, amount *money.Money, now time.Time) (domain.Goal, bool, error)
+}
+
+func (m mockGoalRepo) FindAllByUserId(ctx context.Context, userId int64) (domain.Goals, error) {
+	return m.findAllByUserIdFn(ctx, userId)
+}
+
+func (m mockGoalRepo) Add(ctx context.Context, goal domain.Goal) (int, error) {
+	return m.addFn(ctx, goal)
+}
+
+func (m mockGoalRepo) DeleteById(ctx context.Context, id int, userId int64) error {
+	return m.deleteByIdFn(ctx, id, userId)
+}
+
+func (m mockGoalRepo) SetMonthlyAmount(ctx context.Context, id int, userId int64, amount *money.Money, next time.Time) error {
+	return m.setMonthlyAmountFn(ctx, id, userId, amount, next)
+}
+
+func (m mockGoalRepo) Contribute(ctx context.Context, id int, userId int64, amount *money.Money, now time.Time) (domain.Goal, bool, error) {
+	return m.contributeFn(ctx, id, userId, amount, now)
+}
+
+type mockIouRepo struct {
+	addFn            func(ctx context.Context, iou domain.Iou) (*money.Money, error)
+	getBalanceFn     func(ctx context.Context, userId int64, person string, currency string) (*money.Money, error)
+	getBalancesFn    func(ctx context.Context, userId int64) (domain.IouBalances, error)
+	setReminderFn    func(ctx context.Context, userId int64, chatId int64, days int) error
+	deleteReminderFn func(ctx context.Context, userId int64) error
+}
+
+func (m mockIouRepo) Add(ctx context.Context, iou domain.Iou) (*money.Money, error) {
+	return m.addFn(ctx, iou)
+}
+
+func (m mockIouRepo) GetBalance(ctx context.Context, userId int64, person string, currency string) (*money.Money, error) {
+	return m.getBalanceFn(ctx, userId, person, currency)
+}
+
+func (m mockIouRepo) GetBalances(ctx context.Context, userId int64) (domain.IouBalances, error) {
+	return m.getBalancesFn(ctx, userId)
+}
+
+func (m mockIouRepo) SetReminder(ctx context.Context, userId int64, chatId int64, days int) error {
+	return m.setReminderFn(ctx, userId, chatId, days)
+}
+
+func (m mockIouRepo) DeleteReminder(ctx context.Context, userId int64) error {
+	return m.deleteReminderFn(ctx, userId)
+}
+
+type mockReminderRepo struct {
+	getByUserIdFn func(ctx context.Context, userId int64) (*domain.Reminder, error)
+	saveFn        func(ctx context.Context, reminder domain.Reminder) error
+	snoozeFn      func(ctx context.Context, userId int64, until time.Time) error
+}
+
+func (m mockReminderRepo) GetByUserId(ctx context.Context, userId int64) (*domain.Reminder, error) {
+	return m.getByUserIdFn(ctx, userId)
+}
+
+func (m mockReminderRepo) Save(ctx context.Context, reminder domain.Reminder) error {
+	return m.saveFn(ctx, reminder)
+}
+
+func (m mockReminderRepo) Snooze(ctx context.Context, userId int64, until time.Time) error {
+	return m.snoozeFn(ctx, userId, until)
+}
+
+type mockApiTokenRepo struct {
+	issueFn        func(ctx context.Context, userId int64) (string, error)
+	authenticateFn func(ctx context.Context, token string) (int64, error)
+	revokeAllFn    func(ctx context.Context, userId int64) error
+}
+
+func (m mockApiTokenRepo) Issue(ctx context.Context, userId int64) (string, error) {
+	return m.issueFn(ctx, userId)
+}
+
+func (m mockApiTokenRepo) Authenticate(ctx context.Context, token string) (int64, error) {
+	return m.authenticateFn(ctx, token)
+}
+
+func (m mockApiTokenRepo) RevokeAll(ctx context.Context, userId int64) error {
+	return m.revokeAllFn(ctx, userId)
+}
+
+// memoryConversationStore keeps the conversations of the tests in memory
+type memoryConversationStore struct {
+	conversations map[int]entity.Conversation
+	lastId        int
+}
+
+func newMemoryConversationStore() *memoryConversationStore {
+	return &memoryConversationStore{conversations: map[int]entity.Conversation{}}
+}
+
+func (m *memoryConversationStore) Insert(ctx context.Context, c entity.Conversation) (int, error) {
+	m.lastId++
+	c.Id = m.lastId
+	m.conversations[c.Id] = c
+	return c.Id, nil
+}
+
+func (m *memoryConversationStore) GetById(ctx context.Context, id int) (*entity.Conversation, error) {
+	c, ok := m.conversations[id]
+	if !ok {
+		return nil, fmt.Errorf("conversation %w: id=%d", entity.ErrNotFound, id)
+	}
+	return &c, nil
+}
+
+func (m *memoryConversationStore) FindLatestAwaitingText(ctx context.Context, chatId int64, userId int64, now time.Time) (*entity.Conversation, error) {
+	var latest *entity.Conversation
+	for _, c := range m.conversations {
+		if c.ChatId == chatId && c.UserId == userId && c.AwaitingText && c.ExpiresAt.After(now) && (latest == nil || c.Id > latest.Id) {
+			latest = &c
+		}
+	}
+	return latest, nil
+}
+
+func (m *memoryConversationStore) Extend(ctx context.Context, id int, now time.Time, expiresAt time.Time) error {
+	c, ok := m.conversations[id]
+	if !ok || !c.ExpiresAt.After(now) {
+		return fmt.Errorf("conversation %w: id=%d", entity.ErrNotFound, id)
+	}
+	c.ExpiresAt = expiresAt
+	m.conversations[id] = c
+	return nil
+}
+
+func (m *memoryConversationStore) Update(ctx context.Context, c entity.Conversation) error {
+	if _, ok := m.conversations[c.Id]; !ok {
+		return fmt.Errorf("conversation %w: id=%d", entity.ErrNotFound, c.Id)
+	}
+	m.conversations[c.Id] = c
+	return nil
+}
+
+func (m *memoryConversationStore) DeleteById(ctx context.Context, id int) error {
+	if _, ok := m.conversations[id]; !ok {
+		return fmt.Errorf("conversation %w: id=%d", entity.ErrNotFound, id)
+	}
+	delete(m.conversations, id)
+	return nil
+}
+
+func (m *memoryConversationStore) TakeByChatIdAndUserId(ctx context.Context, chatId int64, userId int64) ([]entity.Conversation, error) {
+	var taken []entity.Conversation
+	for id, c := range m.conversations {
+		if c.ChatId == chatId && c.UserId == userId {
+			taken = append(taken, c)
+			delete(m.conversations, id)
+		}
+	}
+	return taken, nil
+}
+
+func (m *memoryConversationStore) TakeExpired(ctx context.Context, now time.Time) ([]entity.Conversation, error) {
+	var taken []entity.Conversation
+	for id, c := range m.conversations {
+		if !c.ExpiresAt.After(now) {
+			taken = append(taken, c)
+			delete(m.conversations, id)
+		}
+	}
+	return taken, nil
+}
@@ -2,18 +2,37 @@ package handler
 
 import (
 	"errors"
-	"fmt"
 	"regexp"
+	"strings"
+
+	"github.com/aattwwss/telegram-expense-bot/logging"
 )
 
-var floatParser = regexp.MustCompile(`^(-?\d+\.?\d{0,2})`)
+var floatParser = regexp.MustCompile(`^(-?\d+(?:\.\d*)?)`)
 
 // parseFloatStringFromString retrieves a valid float string from a string
 func parseFloatStringFromString(s string) (string, error) {
 	matches := floatParser.FindAllString(s, -1)
-	fmt.Printf("%v", matches)
 	if len(matches) == 0 {
-		return "", errors.New("no float found in string: " + s)
+		return "", errors.New("no float found in string: " + logging.Text(s))
 	}
 	return matches[0], nil
 }
+
+// parseAccountHints returns the accounts hinted as ^name in a text and the text without them, e.g. "5.50 Chicken Rice"
+// and [dbs] for "5.50 Chicken Rice ^dbs"
+func parseAccountHints(s string) (string, []string) {
+	var hints []string
+	var rest []string
+	for _, field := range strings.Fields(s) {
+		if hint, ok := strings.CutPrefix(field, "^"); ok && hint != "" {
+			hints = append(hints, hint)
+			continue
+		}
+		rest = append(rest, field)
+	}
+	if len(hints) == 0 {
+		return s, nil
+	}
+	return strings.Join(rest, " "), hints
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -34,3 +35,26 @@ func TestParseFloatStringFromString(t *testing.T) {
 		})
 	}
 }
+
+func TestParseAccountHints(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantText  string
+		wantHints []string
+	}{
+		{"5.50 Chicken  Rice", "5.50 Chicken  Rice", nil},
+		{"5.50 Chicken Rice ^dbs", "5.50 Chicken Rice", []string{"dbs"}},
+		{"^cash 5.50 Chicken Rice", "5.50 Chicken Rice", []string{"cash"}},
+		{"100 ^dbs ^cash ATM", "100 ATM", []string{"dbs", "cash"}},
+		{"5 ^ lonely caret", "5 ^ lonely caret", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			text, hints := parseAccountHints(tt.input)
+			if text != tt.wantText || !reflect.DeepEqual(hints, tt.wantHints) {
+				t.Errorf("parseAccountHints(%q) = %q, %v, want %q, %v", tt.input, text, hints, tt.wantText, tt.wantHints)
+			}
+		})
+	}
+}
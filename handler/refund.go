@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	"github.com/aattwwss/telegram-expense-bot/util"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	refundListLimit     = 10
+	refundInlineColSize = 5
+)
+
+// transactionIdLine matches the line of the reply confirming a transaction with its id, e.g. #12
+var transactionIdLine = regexp.MustCompile(`(?m)^#(\d+)$`)
+
+// Refund records a refund of the purchase confirmed by the message replied to, or lists the latest purchases to pick
+// the one to refund. "/refund 20" refunds 20 of the purchase instead of what is left of it, and
+// "/refund month original|refund" sets the month stats count refunds in.
+func (handler CommandHandler) Refund(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	user := userFromContext(ctx)
+	locale := user.GetLocale()
+	chatId := update.Message.Chat.ID
+
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) > 0 && strings.EqualFold(args[0], "month") {
+		handler.setRefundMonth(ctx, bot, chatId, *user, args[1:])
+		return
+	}
+	if len(args) > 1 {
+		util.BotSendMessage(bot, chatId, locale.Get(message.RefundUsageMsg))
+		return
+	}
+	var amount int64
+	if len(args) == 1 {
+		parsed, rest, err := parseAmount(args[0], *user.Currency)
+		if err != nil || rest != "" || !parsed.IsPositive() {
+			util.BotSendMessage(bot, chatId, locale.Get(message.RefundUsageMsg))
+			return
+		}
+		amount = parsed.Amount()
+	}
+
+	if id, ok := repliedTransactionId(update.Message); ok {
+		text, _, err := refundTransaction(ctx, handler.transactionRepo, *user, id, amount)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("Error refunding transaction: %v", err)
+			util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+			return
+		}
+		util.BotSendMessage(bot, chatId, text)
+		return
+	}
+
+	transactions, err := handler.transactionRepo.FindRefundable(ctx, user.Id, refundListLimit)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("FindRefundable error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	if len(transactions) == 0 {
+		util.BotSendMessage(bot, chatId, locale.Get(message.RefundNoneMsg))
+		return
+	}
+
+	text, inlineKeyboard, err := refundMessage(transactions, amount, *user)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("NewRefundKeyboard error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	msg := tgbotapi.NewMessage(chatId, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: inlineKeyboard}
+	util.BotSendWrapper(bot, msg)
+}
+
+// setRefundMonth sets whether stats count refunds in the month of their purchase or in the month they were refunded,
+// or tells which one is set when none is given
+func (handler CommandHandler) setRefundMonth(ctx context.Context, bot *sender.Sender, chatId int64, user domain.User, args []string) {
+	locale := user.GetLocale()
+	if len(args) == 0 {
+		util.BotSendMessage(bot, chatId, refundMonthMsg(user.RefundMonth, locale)+"\n\n"+locale.Get(message.RefundUsageMsg))
+		return
+	}
+	refundMonth := strings.ToLower(args[0])
+	if len(args) > 1 || (refundMonth != domain.RefundMonthOriginal && refundMonth != domain.RefundMonthRefund) {
+		util.BotSendMessage(bot, chatId, locale.Get(message.RefundUsageMsg))
+		return
+	}
+	err := handler.userRepo.UpdateRefundMonth(ctx, user.Id, refundMonth)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error updating refund month: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	util.BotSendMessage(bot, chatId, refundMonthMsg(refundMonth, locale))
+}
+
+func refundMonthMsg(refundMonth string, locale message.Locale) string {
+	if refundMonth == domain.RefundMonthRefund {
+		return locale.Get(message.RefundMonthRefundMsg)
+	}
+	return locale.Get(message.RefundMonthOriginalMsg)
+}
+
+// FromRefund refunds the purchase tapped and replaces the list with the reply, or alerts why it cannot be refunded
+func (handler CallbackHandler) FromRefund(ctx context.Context, bot *sender.Sender, callbackQuery *tgbotapi.CallbackQuery) {
+	answer := util.NewCallbackAnswer(bot, callbackQuery.ID)
+	defer answer.Send()
+
+	chatId, messageId := callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID
+	locale := clientLocale(callbackQuery.From)
+	user, err := handler.userRepo.FindUserById(ctx, callbackQuery.From.ID)
+	if err != nil || user == nil {
+		log.Ctx(ctx).Error().Msgf("FromRefund cannot find user error: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	locale = user.GetLocale()
+
+	var refundCallback domain.RefundCallback
+	err = domain.DecodeCallback(callbackQuery.Data, &refundCallback)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("FromRefund unmarshall error: %v", err)
+		util.BotRemoveKeyboard(bot, chatId, messageId)
+		return
+	}
+
+	text, ok, err := refundTransaction(ctx, handler.transactionRepo, *user, refundCallback.TransactionId, refundCallback.Amount)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error refunding transaction: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	if !ok {
+		answer.Alert(text)
+		return
+	}
+	util.BotEditMessage(bot, chatId, messageId, text)
+}
+
+// refundTransaction records a refund of the amount of the purchase of the user, or of what is left of it when the
+// amount is 0, under the category and account of the purchase. It returns the reply, and whether the purchase was
+// refunded or the reply is why it cannot be.
+func refundTransaction(ctx context.Context, transactionRepo TransactionRepo, user domain.User, id int, amount int64) (string, bool, error) {
+	locale := user.GetLocale()
+	purchase, err := transactionRepo.GetById(ctx, id, user.Id)
+	if errors.Is(err, entity.ErrNotFound) {
+		return locale.Get(message.RefundNotFoundMsg), false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if purchase.IsRefund() || !purchase.Amount.IsPositive() {
+		return locale.Get(message.RefundNotPurchaseMsg, purchase.Id), false, nil
+	}
+	// a refund takes the category of its purchase, so it could not be netted against the lines of a split one
+	if purchase.IsSplit() {
+		return locale.Get(message.RefundSplitMsg, purchase.Id), false, nil
+	}
+
+	left := purchase.Refundable()
+	refunded := left
+	if amount != 0 {
+		refunded = money.New(amount, left.Currency().Code)
+	}
+	if !left.IsPositive() || refunded.Amount() > left.Amount() {
+		return locale.Get(message.RefundTooMuchMsg, locale.FormatMoney(left), purchase.Id), false, nil
+	}
+
+	refund := domain.Transaction{
+		Datetime:    time.Now(),
+		CategoryId:  purchase.CategoryId,
+		Description: purchase.Description,
+		UserId:      user.Id,
+		Amount:      refunded.Negative(),
+		AccountId:   purchase.AccountId,
+		RefundOf:    purchase.Id,
+	}
+	_, err = transactionRepo.Add(ctx, refund)
+	if err != nil {
+		return "", false, fmt.Errorf("adding refund error: %w", err)
+	}
+	return locale.Get(message.RefundReplyMsg, locale.FormatMoney(refunded), purchase.Id, purchase.Description, locale.CategoryName(purchase.CategoryName)), true, nil
+}
+
+// repliedTransactionId returns the id of the transaction confirmed by the message of the bot replied to
+func repliedTransactionId(msg *tgbotapi.Message) (int, bool) {
+	reply := msg.ReplyToMessage
+	if reply == nil || reply.From == nil || !reply.From.IsBot {
+		return 0, false
+	}
+	matches := transactionIdLine.FindAllStringSubmatch(reply.Text, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	id, err := strconv.Atoi(matches[len(matches)-1][1])
+	return id, err == nil
+}
+
+// refundMessage returns the list of the purchases with what is left to refund of each, and the buttons that refund them
+func refundMessage(transactions domain.Transactions, amount int64, user domain.User) (string, [][]tgbotapi.InlineKeyboardButton, error) {
+	locale := user.GetLocale()
+	inlineKeyboard, err := util.NewRefundKeyboard(transactions, amount, refundInlineColSize, locale)
+	if err != nil {
+		return "", nil, err
+	}
+	text := locale.Get(message.RefundSelectHeader)
+	for i, t := range transactions {
+		dtString := locale.FormatDateTime(t.Datetime.In(user.Location))
+		text += locale.Get(message.RefundSelectLine, i+1, dtString, t.Id, html.EscapeString(locale.CategoryName(t.CategoryName)), html.EscapeString(t.Description), locale.FormatMoney(t.Refundable()))
+	}
+	if amount != 0 {
+		text += locale.Get(message.RefundSelectAmountFooterMsg, locale.FormatMoney(money.New(amount, user.Currency.Code)))
+	} else {
+		text += locale.Get(message.RefundSelectFooterMsg)
+	}
+	return text, inlineKeyboard, nil
+}
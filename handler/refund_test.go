@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/enum"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// refundTestPurchase is a purchase of $5.50 with $1.00 refunded so far
+func refundTestPurchase() domain.Transaction {
+	return domain.Transaction{
+		Id:           12,
+		Datetime:     time.Date(2024, 6, 3, 12, 30, 0, 0, time.UTC),
+		CategoryId:   4,
+		CategoryName: "Food",
+		Description:  "Chicken Rice",
+		UserId:       1,
+		Amount:       money.New(550, "SGD"),
+		AccountId:    7,
+		Refunded:     money.New(-100, "SGD"),
+	}
+}
+
+func refundTestRepo(purchase domain.Transaction, added *domain.Transaction) mockTransactionRepo {
+	return mockTransactionRepo{
+		getByIdFn: func(ctx context.Context, id int, userId int64) (domain.Transaction, error) {
+			if id != purchase.Id {
+				return domain.Transaction{}, entity.ErrNotFound
+			}
+			return purchase, nil
+		},
+		addFn: func(ctx context.Context, t domain.Transaction) (int, error) {
+			*added = t
+			return 15, nil
+		},
+		findRefundableFn: func(ctx context.Context, userId int64, limit int) (domain.Transactions, error) {
+			return domain.Transactions{purchase}, nil
+		},
+	}
+}
+
+// refundReplyUpdate is the command replying to the message of the bot
+func refundReplyUpdate(command string, replyTo string) tgbotapi.Update {
+	update := commandUpdate(command)
+	update.Message.ReplyToMessage = &tgbotapi.Message{From: &tgbotapi.User{ID: 99, IsBot: true}, Text: replyTo}
+	return update
+}
+
+func TestRefund_ReplyToConfirmation(t *testing.T) {
+	confirmation := "Spent $5.50 on Food\nChicken Rice\n#12"
+	tests := []struct {
+		name       string
+		update     tgbotapi.Update
+		wantAmount int64
+		want       string
+	}{
+		{"what is left", refundReplyUpdate("/refund", confirmation), -450, "Refunded+%244.50+of+%2312+Chicken+Rice+under+Food"},
+		{"part of it", refundReplyUpdate("/refund 2", confirmation), -200, "Refunded+%242.00+of+%2312"},
+		{"more than is left", refundReplyUpdate("/refund 5", confirmation), 0, "more+than+the+%244.50+left+to+refund+of+%2312"},
+		{"no longer there", refundReplyUpdate("/refund", "Spent $5.50 on Food\n#13"), 0, "no+longer+there"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var added domain.Transaction
+			handler := CommandHandler{transactionRepo: refundTestRepo(refundTestPurchase(), &added)}
+			bot, client := newRecordingSender()
+
+			handler.Refund(accountsTestContext(), bot, tt.update)
+
+			if tt.wantAmount == 0 && added.Amount != nil {
+				t.Errorf("expected no refund, got %+v", added)
+			}
+			if tt.wantAmount != 0 && (added.Amount == nil || added.Amount.Amount() != tt.wantAmount || added.RefundOf != 12 || added.CategoryId != 4 || added.AccountId != 7) {
+				t.Errorf("expected a refund of %d linked to #12 under its category and account, got %+v", tt.wantAmount, added)
+			}
+			if len(client.requests) != 1 || !strings.Contains(client.requests[0], tt.want) {
+				t.Errorf("expected %q, got %v", tt.want, client.requests)
+			}
+		})
+	}
+}
+
+func TestRefund_RefundOfRefund(t *testing.T) {
+	refund := refundTestPurchase()
+	refund.Amount = money.New(-100, "SGD")
+	refund.RefundOf = 3
+	var added domain.Transaction
+	handler := CommandHandler{transactionRepo: refundTestRepo(refund, &added)}
+	bot, client := newRecordingSender()
+
+	handler.Refund(accountsTestContext(), bot, refundReplyUpdate("/refund", "Refunded $1.00\n#12"))
+
+	if added.Amount != nil || len(client.requests) != 1 || !strings.Contains(client.requests[0], "cannot+be+refunded") {
+		t.Errorf("expected a refund not to be refunded, got %+v %v", added, client.requests)
+	}
+}
+
+func TestRefund_SplitPurchase(t *testing.T) {
+	purchase := refundTestPurchase()
+	purchase.Splits = []domain.TransactionSplit{
+		{CategoryId: 4, CategoryName: "Food", Amount: money.New(350, "SGD")},
+		{CategoryId: 6, CategoryName: "Transport", Amount: money.New(200, "SGD")},
+	}
+	var added domain.Transaction
+	handler := CommandHandler{transactionRepo: refundTestRepo(purchase, &added)}
+	bot, client := newRecordingSender()
+
+	handler.Refund(accountsTestContext(), bot, refundReplyUpdate("/refund", "Spent $5.50 on Food, Transport\n#12"))
+
+	if added.Amount != nil || len(client.requests) != 1 || !strings.Contains(client.requests[0], "split+across+categories") {
+		t.Errorf("expected a split purchase not to be refunded, got %+v %v", added, client.requests)
+	}
+}
+
+func TestRefund_ListsPurchases(t *testing.T) {
+	var added domain.Transaction
+	handler := CommandHandler{transactionRepo: refundTestRepo(refundTestPurchase(), &added)}
+	bot, client := newRecordingSender()
+
+	handler.Refund(accountsTestContext(), bot, commandUpdate("/refund 2"))
+
+	if len(client.requests) != 1 || !strings.Contains(client.requests[0], "Chicken+Rice+%244.50") || !strings.Contains(client.requests[0], "refund+%242.00+of+it") {
+		t.Fatalf("expected the purchases listed with what is left of them, got %v", client.requests)
+	}
+	if !strings.Contains(client.requests[0], "%E2%86%A9%EF%B8%8F+1") {
+		t.Errorf("expected a numbered refund button, got %v", client.requests[0])
+	}
+}
+
+func TestRefundMessage_EscapesHTML(t *testing.T) {
+	purchase := refundTestPurchase()
+	purchase.CategoryName = "Food & Drinks"
+	user := domain.User{Id: 1, Currency: money.GetCurrency("SGD"), Location: time.UTC, Locale: "en"}
+
+	text, _, err := refundMessage(domain.Transactions{purchase}, 0, user)
+
+	if err != nil || !strings.Contains(text, "Food &amp; Drinks Chicken Rice $4.50") {
+		t.Errorf("expected the category escaped, got %q, %v", text, err)
+	}
+}
+
+func TestRefund_Month(t *testing.T) {
+	tests := []struct {
+		name string
+		args string
+		want string
+		set  string
+	}{
+		{"refund month", "month refund", "in+the+month+they+were+refunded", domain.RefundMonthRefund},
+		{"original month", "month Original", "in+the+month+of+their+purchase", domain.RefundMonthOriginal},
+		{"current", "month", "in+the+month+of+their+purchase", ""},
+		{"unknown", "month later", "Type+%2Frefund", ""},
+		{"usage", "2 3", "Type+%2Frefund", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var set string
+			ur := mockUserRepo{
+				updateRefundFn: func(ctx context.Context, id int64, refundMonth string) error {
+					set = refundMonth
+					return nil
+				},
+			}
+			handler := CommandHandler{userRepo: ur}
+			bot, client := newRecordingSender()
+
+			handler.Refund(accountsTestContext(), bot, commandUpdate("/refund "+tt.args))
+
+			if set != tt.set {
+				t.Errorf("expected %q set, got %q", tt.set, set)
+			}
+			if len(client.requests) != 1 || !strings.Contains(client.requests[0], tt.want) {
+				t.Errorf("expected %q, got %v", tt.want, client.requests)
+			}
+		})
+	}
+}
+
+func TestFromRefund(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     int64
+		wantAmount int64
+		wantEdit   bool
+	}{
+		{"what is left", 0, -450, true},
+		{"more than is left", 500, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var added domain.Transaction
+			ur := mockUserRepo{
+				findByIdFn: func(ctx context.Context, id int64) (*domain.User, error) {
+					return &domain.User{Id: id, Currency: money.GetCurrency("SGD"), Location: time.UTC, Locale: "en"}, nil
+				},
+			}
+			handler := NewCallbackHandler(ur, refundTestRepo(refundTestPurchase(), &added), mockMessageContextRepo{}, mockTransactionTypeRepo{}, mockCategoryRepo{}, nil, nil, nil)
+			data, _ := domain.EncodeCallback(domain.RefundCallback{Callback: domain.Callback{Type: enum.Refund}, TransactionId: 12, Amount: tt.amount})
+			bot, client := newRecordingSender()
+
+			handler.FromRefund(context.Background(), bot, &tgbotapi.CallbackQuery{
+				ID:      "q1",
+				From:    &tgbotapi.User{ID: 1},
+				Message: &tgbotapi.Message{MessageID: 2, Chat: &tgbotapi.Chat{ID: 3}},
+				Data:    data,
+			})
+
+			if tt.wantAmount != 0 && (added.Amount == nil || added.Amount.Amount() != tt.wantAmount) {
+				t.Errorf("expected a refund of %d, got %+v", tt.wantAmount, added)
+			}
+			edited := len(client.requests) == 2 && strings.HasPrefix(client.requests[0], "editMessageText") && strings.Contains(client.requests[0], "Refunded")
+			if edited != tt.wantEdit {
+				t.Errorf("expected the list replaced with the reply %v, got %v", tt.wantEdit, client.requests)
+			}
+			if !tt.wantEdit && (len(client.requests) != 1 || !strings.Contains(client.requests[0], "show_alert=true")) {
+				t.Errorf("expected an alert, got %v", client.requests)
+			}
+		})
+	}
+}
+
+func TestRepliedTransactionId(t *testing.T) {
+	tests := []struct {
+		name   string
+		reply  *tgbotapi.Message
+		want   int
+		wantOk bool
+	}{
+		{"confirmation", &tgbotapi.Message{From: &tgbotapi.User{IsBot: true}, Text: "Spent $5.50 on Food\n#food lunch\n#12"}, 12, true},
+		{"no id", &tgbotapi.Message{From: &tgbotapi.User{IsBot: true}, Text: "Select a category"}, 0, false},
+		{"not the bot", &tgbotapi.Message{From: &tgbotapi.User{}, Text: "#12"}, 0, false},
+		{"no reply", nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := repliedTransactionId(&tgbotapi.Message{ReplyToMessage: tt.reply})
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("repliedTransactionId() = %d %v, want %d %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -12,15 +12,25 @@ import (
 type UserRepo interface {
 	FindUserById(ctx context.Context, id int64) (*domain.User, error)
 	Add(ctx context.Context, user domain.User) error
+	UpdateLocale(ctx context.Context, id int64, locale string) error
+	UpdateRefundMonth(ctx context.Context, id int64, refundMonth string) error
 }
 
 type TransactionRepo interface {
-	Add(ctx context.Context, t domain.Transaction) error
+	Add(ctx context.Context, t domain.Transaction) (int, error)
+	Update(ctx context.Context, t domain.Transaction) error
 	GetById(ctx context.Context, id int, userId int64) (domain.Transaction, error)
 	FindLastestByUserId(ctx context.Context, userId int64) (*domain.Transaction, error)
 	DeleteById(ctx context.Context, id int, userId int64) error
-	GetTransactionBreakdownByCategory(ctx context.Context, month time.Month, year int, user domain.User) (domain.Breakdowns, *money.Money, error)
-	ListByMonthAndYear(ctx context.Context, q entity.TransactionListQuery) (domain.Transactions, int, error)
+	FindDeleted(ctx context.Context, userId int64, limit int) (domain.Transactions, error)
+	FindRefundable(ctx context.Context, userId int64, limit int) (domain.Transactions, error)
+	Restore(ctx context.Context, id int, userId int64) (domain.Transaction, error)
+	RestoreLatest(ctx context.Context, userId int64) (*domain.Transaction, error)
+	GetHistory(ctx context.Context, id int, userId int64) (domain.TransactionHistory, error)
+	ListHistory(ctx context.Context, userId int64, dateFrom time.Time, dateTo time.Time) (domain.TransactionHistory, error)
+	GetTransactionBreakdownByCategory(ctx context.Context, dateFrom time.Time, dateTo time.Time, user domain.User) (domain.Breakdowns, *money.Money, error)
+	ListByDateRange(ctx context.Context, q entity.TransactionListQuery) (domain.Transactions, int, error)
+	List(ctx context.Context, filter entity.TransactionFilter) (domain.Transactions, int, error)
 }
 
 type MessageContextRepo interface {
@@ -34,6 +44,47 @@ type TransactionTypeRepo interface {
 }
 
 type CategoryRepo interface {
-	FindAll(ctx context.Context) ([]*entity.Category, error)
+	FindAllByUserId(ctx context.Context, userId int64) ([]*entity.Category, error)
 	GetById(ctx context.Context, id int) (*entity.Category, error)
+	Add(ctx context.Context, category entity.Category) (int, error)
+	Rename(ctx context.Context, id int, userId int64, name string) error
+	DeleteById(ctx context.Context, id int, userId int64) error
+}
+
+type AccountRepo interface {
+	FindAllByUserId(ctx context.Context, userId int64) (domain.Accounts, error)
+	GetById(ctx context.Context, id int, userId int64) (domain.Account, error)
+	Add(ctx context.Context, account domain.Account) (int, error)
+	SetOpeningBalance(ctx context.Context, id int, userId int64, openingBalance *money.Money) error
+	DeleteById(ctx context.Context, id int, userId int64) error
+	GetBalances(ctx context.Context, userId int64) (domain.AccountBalances, error)
+	Transfer(ctx context.Context, transfer domain.Transfer) (int, error)
+}
+
+type GoalRepo interface {
+	FindAllByUserId(ctx context.Context, userId int64) (domain.Goals, error)
+	Add(ctx context.Context, goal domain.Goal) (int, error)
+	DeleteById(ctx context.Context, id int, userId int64) error
+	SetMonthlyAmount(ctx context.Context, id int, userId int64, amount *money.Money, next time.Time) error
+	Contribute(ctx context.Context, id int, userId int64, amount *money.Money, now time.Time) (domain.Goal, bool, error)
+}
+
+type IouRepo interface {
+	Add(ctx context.Context, iou domain.Iou) (*money.Money, error)
+	GetBalance(ctx context.Context, userId int64, person string, currency string) (*money.Money, error)
+	GetBalances(ctx context.Context, userId int64) (domain.IouBalances, error)
+	SetReminder(ctx context.Context, userId int64, chatId int64, days int) error
+	DeleteReminder(ctx context.Context, userId int64) error
+}
+
+type ReminderRepo interface {
+	GetByUserId(ctx context.Context, userId int64) (*domain.Reminder, error)
+	Save(ctx context.Context, reminder domain.Reminder) error
+	Snooze(ctx context.Context, userId int64, until time.Time) error
+}
+
+type ApiTokenRepo interface {
+	Issue(ctx context.Context, userId int64) (string, error)
+	Authenticate(ctx context.Context, token string) (int64, error)
+	RevokeAll(ctx context.Context, userId int64) error
 }
@@ -0,0 +1,300 @@
+package handler
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/conversation"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	"github.com/aattwwss/telegram-expense-bot/util"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// entrySplit is a part of a split amount, in the lowest denomination of the currency of the user
+type entrySplit struct {
+	CategoryId   int    `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	Amount       int64  `json:"amount"`
+}
+
+// newEntryCategoriesKeyboard returns the categories to choose from for an amount typed by the user, with a button to
+// split it across categories when it is more than 0 and a button to cancel it
+func newEntryCategoriesKeyboard(categories []*entity.Category, conversationId int, colSize int, amount *money.Money, locale message.Locale) ([][]tgbotapi.InlineKeyboardButton, error) {
+	inlineKeyboard, err := newCategoriesKeyboard(categories, conversationId, colSize, locale)
+	if err != nil {
+		return nil, err
+	}
+	if !amount.IsPositive() {
+		return inlineKeyboard, nil
+	}
+	splitRow, err := util.NewSplitConversationRow(conversationId, locale)
+	if err != nil {
+		return nil, err
+	}
+	// the cancel button stays last
+	last := len(inlineKeyboard) - 1
+	return append(inlineKeyboard[:last:last], splitRow, inlineKeyboard[last]), nil
+}
+
+// startSplit replaces the categories of the amount with the categories of its first part. An amount of 0 or less, such
+// as a correction, cannot be split, as no part would fit in what is left of it.
+func (handler CallbackHandler) startSplit(ctx context.Context, bot *sender.Sender, c *conversation.Conversation[entryData], menu *tgbotapi.Message, user domain.User) conversation.Transition {
+	locale := user.GetLocale()
+	messageId := menu.MessageID
+	total, _, err := parseAmount(c.Data.Text, *user.Currency)
+	if err == nil && !total.IsPositive() {
+		editKeepingMarkup(bot, menu, locale.Get(message.TransactionTypeReplyMsg)+locale.Get(message.SplitNotPositiveMsg))
+		return conversation.Stay()
+	}
+	c.Data.Splits = nil
+	text, inlineKeyboard, err := handler.splitMenu(ctx, c.Id, c.Data, user)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("startSplit error: %v", err)
+		util.BotEditMessage(bot, c.ChatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return conversation.End()
+	}
+	edit := tgbotapi.NewEditMessageTextAndMarkup(c.ChatId, messageId, text, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: inlineKeyboard})
+	util.BotEditWrapper(bot, edit)
+	return conversation.Goto(entrySplitCategoryState)
+}
+
+// chooseSplitCategory takes the category tapped for the next part of a split amount and asks for its amount. Every
+// part must be of the same transaction type, so the amount counts the same way whichever category it is under.
+func (handler CallbackHandler) chooseSplitCategory(ctx context.Context, bot *sender.Sender, c *conversation.Conversation[entryData], callbackQuery *tgbotapi.CallbackQuery) (conversation.Transition, error) {
+	messageId := callbackQuery.Message.MessageID
+	locale := clientLocale(callbackQuery.From)
+	user, err := handler.findEntryUser(ctx, c.UserId)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error finding user for split category: %v", err)
+		util.BotEditMessage(bot, c.ChatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return conversation.End(), nil
+	}
+	locale = user.GetLocale()
+
+	var categoryCallback domain.CategoryCallback
+	err = domain.DecodeCallback(callbackQuery.Data, &categoryCallback)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("FromCategory split unmarshall error: %v", err)
+		util.BotEditMessage(bot, c.ChatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return conversation.End(), nil
+	}
+
+	category, err := handler.findEntryCategory(ctx, categoryCallback.CategoryId, user.Id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Get split category by id error: %v", err)
+		util.BotEditMessage(bot, c.ChatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return conversation.End(), nil
+	}
+
+	text, total, err := splitProgress(c.Data, *user)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("splitProgress error: %v", err)
+		util.BotEditMessage(bot, c.ChatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return conversation.End(), nil
+	}
+
+	if len(c.Data.Splits) > 0 {
+		first, err := handler.findEntryCategory(ctx, c.Data.Splits[0].CategoryId, user.Id)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("Get first split category by id error: %v", err)
+			util.BotEditMessage(bot, c.ChatId, messageId, locale.Get(message.GenericErrReplyMsg))
+			return conversation.End(), nil
+		}
+		if first.TransactionTypeId != category.TransactionTypeId {
+			editKeepingMarkup(bot, callbackQuery.Message, text+locale.Get(message.SplitTypeMismatchMsg, locale.CategoryName(first.Name)))
+			return conversation.Stay(), nil
+		}
+	}
+
+	c.Data.SplitCategoryId = category.Id
+	remaining := money.New(total.Amount()-splitTotal(c.Data.Splits), total.Currency().Code)
+	editKeepingMarkup(bot, callbackQuery.Message, text+locale.Get(message.SplitAmountAskMsg, locale.FormatMoney(remaining), locale.CategoryName(category.Name)))
+	return conversation.Goto(entrySplitAmountState), nil
+}
+
+// readSplitAmount takes the amount or percentage of the total replied for the category tapped, then asks for the
+// category of the next part, or adds the amount split across the categories once the parts add up to it
+func (handler CallbackHandler) readSplitAmount(ctx context.Context, bot *sender.Sender, c *conversation.Conversation[entryData], msg *tgbotapi.Message) (conversation.Transition, error) {
+	user, err := handler.findEntryUser(ctx, c.UserId)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error finding user for split amount: %v", err)
+		util.BotSendMessage(bot, c.ChatId, clientLocale(msg.From).Get(message.GenericErrReplyMsg))
+		return conversation.End(), nil
+	}
+	locale := user.GetLocale()
+
+	total, _, err := parseAmount(c.Data.Text, *user.Currency)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("parsing amount of split error: %v", err)
+		util.BotSendMessage(bot, c.ChatId, locale.Get(message.GenericErrReplyMsg))
+		return conversation.End(), nil
+	}
+	remaining := total.Amount() - splitTotal(c.Data.Splits)
+	amount, ok := parseSplitAmount(msg.Text, total, *user.Currency)
+	if !ok || amount <= 0 || amount > remaining {
+		util.BotSendMessage(bot, c.ChatId, locale.Get(message.SplitAmountInvalidMsg, locale.FormatMoney(money.New(remaining, total.Currency().Code))))
+		return conversation.Stay(), nil
+	}
+
+	category, err := handler.findEntryCategory(ctx, c.Data.SplitCategoryId, user.Id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Get split category by id error: %v", err)
+		util.BotSendMessage(bot, c.ChatId, locale.Get(message.GenericErrReplyMsg))
+		return conversation.End(), nil
+	}
+	c.Data.Splits = append(c.Data.Splits, entrySplit{CategoryId: category.Id, CategoryName: category.Name, Amount: amount})
+	c.Data.SplitCategoryId = 0
+
+	if amount < remaining {
+		text, inlineKeyboard, err := handler.splitMenu(ctx, c.Id, c.Data, *user)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("splitMenu error: %v", err)
+			util.BotSendMessage(bot, c.ChatId, locale.Get(message.GenericErrReplyMsg))
+			return conversation.End(), nil
+		}
+		reply := tgbotapi.NewMessage(c.ChatId, text)
+		reply.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: inlineKeyboard}
+		util.BotSendWrapper(bot, reply)
+		return conversation.Goto(entrySplitCategoryState), nil
+	}
+
+	return handler.finishSplit(ctx, bot, c, *user), nil
+}
+
+// finishSplit files the amount whose parts add up to it under the category of its largest part, then asks for the
+// account when the user has accounts and did not hint one, or adds the amount
+func (handler CallbackHandler) finishSplit(ctx context.Context, bot *sender.Sender, c *conversation.Conversation[entryData], user domain.User) conversation.Transition {
+	locale := user.GetLocale()
+	largest := c.Data.Splits[0]
+	for _, s := range c.Data.Splits {
+		if s.Amount > largest.Amount {
+			largest = s
+		}
+	}
+	c.Data.CategoryId = largest.CategoryId
+
+	category, err := handler.findEntryCategory(ctx, c.Data.CategoryId, user.Id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Get split category by id error: %v", err)
+		util.BotSendMessage(bot, c.ChatId, locale.Get(message.GenericErrReplyMsg))
+		return conversation.End()
+	}
+
+	var account *domain.Account
+	if c.Data.AccountId != 0 {
+		hinted, err := handler.accountRepo.GetById(ctx, c.Data.AccountId, user.Id)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("Get hinted account by id error: %v", err)
+			util.BotSendMessage(bot, c.ChatId, locale.Get(message.GenericErrReplyMsg))
+			return conversation.End()
+		}
+		account = &hinted
+	} else {
+		accounts, err := handler.accountRepo.FindAllByUserId(ctx, user.Id)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("FindAllByUserId accounts error: %v", err)
+			util.BotSendMessage(bot, c.ChatId, locale.Get(message.GenericErrReplyMsg))
+			return conversation.End()
+		}
+		if len(accounts) > 0 {
+			inlineKeyboard, err := newAccountsKeyboard(accounts, c.Id, accountsInlineColSize, locale)
+			if err != nil {
+				log.Ctx(ctx).Error().Msgf("newAccountsKeyboard error: %v", err)
+				util.BotSendMessage(bot, c.ChatId, locale.Get(message.GenericErrReplyMsg))
+				return conversation.End()
+			}
+			msg := tgbotapi.NewMessage(c.ChatId, locale.Get(message.AccountSelectMsg))
+			msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: inlineKeyboard}
+			util.BotSendWrapper(bot, msg)
+			return conversation.Goto(entryAccountState)
+		}
+	}
+
+	text, err := handler.addTransaction(ctx, user, *category, account, c.Data.Text, c.Data.Splits, time.Now())
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("finishSplit error: %v", err)
+		util.BotSendMessage(bot, c.ChatId, locale.Get(message.GenericErrReplyMsg))
+		return conversation.End()
+	}
+	msg := tgbotapi.NewMessage(c.ChatId, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	util.BotSendWrapper(bot, msg)
+	return conversation.End()
+}
+
+// editKeepingMarkup replaces the text of the menu, keeping its buttons
+func editKeepingMarkup(bot *sender.Sender, menu *tgbotapi.Message, text string) {
+	if menu.ReplyMarkup == nil {
+		util.BotEditMessage(bot, menu.Chat.ID, menu.MessageID, text)
+		return
+	}
+	util.BotEditWrapper(bot, tgbotapi.NewEditMessageTextAndMarkup(menu.Chat.ID, menu.MessageID, text, *menu.ReplyMarkup))
+}
+
+// splitMenu returns the parts of the amount so far with the categories of the next part to choose from
+func (handler CallbackHandler) splitMenu(ctx context.Context, conversationId int, data entryData, user domain.User) (string, [][]tgbotapi.InlineKeyboardButton, error) {
+	locale := user.GetLocale()
+	text, total, err := splitProgress(data, user)
+	if err != nil {
+		return "", nil, err
+	}
+	categories, err := handler.categoryRepo.FindAllByUserId(ctx, user.Id)
+	if err != nil {
+		return "", nil, err
+	}
+	inlineKeyboard, err := newCategoriesKeyboard(categories, conversationId, categoriesInlineColSize, locale)
+	if err != nil {
+		return "", nil, err
+	}
+	remaining := money.New(total.Amount()-splitTotal(data.Splits), total.Currency().Code)
+	return text + locale.Get(message.SplitCategoryAskMsg, locale.FormatMoney(remaining)), inlineKeyboard, nil
+}
+
+// splitProgress returns the amount being split with its parts so far, and the amount
+func splitProgress(data entryData, user domain.User) (string, *money.Money, error) {
+	locale := user.GetLocale()
+	total, description, err := parseAmount(data.Text, *user.Currency)
+	if err != nil {
+		return "", nil, err
+	}
+	text := locale.Get(message.SplitHeaderMsg, locale.FormatMoney(total), description)
+	for _, s := range data.Splits {
+		text += locale.Get(message.SplitLineMsg, locale.CategoryName(s.CategoryName), locale.FormatMoney(money.New(s.Amount, total.Currency().Code)))
+	}
+	return text, total, nil
+}
+
+// splitTotal returns the amount of the parts so far
+func splitTotal(splits []entrySplit) int64 {
+	var sum int64
+	for _, s := range splits {
+		sum += s.Amount
+	}
+	return sum
+}
+
+// parseSplitAmount parses an amount such as 80, or a percentage of the total such as 40%, in the lowest denomination
+// of the currency
+func parseSplitAmount(s string, total *money.Money, currency money.Currency) (int64, bool) {
+	s = strings.TrimSpace(s)
+	if percent, found := strings.CutSuffix(s, "%"); found {
+		value, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+		if err != nil || value <= 0 || value > 100 {
+			return 0, false
+		}
+		return int64(math.Round(float64(total.Amount()) * value / 100)), true
+	}
+	amount, rest, err := parseAmount(s, currency)
+	if err != nil || rest != "" {
+		return 0, false
+	}
+	return amount.Amount(), true
+}
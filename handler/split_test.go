@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/conversation"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/enum"
+	"github.com/aattwwss/telegram-expense-bot/message"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// newSplitTestHandler returns a callback handler with the entry flow registered for a user without accounts, with
+// the expense categories Grocery (5) and Housing (6) and the income category Salary (9)
+func newSplitTestHandler(tr mockTransactionRepo) (CallbackHandler, *conversation.Manager, *memoryConversationStore) {
+	categories := []*entity.Category{
+		{Id: 5, Name: "Grocery", TransactionTypeId: 1},
+		{Id: 6, Name: "Housing", TransactionTypeId: 1},
+		{Id: 9, Name: "Salary", TransactionTypeId: 2},
+	}
+	ur := mockUserRepo{
+		findByIdFn: func(ctx context.Context, id int64) (*domain.User, error) {
+			return &domain.User{Id: id, Currency: money.GetCurrency("SGD"), Location: time.UTC, Locale: "en"}, nil
+		},
+	}
+	ttr := mockTransactionTypeRepo{
+		getByIdFn: func(ctx context.Context, id int) (*entity.TransactionType, error) {
+			return &entity.TransactionType{Id: id, ReplyText: "Spent %s on %s"}, nil
+		},
+	}
+	cr := mockCategoryRepo{
+		findAllByUserIdFn: func(ctx context.Context, userId int64) ([]*entity.Category, error) {
+			return categories, nil
+		},
+		getByIdFn: func(ctx context.Context, id int) (*entity.Category, error) {
+			for _, category := range categories {
+				if category.Id == id {
+					return category, nil
+				}
+			}
+			return nil, entity.ErrNotFound
+		},
+	}
+	ar := mockAccountRepo{
+		findAllByUserIdFn: func(ctx context.Context, userId int64) (domain.Accounts, error) {
+			return nil, nil
+		},
+	}
+	store := newMemoryConversationStore()
+	manager := conversation.NewManager(store)
+	handler := NewCallbackHandler(ur, tr, mockMessageContextRepo{}, ttr, cr, ar, nil, manager)
+	RegisterFlows(manager, handler, 15*time.Minute, 0)
+	return handler, manager, store
+}
+
+func splitCallbackQuery(conversationId int) *tgbotapi.CallbackQuery {
+	query := categoryCallbackQuery(conversationId, 0)
+	query.Data, _ = domain.EncodeCallback(domain.GenericCallback{
+		Callback: domain.Callback{Type: enum.Split, MessageContextId: conversationId},
+	})
+	return query
+}
+
+func splitReply(text string) *tgbotapi.Message {
+	return &tgbotapi.Message{MessageID: 10, Chat: &tgbotapi.Chat{ID: 3}, From: &tgbotapi.User{ID: 1}, Text: text}
+}
+
+func TestEntryFlow_Split(t *testing.T) {
+	var added domain.Transaction
+	tr := mockTransactionRepo{
+		addFn: func(ctx context.Context, tr domain.Transaction) (int, error) {
+			added = tr
+			return 1, nil
+		},
+	}
+	handler, manager, store := newSplitTestHandler(tr)
+	ctx := context.Background()
+	id, _ := conversation.Start(ctx, manager, entryFlow, 3, 1, entryData{Text: "120 NTUC", TypedAt: time.Now()})
+	bot, client := newRecordingSender()
+
+	handler.FromSplit(ctx, bot, splitCallbackQuery(id))
+	if !strings.Contains(client.requests[0], "Splitting+%24120.00+NTUC") {
+		t.Fatalf("expected the menu replaced with the categories of the first part, got %v", client.requests)
+	}
+
+	handler.FromCategory(ctx, bot, categoryCallbackQuery(id, 6))
+	if handled, err := manager.HandleText(ctx, bot, splitReply("1/3")); !handled || err != nil {
+		t.Fatalf("expected the reply taken by the split, got %v %v", handled, err)
+	}
+	if last := client.requests[len(client.requests)-1]; !strings.Contains(last, "up+to+%24120.00") {
+		t.Errorf("expected the amount asked again, got %v", last)
+	}
+	manager.HandleText(ctx, bot, splitReply("25%"))
+	if last := client.requests[len(client.requests)-1]; !strings.Contains(last, "Housing+%2430.00") || !strings.Contains(last, "%2490.00+left") {
+		t.Errorf("expected the parts so far with the categories of the next, got %v", last)
+	}
+
+	handler.FromCategory(ctx, bot, categoryCallbackQuery(id, 5))
+	manager.HandleText(ctx, bot, splitReply("90"))
+
+	if added.Amount.Amount() != 12000 || added.CategoryId != 5 || len(added.Splits) != 2 {
+		t.Fatalf("expected the amount split under its largest part, got %+v", added)
+	}
+	if added.Splits[0].CategoryId != 6 || added.Splits[0].Amount.Amount() != 3000 || added.Splits[1].CategoryId != 5 || added.Splits[1].Amount.Amount() != 9000 {
+		t.Errorf("unexpected parts %+v", added.Splits)
+	}
+	if len(store.conversations) != 0 {
+		t.Errorf("expected the conversation to end, got %v", store.conversations)
+	}
+	if last := client.requests[len(client.requests)-1]; !strings.Contains(last, "Housing%2C+Grocery") || !strings.Contains(last, "Grocery+%2490.00") {
+		t.Errorf("expected the reply to list the parts, got %v", last)
+	}
+	if last := client.requests[len(client.requests)-1]; !strings.Contains(last, "%3Ccode%3E%231%3C%2Fcode%3E") {
+		t.Errorf("expected the reply to end with the id of the transaction, got %v", last)
+	}
+}
+
+func TestEntryFlow_SplitOtherType(t *testing.T) {
+	handler, manager, store := newSplitTestHandler(mockTransactionRepo{})
+	ctx := context.Background()
+	id, _ := conversation.Start(ctx, manager, entryFlow, 3, 1, entryData{Text: "120 NTUC", TypedAt: time.Now()})
+	bot, client := newRecordingSender()
+	handler.FromSplit(ctx, bot, splitCallbackQuery(id))
+	handler.FromCategory(ctx, bot, categoryCallbackQuery(id, 5))
+	manager.HandleText(ctx, bot, splitReply("80"))
+	client.requests = nil
+
+	handler.FromCategory(ctx, bot, categoryCallbackQuery(id, 9))
+
+	if len(client.requests) == 0 || !strings.Contains(client.requests[0], "same+type+as+Grocery") {
+		t.Errorf("expected a part of another type refused, got %v", client.requests)
+	}
+	if store.conversations[id].State != string(entrySplitCategoryState) {
+		t.Errorf("expected the category to be asked again, got %v", store.conversations[id].State)
+	}
+}
+
+func TestEntryFlow_SplitNotPositive(t *testing.T) {
+	handler, manager, store := newSplitTestHandler(mockTransactionRepo{})
+	ctx := context.Background()
+	id, _ := conversation.Start(ctx, manager, entryFlow, 3, 1, entryData{Text: "-120 correction", TypedAt: time.Now()})
+	bot, client := newRecordingSender()
+	query := splitCallbackQuery(id)
+	query.Message.ReplyMarkup = &tgbotapi.InlineKeyboardMarkup{}
+
+	handler.FromSplit(ctx, bot, query)
+
+	if len(client.requests) == 0 || !strings.Contains(client.requests[0], "more+than+0+can+be+split") || !strings.Contains(client.requests[0], "reply_markup") {
+		t.Errorf("expected the split refused keeping the categories, got %v", client.requests)
+	}
+	if store.conversations[id].State != string(entryCategoryState) {
+		t.Errorf("expected a category to be asked again, got %v", store.conversations[id].State)
+	}
+}
+
+func TestNewEntryCategoriesKeyboard(t *testing.T) {
+	categories := []*entity.Category{{Id: 5, Name: "Grocery"}, {Id: 6, Name: "Housing"}}
+	kb, err := newEntryCategoriesKeyboard(categories, 42, 2, money.New(12000, "SGD"), message.GetLocale("en"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(kb) != 3 || kb[1][0].Text != "✂️ Split" || kb[2][0].Text != "Cancel" {
+		t.Errorf("expected the split button above the cancel button, got %+v", kb)
+	}
+
+	for _, amount := range []int64{0, -12000} {
+		kb, err = newEntryCategoriesKeyboard(categories, 42, 2, money.New(amount, "SGD"), message.GetLocale("en"))
+		if err != nil || len(kb) != 2 || kb[1][0].Text != "Cancel" {
+			t.Errorf("expected no split button for %d, got %+v, %v", amount, kb, err)
+		}
+	}
+}
+
+func TestParseSplitAmount(t *testing.T) {
+	total := money.New(12000, "SGD")
+	tests := []struct {
+		s      string
+		want   int64
+		wantOk bool
+	}{
+		{"80", 8000, true},
+		{" 40.5 ", 4050, true},
+		{"40%", 4800, true},
+		{"33.3 %", 3996, true},
+		{"0%", 0, false},
+		{"120%", 0, false},
+		{"80 grocery", 0, false},
+		{"abc", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, ok := parseSplitAmount(tt.s, total, *money.GetCurrency("SGD"))
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("parseSplitAmount(%q) = %d %v, want %d %v", tt.s, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
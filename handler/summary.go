@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	"github.com/aattwwss/telegram-expense-bot/util"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// Summary shows the breakdown of the month so far as /stats does, followed by the progress of the savings goals of the
+// user when there are any
+func (handler CommandHandler) Summary(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	user := userFromContext(ctx)
+	locale := user.GetLocale()
+	chatId := update.Message.Chat.ID
+	now := time.Now().In(user.Location)
+
+	period := util.MonthPeriod(now.Month(), now.Year(), user.Location)
+	breakdowns, total, err := handler.transactionRepo.GetTransactionBreakdownByCategory(ctx, period.From, period.To, *user)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("Error getting breakdowns: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	goals, err := handler.goalRepo.FindAllByUserId(ctx, user.Id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("FindAllByUserId goals error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+
+	text := fmt.Sprintf(statsHeaderHTMLMsg, period.Label(locale), locale.FormatMoney(total)) + breakdowns.GetFormattedHTMLMsg(locale)
+	if len(goals) > 0 {
+		text += "\n" + locale.Get(message.GoalListHeader) + goals.GetFormattedHTMLMsg(locale, now)
+	}
+	msg := tgbotapi.NewMessage(chatId, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	util.BotSendWrapper(bot, msg)
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+)
+
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		name      string
+		goals     domain.Goals
+		wantGoals bool
+	}{
+		{"with goals", domain.Goals{{Id: 3, Name: "Japan trip", Target: money.New(300000, money.SGD), Saved: money.New(75000, money.SGD), MonthlyAmount: money.New(0, money.SGD), CreateTime: time.Now()}}, true},
+		{"without goals", domain.Goals{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var from time.Time
+			tr := mockTransactionRepo{
+				getTransactionBreakdownByCatFn: func(ctx context.Context, dateFrom time.Time, dateTo time.Time, user domain.User) (domain.Breakdowns, *money.Money, error) {
+					from = dateFrom
+					return domain.Breakdowns{{CategoryName: "Food", Amount: money.New(5000, money.SGD), Percent: 100}}, money.New(5000, money.SGD), nil
+				},
+			}
+			gr := mockGoalRepo{
+				findAllByUserIdFn: func(ctx context.Context, userId int64) (domain.Goals, error) {
+					return tt.goals, nil
+				},
+			}
+			handler := CommandHandler{transactionRepo: tr, goalRepo: gr}
+			bot, client := newRecordingSender()
+
+			handler.Summary(accountsTestContext(), bot, commandUpdate("/summary"))
+
+			now := time.Now().UTC()
+			if want := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC); !from.Equal(want) {
+				t.Errorf("expected the breakdown of the month from %v, got %v", want, from)
+			}
+			if len(client.requests) != 1 || !strings.Contains(client.requests[0], "Food") {
+				t.Fatalf("expected the breakdown of the month, got %v", client.requests)
+			}
+			if got := strings.Contains(client.requests[0], "Japan+trip") && strings.Contains(client.requests[0], "25%25"); got != tt.wantGoals {
+				t.Errorf("expected goals shown %v, got %v", tt.wantGoals, client.requests)
+			}
+		})
+	}
+}
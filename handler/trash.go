@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	"github.com/aattwwss/telegram-expense-bot/util"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	trashListLimit     = 10
+	trashInlineColSize = 5
+)
+
+// Trash lists the transactions deleted most recently, each with a button that restores it
+func (handler CommandHandler) Trash(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	user := userFromContext(ctx)
+	locale := user.GetLocale()
+	chatId := update.Message.Chat.ID
+
+	transactions, err := handler.transactionRepo.FindDeleted(ctx, user.Id, trashListLimit)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("FindDeleted error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	if len(transactions) == 0 {
+		util.BotSendMessage(bot, chatId, locale.Get(message.TrashEmptyMsg))
+		return
+	}
+
+	text, inlineKeyboard, err := trashMessage(transactions, *user)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("NewTrashKeyboard error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	msg := tgbotapi.NewMessage(chatId, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: inlineKeyboard}
+	util.BotSendWrapper(bot, msg)
+}
+
+// Redo restores the transaction deleted most recently, so repeating it restores the ones undone before in turn
+func (handler CommandHandler) Redo(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	user := userFromContext(ctx)
+	locale := user.GetLocale()
+	chatId := update.Message.Chat.ID
+
+	transaction, err := handler.transactionRepo.RestoreLatest(ctx, user.Id)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("RestoreLatest error: %v", err)
+		util.BotSendMessage(bot, chatId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	if transaction == nil {
+		util.BotSendMessage(bot, chatId, locale.Get(message.RedoNothingMsg))
+		return
+	}
+	util.BotSendMessage(bot, chatId, locale.Get(message.TransactionRestoredReplyMsg, locale.FormatMoney(transaction.Amount), transaction.Description))
+}
+
+// FromRestore restores the transaction tapped in the trash, and lists the rest of the trash in its place
+func (handler CallbackHandler) FromRestore(ctx context.Context, bot *sender.Sender, callbackQuery *tgbotapi.CallbackQuery) {
+	answer := util.NewCallbackAnswer(bot, callbackQuery.ID)
+	defer answer.Send()
+
+	chatId, messageId := callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID
+	locale := clientLocale(callbackQuery.From)
+	user, err := handler.userRepo.FindUserById(ctx, callbackQuery.From.ID)
+	if err != nil || user == nil {
+		log.Ctx(ctx).Error().Msgf("FromRestore cannot find user error: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	locale = user.GetLocale()
+
+	var restoreCallback domain.RestoreCallback
+	err = domain.DecodeCallback(callbackQuery.Data, &restoreCallback)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("FromRestore unmarshall error: %v", err)
+		util.BotRemoveKeyboard(bot, chatId, messageId)
+		return
+	}
+
+	transaction, err := handler.transactionRepo.Restore(ctx, restoreCallback.TransactionId, user.Id)
+	if errors.Is(err, entity.ErrNotFound) {
+		answer.Alert(locale.Get(message.TransactionNotInTrashMsg))
+	} else if err != nil {
+		log.Ctx(ctx).Error().Msgf("Restore transaction error: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return
+	} else {
+		answer.Alert(locale.Get(message.TransactionRestoredReplyMsg, locale.FormatMoney(transaction.Amount), transaction.Description))
+	}
+
+	transactions, err := handler.transactionRepo.FindDeleted(ctx, user.Id, trashListLimit)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("FindDeleted error: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	if len(transactions) == 0 {
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.TrashEmptyMsg))
+		return
+	}
+
+	text, inlineKeyboard, err := trashMessage(transactions, *user)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("NewTrashKeyboard error: %v", err)
+		util.BotEditMessage(bot, chatId, messageId, locale.Get(message.GenericErrReplyMsg))
+		return
+	}
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatId, messageId, text, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: inlineKeyboard})
+	edit.ParseMode = tgbotapi.ModeHTML
+	util.BotEditWrapper(bot, edit)
+}
+
+// trashMessage returns the list of the transactions in the trash with the buttons that restore them
+func trashMessage(transactions domain.Transactions, user domain.User) (string, [][]tgbotapi.InlineKeyboardButton, error) {
+	locale := user.GetLocale()
+	inlineKeyboard, err := util.NewTrashKeyboard(transactions, trashInlineColSize, locale)
+	if err != nil {
+		return "", nil, err
+	}
+	text := locale.Get(message.TrashHeader) + transactions.GetFormattedTrashHTMLMsg(user.Location, locale) + locale.Get(message.TrashFooterMsg, domain.TrashRetentionDays)
+	return text, inlineKeyboard, nil
+}
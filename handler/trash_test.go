@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/enum"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func trashTestTransactions() domain.Transactions {
+	dt := time.Date(2024, 6, 3, 12, 30, 0, 0, time.UTC)
+	return domain.Transactions{
+		{Id: 7, Datetime: dt, CategoryName: "Food", Description: "Chicken Rice", Amount: money.New(550, money.SGD)},
+		{Id: 5, Datetime: dt, CategoryName: "Transport", Description: "Taxi", Amount: money.New(1200, money.SGD)},
+	}
+}
+
+func TestTrash(t *testing.T) {
+	tr := mockTransactionRepo{
+		findDeletedFn: func(ctx context.Context, userId int64, limit int) (domain.Transactions, error) {
+			return trashTestTransactions(), nil
+		},
+	}
+	handler := CommandHandler{transactionRepo: tr}
+	bot, client := newRecordingSender()
+
+	handler.Trash(accountsTestContext(), bot, commandUpdate("/trash"))
+
+	if len(client.requests) != 1 || !strings.Contains(client.requests[0], "Chicken+Rice") || !strings.Contains(client.requests[0], "Taxi") || !strings.Contains(client.requests[0], "reply_markup") {
+		t.Errorf("expected the trash with restore buttons, got %v", client.requests)
+	}
+}
+
+func TestRedo(t *testing.T) {
+	tr := mockTransactionRepo{
+		restoreLatestFn: func(ctx context.Context, userId int64) (*domain.Transaction, error) {
+			return &trashTestTransactions()[0], nil
+		},
+	}
+	handler := CommandHandler{transactionRepo: tr}
+	bot, client := newRecordingSender()
+
+	handler.Redo(accountsTestContext(), bot, commandUpdate("/redo"))
+
+	if len(client.requests) != 1 || !strings.Contains(client.requests[0], "Chicken+Rice+has+been+restored") {
+		t.Errorf("expected the transaction restored, got %v", client.requests)
+	}
+}
+
+func TestRedo_EmptyTrash(t *testing.T) {
+	tr := mockTransactionRepo{
+		restoreLatestFn: func(ctx context.Context, userId int64) (*domain.Transaction, error) {
+			return nil, nil
+		},
+	}
+	handler := CommandHandler{transactionRepo: tr}
+	bot, client := newRecordingSender()
+
+	handler.Redo(accountsTestContext(), bot, commandUpdate("/redo"))
+
+	if len(client.requests) != 1 || !strings.Contains(client.requests[0], "no+deleted+transaction") {
+		t.Errorf("expected nothing to restore, got %v", client.requests)
+	}
+}
+
+func TestFromRestore(t *testing.T) {
+	trash := trashTestTransactions()
+	tests := []struct {
+		name       string
+		restoreErr error
+		wantAnswer string
+	}{
+		{"restored", nil, "has+been+restored"},
+		{"no longer in the trash", entity.ErrNotFound, "no+longer+in+the+trash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var restored int
+			tr := mockTransactionRepo{
+				restoreFn: func(ctx context.Context, id int, userId int64) (domain.Transaction, error) {
+					restored = id
+					return trash[0], tt.restoreErr
+				},
+				findDeletedFn: func(ctx context.Context, userId int64, limit int) (domain.Transactions, error) {
+					return trash[1:], nil
+				},
+			}
+			ur := mockUserRepo{
+				findByIdFn: func(ctx context.Context, id int64) (*domain.User, error) {
+					return &domain.User{Id: id, Currency: money.GetCurrency("SGD"), Location: time.UTC, Locale: "en"}, nil
+				},
+			}
+			handler := NewCallbackHandler(ur, tr, mockMessageContextRepo{}, mockTransactionTypeRepo{}, mockCategoryRepo{}, nil, nil, nil)
+			data, _ := domain.EncodeCallback(domain.RestoreCallback{Callback: domain.Callback{Type: enum.Restore}, TransactionId: 7})
+			bot, client := newRecordingSender()
+
+			handler.FromRestore(context.Background(), bot, &tgbotapi.CallbackQuery{
+				ID:      "q1",
+				From:    &tgbotapi.User{ID: 1},
+				Message: &tgbotapi.Message{MessageID: 2, Chat: &tgbotapi.Chat{ID: 3}},
+				Data:    data,
+			})
+
+			if restored != 7 {
+				t.Errorf("expected the transaction 7 restored, got %d", restored)
+			}
+			if len(client.requests) != 2 || !strings.HasPrefix(client.requests[0], "editMessageText") ||
+				strings.Contains(client.requests[0], "Chicken+Rice") || !strings.Contains(client.requests[0], "Taxi") {
+				t.Fatalf("expected the rest of the trash edited in place, got %v", client.requests)
+			}
+			if !strings.HasPrefix(client.requests[1], "answerCallbackQuery") || !strings.Contains(client.requests[1], tt.wantAnswer) {
+				t.Errorf("expected the callback answered with %s, got %v", tt.wantAnswer, client.requests[1])
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/audit"
+	"github.com/aattwwss/telegram-expense-bot/util"
+	"github.com/aattwwss/telegram-expense-bot/webapp"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	WebAppPrefix = "/app/"
+
+	webAppInitDataMaxAge = 24 * time.Hour
+)
+
+// WebAppHandler serves the mini app and the same endpoints as the REST API, authenticated with the init data telegram
+// passes to the mini app instead of a personal access token
+type WebAppHandler struct {
+	api      ApiHandler
+	userRepo UserRepo
+	botToken string
+}
+
+func NewWebAppHandler(api ApiHandler, userRepo UserRepo, botToken string) WebAppHandler {
+	return WebAppHandler{
+		api:      api,
+		userRepo: userRepo,
+		botToken: botToken,
+	}
+}
+
+// Routes returns the handler of the mini app and its endpoints under WebAppPrefix
+func (handler WebAppHandler) Routes() http.Handler {
+	static, err := fs.Sub(webapp.Static, "static")
+	if err != nil {
+		panic(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(WebAppPrefix, http.StripPrefix(WebAppPrefix, http.FileServerFS(static)))
+	handler.api.register(mux, WebAppPrefix+"api/", handler.authenticate)
+	return mux
+}
+
+// authenticate resolves the user from the "tma <init data>" authorization header before calling next
+func (handler WebAppHandler) authenticate(next apiHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		initData, found := strings.CutPrefix(r.Header.Get("Authorization"), "tma ")
+		if !found || strings.TrimSpace(initData) == "" {
+			writeApiError(w, http.StatusUnauthorized, "missing init data")
+			return
+		}
+
+		webAppUser, err := util.ValidateWebAppInitData(strings.TrimSpace(initData), handler.botToken, webAppInitDataMaxAge, time.Now())
+		if errors.Is(err, util.ErrInvalidInitData) {
+			writeApiError(w, http.StatusUnauthorized, "invalid init data")
+			return
+		}
+		if err != nil {
+			log.Ctx(r.Context()).Error().Msgf("Error validating web app init data: %v", err)
+			writeApiError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		user, err := handler.userRepo.FindUserById(r.Context(), webAppUser.Id)
+		if err != nil {
+			log.Ctx(r.Context()).Error().Msgf("Error finding user for web app: %v", err)
+			writeApiError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		if user == nil {
+			writeApiError(w, http.StatusForbidden, "send /start to the bot first")
+			return
+		}
+
+		next(w, r.WithContext(audit.WithSource(r.Context(), audit.SourceApp)), *user)
+	}
+}
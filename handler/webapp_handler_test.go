@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/util"
+)
+
+const testWebAppBotToken = "123456:ABC-DEF"
+
+func newTestWebAppHandler(tr mockTransactionRepo) http.Handler {
+	ur := mockUserRepo{
+		findByIdFn: func(ctx context.Context, id int64) (*domain.User, error) {
+			if id != 7 {
+				return nil, nil
+			}
+			return &domain.User{Id: id, Locale: "en", Currency: money.GetCurrency("SGD"), Location: time.UTC}, nil
+		},
+	}
+	api := NewApiHandler(ur, tr, mockCategoryRepo{}, mockApiTokenRepo{})
+	return NewWebAppHandler(api, ur, testWebAppBotToken).Routes()
+}
+
+func testInitData(userId int64, botToken string) string {
+	values := url.Values{}
+	values.Set("user", `{"id":`+strconv.FormatInt(userId, 10)+`,"first_name":"Alice"}`)
+	values.Set("auth_date", strconv.FormatInt(time.Now().Unix(), 10))
+	values.Set("hash", util.SignWebAppInitData(values, botToken))
+	return values.Encode()
+}
+
+func doWebAppRequest(h http.Handler, target, initData string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if initData != "" {
+		req.Header.Set("Authorization", "tma "+initData)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestWebApp_ServesStaticFiles(t *testing.T) {
+	h := newTestWebAppHandler(mockTransactionRepo{})
+
+	for _, target := range []string{"/app/", "/app/app.js", "/app/style.css"} {
+		rec := doWebAppRequest(h, target, "")
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected 200 for %s, got %d", target, rec.Code)
+		}
+	}
+	if rec := doWebAppRequest(h, "/app/", ""); !strings.Contains(rec.Body.String(), "telegram-web-app.js") {
+		t.Errorf("expected the mini app page")
+	}
+}
+
+func TestWebApp_Authenticate(t *testing.T) {
+	tr := mockTransactionRepo{
+		listFn: func(ctx context.Context, filter entity.TransactionFilter) (domain.Transactions, int, error) {
+			return domain.Transactions{testTransaction(1, filter.UserId)}, 1, nil
+		},
+	}
+	h := newTestWebAppHandler(tr)
+
+	tests := []struct {
+		name     string
+		initData string
+		wantCode int
+	}{
+		{"valid", testInitData(7, testWebAppBotToken), http.StatusOK},
+		{"missing", "", http.StatusUnauthorized},
+		{"signed with another bot", testInitData(7, "654321:XYZ"), http.StatusUnauthorized},
+		{"not registered", testInitData(8, testWebAppBotToken), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doWebAppRequest(h, "/app/api/transactions", tt.initData)
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestWebApp_BearerTokenIsNotAccepted(t *testing.T) {
+	h := newTestWebAppHandler(mockTransactionRepo{})
+
+	req := httptest.NewRequest(http.MethodGet, "/app/api/transactions", nil)
+	req.Header.Set("Authorization", "Bearer "+testApiToken)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
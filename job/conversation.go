@@ -0,0 +1,53 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/audit"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	"github.com/rs/zerolog/log"
+)
+
+const ConversationInterval = time.Minute
+
+type ConversationManager interface {
+	Expire(ctx context.Context, bot *sender.Sender, now time.Time) (int, error)
+}
+
+// ConversationJob times out the conversations the users did not finish, such as adding an amount under the default
+// category when none was chosen
+type ConversationJob struct {
+	conversations ConversationManager
+}
+
+func NewConversationJob(conversations ConversationManager) ConversationJob {
+	return ConversationJob{conversations: conversations}
+}
+
+// Start runs the job on every interval until the context is cancelled
+func (job ConversationJob) Start(ctx context.Context, bot *sender.Sender, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			job.Run(ctx, bot, now)
+		}
+	}
+}
+
+// Run times out the conversations that have expired at now, so the changes made by their timeouts are made by the job
+func (job ConversationJob) Run(ctx context.Context, bot *sender.Sender, now time.Time) {
+	ctx = audit.WithJob(ctx, "conversation")
+	expired, err := job.conversations.Expire(ctx, bot, now)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("ConversationJob Expire error: %v", err)
+		return
+	}
+	if expired > 0 {
+		log.Ctx(ctx).Info().Msgf("Timed out %d conversations", expired)
+	}
+}
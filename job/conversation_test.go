@@ -0,0 +1,40 @@
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/audit"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+)
+
+type mockConversationManager struct {
+	expireFn func(ctx context.Context, bot *sender.Sender, now time.Time) (int, error)
+}
+
+func (m mockConversationManager) Expire(ctx context.Context, bot *sender.Sender, now time.Time) (int, error) {
+	return m.expireFn(ctx, bot, now)
+}
+
+func TestConversationJobRun(t *testing.T) {
+	now := time.Date(2024, 6, 15, 21, 30, 0, 0, time.UTC)
+	var expiredAt time.Time
+	var origin audit.Origin
+	cm := mockConversationManager{
+		expireFn: func(ctx context.Context, bot *sender.Sender, gotNow time.Time) (int, error) {
+			expiredAt = gotNow
+			origin = audit.FromContext(ctx)
+			return 2, nil
+		},
+	}
+
+	NewConversationJob(cm).Run(context.Background(), nil, now)
+
+	if !expiredAt.Equal(now) {
+		t.Errorf("expected conversations expired at %v, got %v", now, expiredAt)
+	}
+	if origin.Actor != "job:conversation" || origin.Source != audit.SourceJob {
+		t.Errorf("expected the timeouts made by the conversation job, got %+v", origin)
+	}
+}
@@ -0,0 +1,64 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	"github.com/aattwwss/telegram-expense-bot/util"
+	"github.com/rs/zerolog/log"
+)
+
+const GoalInterval = time.Hour
+
+type GoalRepo interface {
+	ContributeMonthly(ctx context.Context, now time.Time) (domain.Goals, error)
+}
+
+type UserRepo interface {
+	FindUserById(ctx context.Context, id int64) (*domain.User, error)
+}
+
+// GoalJob puts the monthly amount of the goals towards them, and celebrates the goals it reaches
+type GoalJob struct {
+	goalRepo GoalRepo
+	userRepo UserRepo
+}
+
+func NewGoalJob(goalRepo GoalRepo, userRepo UserRepo) GoalJob {
+	return GoalJob{goalRepo: goalRepo, userRepo: userRepo}
+}
+
+// Start runs the job on every interval until the context is cancelled
+func (job GoalJob) Start(ctx context.Context, bot *sender.Sender, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			job.Run(ctx, bot, now)
+		}
+	}
+}
+
+// Run adds the monthly contributions due at now
+func (job GoalJob) Run(ctx context.Context, bot *sender.Sender, now time.Time) {
+	reached, err := job.goalRepo.ContributeMonthly(ctx, now)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("GoalJob ContributeMonthly error: %v", err)
+	}
+
+	for _, goal := range reached {
+		user, err := job.userRepo.FindUserById(ctx, goal.UserId)
+		if err != nil || user == nil {
+			log.Ctx(ctx).Error().Msgf("GoalJob FindUserById %d error: %v", goal.UserId, err)
+			continue
+		}
+		locale := user.GetLocale()
+		util.BotSendMessage(bot, goal.ChatId, locale.Get(message.GoalReachedMsg, goal.Name, locale.FormatMoney(goal.Target)))
+	}
+}
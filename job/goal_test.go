@@ -0,0 +1,80 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type mockGoalRepo struct {
+	contributeMonthlyFn func(ctx context.Context, now time.Time) (domain.Goals, error)
+}
+
+func (m mockGoalRepo) ContributeMonthly(ctx context.Context, now time.Time) (domain.Goals, error) {
+	return m.contributeMonthlyFn(ctx, now)
+}
+
+type mockUserRepo struct {
+	findByIdFn func(ctx context.Context, id int64) (*domain.User, error)
+}
+
+func (m mockUserRepo) FindUserById(ctx context.Context, id int64) (*domain.User, error) {
+	return m.findByIdFn(ctx, id)
+}
+
+type recordingClient struct {
+	requests []string
+}
+
+func (c *recordingClient) Do(req *http.Request) (*http.Response, error) {
+	body, _ := io.ReadAll(req.Body)
+	c.requests = append(c.requests, string(body))
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"ok":true,"result":{}}`))}, nil
+}
+
+func TestGoalJobRun(t *testing.T) {
+	now := time.Date(2024, 7, 1, 0, 30, 0, 0, time.UTC)
+	var contributedAt time.Time
+	gr := mockGoalRepo{
+		contributeMonthlyFn: func(ctx context.Context, gotNow time.Time) (domain.Goals, error) {
+			contributedAt = gotNow
+			return domain.Goals{{UserId: 1, ChatId: 456, Name: "Japan trip", Target: money.New(300000, money.SGD)}}, nil
+		},
+	}
+	ur := mockUserRepo{
+		findByIdFn: func(ctx context.Context, id int64) (*domain.User, error) {
+			return &domain.User{Id: id, Locale: "en"}, nil
+		},
+	}
+	client := &recordingClient{}
+	bot := &tgbotapi.BotAPI{Token: "dummy", Client: client}
+	bot.SetAPIEndpoint(tgbotapi.APIEndpoint)
+
+	NewGoalJob(gr, ur).Run(context.Background(), sender.New(bot, sender.Options{GlobalRate: 1000, GlobalBurst: 1000, ChatRate: 1000, ChatBurst: 1000}), now)
+
+	if !contributedAt.Equal(now) {
+		t.Errorf("expected the contributions due at %v, got %v", now, contributedAt)
+	}
+	if len(client.requests) != 1 || !strings.Contains(client.requests[0], "chat_id=456") || !strings.Contains(client.requests[0], "Japan+trip") {
+		t.Errorf("expected the goal to be celebrated, got %v", client.requests)
+	}
+}
+
+func TestGoalJobRun_Error(t *testing.T) {
+	gr := mockGoalRepo{
+		contributeMonthlyFn: func(ctx context.Context, now time.Time) (domain.Goals, error) {
+			return nil, errors.New("db down")
+		},
+	}
+
+	NewGoalJob(gr, mockUserRepo{}).Run(context.Background(), nil, time.Now())
+}
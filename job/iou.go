@@ -0,0 +1,76 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	"github.com/aattwwss/telegram-expense-bot/util"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const IouInterval = time.Hour
+
+type IouRepo interface {
+	FindDueReminders(ctx context.Context, now time.Time) ([]domain.IouReminder, error)
+	GetBalances(ctx context.Context, userId int64) (domain.IouBalances, error)
+	MarkReminded(ctx context.Context, userId int64, now time.Time) error
+}
+
+// IouJob reminds the users who asked for it of the debts outstanding for longer than their number of days
+type IouJob struct {
+	iouRepo IouRepo
+}
+
+func NewIouJob(iouRepo IouRepo) IouJob {
+	return IouJob{iouRepo: iouRepo}
+}
+
+// Start runs the job on every interval until the context is cancelled
+func (job IouJob) Start(ctx context.Context, bot *sender.Sender, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			job.Run(ctx, bot, now)
+		}
+	}
+}
+
+// Run sends the reminders due at now. A reminder with no old debt is not marked as sent, so it is sent as soon as a
+// debt gets old enough.
+func (job IouJob) Run(ctx context.Context, bot *sender.Sender, now time.Time) {
+	reminders, err := job.iouRepo.FindDueReminders(ctx, now)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("IouJob FindDueReminders error: %v", err)
+		return
+	}
+
+	for _, reminder := range reminders {
+		balances, err := job.iouRepo.GetBalances(ctx, reminder.UserId)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("IouJob GetBalances %d error: %v", reminder.UserId, err)
+			continue
+		}
+		old := balances.OpenSince(now.AddDate(0, 0, -reminder.Days))
+		if len(old) == 0 {
+			continue
+		}
+
+		locale := reminder.GetLocale()
+		msg := tgbotapi.NewMessage(reminder.ChatId, locale.Get(message.IouReminderMsg, reminder.Days)+old.GetFormattedHTMLMsg(locale, reminder.Location))
+		msg.ParseMode = tgbotapi.ModeHTML
+		util.BotSendWrapper(bot, msg)
+
+		err = job.iouRepo.MarkReminded(ctx, reminder.UserId, now)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("IouJob MarkReminded %d error: %v", reminder.UserId, err)
+		}
+	}
+}
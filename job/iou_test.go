@@ -0,0 +1,70 @@
+package job
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type mockIouRepo struct {
+	findDueRemindersFn func(ctx context.Context, now time.Time) ([]domain.IouReminder, error)
+	getBalancesFn      func(ctx context.Context, userId int64) (domain.IouBalances, error)
+	markRemindedFn     func(ctx context.Context, userId int64, now time.Time) error
+}
+
+func (m mockIouRepo) FindDueReminders(ctx context.Context, now time.Time) ([]domain.IouReminder, error) {
+	return m.findDueRemindersFn(ctx, now)
+}
+
+func (m mockIouRepo) GetBalances(ctx context.Context, userId int64) (domain.IouBalances, error) {
+	return m.getBalancesFn(ctx, userId)
+}
+
+func (m mockIouRepo) MarkReminded(ctx context.Context, userId int64, now time.Time) error {
+	return m.markRemindedFn(ctx, userId, now)
+}
+
+func TestIouJobRun(t *testing.T) {
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	var reminded []int64
+	ir := mockIouRepo{
+		findDueRemindersFn: func(ctx context.Context, now time.Time) ([]domain.IouReminder, error) {
+			return []domain.IouReminder{
+				{UserId: 1, ChatId: 456, Days: 30, Location: time.UTC, Locale: "en"},
+				{UserId: 2, ChatId: 789, Days: 30, Location: time.UTC, Locale: "en"},
+			}, nil
+		},
+		getBalancesFn: func(ctx context.Context, userId int64) (domain.IouBalances, error) {
+			if userId == 2 {
+				// outstanding for less than 30 days
+				return domain.IouBalances{{Person: "Carol", Balance: money.New(1000, money.SGD), OpenSince: now.AddDate(0, 0, -5)}}, nil
+			}
+			return domain.IouBalances{
+				{Person: "Alice", Balance: money.New(5000, money.SGD), OpenSince: now.AddDate(0, 0, -40)},
+				{Person: "Bob", Balance: money.New(-2000, money.SGD), OpenSince: now.AddDate(0, 0, -5)},
+			}, nil
+		},
+		markRemindedFn: func(ctx context.Context, userId int64, now time.Time) error {
+			reminded = append(reminded, userId)
+			return nil
+		},
+	}
+	client := &recordingClient{}
+	bot := &tgbotapi.BotAPI{Token: "dummy", Client: client}
+	bot.SetAPIEndpoint(tgbotapi.APIEndpoint)
+
+	NewIouJob(ir).Run(context.Background(), sender.New(bot, sender.Options{GlobalRate: 1000, GlobalBurst: 1000, ChatRate: 1000, ChatBurst: 1000}), now)
+
+	if len(client.requests) != 1 || !strings.Contains(client.requests[0], "chat_id=456") || !strings.Contains(client.requests[0], "Alice") || strings.Contains(client.requests[0], "Bob") {
+		t.Errorf("expected a reminder of the debt of Alice only, got %v", client.requests)
+	}
+	if len(reminded) != 1 || reminded[0] != 1 {
+		t.Errorf("expected only the reminder sent to be marked, got %v", reminded)
+	}
+}
@@ -0,0 +1,50 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	"github.com/rs/zerolog/log"
+)
+
+const MessageContextInterval = time.Minute
+
+type MessageContextRepo interface {
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// MessageContextJob purges the expired message contexts
+type MessageContextJob struct {
+	messageContextRepo MessageContextRepo
+}
+
+func NewMessageContextJob(messageContextRepo MessageContextRepo) MessageContextJob {
+	return MessageContextJob{messageContextRepo: messageContextRepo}
+}
+
+// Start runs the job on every interval until the context is cancelled
+func (job MessageContextJob) Start(ctx context.Context, bot *sender.Sender, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			job.Run(ctx, bot, now)
+		}
+	}
+}
+
+// Run deletes the message contexts that have expired at now
+func (job MessageContextJob) Run(ctx context.Context, bot *sender.Sender, now time.Time) {
+	deleted, err := job.messageContextRepo.DeleteExpired(ctx, now)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("MessageContextJob DeleteExpired error: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Ctx(ctx).Info().Msgf("Deleted %d expired message contexts", deleted)
+	}
+}
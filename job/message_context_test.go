@@ -0,0 +1,43 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type mockMessageContextRepo struct {
+	deleteExpiredFn func(ctx context.Context, now time.Time) (int64, error)
+}
+
+func (m mockMessageContextRepo) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return m.deleteExpiredFn(ctx, now)
+}
+
+func TestMessageContextJobRun(t *testing.T) {
+	now := time.Date(2024, 6, 15, 21, 30, 0, 0, time.UTC)
+	var deletedAt time.Time
+	mr := mockMessageContextRepo{
+		deleteExpiredFn: func(ctx context.Context, gotNow time.Time) (int64, error) {
+			deletedAt = gotNow
+			return 3, nil
+		},
+	}
+
+	NewMessageContextJob(mr).Run(context.Background(), nil, now)
+
+	if !deletedAt.Equal(now) {
+		t.Errorf("expected expired contexts deleted at %v, got %v", now, deletedAt)
+	}
+}
+
+func TestMessageContextJobRun_Error(t *testing.T) {
+	mr := mockMessageContextRepo{
+		deleteExpiredFn: func(ctx context.Context, now time.Time) (int64, error) {
+			return 0, errors.New("db down")
+		},
+	}
+
+	NewMessageContextJob(mr).Run(context.Background(), nil, time.Now())
+}
@@ -0,0 +1,110 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/logging"
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	"github.com/aattwwss/telegram-expense-bot/util"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	ReminderInterval = time.Minute
+
+	reminderSnoozeMinutes = 60
+	reminderInlineColSize = 2
+	reminderTimeout       = 10 * time.Second
+)
+
+type ReminderRepo interface {
+	FindAllEnabled(ctx context.Context) ([]domain.Reminder, error)
+	MarkSent(ctx context.Context, userId int64, localDate time.Time) error
+}
+
+type TransactionRepo interface {
+	CountByDateRange(ctx context.Context, userId int64, dateFrom time.Time, dateTo time.Time) (int, error)
+}
+
+// ReminderJob nudges users who have not logged any transaction on the current day
+type ReminderJob struct {
+	reminderRepo    ReminderRepo
+	transactionRepo TransactionRepo
+}
+
+func NewReminderJob(reminderRepo ReminderRepo, transactionRepo TransactionRepo) ReminderJob {
+	return ReminderJob{
+		reminderRepo:    reminderRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+// Start runs the job on every interval until the context is cancelled
+func (job ReminderJob) Start(ctx context.Context, bot *sender.Sender, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			job.Run(ctx, bot, now)
+		}
+	}
+}
+
+// Run sends the reminders due at now. Once the context is cancelled no other reminder is started, but the reminder in
+// progress is still finished so it is never sent without being marked as sent.
+func (job ReminderJob) Run(ctx context.Context, bot *sender.Sender, now time.Time) {
+	reminders, err := job.reminderRepo.FindAllEnabled(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("ReminderJob FindAllEnabled error: %v", err)
+		return
+	}
+
+	for _, reminder := range reminders {
+		if ctx.Err() != nil {
+			return
+		}
+		if !reminder.IsDue(now) {
+			continue
+		}
+		remindCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), reminderTimeout)
+		job.remind(logging.WithFields(remindCtx, map[string]any{"job": "reminder", "user_id": reminder.UserId, "chat_id": reminder.ChatId}), bot, reminder, now)
+		cancel()
+	}
+}
+
+func (job ReminderJob) remind(ctx context.Context, bot *sender.Sender, reminder domain.Reminder, now time.Time) {
+	local := now.In(reminder.Location)
+	dateFrom := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, reminder.Location)
+	dateTo := dateFrom.AddDate(0, 0, 1)
+
+	count, err := job.transactionRepo.CountByDateRange(ctx, reminder.UserId, dateFrom, dateTo)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("ReminderJob CountByDateRange error: %v", err)
+		return
+	}
+
+	if count == 0 {
+		locale := reminder.GetLocale()
+		inlineKeyboard, err := util.NewReminderKeyboard(reminderSnoozeMinutes, reminderInlineColSize, locale)
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("NewReminderKeyboard error: %v", err)
+			return
+		}
+		msg := tgbotapi.NewMessage(reminder.ChatId, locale.Get(message.ReminderMsg))
+		msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: inlineKeyboard}
+		util.BotSendWrapper(bot.WithContext(ctx), msg)
+	}
+
+	// mark as sent even when something was logged, so the day is not checked again
+	err = job.reminderRepo.MarkSent(ctx, reminder.UserId, local)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("ReminderJob MarkSent error: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/domain"
+)
+
+type mockReminderRepo struct {
+	findAllEnabledFn func(ctx context.Context) ([]domain.Reminder, error)
+	markSentFn       func(ctx context.Context, userId int64, localDate time.Time) error
+}
+
+func (m mockReminderRepo) FindAllEnabled(ctx context.Context) ([]domain.Reminder, error) {
+	return m.findAllEnabledFn(ctx)
+}
+
+func (m mockReminderRepo) MarkSent(ctx context.Context, userId int64, localDate time.Time) error {
+	return m.markSentFn(ctx, userId, localDate)
+}
+
+type mockTransactionRepo struct {
+	countByDateRangeFn func(ctx context.Context, userId int64, dateFrom time.Time, dateTo time.Time) (int, error)
+}
+
+func (m mockTransactionRepo) CountByDateRange(ctx context.Context, userId int64, dateFrom time.Time, dateTo time.Time) (int, error) {
+	return m.countByDateRangeFn(ctx, userId, dateFrom, dateTo)
+}
+
+func TestReminderJobRun_AlreadyLogged(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Singapore")
+	now := time.Date(2024, 6, 15, 21, 30, 0, 0, loc)
+
+	var countedFrom, countedTo time.Time
+	var markedUserIds []int64
+
+	rr := mockReminderRepo{
+		findAllEnabledFn: func(ctx context.Context) ([]domain.Reminder, error) {
+			return []domain.Reminder{
+				{UserId: 1, ChatId: 1, Enabled: true, RemindAt: 21 * 60, Location: loc},
+				{UserId: 2, ChatId: 2, Enabled: true, RemindAt: 22 * 60, Location: loc}, // not due yet
+			}, nil
+		},
+		markSentFn: func(ctx context.Context, userId int64, localDate time.Time) error {
+			markedUserIds = append(markedUserIds, userId)
+			return nil
+		},
+	}
+	tr := mockTransactionRepo{
+		countByDateRangeFn: func(ctx context.Context, userId int64, dateFrom time.Time, dateTo time.Time) (int, error) {
+			countedFrom, countedTo = dateFrom, dateTo
+			return 3, nil
+		},
+	}
+
+	NewReminderJob(rr, tr).Run(context.Background(), nil, now)
+
+	if len(markedUserIds) != 1 || markedUserIds[0] != 1 {
+		t.Fatalf("expected only user 1 to be marked as sent, got %v", markedUserIds)
+	}
+	if !countedFrom.Equal(time.Date(2024, 6, 15, 0, 0, 0, 0, loc)) || !countedTo.Equal(time.Date(2024, 6, 16, 0, 0, 0, 0, loc)) {
+		t.Errorf("expected the local day to be counted, got %v - %v", countedFrom, countedTo)
+	}
+}
+
+func TestReminderJobRun_FinishesReminderInProgressOnCancel(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Singapore")
+	now := time.Date(2024, 6, 15, 21, 30, 0, 0, loc)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var markedUserIds []int64
+	rr := mockReminderRepo{
+		findAllEnabledFn: func(ctx context.Context) ([]domain.Reminder, error) {
+			return []domain.Reminder{
+				{UserId: 1, ChatId: 1, Enabled: true, RemindAt: 21 * 60, Location: loc},
+				{UserId: 2, ChatId: 2, Enabled: true, RemindAt: 21 * 60, Location: loc},
+			}, nil
+		},
+		markSentFn: func(ctx context.Context, userId int64, localDate time.Time) error {
+			if ctx.Err() != nil {
+				t.Errorf("expected the reminder in progress to be marked under a live context")
+			}
+			markedUserIds = append(markedUserIds, userId)
+			return nil
+		},
+	}
+	tr := mockTransactionRepo{
+		countByDateRangeFn: func(ctx context.Context, userId int64, dateFrom time.Time, dateTo time.Time) (int, error) {
+			cancel() // shutting down while the first reminder is in progress
+			return 3, nil
+		},
+	}
+
+	NewReminderJob(rr, tr).Run(ctx, nil, now)
+
+	if len(markedUserIds) != 1 || markedUserIds[0] != 1 {
+		t.Errorf("expected only the reminder in progress to be finished, got %v", markedUserIds)
+	}
+}
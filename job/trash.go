@@ -0,0 +1,53 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/audit"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	"github.com/rs/zerolog/log"
+)
+
+const TrashInterval = time.Hour
+
+type TrashRepo interface {
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+}
+
+// TrashJob deletes for good the transactions that have been in the trash for longer than domain.TrashRetentionDays
+type TrashJob struct {
+	trashRepo TrashRepo
+}
+
+func NewTrashJob(trashRepo TrashRepo) TrashJob {
+	return TrashJob{trashRepo: trashRepo}
+}
+
+// Start runs the job on every interval until the context is cancelled
+func (job TrashJob) Start(ctx context.Context, bot *sender.Sender, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			job.Run(ctx, bot, now)
+		}
+	}
+}
+
+// Run purges the transactions deleted more than domain.TrashRetentionDays before now
+func (job TrashJob) Run(ctx context.Context, bot *sender.Sender, now time.Time) {
+	ctx = audit.WithJob(ctx, "trash")
+	purged, err := job.trashRepo.PurgeDeleted(ctx, now.AddDate(0, 0, -domain.TrashRetentionDays))
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("TrashJob PurgeDeleted error: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Ctx(ctx).Info().Msgf("Purged %d transactions from the trash", purged)
+	}
+}
@@ -0,0 +1,33 @@
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type mockTrashRepo struct {
+	purgeDeletedFn func(ctx context.Context, before time.Time) (int64, error)
+}
+
+func (m mockTrashRepo) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	return m.purgeDeletedFn(ctx, before)
+}
+
+func TestTrashJobRun(t *testing.T) {
+	now := time.Date(2024, 7, 31, 12, 0, 0, 0, time.UTC)
+	var purgedBefore time.Time
+	tr := mockTrashRepo{
+		purgeDeletedFn: func(ctx context.Context, before time.Time) (int64, error) {
+			purgedBefore = before
+			return 2, nil
+		},
+	}
+
+	NewTrashJob(tr).Run(context.Background(), nil, now)
+
+	want := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	if !purgedBefore.Equal(want) {
+		t.Errorf("expected the transactions deleted before %v purged, got %v", want, purgedBefore)
+	}
+}
@@ -0,0 +1,64 @@
+// Package logging attaches a logger carrying the ids of an update to the context, so every log line written while
+// handling the update, down to the database queries, can be correlated
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync/atomic"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const redacted = "[redacted]"
+
+var redact atomic.Bool
+
+// SetRedaction hides the amounts and descriptions of the users from the logs when enabled
+func SetRedaction(enabled bool) {
+	redact.Store(enabled)
+}
+
+// Text returns the text typed by a user, or a placeholder when redaction is enabled
+func Text(s string) string {
+	if redact.Load() {
+		return redacted
+	}
+	return s
+}
+
+// NewCorrelationId returns a random id to tie together the log lines of an update or request
+func NewCorrelationId() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithUpdate returns a context with a logger carrying the update, user and chat ids, the handler and a new
+// correlation id. The handlers log through log.Ctx(ctx).
+func WithUpdate(ctx context.Context, update tgbotapi.Update, handler string) context.Context {
+	logCtx := log.With().
+		Str("correlation_id", NewCorrelationId()).
+		Int("update_id", update.UpdateID).
+		Str("handler", handler)
+	if from := update.SentFrom(); from != nil {
+		logCtx = logCtx.Int64("user_id", from.ID)
+	}
+	if chat := update.FromChat(); chat != nil {
+		logCtx = logCtx.Int64("chat_id", chat.ID)
+	}
+	return logCtx.Logger().WithContext(ctx)
+}
+
+// WithFields returns a context with a logger carrying a new correlation id and the fields, for work that does not
+// come from an update such as the jobs
+func WithFields(ctx context.Context, fields map[string]any) context.Context {
+	return zerolog.Ctx(ctx).With().
+		Str("correlation_id", NewCorrelationId()).
+		Fields(fields).
+		Logger().
+		WithContext(ctx)
+}
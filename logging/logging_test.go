@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	original := log.Logger
+	log.Logger = zerolog.New(&buf)
+	t.Cleanup(func() { log.Logger = original })
+	return &buf
+}
+
+func decodeLine(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("unexpected log line %q: %v", buf.String(), err)
+	}
+	return line
+}
+
+func TestWithUpdate(t *testing.T) {
+	buf := captureLogs(t)
+	update := tgbotapi.Update{
+		UpdateID: 11,
+		Message: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: 7},
+			Chat: &tgbotapi.Chat{ID: 70},
+			Text: "/stats",
+		},
+	}
+
+	ctx := WithUpdate(context.Background(), update, "command:stats")
+	log.Ctx(ctx).Info().Msg("hello")
+
+	line := decodeLine(t, buf)
+	if line["update_id"] != float64(11) || line["user_id"] != float64(7) || line["chat_id"] != float64(70) || line["handler"] != "command:stats" {
+		t.Errorf("unexpected fields %v", line)
+	}
+	if id, _ := line["correlation_id"].(string); len(id) != 16 {
+		t.Errorf("expected a correlation id, got %v", line["correlation_id"])
+	}
+}
+
+func TestWithUpdate_NewCorrelationIdPerUpdate(t *testing.T) {
+	buf := captureLogs(t)
+	update := tgbotapi.Update{UpdateID: 11}
+
+	log.Ctx(WithUpdate(context.Background(), update, "none")).Info().Msg("first")
+	first := decodeLine(t, buf)
+	buf.Reset()
+	log.Ctx(WithUpdate(context.Background(), update, "none")).Info().Msg("second")
+	second := decodeLine(t, buf)
+
+	if first["correlation_id"] == second["correlation_id"] {
+		t.Errorf("expected different correlation ids, got %v", first["correlation_id"])
+	}
+}
+
+func TestText(t *testing.T) {
+	t.Cleanup(func() { SetRedaction(false) })
+
+	if got := Text("5.50 Chicken Rice"); got != "5.50 Chicken Rice" {
+		t.Errorf("expected the text without redaction, got %q", got)
+	}
+	SetRedaction(true)
+	if got := Text("5.50 Chicken Rice"); got != redacted {
+		t.Errorf("expected the text to be redacted, got %q", got)
+	}
+}
+
+func TestQueryTracer(t *testing.T) {
+	buf := captureLogs(t)
+	ctx := WithUpdate(context.Background(), tgbotapi.Update{UpdateID: 11}, "transaction")
+	tracer := QueryTracer{}
+
+	ctx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{
+		SQL:  "INSERT INTO transaction (amount)\n\t\tVALUES ($1)",
+		Args: []any{550},
+	})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{
+		CommandTag: pgconn.NewCommandTag("INSERT 0 1"),
+		Err:        errors.New("boom"),
+	})
+
+	line := decodeLine(t, buf)
+	if line["level"] != "warn" || line["update_id"] != float64(11) || line["sql"] != "INSERT INTO transaction (amount) VALUES ($1)" {
+		t.Errorf("unexpected query log %v", line)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("550")) {
+		t.Errorf("expected the arguments not to be logged, got %s", buf.String())
+	}
+}
@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+const slowQueryThreshold = 500 * time.Millisecond
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	sql   string
+	start time.Time
+}
+
+// QueryTracer logs the queries of the DAOs with the logger of the context. The arguments are never logged since they
+// hold the amounts and descriptions of the users.
+type QueryTracer struct{}
+
+func (t QueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, queryStart{sql: strings.Join(strings.Fields(data.SQL), " "), start: time.Now()})
+}
+
+func (t QueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	q, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+	duration := time.Since(q.start)
+
+	logger := zerolog.Ctx(ctx)
+	var event *zerolog.Event
+	switch {
+	case data.Err != nil:
+		event = logger.Warn().Err(data.Err)
+	case duration >= slowQueryThreshold:
+		event = logger.Warn()
+	default:
+		event = logger.Debug()
+	}
+	event.Str("sql", q.sql).Dur("duration", duration).Str("command_tag", data.CommandTag.String()).Msg("Query")
+}
@@ -1,25 +1,34 @@
 package main
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
+	"crypto/sha256"
+	"errors"
 	"github.com/rs/zerolog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/aattwwss/telegram-expense-bot/alert"
 	"github.com/aattwwss/telegram-expense-bot/config"
+	"github.com/aattwwss/telegram-expense-bot/conversation"
 	"github.com/aattwwss/telegram-expense-bot/dao"
 	"github.com/aattwwss/telegram-expense-bot/db"
+	"github.com/aattwwss/telegram-expense-bot/dispatch"
 	"github.com/aattwwss/telegram-expense-bot/domain"
 	"github.com/aattwwss/telegram-expense-bot/enum"
 	"github.com/aattwwss/telegram-expense-bot/handler"
+	"github.com/aattwwss/telegram-expense-bot/job"
+	"github.com/aattwwss/telegram-expense-bot/logging"
 	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/metrics"
 	"github.com/aattwwss/telegram-expense-bot/repo"
+	"github.com/aattwwss/telegram-expense-bot/router"
+	"github.com/aattwwss/telegram-expense-bot/sender"
 	"github.com/aattwwss/telegram-expense-bot/util"
 	"github.com/caarlos0/env/v6"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -29,61 +38,80 @@ import (
 
 func getCallbackType(callbackData string) (enum.CallbackType, error) {
 	var genericCallback domain.GenericCallback
-	err := json.Unmarshal([]byte(callbackData), &genericCallback)
+	err := domain.DecodeCallback(callbackData, &genericCallback)
 	if err != nil {
 		return "", err
 	}
 	return genericCallback.Type, nil
 }
 
-func handleCallback(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update, callbackHandler *handler.CallbackHandler) {
+func handleCallback(ctx context.Context, bot *sender.Sender, update tgbotapi.Update, callbackHandler *handler.CallbackHandler) {
 	callbackType, err := getCallbackType(update.CallbackQuery.Data)
 	if err != nil {
-		log.Error().Msg("handleCallback getCallbackType error: unrecognised callback")
-		util.BotSendMessage(bot, update.CallbackQuery.Message.Chat.ID, message.GenericErrReplyMsg)
+		// tampered data, or buttons signed with a previous key
+		log.Ctx(ctx).Warn().Msgf("handleCallback getCallbackType error: %v", err)
+		util.BotAnswerCallback(bot, update.CallbackQuery.ID, message.GetLocale(update.CallbackQuery.From.LanguageCode).Get(message.MenuExpiredMsg))
 		return
 	}
 
 	switch callbackType {
 	case enum.Category:
 		callbackHandler.FromCategory(ctx, bot, update.CallbackQuery)
+	case enum.Account:
+		callbackHandler.FromAccount(ctx, bot, update.CallbackQuery)
+	case enum.Split:
+		callbackHandler.FromSplit(ctx, bot, update.CallbackQuery)
 	case enum.Pagination:
 		callbackHandler.FromPagination(ctx, bot, update.CallbackQuery)
 	case enum.Undo:
 		callbackHandler.FromUndo(ctx, bot, update.CallbackQuery)
+	case enum.Restore:
+		callbackHandler.FromRestore(ctx, bot, update.CallbackQuery)
+	case enum.Refund:
+		callbackHandler.FromRefund(ctx, bot, update.CallbackQuery)
 	case enum.Cancel:
 		callbackHandler.FromCancel(ctx, bot, update.CallbackQuery)
+	case enum.CancelConversation:
+		callbackHandler.FromCancelConversation(ctx, bot, update.CallbackQuery)
+	case enum.Snooze:
+		callbackHandler.FromSnooze(ctx, bot, update.CallbackQuery)
+	case enum.LogNow:
+		callbackHandler.FromLogNow(ctx, bot, update.CallbackQuery)
+	case enum.Language:
+		callbackHandler.FromLanguage(ctx, bot, update.CallbackQuery)
 	default:
-		log.Error().Msg("handleCallback error: unrecognised callback")
-		util.BotSendMessage(bot, update.CallbackQuery.Message.Chat.ID, message.GenericErrReplyMsg)
+		log.Ctx(ctx).Error().Msg("handleCallback error: unrecognised callback")
+		util.BotSendMessage(bot, update.CallbackQuery.Message.Chat.ID, message.GetLocale(update.CallbackQuery.From.LanguageCode).Get(message.GenericErrReplyMsg))
 	}
 }
 
-func handleMessage(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update, commandHandler *handler.CommandHandler) {
-	log.Info().Msgf("Received: %v", update.Message.Text)
-
-	if update.Message.IsCommand() {
-		switch update.Message.Command() {
-		case "start":
-			commandHandler.Start(ctx, bot, update)
-		case "help":
-			commandHandler.Help(ctx, bot, update)
-		case "stats":
-			commandHandler.Stats(ctx, bot, update)
-		case "undo":
-			commandHandler.Undo(ctx, bot, update)
-		case "list":
-			commandHandler.List(ctx, bot, update)
-		case "export":
-			commandHandler.Export(ctx, bot, update)
-		default:
-			commandHandler.Help(ctx, bot, update)
+// updateLabels returns the type of the update and the handler it is dispatched to, as labels of the metrics
+func updateLabels(update tgbotapi.Update, commandRouter *router.Router) (string, string) {
+	switch {
+	case update.Message != nil:
+		name := commandRouter.Name(update)
+		if name == "" {
+			return "message", "transaction"
 		}
-	} else {
-		commandHandler.StartTransaction(ctx, bot, update)
+		return "message", "command:" + name
+	case update.CallbackQuery != nil:
+		callbackType, err := getCallbackType(update.CallbackQuery.Data)
+		if err != nil {
+			return "callback_query", "callback:unknown"
+		}
+		return "callback_query", "callback:" + string(callbackType)
+	case update.InlineQuery != nil:
+		return "inline_query", "inline"
+	default:
+		return "other", "none"
 	}
 }
 
+func handleInlineQuery(ctx context.Context, bot *sender.Sender, update tgbotapi.Update, inlineHandler *handler.InlineHandler) {
+	log.Ctx(ctx).Info().Str("query", logging.Text(update.InlineQuery.Query)).Msg("Received inline query")
+	inlineHandler.FromInlineQuery(ctx, bot, update.InlineQuery)
+}
+
 func loadEnv() error {
 	appEnv := os.Getenv("APP_ENV")
 	if appEnv == "" || appEnv == "DEV" {
@@ -101,11 +129,11 @@ func loadEnv() error {
 	return nil
 }
 
-func runWebhook(bot *tgbotapi.BotAPI, cfg config.EnvConfig) tgbotapi.UpdatesChannel {
+func runWebhook(bot *tgbotapi.BotAPI, cfg config.EnvConfig, server *http.Server) tgbotapi.UpdatesChannel {
 	log.Info().Msg("Running on webhook!")
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("OK")) })
 
-	go http.ListenAndServe(cfg.AppHost+":"+cfg.AppPort, nil)
+	go serveHttp(server)
 	time.Sleep(200 * time.Millisecond)
 
 	webhook, err := tgbotapi.NewWebhook(cfg.WebhookHost + "/" + bot.Token)
@@ -139,37 +167,36 @@ func runPolling(bot *tgbotapi.BotAPI) tgbotapi.UpdatesChannel {
 	return bot.GetUpdatesChan(u)
 }
 
-type telegramHook struct {
-	token           string
-	chatId          string
-	applicationName string
+// callbackKey returns the key that signs the callback data, keyboards already sent stop working when it changes
+func callbackKey(cfg config.EnvConfig) []byte {
+	if cfg.CallbackSecret != "" {
+		return []byte(cfg.CallbackSecret)
+	}
+	key := sha256.Sum256([]byte("callback:" + cfg.TelegramApiToken))
+	return key[:]
 }
 
-func (h telegramHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
-	if level == zerolog.ErrorLevel || level == zerolog.FatalLevel || level == zerolog.PanicLevel {
-		apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", h.token)
-
-		logMsg := fmt.Sprintf("<b>%s</b><code>\n\nlevel: %s\ntime : %s\nmsg  : %s</code>", h.applicationName, level, time.Now().Format("2006-01-02 15:04:05.000"), msg)
-		requestBody := fmt.Sprintf(`{"chat_id": "%s", "text": "%s", "parse_mode": "HTML"}`, h.chatId, logMsg)
-		resp, err := http.Post(apiURL, "application/json", bytes.NewBuffer([]byte(requestBody)))
+// newAlertSink returns the sink of the error alerts configured, or nil when alerts are disabled
+func newAlertSink(cfg config.EnvConfig) (alert.Sink, error) {
+	switch {
+	case cfg.LogTelegramToken != "" || cfg.LogTelegramChatId != "":
+		return alert.NewTelegramSink(cfg.LogTelegramToken, cfg.LogTelegramChatId), nil
+	case cfg.AlertWebhookUrl != "":
+		return alert.NewWebhookSink(cfg.AlertWebhookUrl), nil
+	case cfg.AlertFile != "":
+		f, err := os.OpenFile(cfg.AlertFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
 		if err != nil {
-			return
+			return nil, err
 		}
-		defer resp.Body.Close()
-	}
-}
-
-func newTelegramHook(token, chatId string) zerolog.Hook {
-	telegramHook := telegramHook{
-		token:           token,
-		chatId:          chatId,
-		applicationName: "expense-tracker-bot",
+		return alert.NewWriterSink(f), nil
+	default:
+		return nil, nil
 	}
-	return telegramHook
 }
 
 func main() {
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	err := loadEnv()
 	if err != nil {
@@ -179,26 +206,83 @@ func main() {
 	if err := env.Parse(&cfg); err != nil {
 		log.Fatal().Err(err)
 	}
-	if cfg.LogTelegramToken != "" || cfg.LogTelegramChatId != "" {
-		telegramHook := newTelegramHook(cfg.LogTelegramToken, cfg.LogTelegramChatId)
-		log.Logger = log.Hook(telegramHook)
+	level, err := zerolog.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid LOG_LEVEL")
+	}
+	zerolog.SetGlobalLevel(level)
+	zerolog.DefaultContextLogger = &log.Logger
+	logging.SetRedaction(cfg.LogRedact)
+	domain.SetCallbackKey(callbackKey(cfg))
+	domain.SetAcceptJsonCallbacks(cfg.CallbackAcceptJson)
+	alertSink, err := newAlertSink(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid ALERT_FILE")
+	}
+	var alerter *alert.Alerter
+	if alertSink != nil {
+		alertOptions := alert.DefaultOptions()
+		alertOptions.Window = cfg.AlertWindow
+		alertOptions.RateLimit = cfg.AlertRateLimit
+		alerter = alert.New(alertSink, alertOptions)
+		alerter.Start()
+		log.Logger = log.Output(zerolog.MultiLevelWriter(os.Stderr, alerter))
 	}
 
 	dbLoaded, _ := db.LoadDB(ctx, cfg)
+	metrics.RegisterPool(dbLoaded)
 	userDAO := dao.NewUserDao(dbLoaded)
 	transactionDao := dao.NewTransactionDao(dbLoaded)
 	messageContextDao := dao.NewMessageContextDao(dbLoaded)
 	transactionTypeDao := dao.NewTransactionTypeDAO(dbLoaded)
 	categoryDao := dao.NewCategoryDAO(dbLoaded)
+	accountDao := dao.NewAccountDAO(dbLoaded)
+	transferDao := dao.NewTransferDAO(dbLoaded)
+	goalDao := dao.NewGoalDAO(dbLoaded)
+	iouDao := dao.NewIouDAO(dbLoaded)
+	reminderDao := dao.NewReminderDAO(dbLoaded)
+	apiTokenDao := dao.NewApiTokenDAO(dbLoaded)
+	conversationDao := dao.NewConversationDAO(dbLoaded)
+	currencyDao := dao.NewCurrencyDAO(dbLoaded)
 
 	transactionRepo := repo.NewTransactionRepo(transactionDao)
-	messageContextRepo := repo.NewMessageContextRepo(messageContextDao)
+	messageContextRepo := repo.NewMessageContextRepo(messageContextDao, cfg.MessageContextTtl)
 	transactionTypeRepo := repo.NewTransactionTypeRepo(transactionTypeDao)
 	userRepo := repo.NewUserRepo(userDAO)
 	categoryRepo := repo.NewCategoryRepo(categoryDao)
+	accountRepo := repo.NewAccountRepo(accountDao, transferDao)
+	goalRepo := repo.NewGoalRepo(goalDao)
+	iouRepo := repo.NewIouRepo(iouDao)
+	reminderRepo := repo.NewReminderRepo(reminderDao)
+	apiTokenRepo := repo.NewApiTokenRepo(apiTokenDao)
+	conversationRepo := repo.NewConversationRepo(conversationDao)
+	conversations := conversation.NewManager(conversationRepo)
+
+	currencies, err := repo.NewCurrencyRepo(currencyDao).GetAll(ctx)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Cannot load currencies")
+	}
+	err = domain.RegisterCurrencies(currencies)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid currency")
+	}
 
-	commandHandler := handler.NewCommandHandler(userRepo, transactionRepo, messageContextRepo, transactionTypeRepo, categoryRepo)
-	callbackHandler := handler.NewCallbackHandler(userRepo, transactionRepo, messageContextRepo, transactionTypeRepo, categoryRepo)
+	commandHandler := handler.NewCommandHandler(userRepo, transactionRepo, messageContextRepo, transactionTypeRepo, categoryRepo, accountRepo, goalRepo, iouRepo, reminderRepo, apiTokenRepo, conversations, cfg.WebAppUrl)
+	commandRouter := handler.NewCommandRouter(commandHandler, router.Logging(), router.RateLimit(cfg.UserRateLimit, cfg.UserRateBurst))
+	callbackHandler := handler.NewCallbackHandler(userRepo, transactionRepo, messageContextRepo, transactionTypeRepo, categoryRepo, accountRepo, reminderRepo, conversations)
+	entryTtl := cfg.MessageContextTtl
+	if cfg.DefaultCategoryId != 0 {
+		entryTtl = cfg.PendingEntryTtl
+	}
+	handler.RegisterFlows(conversations, callbackHandler, entryTtl, cfg.DefaultCategoryId)
+	inlineHandler := handler.NewInlineHandler(userRepo, transactionRepo)
+	apiHandler := handler.NewApiHandler(userRepo, transactionRepo, categoryRepo, apiTokenRepo)
+	reminderJob := job.NewReminderJob(reminderRepo, transactionRepo)
+	messageContextJob := job.NewMessageContextJob(messageContextRepo)
+	conversationJob := job.NewConversationJob(conversations)
+	goalJob := job.NewGoalJob(goalRepo, userRepo)
+	iouJob := job.NewIouJob(iouRepo)
+	trashJob := job.NewTrashJob(transactionRepo)
 
 	bot, err := tgbotapi.NewBotAPI(cfg.TelegramApiToken)
 	if err != nil {
@@ -206,32 +290,166 @@ func main() {
 	}
 	log.Info().Msgf("Authorized on account %s", bot.Self.UserName)
 
+	if cfg.MetricsEnabled {
+		http.Handle("/metrics", metrics.Handler())
+	}
+	if cfg.ApiEnabled {
+		http.Handle(handler.ApiPrefix, apiHandler.Routes())
+	}
+	if cfg.WebAppUrl != "" {
+		webAppHandler := handler.NewWebAppHandler(apiHandler, userRepo, bot.Token)
+		http.Handle(handler.WebAppPrefix, webAppHandler.Routes())
+	}
+
+	// the handlers run under workCtx rather than the signal context, so the updates in flight can finish after the
+	// signal and workCtx is only cancelled once the shutdown timeout is over
+	workCtx, cancelWork := context.WithCancel(context.Background())
+	defer cancelWork()
+	server := &http.Server{
+		Addr:        cfg.AppHost + ":" + cfg.AppPort,
+		BaseContext: func(net.Listener) context.Context { return workCtx },
+	}
+	serving := cfg.WebhookEnabled || cfg.ApiEnabled || cfg.WebAppUrl != "" || cfg.MetricsEnabled
+
 	var updates tgbotapi.UpdatesChannel
 
 	if cfg.WebhookEnabled {
-		updates = runWebhook(bot, cfg)
+		updates = runWebhook(bot, cfg, server)
 	} else {
+		if serving {
+			go serveHttp(server)
+		}
 		updates = runPolling(bot)
 	}
 
-	processUpdate := func(bot *tgbotapi.BotAPI, update <-chan tgbotapi.Update) {
-		for update := range updates {
-			if update.Message != nil {
-				handleMessage(ctx, bot, update, &commandHandler)
-			} else if update.CallbackQuery != nil {
-				handleCallback(ctx, bot, update, &callbackHandler)
-			}
-		}
-	}
+	metrics.RegisterQueueDepth(func() int { return len(updates) })
 
-	for i := 0; i < cfg.NumRoutines; i++ {
-		go processUpdate(bot, updates)
+	telegramSender := sender.New(bot, sender.DefaultOptions())
+	err = commandRouter.SetMyCommands(telegramSender.WithBot(metrics.InstrumentBot(bot, "commands")).WithContext(ctx))
+	if err != nil {
+		log.Error().Msgf("Error setting the command menu: %v", err)
 	}
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	// a panic in a handler is logged and answered rather than taking the bot down
+	dispatchUpdate := router.Recover()(func(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+		if update.Message != nil {
+			commandRouter.Dispatch(ctx, bot, update)
+		} else if update.CallbackQuery != nil {
+			handleCallback(ctx, bot, update, &callbackHandler)
+		} else if update.InlineQuery != nil {
+			handleInlineQuery(ctx, bot, update, &inlineHandler)
+		}
+	})
+
+	handleUpdate := func(update tgbotapi.Update) {
+		updateType, handlerName := updateLabels(update, commandRouter)
+		metrics.ObserveUpdate(updateType)
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(workCtx, cfg.UpdateTimeout)
+		defer cancel()
+		ctx = logging.WithUpdate(ctx, update, handlerName)
+		bot := telegramSender.WithBot(metrics.InstrumentBot(bot, handlerName)).WithContext(ctx)
+
+		dispatchUpdate(ctx, bot, update)
+		metrics.ObserveHandler(handlerName, start)
+		log.Ctx(ctx).Info().Dur("duration", time.Since(start)).Msg("Handled update")
+	}
 
+	dispatcher := dispatch.NewDispatcher(cfg.NumRoutines, cfg.ShardBacklog, handleUpdate)
+	metrics.RegisterDispatchDepth(dispatcher.Depth)
+	dispatcher.Start()
+
+	stopping := make(chan struct{})
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		dispatcher.Run(updates, stopping)
+	}()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		reminderJob.Start(ctx, telegramSender.WithBot(metrics.InstrumentBot(bot, "job:reminder")), job.ReminderInterval)
+	}()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		messageContextJob.Start(ctx, telegramSender.WithBot(metrics.InstrumentBot(bot, "job:message_context")), job.MessageContextInterval)
+	}()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		conversationJob.Start(ctx, telegramSender.WithBot(metrics.InstrumentBot(bot, "job:conversation")), job.ConversationInterval)
+	}()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		goalJob.Start(ctx, telegramSender.WithBot(metrics.InstrumentBot(bot, "job:goal")), job.GoalInterval)
+	}()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		iouJob.Start(ctx, telegramSender.WithBot(metrics.InstrumentBot(bot, "job:iou")), job.IouInterval)
+	}()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		trashJob.Start(ctx, telegramSender.WithBot(metrics.InstrumentBot(bot, "job:trash")), job.TrashInterval)
+	}()
+
+	<-ctx.Done()
 	log.Info().Msg("Shutting down...")
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancelShutdown()
+
+	// stop taking new updates first, the webhook only returns once its updates are queued
+	if !cfg.WebhookEnabled {
+		bot.StopReceivingUpdates()
+	}
+	if serving {
+		err := server.Shutdown(shutdownCtx)
+		if err != nil {
+			log.Error().Msgf("Error shutting down http server: %v", err)
+		}
+	}
+	close(stopping)
+
+	drained := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		log.Info().Msg("Drained all updates")
+	case <-shutdownCtx.Done():
+		log.Error().Msgf("Updates still in flight after %v, cancelling them", cfg.ShutdownTimeout)
+		cancelWork()
+		<-drained
+	}
+
 	dbLoaded.Close()
+
+	if alerter != nil {
+		alertCtx, cancelAlerts := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelAlerts()
+		err := alerter.Close(alertCtx)
+		if err != nil {
+			log.Warn().Msgf("Error sending the pending alerts: %v", err)
+		}
+	}
+}
+
+func serveHttp(server *http.Server) {
+	err := server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Error().Msgf("Error serving http: %v", err)
+	}
 }
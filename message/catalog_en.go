@@ -0,0 +1,279 @@
+package message
+
+var enCatalog = map[Key]string{
+	HelpMsg: `
+Message directly with the format "[amount] [description]" to start recording an expenses.
+Make sure the amount is purely a number or decimal with no currency code or symbol.
+The description can be made up of any characters.
+
+Example:
+✔️ "5.50 Chicken Rice" (without the quotes) to record an expense of $5.50 with the description "Chicken Rice".
+✔️ "100 Ikea Table" (without the quotes) to record an expense of $100 with the description "Ikea Table".
+❌ "Computer 2400" (without the quotes) will give an error".
+❌ "$20.78 Pizza" (without the quotes) will give an error".
+
+The recorded dollar ($) is the default currency symbol with support to up to 2 decimal places for the cents.
+
+`,
+
+	HelpExamplesMsg: `
+List the expenses for current month and year
+E.g. "/list".
+
+List the expenses for a month
+E.g. "/list Feb" for February of the current year.
+E.g. "/list Feb 2022" or "/list 2022-02".
+
+List the expenses for any other period
+E.g. "/list this week" or "/list last month".
+E.g. "/list 2023-Q1" or "/list 2023".
+E.g. "/list last 30 days" or "/list ytd".
+E.g. "/list 2023-01-15..2023-02-10".
+
+List the expenses paid with an account
+E.g. "/list last month ^dbs".
+
+Stats and export follow the same rules as well!
+
+If you have any questions or problems, email me at telegram.expense.bot@gmail.com
+`,
+
+	UserExistsMsg:            "Welcome back! These are the summary of your transactions: \n",
+	ErrorFindingUserMsg:      "Sorry there is a problem fetching your information.\n",
+	ErrorCreatingUserMsg:     "Sorry there is a problem signing you up.\n",
+	SignUpSuccessMsg:         "Congratulations!\nWelcome to your expense tracker!\nType /help to learn how you can start using this bot right away!",
+	CannotRecogniseAmountMsg: "I don't recognise that amount of money :(\nType /help to learn how you can start tracking your expenses!",
+	AmountPrecisionMsg:       "Amounts in %s can have up to %d decimal places.",
+	DescriptionTooLongMsg:    "Sorry, your description (max %d characters) is too long :( \n",
+	TransactionListEmptyMsg:  "You have no transactions in %s.",
+
+	TransactionTypeReplyMsg:          "Select a transaction type",
+	TransactionStartReplyMsg:         "Select a category",
+	TransactionEndReplyMsg:           "\n<i>%s</i>",
+	TransactionLatestNotFound:        "You have no more transaction to delete.",
+	TransactionDeleteConfirmationMsg: "Do you want to delete your transaction of %s %s ?",
+	TransactionDeletedReplyMsg:       "Your transaction of %s %s has been deleted.",
+	TransactionUndoMoreHint:          "\nType /undo again to delete the one before it, or /redo to restore it.",
+	TransactionRestoredReplyMsg:      "Your transaction of %s %s has been restored.",
+	TransactionNotInTrashMsg:         "That transaction is no longer in the trash.",
+	RedoNothingMsg:                   "There is no deleted transaction to restore.",
+	TrashHeader:                      "<b>Trash</b>\n\n",
+	TrashEmptyMsg:                    "Your trash is empty.",
+	TrashFooterMsg:                   "Tap a number to restore its transaction. Deleted transactions are kept for %d days.",
+	HistoryHeader:                    "<b>History of #%d</b>\n\n",
+	HistoryEntryLine:                 "<code>%s</code> <b>%s</b> · %s\n%s\n\n",
+	HistoryDiffLine:                  "%s: %s → %s",
+	HistoryNoChangeMsg:               "Nothing changed",
+	HistoryUsageMsg:                  "Send /history followed by the number shown after # in /list or /trash, e.g. /history 12",
+	HistoryNotFoundMsg:               "There is no history of #%d.",
+	HistoryActionCreate:              "Created",
+	HistoryActionUpdate:              "Edited",
+	HistoryActionDelete:              "Deleted",
+	HistoryActionRestore:             "Restored",
+	HistoryActionPurge:               "Purged",
+	HistorySourceBot:                 "bot",
+	HistorySourceApi:                 "API",
+	HistorySourceApp:                 "mini app",
+	HistorySourceImport:              "import",
+	HistorySourceRecurring:           "recurring",
+	HistorySourceJob:                 "%s job",
+
+	StatsTotalLabel:          "Total",
+	ExportCaptionMsg:         "Exported expenses for %s",
+	ExportSheetName:          "Expenses",
+	ExportDateHeader:         "Date",
+	ExportDescHeader:         "Description",
+	ExportAmtHeader:          "Amount",
+	ExportCatHeader:          "Category",
+	ExportCurrHeader:         "Currency",
+	ExportAccountHeader:      "Account",
+	HistorySheetName:         "History",
+	HistoryTimeHeader:        "Changed at",
+	HistoryTransactionHeader: "Transaction",
+	HistoryActionHeader:      "Change",
+	HistorySourceHeader:      "By",
+	HistoryBeforeHeader:      "Before",
+	HistoryAfterHeader:       "After",
+
+	ReminderMsg:               "You have not logged any expenses today. Did you pay for anything in cash?",
+	ReminderLogNowPromptMsg:   "Send me the amount and description, e.g. 5.50 Chicken Rice",
+	ReminderLogNowPlaceholder: "5.50 Chicken Rice",
+	ReminderSnoozedReplyMsg:   "Okay, I will remind you again at %s.",
+	ReminderNotFoundMsg:       "You have not set up any reminder. Type /remind 21:00 to get started.",
+	ReminderUsageMsg: `
+Type /remind 21:00 to be reminded at 9pm on days you have not logged anything.
+Type /remind off to stop the reminders, and /remind on to resume them.
+Type /remind quiet 23:00 07:00 to never be reminded between 11pm and 7am.
+Type /remind quiet off to remove the quiet hours.`,
+	ReminderInvalidMsg:     "I don't understand that reminder setting :(\n",
+	ReminderQuietCoversMsg: "Quiet hours cannot cover the time of the reminder at %s.\n",
+	ReminderOffMsg:         "Reminders are off.",
+	ReminderOnMsg:          "I will remind you at %s if you have not logged anything that day.",
+	ReminderQuietHoursMsg:  "\nQuiet hours: %s - %s",
+	ReminderSnoozedMsg:     "\nSnoozed until %s",
+
+	LanguageSelectMsg:      "Select your language",
+	LanguageUpdatedMsg:     "Your language is now English.",
+	LanguageUnsupportedMsg: "Sorry, I don't speak that language yet :(",
+
+	PeriodInvalidMsg:   "I don't understand that period :(\nTry this week, last month, 2023-Q1, 2023, last 30 days, ytd or 2023-01-15..2023-02-10.",
+	PeriodQuarterLabel: "Q%d %d",
+
+	InlineTotalTitle:         "Total spent in %s",
+	InlineTopCategoriesTitle: "Top categories in %s",
+	InlineRecentTitle:        "Recent expenses in %s",
+	InlineRecentDescription:  "%d expenses",
+	InlineSignUpMsg:          "Sign up to start tracking your expenses",
+
+	TokenIssuedMsg:      "Here is your API token, it will not be shown again:\n<code>%s</code>\n\nSend it in the Authorization header as \"Bearer &lt;token&gt;\".\nType /token revoke to revoke all your tokens.",
+	TokenRevokedMsg:     "All your API tokens have been revoked.",
+	TokenPrivateChatMsg: "For your safety, send /token to me in a private chat.",
+
+	WebAppOpenMsg:          "Tap the button below to browse, edit and chart your expenses.",
+	WebAppNotConfiguredMsg: "The mini app is not available on this bot.",
+	WebAppPrivateChatMsg:   "The mini app can only be opened from a private chat with me.",
+
+	MenuExpiredMsg:            "This menu has expired, please send it again.",
+	DefaultCategoryAppliedMsg: "No category was chosen in time, so the default category was used.\n",
+	NoAccountAppliedMsg:       "No account was chosen in time, so the amount was added without one.\n",
+
+	ConversationCancelledMsg: "Cancelled.",
+	NoConversationMsg:        "There is nothing to cancel.",
+
+	AccountUsageMsg: `
+Type /account add DBS card to add an account.
+Type /account opening ^dbs 1200.50 to set the balance of an account before its first transaction.
+Type /account delete ^dbs to delete an account without transactions.
+Add ^dbs to an amount to pay with an account, e.g. "5.50 Chicken Rice ^dbs".
+Type /transfer 100 ^dbs ^cash to move money between accounts, and /balances to see what is left in each.`,
+	AccountListMsg:             "Your accounts:\n%s",
+	AccountNoneMsg:             "You have no accounts yet.\n",
+	AccountAddedMsg:            "Added the account %s. Add ^%s to an amount to pay with it.",
+	AccountExistsMsg:           "You already have an account with that name.",
+	AccountNameInvalidMsg:      "The name of an account must be 1 to %d characters, without ^.",
+	AccountOpeningSetMsg:       "The opening balance of %s is now %s.",
+	AccountDeletedMsg:          "Deleted the account %s.",
+	AccountInUseMsg:            "%s has transactions or transfers, so it cannot be deleted.",
+	AccountUnknownMsg:          "You have no account starting with ^%s. Type /account to see your accounts.",
+	AccountAmbiguousMsg:        "More than one of your accounts starts with ^%s, type more of its name.",
+	AccountTooManyMsg:          "Give one account only, e.g. ^dbs.",
+	AccountSelectMsg:           "Select the account you paid with",
+	AccountFilterLabel:         "%s (%s)",
+	TransactionAccountReplyMsg: "\nPaid with <b>%s</b>",
+	TransactionSplitMarker:     "✂️ Split",
+	TransactionSplitReplyLine:  "\n• %s %s",
+	SplitHeaderMsg:             "Splitting %s %s\n",
+	SplitLineMsg:               "• %s %s\n",
+	SplitCategoryAskMsg:        "\n%s left. Tap the category of the next part.",
+	SplitAmountAskMsg:          "\nHow much of the %s left goes to %s? Send an amount such as 80, or a percentage of the total such as 40%%.",
+	SplitAmountInvalidMsg:      "Send an amount of up to %s, or a percentage of the total such as 40%%.",
+	SplitTypeMismatchMsg:       "\nEvery part must be of the same type as %s. Tap another category.",
+	SplitNotPositiveMsg:        "\nOnly an amount of more than 0 can be split. Tap a category.",
+	TransactionIdReplyMsg:      "\n<code>#%d</code>",
+	TransactionRefundLink:      " ↩️ #%d",
+	RefundUsageMsg: `Type /refund to pick a recent purchase to refund in full, or /refund 20 to refund 20 of it. You can also reply /refund to the message confirming a purchase.
+Type /refund month original to count refunds in the month of their purchase, or /refund month refund to count them in the month they were refunded.`,
+	RefundSelectHeader:          "<b>Refund</b>\n\n",
+	RefundSelectLine:            "<code>%d. %s #%d\n%s %s %s</code>\n\n",
+	RefundSelectFooterMsg:       "Tap the number of the purchase to refund what is left of it.",
+	RefundSelectAmountFooterMsg: "Tap the number of the purchase to refund %s of it.",
+	RefundNoneMsg:               "You have no purchases left to refund.",
+	RefundNotFoundMsg:           "That purchase is no longer there to refund.",
+	RefundNotPurchaseMsg:        "#%d is a refund or a negative entry, so it cannot be refunded.",
+	RefundSplitMsg:              "#%d is split across categories, so it cannot be refunded.",
+	RefundTooMuchMsg:            "That is more than the %s left to refund of #%d.",
+	RefundReplyMsg:              "Refunded %s of #%d %s under %s.",
+	RefundMonthOriginalMsg:      "Stats count refunds in the month of their purchase.",
+	RefundMonthRefundMsg:        "Stats count refunds in the month they were refunded.",
+	BalancesHeader:              "<b>Balances</b>\n",
+	TransferUsageMsg:            "Type /transfer 100 ^dbs ^cash ATM to move 100 from ^dbs to ^cash. Transfers are not counted as spending.",
+	TransferReplyMsg:            "Moved %s from %s to %s.",
+	GoalUsageMsg: `
+Type /goal add Japan trip 3000 by 2024-12 to save 3000 for a trip by December 2024.
+Type /goal add 200 Japan to put 200 towards it.
+Type /goal monthly 250 Japan to put 250 towards it on the 1st of every month, or 0 to stop.
+Type /goal delete Japan to delete it.`,
+	GoalListHeader:        "<b>Goals</b>\n\n",
+	GoalNoneMsg:           "You have no savings goals yet.\n",
+	GoalAddedMsg:          "Added the goal %s to save %s.",
+	GoalAddedByMsg:        "Added the goal %s to save %s by %s, which is %s a month.",
+	GoalExistsMsg:         "You already have a goal with that name.",
+	GoalNameInvalidMsg:    "The name of a goal must be 1 to %d characters.",
+	GoalUnknownMsg:        "You have no goal starting with %s. Type /goal to see your goals.",
+	GoalAmbiguousMsg:      "More than one of your goals starts with %s, type more of its name.",
+	GoalContributedMsg:    "Added %s to %s.\n\n",
+	GoalReachedMsg:        "🎉 You reached your goal %s of %s! 🎉",
+	GoalMonthlySetMsg:     "%s will be put towards %s on the 1st of every month.",
+	GoalMonthlyStoppedMsg: "Stopped putting money towards %s every month.",
+	GoalDeletedMsg:        "Deleted the goal %s.",
+	GoalPaceMsg:           "Needs %s a month until %s, saving %s a month",
+	GoalOverdueMsg:        "Was due by %s, saving %s a month",
+	GoalActualPaceMsg:     "Saving %s a month",
+	GoalReachedLabel:      "🎉 Reached!",
+	GoalMonthlyLabel:      "\n+%s on the 1st of every month",
+	IouUsageMsg: `
+Type /lent 50 Alice concert when you pay 50 for Alice.
+Type /borrowed 20 Bob taxi when Bob pays 20 for you.
+Type /repaid 20 Alice when 20 is paid back between you and Alice, whoever owed it.
+Type /owed remind 30 to be reminded of debts outstanding for 30 days or more, or /owed remind off to stop.
+Money lent and borrowed is not counted as spending.`,
+	IouListHeader:       "<b>Who owes what</b>\n",
+	IouNoneMsg:          "Nobody owes you anything, and you owe nobody.\n",
+	IouOwesYouLine:      "%s owes you %s since %s\n",
+	IouYouOweLine:       "You owe %s %s since %s\n",
+	IouOwesYouMsg:       "%s owes you %s.",
+	IouYouOweMsg:        "You owe %s %s.",
+	IouSettledMsg:       "You and %s are all square.",
+	IouLentMsg:          "Lent %s to %s.\n",
+	IouBorrowedMsg:      "Borrowed %s from %s.\n",
+	IouRepaidYouMsg:     "%s paid you back %s.\n",
+	IouYouRepaidMsg:     "You paid %s back %s.\n",
+	IouNothingOwedMsg:   "You and %s owe each other nothing.",
+	IouRepaidTooMuchMsg: "That is more than the %s outstanding with %s.",
+	IouPersonInvalidMsg: "The name of a person must be 1 to %d characters.",
+	IouReminderSetMsg:   "I will remind you of debts outstanding for %d days or more.",
+	IouReminderOffMsg:   "Debt reminders are off.",
+	IouReminderMsg:      "<b>Outstanding for %d days or more</b>\n",
+
+	CommandStartDesc:    "Sign up to start tracking your expenses",
+	CommandHelpDesc:     "Show how to use the bot",
+	CommandStatsDesc:    "View the breakdown for a period",
+	CommandSummaryDesc:  "View this month's spending and your goals",
+	CommandListDesc:     "View the expenses for a period",
+	CommandExportDesc:   "Export the expenses for a period",
+	CommandUndoDesc:     "Revert the last recorded expense",
+	CommandRedoDesc:     "Restore the last deleted expense",
+	CommandTrashDesc:    "View and restore recently deleted expenses",
+	CommandHistoryDesc:  "Show the changes made to an expense",
+	CommandRemindDesc:   "Get a reminder when you have not logged anything that day",
+	CommandLanguageDesc: "Change the language of the bot",
+	CommandTokenDesc:    "Get a token for the REST API",
+	CommandAppDesc:      "Browse and edit your expenses in the mini app",
+	CommandCancelDesc:   "Stop choosing a category or any other step you are in",
+	CommandAccountDesc:  "Add and manage the accounts you pay with",
+	CommandTransferDesc: "Move money between your accounts",
+	CommandBalancesDesc: "View the balance of each account",
+	CommandGoalDesc:     "Save towards goals and track their progress",
+	CommandLentDesc:     "Record money you lent someone",
+	CommandBorrowedDesc: "Record money you borrowed from someone",
+	CommandRepaidDesc:   "Record a repayment between you and someone",
+	CommandOwedDesc:     "See who owes what, or set debt reminders",
+	CommandRefundDesc:   "Refund part or all of a purchase",
+
+	RateLimitedMsg:   "You are sending messages too quickly, please wait a moment.",
+	NotRegisteredMsg: "Please send /start to sign up first.",
+
+	YesButton:         "Yes",
+	RestoreButton:     "♻️ %d",
+	SplitButton:       "✂️ Split",
+	RefundButton:      "↩️ %d",
+	CancelButton:      "Cancel",
+	LogNowButton:      "Log now",
+	SnoozeButton:      "Snooze %dm",
+	SnoozeHrButton:    "Snooze %dh",
+	WebAppButton:      "Open expenses",
+	AccountNoneButton: "No account",
+
+	GenericErrReplyMsg: "Something went wrong :(",
+	WorkInProgressMsg:  "Sorry this function is still a work in progress.",
+}
@@ -0,0 +1,296 @@
+package message
+
+var idCatalog = map[Key]string{
+	HelpMsg: `
+Kirim pesan dengan format "[jumlah] [keterangan]" untuk mencatat pengeluaran.
+Pastikan jumlah hanya berupa angka atau desimal tanpa kode atau simbol mata uang.
+Keterangan boleh berisi karakter apa saja.
+
+Contoh:
+✔️ "5.50 Nasi Ayam" (tanpa tanda kutip) untuk mencatat pengeluaran $5,50 dengan keterangan "Nasi Ayam".
+✔️ "100 Meja Ikea" (tanpa tanda kutip) untuk mencatat pengeluaran $100 dengan keterangan "Meja Ikea".
+❌ "Komputer 2400" (tanpa tanda kutip) akan menghasilkan galat.
+❌ "$20.78 Piza" (tanpa tanda kutip) akan menghasilkan galat.
+
+Simbol mata uang bawaan adalah dolar ($) dengan dukungan hingga 2 angka desimal untuk sen.
+
+`,
+
+	HelpExamplesMsg: `
+Daftar pengeluaran bulan dan tahun ini
+Cth. "/list".
+
+Daftar pengeluaran untuk suatu bulan
+Cth. "/list Feb" untuk Februari tahun ini.
+Cth. "/list Feb 2022" atau "/list 2022-02".
+
+Daftar pengeluaran untuk periode lain
+Cth. "/list this week" atau "/list last month".
+Cth. "/list 2023-Q1" atau "/list 2023".
+Cth. "/list last 30 days" atau "/list ytd".
+Cth. "/list 2023-01-15..2023-02-10".
+
+Daftar pengeluaran yang dibayar dengan satu akun
+Cth. "/list last month ^dbs".
+
+Stats dan export juga mengikuti aturan yang sama!
+
+Jika ada pertanyaan atau masalah, kirim email ke telegram.expense.bot@gmail.com
+`,
+
+	UserExistsMsg:            "Selamat datang kembali! Berikut ringkasan transaksi Anda: \n",
+	ErrorFindingUserMsg:      "Maaf, ada masalah saat mengambil informasi Anda.\n",
+	ErrorCreatingUserMsg:     "Maaf, ada masalah saat mendaftarkan Anda.\n",
+	SignUpSuccessMsg:         "Selamat!\nSelamat datang di pencatat pengeluaran Anda!\nKetik /help untuk mempelajari cara langsung menggunakan bot ini!",
+	CannotRecogniseAmountMsg: "Saya tidak mengenali jumlah uang itu :(\nKetik /help untuk mempelajari cara mulai mencatat pengeluaran Anda!",
+	AmountPrecisionMsg:       "Jumlah dalam %s dapat memiliki paling banyak %d angka desimal.",
+	DescriptionTooLongMsg:    "Maaf, keterangan Anda (maksimal %d karakter) terlalu panjang :( \n",
+	TransactionListEmptyMsg:  "Anda tidak punya transaksi di %s.",
+
+	TransactionTypeReplyMsg:          "Pilih jenis transaksi",
+	TransactionStartReplyMsg:         "Pilih kategori",
+	TransactionEndReplyMsg:           "\n<i>%s</i>",
+	TransactionLatestNotFound:        "Tidak ada lagi transaksi yang bisa dihapus.",
+	TransactionDeleteConfirmationMsg: "Apakah Anda ingin menghapus transaksi %s %s ?",
+	TransactionDeletedReplyMsg:       "Transaksi %s %s Anda telah dihapus.",
+	TransactionUndoMoreHint:          "\nKetik /undo lagi untuk menghapus transaksi sebelumnya, atau /redo untuk memulihkannya.",
+	TransactionRestoredReplyMsg:      "Transaksi %s %s Anda telah dipulihkan.",
+	TransactionNotInTrashMsg:         "Transaksi itu sudah tidak ada di tempat sampah.",
+	RedoNothingMsg:                   "Tidak ada transaksi terhapus untuk dipulihkan.",
+	TrashHeader:                      "<b>Tempat sampah</b>\n\n",
+	TrashEmptyMsg:                    "Tempat sampah Anda kosong.",
+	TrashFooterMsg:                   "Ketuk nomor untuk memulihkan transaksinya. Transaksi yang dihapus disimpan selama %d hari.",
+	HistoryHeader:                    "<b>Riwayat #%d</b>\n\n",
+	HistoryEntryLine:                 "<code>%s</code> <b>%s</b> · %s\n%s\n\n",
+	HistoryDiffLine:                  "%s: %s → %s",
+	HistoryNoChangeMsg:               "Tidak ada perubahan",
+	HistoryUsageMsg:                  "Kirim /history diikuti nomor setelah # di /list atau /trash, mis. /history 12",
+	HistoryNotFoundMsg:               "Tidak ada riwayat untuk #%d.",
+	HistoryActionCreate:              "Dibuat",
+	HistoryActionUpdate:              "Diubah",
+	HistoryActionDelete:              "Dihapus",
+	HistoryActionRestore:             "Dipulihkan",
+	HistoryActionPurge:               "Dimusnahkan",
+	HistorySourceBot:                 "bot",
+	HistorySourceApi:                 "API",
+	HistorySourceApp:                 "aplikasi mini",
+	HistorySourceImport:              "impor",
+	HistorySourceRecurring:           "berulang",
+	HistorySourceJob:                 "tugas %s",
+
+	StatsTotalLabel:          "Total",
+	ExportCaptionMsg:         "Pengeluaran yang diekspor untuk %s",
+	ExportSheetName:          "Pengeluaran",
+	ExportDateHeader:         "Tanggal",
+	ExportDescHeader:         "Keterangan",
+	ExportAmtHeader:          "Jumlah",
+	ExportCatHeader:          "Kategori",
+	ExportCurrHeader:         "Mata Uang",
+	ExportAccountHeader:      "Akun",
+	HistorySheetName:         "Riwayat",
+	HistoryTimeHeader:        "Diubah pada",
+	HistoryTransactionHeader: "Transaksi",
+	HistoryActionHeader:      "Perubahan",
+	HistorySourceHeader:      "Oleh",
+	HistoryBeforeHeader:      "Sebelum",
+	HistoryAfterHeader:       "Sesudah",
+
+	ReminderMsg:               "Anda belum mencatat pengeluaran apa pun hari ini. Ada yang dibayar tunai?",
+	ReminderLogNowPromptMsg:   "Kirim jumlah dan keterangan, cth. 5.50 Nasi Ayam",
+	ReminderLogNowPlaceholder: "5.50 Nasi Ayam",
+	ReminderSnoozedReplyMsg:   "Oke, saya akan mengingatkan Anda lagi pukul %s.",
+	ReminderNotFoundMsg:       "Anda belum mengatur pengingat. Ketik /remind 21:00 untuk memulai.",
+	ReminderUsageMsg: `
+Ketik /remind 21:00 untuk diingatkan pukul 9 malam pada hari Anda belum mencatat apa pun.
+Ketik /remind off untuk menghentikan pengingat, dan /remind on untuk melanjutkannya.
+Ketik /remind quiet 23:00 07:00 agar tidak diingatkan antara pukul 11 malam dan 7 pagi.
+Ketik /remind quiet off untuk menghapus jam tenang.`,
+	ReminderInvalidMsg:     "Saya tidak mengerti pengaturan pengingat itu :(\n",
+	ReminderQuietCoversMsg: "Jam tenang tidak boleh mencakup waktu pengingat pada %s.\n",
+	ReminderOffMsg:         "Pengingat dimatikan.",
+	ReminderOnMsg:          "Saya akan mengingatkan Anda pukul %s jika Anda belum mencatat apa pun hari itu.",
+	ReminderQuietHoursMsg:  "\nJam tenang: %s - %s",
+	ReminderSnoozedMsg:     "\nDitunda hingga %s",
+
+	LanguageSelectMsg:      "Pilih bahasa Anda",
+	LanguageUpdatedMsg:     "Bahasa Anda sekarang Bahasa Indonesia.",
+	LanguageUnsupportedMsg: "Maaf, saya belum bisa berbahasa itu :(",
+
+	PeriodInvalidMsg:   "Saya tidak mengerti periode itu :(\nCoba this week, last month, 2023-Q1, 2023, last 30 days, ytd atau 2023-01-15..2023-02-10.",
+	PeriodQuarterLabel: "Kuartal %d %d",
+
+	InlineTotalTitle:         "Total pengeluaran %s",
+	InlineTopCategoriesTitle: "Kategori teratas %s",
+	InlineRecentTitle:        "Pengeluaran terbaru %s",
+	InlineRecentDescription:  "%d pengeluaran",
+	InlineSignUpMsg:          "Daftar untuk mulai mencatat pengeluaran Anda",
+
+	TokenIssuedMsg:      "Ini token API Anda, token ini tidak akan ditampilkan lagi:\n<code>%s</code>\n\nKirim token di header Authorization sebagai \"Bearer &lt;token&gt;\".\nKetik /token revoke untuk mencabut semua token Anda.",
+	TokenRevokedMsg:     "Semua token API Anda telah dicabut.",
+	TokenPrivateChatMsg: "Demi keamanan Anda, kirim /token kepada saya di obrolan pribadi.",
+
+	WebAppOpenMsg:          "Ketuk tombol di bawah untuk melihat, mengubah, dan membuat grafik pengeluaran Anda.",
+	WebAppNotConfiguredMsg: "Aplikasi mini tidak tersedia di bot ini.",
+	WebAppPrivateChatMsg:   "Aplikasi mini hanya dapat dibuka dari obrolan pribadi dengan saya.",
+
+	MenuExpiredMsg:            "Menu ini sudah kedaluwarsa, silakan kirim ulang.",
+	DefaultCategoryAppliedMsg: "Tidak ada kategori yang dipilih tepat waktu, jadi kategori bawaan digunakan.\n",
+	NoAccountAppliedMsg:       "Tidak ada akun yang dipilih tepat waktu, jadi jumlahnya ditambahkan tanpa akun.\n",
+
+	ConversationCancelledMsg: "Dibatalkan.",
+	NoConversationMsg:        "Tidak ada yang perlu dibatalkan.",
+
+	AccountUsageMsg: `
+Ketik /account add DBS card untuk menambah akun.
+Ketik /account opening ^dbs 1200.50 untuk mengatur saldo akun sebelum transaksi pertamanya.
+Ketik /account delete ^dbs untuk menghapus akun yang tidak memiliki transaksi.
+Tambahkan ^dbs pada jumlah untuk membayar dengan akun, mis. "5.50 Nasi Ayam ^dbs".
+Ketik /transfer 100 ^dbs ^cash untuk memindahkan uang antar akun, dan /balances untuk melihat sisa saldo setiap akun.`,
+	AccountListMsg:             "Akun Anda:\n%s",
+	AccountNoneMsg:             "Anda belum memiliki akun.\n",
+	AccountAddedMsg:            "Akun %s telah ditambahkan. Tambahkan ^%s pada jumlah untuk membayar dengannya.",
+	AccountExistsMsg:           "Anda sudah memiliki akun dengan nama itu.",
+	AccountNameInvalidMsg:      "Nama akun harus 1 sampai %d karakter, tanpa ^.",
+	AccountOpeningSetMsg:       "Saldo awal %s sekarang %s.",
+	AccountDeletedMsg:          "Akun %s telah dihapus.",
+	AccountInUseMsg:            "%s memiliki transaksi atau transfer, sehingga tidak dapat dihapus.",
+	AccountUnknownMsg:          "Anda tidak memiliki akun yang diawali ^%s. Ketik /account untuk melihat akun Anda.",
+	AccountAmbiguousMsg:        "Lebih dari satu akun Anda diawali ^%s, ketik lebih banyak dari namanya.",
+	AccountTooManyMsg:          "Berikan satu akun saja, mis. ^dbs.",
+	AccountSelectMsg:           "Pilih akun yang Anda gunakan untuk membayar",
+	AccountFilterLabel:         "%s (%s)",
+	TransactionAccountReplyMsg: "\nDibayar dengan <b>%s</b>",
+	TransactionSplitMarker:     "✂️ Dipecah",
+	TransactionSplitReplyLine:  "\n• %s %s",
+	SplitHeaderMsg:             "Memecah %s %s\n",
+	SplitLineMsg:               "• %s %s\n",
+	SplitCategoryAskMsg:        "\nSisa %s. Ketuk kategori bagian berikutnya.",
+	SplitAmountAskMsg:          "\nBerapa dari sisa %s untuk %s? Kirim jumlah seperti 80, atau persentase dari total seperti 40%%.",
+	SplitAmountInvalidMsg:      "Kirim jumlah hingga %s, atau persentase dari total seperti 40%%.",
+	SplitTypeMismatchMsg:       "\nSetiap bagian harus sejenis dengan %s. Ketuk kategori lain.",
+	SplitNotPositiveMsg:        "\nHanya jumlah lebih dari 0 yang dapat dibagi. Ketuk sebuah kategori.",
+	TransactionIdReplyMsg:      "\n<code>#%d</code>",
+	TransactionRefundLink:      " ↩️ #%d",
+	RefundUsageMsg: `Ketik /refund untuk memilih pembelian terbaru yang dikembalikan penuh, atau /refund 20 untuk mengembalikan 20 darinya. Anda juga bisa membalas /refund pada pesan yang mengonfirmasi pembelian.
+Ketik /refund month original untuk menghitung pengembalian dana di bulan pembeliannya, atau /refund month refund untuk menghitungnya di bulan dana dikembalikan.`,
+	RefundSelectHeader:          "<b>Pengembalian dana</b>\n\n",
+	RefundSelectLine:            "<code>%d. %s #%d\n%s %s %s</code>\n\n",
+	RefundSelectFooterMsg:       "Ketuk nomor pembelian untuk mengembalikan sisa jumlahnya.",
+	RefundSelectAmountFooterMsg: "Ketuk nomor pembelian untuk mengembalikan %s darinya.",
+	RefundNoneMsg:               "Anda tidak punya pembelian untuk dikembalikan.",
+	RefundNotFoundMsg:           "Pembelian itu sudah tidak ada untuk dikembalikan.",
+	RefundNotPurchaseMsg:        "#%d adalah pengembalian dana atau catatan negatif, jadi tidak bisa dikembalikan.",
+	RefundSplitMsg:              "#%d dibagi ke beberapa kategori, jadi tidak bisa dikembalikan.",
+	RefundTooMuchMsg:            "Itu melebihi sisa %s yang bisa dikembalikan dari #%d.",
+	RefundReplyMsg:              "Dikembalikan %s dari #%d %s di bawah %s.",
+	RefundMonthOriginalMsg:      "Statistik menghitung pengembalian dana di bulan pembeliannya.",
+	RefundMonthRefundMsg:        "Statistik menghitung pengembalian dana di bulan dana dikembalikan.",
+	BalancesHeader:              "<b>Saldo</b>\n",
+	TransferUsageMsg:            "Ketik /transfer 100 ^dbs ^cash ATM untuk memindahkan 100 dari ^dbs ke ^cash. Transfer tidak dihitung sebagai pengeluaran.",
+	TransferReplyMsg:            "%s telah dipindahkan dari %s ke %s.",
+	GoalUsageMsg: `
+Ketik /goal add Japan trip 3000 by 2024-12 untuk menabung 3000 bagi perjalanan sebelum Desember 2024.
+Ketik /goal add 200 Japan untuk menabung 200 untuknya.
+Ketik /goal monthly 250 Japan untuk menabung 250 untuknya setiap tanggal 1, atau 0 untuk berhenti.
+Ketik /goal delete Japan untuk menghapusnya.`,
+	GoalListHeader:        "<b>Target</b>\n\n",
+	GoalNoneMsg:           "Anda belum memiliki target tabungan.\n",
+	GoalAddedMsg:          "Target %s untuk menabung %s telah ditambahkan.",
+	GoalAddedByMsg:        "Target %s untuk menabung %s sebelum %s telah ditambahkan, yaitu %s per bulan.",
+	GoalExistsMsg:         "Anda sudah memiliki target dengan nama itu.",
+	GoalNameInvalidMsg:    "Nama target harus 1 sampai %d karakter.",
+	GoalUnknownMsg:        "Anda tidak memiliki target yang diawali %s. Ketik /goal untuk melihat target Anda.",
+	GoalAmbiguousMsg:      "Lebih dari satu target Anda diawali %s, ketik lebih banyak dari namanya.",
+	GoalContributedMsg:    "%s telah ditambahkan ke %s.\n\n",
+	GoalReachedMsg:        "🎉 Anda telah mencapai target %s sebesar %s! 🎉",
+	GoalMonthlySetMsg:     "%s akan ditabung untuk %s setiap tanggal 1.",
+	GoalMonthlyStoppedMsg: "Tabungan bulanan untuk %s telah dihentikan.",
+	GoalDeletedMsg:        "Target %s telah dihapus.",
+	GoalPaceMsg:           "Perlu %s per bulan sampai %s, menabung %s per bulan",
+	GoalOverdueMsg:        "Tenggat %s sudah lewat, menabung %s per bulan",
+	GoalActualPaceMsg:     "Menabung %s per bulan",
+	GoalReachedLabel:      "🎉 Tercapai!",
+	GoalMonthlyLabel:      "\n+%s setiap tanggal 1",
+	IouUsageMsg: `
+Ketik /lent 50 Alice concert saat Anda membayar 50 untuk Alice.
+Ketik /borrowed 20 Bob taxi saat Bob membayar 20 untuk Anda.
+Ketik /repaid 20 Alice saat 20 dibayar kembali antara Anda dan Alice, siapa pun yang berutang.
+Ketik /owed remind 30 untuk diingatkan tentang utang yang belum lunas selama 30 hari atau lebih, atau /owed remind off untuk berhenti.
+Uang yang dipinjamkan dan dipinjam tidak dihitung sebagai pengeluaran.`,
+	IouListHeader:       "<b>Siapa berutang apa</b>\n",
+	IouNoneMsg:          "Tidak ada yang berutang kepada Anda, dan Anda tidak berutang kepada siapa pun.\n",
+	IouOwesYouLine:      "%s berutang %s kepada Anda sejak %s\n",
+	IouYouOweLine:       "Anda berutang %[2]s kepada %[1]s sejak %[3]s\n",
+	IouOwesYouMsg:       "%s berutang %s kepada Anda.",
+	IouYouOweMsg:        "Anda berutang %[2]s kepada %[1]s.",
+	IouSettledMsg:       "Anda dan %s sudah impas.",
+	IouLentMsg:          "%s dipinjamkan kepada %s.\n",
+	IouBorrowedMsg:      "%s dipinjam dari %s.\n",
+	IouRepaidYouMsg:     "%s telah membayar kembali %s kepada Anda.\n",
+	IouYouRepaidMsg:     "Anda telah membayar kembali %[2]s kepada %[1]s.\n",
+	IouNothingOwedMsg:   "Anda dan %s tidak saling berutang.",
+	IouRepaidTooMuchMsg: "Itu melebihi %s yang belum lunas dengan %s.",
+	IouPersonInvalidMsg: "Nama seseorang harus 1 sampai %d karakter.",
+	IouReminderSetMsg:   "Saya akan mengingatkan Anda tentang utang yang belum lunas selama %d hari atau lebih.",
+	IouReminderOffMsg:   "Pengingat utang dimatikan.",
+	IouReminderMsg:      "<b>Belum lunas selama %d hari atau lebih</b>\n",
+
+	CommandStartDesc:    "Daftar untuk mulai mencatat pengeluaran",
+	CommandHelpDesc:     "Tampilkan cara menggunakan bot",
+	CommandStatsDesc:    "Lihat rincian suatu periode",
+	CommandSummaryDesc:  "Lihat pengeluaran bulan ini dan target Anda",
+	CommandListDesc:     "Lihat pengeluaran suatu periode",
+	CommandExportDesc:   "Ekspor pengeluaran suatu periode",
+	CommandUndoDesc:     "Batalkan pengeluaran terakhir",
+	CommandRedoDesc:     "Pulihkan pengeluaran terakhir yang dihapus",
+	CommandTrashDesc:    "Lihat dan pulihkan pengeluaran yang baru dihapus",
+	CommandHistoryDesc:  "Lihat perubahan pada pengeluaran",
+	CommandRemindDesc:   "Dapatkan pengingat saat Anda belum mencatat apa pun hari itu",
+	CommandLanguageDesc: "Ganti bahasa bot",
+	CommandTokenDesc:    "Dapatkan token REST API",
+	CommandAppDesc:      "Lihat dan ubah pengeluaran Anda di aplikasi mini",
+	CommandCancelDesc:   "Berhenti memilih kategori atau langkah lain yang sedang berjalan",
+	CommandAccountDesc:  "Tambah dan kelola akun yang Anda gunakan untuk membayar",
+	CommandTransferDesc: "Pindahkan uang antar akun Anda",
+	CommandBalancesDesc: "Lihat saldo setiap akun",
+	CommandGoalDesc:     "Menabung untuk target dan pantau kemajuannya",
+	CommandLentDesc:     "Catat uang yang Anda pinjamkan kepada seseorang",
+	CommandBorrowedDesc: "Catat uang yang Anda pinjam dari seseorang",
+	CommandRepaidDesc:   "Catat pembayaran kembali antara Anda dan seseorang",
+	CommandOwedDesc:     "Lihat siapa berutang apa, atau atur pengingat utang",
+	CommandRefundDesc:   "Kembalikan sebagian atau seluruh pembelian",
+
+	RateLimitedMsg:   "Anda mengirim pesan terlalu cepat, mohon tunggu sebentar.",
+	NotRegisteredMsg: "Silakan kirim /start untuk mendaftar terlebih dahulu.",
+
+	YesButton:         "Ya",
+	RestoreButton:     "♻️ %d",
+	SplitButton:       "✂️ Pecah",
+	RefundButton:      "↩️ %d",
+	CancelButton:      "Batal",
+	LogNowButton:      "Catat sekarang",
+	SnoozeButton:      "Tunda %d mnt",
+	SnoozeHrButton:    "Tunda %d jam",
+	WebAppButton:      "Buka pengeluaran",
+	AccountNoneButton: "Tanpa akun",
+
+	GenericErrReplyMsg: "Terjadi kesalahan :(",
+	WorkInProgressMsg:  "Maaf, fitur ini masih dalam pengerjaan.",
+
+	TransactionTypeReplyKey(1): "Anda menghabiskan <b>%s</b> untuk <b>%s</b>\n",
+
+	CategoryKey("Bills"):     "Tagihan",
+	CategoryKey("Education"): "Pendidikan",
+	CategoryKey("Family"):    "Keluarga",
+	CategoryKey("Food"):      "Makanan",
+	CategoryKey("Fun"):       "Hiburan",
+	CategoryKey("Gifts"):     "Hadiah",
+	CategoryKey("Grocery"):   "Belanja Dapur",
+	CategoryKey("Health"):    "Kesehatan",
+	CategoryKey("Holiday"):   "Liburan",
+	CategoryKey("Housing"):   "Tempat Tinggal",
+	CategoryKey("Insurance"): "Asuransi",
+	CategoryKey("Shopping"):  "Belanja",
+	CategoryKey("Transport"): "Transportasi",
+	CategoryKey("Other"):     "Lainnya",
+}
@@ -0,0 +1,296 @@
+package message
+
+var msCatalog = map[Key]string{
+	HelpMsg: `
+Hantar mesej dengan format "[jumlah] [keterangan]" untuk merekod perbelanjaan.
+Pastikan jumlah hanya nombor atau perpuluhan tanpa kod atau simbol mata wang.
+Keterangan boleh mengandungi apa-apa aksara.
+
+Contoh:
+✔️ "5.50 Nasi Ayam" (tanpa tanda petik) untuk merekod perbelanjaan $5.50 dengan keterangan "Nasi Ayam".
+✔️ "100 Meja Ikea" (tanpa tanda petik) untuk merekod perbelanjaan $100 dengan keterangan "Meja Ikea".
+❌ "Komputer 2400" (tanpa tanda petik) akan memberi ralat.
+❌ "$20.78 Piza" (tanpa tanda petik) akan memberi ralat.
+
+Simbol mata wang lalai ialah dolar ($) dengan sokongan sehingga 2 tempat perpuluhan untuk sen.
+
+`,
+
+	HelpExamplesMsg: `
+Senarai perbelanjaan bulan dan tahun semasa
+Cth. "/list".
+
+Senarai perbelanjaan bagi sesuatu bulan
+Cth. "/list Feb" bagi Februari tahun semasa.
+Cth. "/list Feb 2022" atau "/list 2022-02".
+
+Senarai perbelanjaan bagi tempoh lain
+Cth. "/list this week" atau "/list last month".
+Cth. "/list 2023-Q1" atau "/list 2023".
+Cth. "/list last 30 days" atau "/list ytd".
+Cth. "/list 2023-01-15..2023-02-10".
+
+Senaraikan perbelanjaan yang dibayar dengan satu akaun
+Cth. "/list last month ^dbs".
+
+Stats dan export juga mengikut peraturan yang sama!
+
+Jika ada sebarang soalan atau masalah, e-mel saya di telegram.expense.bot@gmail.com
+`,
+
+	UserExistsMsg:            "Selamat kembali! Berikut ialah ringkasan transaksi anda: \n",
+	ErrorFindingUserMsg:      "Maaf, terdapat masalah untuk mendapatkan maklumat anda.\n",
+	ErrorCreatingUserMsg:     "Maaf, terdapat masalah untuk mendaftarkan anda.\n",
+	SignUpSuccessMsg:         "Tahniah!\nSelamat datang ke penjejak perbelanjaan anda!\nTaip /help untuk mengetahui cara menggunakan bot ini dengan segera!",
+	CannotRecogniseAmountMsg: "Saya tidak kenal jumlah wang itu :(\nTaip /help untuk mengetahui cara mula menjejak perbelanjaan anda!",
+	AmountPrecisionMsg:       "Jumlah dalam %s boleh mempunyai sehingga %d tempat perpuluhan.",
+	DescriptionTooLongMsg:    "Maaf, keterangan anda (maksimum %d aksara) terlalu panjang :( \n",
+	TransactionListEmptyMsg:  "Anda tiada transaksi dalam %s.",
+
+	TransactionTypeReplyMsg:          "Pilih jenis transaksi",
+	TransactionStartReplyMsg:         "Pilih kategori",
+	TransactionEndReplyMsg:           "\n<i>%s</i>",
+	TransactionLatestNotFound:        "Anda tiada lagi transaksi untuk dipadam.",
+	TransactionDeleteConfirmationMsg: "Adakah anda mahu memadam transaksi %s %s ?",
+	TransactionDeletedReplyMsg:       "Transaksi %s %s anda telah dipadam.",
+	TransactionUndoMoreHint:          "\nTaip /undo sekali lagi untuk memadam transaksi sebelumnya, atau /redo untuk memulihkannya.",
+	TransactionRestoredReplyMsg:      "Transaksi %s %s anda telah dipulihkan.",
+	TransactionNotInTrashMsg:         "Transaksi itu tiada lagi dalam tong sampah.",
+	RedoNothingMsg:                   "Tiada transaksi dipadam untuk dipulihkan.",
+	TrashHeader:                      "<b>Tong sampah</b>\n\n",
+	TrashEmptyMsg:                    "Tong sampah anda kosong.",
+	TrashFooterMsg:                   "Ketik nombor untuk memulihkan transaksinya. Transaksi yang dipadam disimpan selama %d hari.",
+	HistoryHeader:                    "<b>Sejarah #%d</b>\n\n",
+	HistoryEntryLine:                 "<code>%s</code> <b>%s</b> · %s\n%s\n\n",
+	HistoryDiffLine:                  "%s: %s → %s",
+	HistoryNoChangeMsg:               "Tiada perubahan",
+	HistoryUsageMsg:                  "Hantar /history diikuti nombor selepas # dalam /list atau /trash, cth. /history 12",
+	HistoryNotFoundMsg:               "Tiada sejarah untuk #%d.",
+	HistoryActionCreate:              "Dicipta",
+	HistoryActionUpdate:              "Disunting",
+	HistoryActionDelete:              "Dipadam",
+	HistoryActionRestore:             "Dipulihkan",
+	HistoryActionPurge:               "Dibuang",
+	HistorySourceBot:                 "bot",
+	HistorySourceApi:                 "API",
+	HistorySourceApp:                 "aplikasi mini",
+	HistorySourceImport:              "import",
+	HistorySourceRecurring:           "berulang",
+	HistorySourceJob:                 "tugas %s",
+
+	StatsTotalLabel:          "Jumlah",
+	ExportCaptionMsg:         "Perbelanjaan yang dieksport bagi %s",
+	ExportSheetName:          "Perbelanjaan",
+	ExportDateHeader:         "Tarikh",
+	ExportDescHeader:         "Keterangan",
+	ExportAmtHeader:          "Jumlah",
+	ExportCatHeader:          "Kategori",
+	ExportCurrHeader:         "Mata Wang",
+	ExportAccountHeader:      "Akaun",
+	HistorySheetName:         "Sejarah",
+	HistoryTimeHeader:        "Diubah pada",
+	HistoryTransactionHeader: "Transaksi",
+	HistoryActionHeader:      "Perubahan",
+	HistorySourceHeader:      "Oleh",
+	HistoryBeforeHeader:      "Sebelum",
+	HistoryAfterHeader:       "Selepas",
+
+	ReminderMsg:               "Anda belum merekod sebarang perbelanjaan hari ini. Ada bayar apa-apa dengan tunai?",
+	ReminderLogNowPromptMsg:   "Hantar jumlah dan keterangan, cth. 5.50 Nasi Ayam",
+	ReminderLogNowPlaceholder: "5.50 Nasi Ayam",
+	ReminderSnoozedReplyMsg:   "Baiklah, saya akan ingatkan anda lagi pada %s.",
+	ReminderNotFoundMsg:       "Anda belum menetapkan sebarang peringatan. Taip /remind 21:00 untuk bermula.",
+	ReminderUsageMsg: `
+Taip /remind 21:00 untuk diingatkan pada 9 malam pada hari anda belum merekod apa-apa.
+Taip /remind off untuk menghentikan peringatan, dan /remind on untuk menyambungnya semula.
+Taip /remind quiet 23:00 07:00 supaya tidak diingatkan antara 11 malam dan 7 pagi.
+Taip /remind quiet off untuk membuang waktu senyap.`,
+	ReminderInvalidMsg:     "Saya tidak faham tetapan peringatan itu :(\n",
+	ReminderQuietCoversMsg: "Waktu senyap tidak boleh meliputi masa peringatan pada %s.\n",
+	ReminderOffMsg:         "Peringatan dimatikan.",
+	ReminderOnMsg:          "Saya akan ingatkan anda pada %s jika anda belum merekod apa-apa hari itu.",
+	ReminderQuietHoursMsg:  "\nWaktu senyap: %s - %s",
+	ReminderSnoozedMsg:     "\nDitangguhkan sehingga %s",
+
+	LanguageSelectMsg:      "Pilih bahasa anda",
+	LanguageUpdatedMsg:     "Bahasa anda kini Bahasa Melayu.",
+	LanguageUnsupportedMsg: "Maaf, saya belum boleh bertutur dalam bahasa itu :(",
+
+	PeriodInvalidMsg:   "Saya tidak faham tempoh itu :(\nCuba this week, last month, 2023-Q1, 2023, last 30 days, ytd atau 2023-01-15..2023-02-10.",
+	PeriodQuarterLabel: "S%d %d",
+
+	InlineTotalTitle:         "Jumlah perbelanjaan %s",
+	InlineTopCategoriesTitle: "Kategori utama %s",
+	InlineRecentTitle:        "Perbelanjaan terkini %s",
+	InlineRecentDescription:  "%d perbelanjaan",
+	InlineSignUpMsg:          "Daftar untuk mula menjejak perbelanjaan anda",
+
+	TokenIssuedMsg:      "Ini token API anda, ia tidak akan dipaparkan lagi:\n<code>%s</code>\n\nHantar token dalam pengepala Authorization sebagai \"Bearer &lt;token&gt;\".\nTaip /token revoke untuk membatalkan semua token anda.",
+	TokenRevokedMsg:     "Semua token API anda telah dibatalkan.",
+	TokenPrivateChatMsg: "Demi keselamatan anda, hantar /token kepada saya dalam sembang peribadi.",
+
+	WebAppOpenMsg:          "Tekan butang di bawah untuk melihat, menyunting dan mencartakan perbelanjaan anda.",
+	WebAppNotConfiguredMsg: "Aplikasi mini tidak tersedia pada bot ini.",
+	WebAppPrivateChatMsg:   "Aplikasi mini hanya boleh dibuka dari sembang peribadi dengan saya.",
+
+	MenuExpiredMsg:            "Menu ini telah tamat tempoh, sila hantar semula.",
+	DefaultCategoryAppliedMsg: "Tiada kategori dipilih dalam masa, jadi kategori lalai digunakan.\n",
+	NoAccountAppliedMsg:       "Tiada akaun dipilih dalam masa, jadi jumlah ditambah tanpa akaun.\n",
+
+	ConversationCancelledMsg: "Dibatalkan.",
+	NoConversationMsg:        "Tiada apa-apa untuk dibatalkan.",
+
+	AccountUsageMsg: `
+Taip /account add DBS card untuk menambah akaun.
+Taip /account opening ^dbs 1200.50 untuk menetapkan baki akaun sebelum transaksi pertamanya.
+Taip /account delete ^dbs untuk memadam akaun yang tiada transaksi.
+Tambah ^dbs pada jumlah untuk membayar dengan akaun, cth. "5.50 Nasi Ayam ^dbs".
+Taip /transfer 100 ^dbs ^cash untuk memindahkan wang antara akaun, dan /balances untuk melihat baki setiap akaun.`,
+	AccountListMsg:             "Akaun anda:\n%s",
+	AccountNoneMsg:             "Anda belum mempunyai sebarang akaun.\n",
+	AccountAddedMsg:            "Akaun %s telah ditambah. Tambah ^%s pada jumlah untuk membayar dengannya.",
+	AccountExistsMsg:           "Anda sudah mempunyai akaun dengan nama itu.",
+	AccountNameInvalidMsg:      "Nama akaun mestilah 1 hingga %d aksara, tanpa ^.",
+	AccountOpeningSetMsg:       "Baki pembukaan %s kini %s.",
+	AccountDeletedMsg:          "Akaun %s telah dipadam.",
+	AccountInUseMsg:            "%s mempunyai transaksi atau pindahan, jadi ia tidak boleh dipadam.",
+	AccountUnknownMsg:          "Anda tiada akaun yang bermula dengan ^%s. Taip /account untuk melihat akaun anda.",
+	AccountAmbiguousMsg:        "Lebih daripada satu akaun anda bermula dengan ^%s, taip lebih banyak daripada namanya.",
+	AccountTooManyMsg:          "Berikan satu akaun sahaja, cth. ^dbs.",
+	AccountSelectMsg:           "Pilih akaun yang anda gunakan untuk membayar",
+	AccountFilterLabel:         "%s (%s)",
+	TransactionAccountReplyMsg: "\nDibayar dengan <b>%s</b>",
+	TransactionSplitMarker:     "✂️ Dipecahkan",
+	TransactionSplitReplyLine:  "\n• %s %s",
+	SplitHeaderMsg:             "Memecahkan %s %s\n",
+	SplitLineMsg:               "• %s %s\n",
+	SplitCategoryAskMsg:        "\nBaki %s. Ketik kategori bahagian seterusnya.",
+	SplitAmountAskMsg:          "\nBerapa banyak daripada baki %s untuk %s? Hantar jumlah seperti 80, atau peratusan jumlah keseluruhan seperti 40%%.",
+	SplitAmountInvalidMsg:      "Hantar jumlah sehingga %s, atau peratusan jumlah keseluruhan seperti 40%%.",
+	SplitTypeMismatchMsg:       "\nSetiap bahagian mesti sama jenis dengan %s. Ketik kategori lain.",
+	SplitNotPositiveMsg:        "\nHanya jumlah lebih daripada 0 boleh dipecahkan. Ketik satu kategori.",
+	TransactionIdReplyMsg:      "\n<code>#%d</code>",
+	TransactionRefundLink:      " ↩️ #%d",
+	RefundUsageMsg: `Taip /refund untuk memilih pembelian terkini untuk dipulangkan sepenuhnya, atau /refund 20 untuk memulangkan 20 daripadanya. Anda juga boleh membalas /refund pada mesej yang mengesahkan pembelian.
+Taip /refund month original untuk mengira bayaran balik dalam bulan pembeliannya, atau /refund month refund untuk mengiranya dalam bulan ia dipulangkan.`,
+	RefundSelectHeader:          "<b>Bayaran balik</b>\n\n",
+	RefundSelectLine:            "<code>%d. %s #%d\n%s %s %s</code>\n\n",
+	RefundSelectFooterMsg:       "Ketik nombor pembelian untuk memulangkan baki jumlahnya.",
+	RefundSelectAmountFooterMsg: "Ketik nombor pembelian untuk memulangkan %s daripadanya.",
+	RefundNoneMsg:               "Anda tiada pembelian untuk dipulangkan.",
+	RefundNotFoundMsg:           "Pembelian itu sudah tiada untuk dipulangkan.",
+	RefundNotPurchaseMsg:        "#%d ialah bayaran balik atau catatan negatif, jadi ia tidak boleh dipulangkan.",
+	RefundSplitMsg:              "#%d dipecahkan kepada beberapa kategori, jadi ia tidak boleh dipulangkan.",
+	RefundTooMuchMsg:            "Itu melebihi baki %s yang boleh dipulangkan bagi #%d.",
+	RefundReplyMsg:              "Dipulangkan %s daripada #%d %s di bawah %s.",
+	RefundMonthOriginalMsg:      "Statistik mengira bayaran balik dalam bulan pembeliannya.",
+	RefundMonthRefundMsg:        "Statistik mengira bayaran balik dalam bulan ia dipulangkan.",
+	BalancesHeader:              "<b>Baki</b>\n",
+	TransferUsageMsg:            "Taip /transfer 100 ^dbs ^cash ATM untuk memindahkan 100 dari ^dbs ke ^cash. Pindahan tidak dikira sebagai perbelanjaan.",
+	TransferReplyMsg:            "%s telah dipindahkan dari %s ke %s.",
+	GoalUsageMsg: `
+Taip /goal add Japan trip 3000 by 2024-12 untuk menyimpan 3000 bagi percutian sebelum Disember 2024.
+Taip /goal add 200 Japan untuk menyimpan 200 ke arahnya.
+Taip /goal monthly 250 Japan untuk menyimpan 250 ke arahnya pada 1hb setiap bulan, atau 0 untuk berhenti.
+Taip /goal delete Japan untuk memadamnya.`,
+	GoalListHeader:        "<b>Matlamat</b>\n\n",
+	GoalNoneMsg:           "Anda belum mempunyai matlamat simpanan.\n",
+	GoalAddedMsg:          "Matlamat %s untuk menyimpan %s telah ditambah.",
+	GoalAddedByMsg:        "Matlamat %s untuk menyimpan %s sebelum %s telah ditambah, iaitu %s sebulan.",
+	GoalExistsMsg:         "Anda sudah mempunyai matlamat dengan nama itu.",
+	GoalNameInvalidMsg:    "Nama matlamat mestilah 1 hingga %d aksara.",
+	GoalUnknownMsg:        "Anda tiada matlamat yang bermula dengan %s. Taip /goal untuk melihat matlamat anda.",
+	GoalAmbiguousMsg:      "Lebih daripada satu matlamat anda bermula dengan %s, taip lebih banyak daripada namanya.",
+	GoalContributedMsg:    "%s telah ditambah ke %s.\n\n",
+	GoalReachedMsg:        "🎉 Anda telah mencapai matlamat %s sebanyak %s! 🎉",
+	GoalMonthlySetMsg:     "%s akan disimpan ke arah %s pada 1hb setiap bulan.",
+	GoalMonthlyStoppedMsg: "Simpanan bulanan ke arah %s telah dihentikan.",
+	GoalDeletedMsg:        "Matlamat %s telah dipadam.",
+	GoalPaceMsg:           "Perlu %s sebulan sehingga %s, menyimpan %s sebulan",
+	GoalOverdueMsg:        "Tarikh akhir %s telah berlalu, menyimpan %s sebulan",
+	GoalActualPaceMsg:     "Menyimpan %s sebulan",
+	GoalReachedLabel:      "🎉 Tercapai!",
+	GoalMonthlyLabel:      "\n+%s pada 1hb setiap bulan",
+	IouUsageMsg: `
+Taip /lent 50 Alice concert apabila anda membayar 50 untuk Alice.
+Taip /borrowed 20 Bob taxi apabila Bob membayar 20 untuk anda.
+Taip /repaid 20 Alice apabila 20 dibayar balik antara anda dan Alice, tidak kira siapa yang berhutang.
+Taip /owed remind 30 untuk diingatkan tentang hutang yang tertunggak 30 hari atau lebih, atau /owed remind off untuk berhenti.
+Wang yang dipinjamkan dan dipinjam tidak dikira sebagai perbelanjaan.`,
+	IouListHeader:       "<b>Siapa berhutang apa</b>\n",
+	IouNoneMsg:          "Tiada siapa berhutang dengan anda, dan anda tidak berhutang dengan sesiapa.\n",
+	IouOwesYouLine:      "%s berhutang %s dengan anda sejak %s\n",
+	IouYouOweLine:       "Anda berhutang %[2]s dengan %[1]s sejak %[3]s\n",
+	IouOwesYouMsg:       "%s berhutang %s dengan anda.",
+	IouYouOweMsg:        "Anda berhutang %[2]s dengan %[1]s.",
+	IouSettledMsg:       "Anda dan %s sudah langsai.",
+	IouLentMsg:          "%s dipinjamkan kepada %s.\n",
+	IouBorrowedMsg:      "%s dipinjam daripada %s.\n",
+	IouRepaidYouMsg:     "%s telah membayar balik %s kepada anda.\n",
+	IouYouRepaidMsg:     "Anda telah membayar balik %[2]s kepada %[1]s.\n",
+	IouNothingOwedMsg:   "Anda dan %s tidak berhutang antara satu sama lain.",
+	IouRepaidTooMuchMsg: "Itu melebihi %s yang tertunggak dengan %s.",
+	IouPersonInvalidMsg: "Nama seseorang mestilah 1 hingga %d aksara.",
+	IouReminderSetMsg:   "Saya akan mengingatkan anda tentang hutang yang tertunggak %d hari atau lebih.",
+	IouReminderOffMsg:   "Peringatan hutang dimatikan.",
+	IouReminderMsg:      "<b>Tertunggak %d hari atau lebih</b>\n",
+
+	CommandStartDesc:    "Daftar untuk mula merekod perbelanjaan",
+	CommandHelpDesc:     "Tunjukkan cara menggunakan bot",
+	CommandStatsDesc:    "Lihat pecahan bagi sesuatu tempoh",
+	CommandSummaryDesc:  "Lihat perbelanjaan bulan ini dan matlamat anda",
+	CommandListDesc:     "Lihat perbelanjaan bagi sesuatu tempoh",
+	CommandExportDesc:   "Eksport perbelanjaan bagi sesuatu tempoh",
+	CommandUndoDesc:     "Batalkan perbelanjaan terakhir",
+	CommandRedoDesc:     "Pulihkan perbelanjaan terakhir yang dipadam",
+	CommandTrashDesc:    "Lihat dan pulihkan perbelanjaan yang baru dipadam",
+	CommandHistoryDesc:  "Lihat perubahan pada perbelanjaan",
+	CommandRemindDesc:   "Terima peringatan apabila anda belum merekod apa-apa hari itu",
+	CommandLanguageDesc: "Tukar bahasa bot",
+	CommandTokenDesc:    "Dapatkan token REST API",
+	CommandAppDesc:      "Lihat dan sunting perbelanjaan anda dalam aplikasi mini",
+	CommandCancelDesc:   "Berhenti memilih kategori atau langkah lain yang sedang berjalan",
+	CommandAccountDesc:  "Tambah dan urus akaun yang anda gunakan untuk membayar",
+	CommandTransferDesc: "Pindahkan wang antara akaun anda",
+	CommandBalancesDesc: "Lihat baki setiap akaun",
+	CommandGoalDesc:     "Simpan ke arah matlamat dan jejak kemajuannya",
+	CommandLentDesc:     "Rekod wang yang anda pinjamkan kepada seseorang",
+	CommandBorrowedDesc: "Rekod wang yang anda pinjam daripada seseorang",
+	CommandRepaidDesc:   "Rekod bayaran balik antara anda dan seseorang",
+	CommandOwedDesc:     "Lihat siapa berhutang apa, atau tetapkan peringatan hutang",
+	CommandRefundDesc:   "Pulangkan sebahagian atau semua pembelian",
+
+	RateLimitedMsg:   "Anda menghantar mesej terlalu cepat, sila tunggu sebentar.",
+	NotRegisteredMsg: "Sila hantar /start untuk mendaftar dahulu.",
+
+	YesButton:         "Ya",
+	RestoreButton:     "♻️ %d",
+	SplitButton:       "✂️ Pecahkan",
+	RefundButton:      "↩️ %d",
+	CancelButton:      "Batal",
+	LogNowButton:      "Rekod sekarang",
+	SnoozeButton:      "Tangguh %d min",
+	SnoozeHrButton:    "Tangguh %d jam",
+	WebAppButton:      "Buka perbelanjaan",
+	AccountNoneButton: "Tiada akaun",
+
+	GenericErrReplyMsg: "Ada sesuatu yang tidak kena :(",
+	WorkInProgressMsg:  "Maaf, fungsi ini masih dalam pembangunan.",
+
+	TransactionTypeReplyKey(1): "Anda berbelanja <b>%s</b> untuk <b>%s</b>\n",
+
+	CategoryKey("Bills"):     "Bil",
+	CategoryKey("Education"): "Pendidikan",
+	CategoryKey("Family"):    "Keluarga",
+	CategoryKey("Food"):      "Makanan",
+	CategoryKey("Fun"):       "Hiburan",
+	CategoryKey("Gifts"):     "Hadiah",
+	CategoryKey("Grocery"):   "Barangan Dapur",
+	CategoryKey("Health"):    "Kesihatan",
+	CategoryKey("Holiday"):   "Percutian",
+	CategoryKey("Housing"):   "Perumahan",
+	CategoryKey("Insurance"): "Insurans",
+	CategoryKey("Shopping"):  "Membeli-belah",
+	CategoryKey("Transport"): "Pengangkutan",
+	CategoryKey("Other"):     "Lain-lain",
+}
@@ -0,0 +1,296 @@
+package message
+
+var zhCatalog = map[Key]string{
+	HelpMsg: `
+直接发送 "[金额] [描述]" 格式的消息即可记录一笔支出。
+金额必须是纯数字或小数，不要带货币代码或符号。
+描述可以包含任何字符。
+
+例子：
+✔️ "5.50 鸡饭"（不含引号）记录一笔 $5.50 的支出，描述为 "鸡饭"。
+✔️ "100 宜家桌子"（不含引号）记录一笔 $100 的支出，描述为 "宜家桌子"。
+❌ "电脑 2400"（不含引号）会出错。
+❌ "$20.78 披萨"（不含引号）会出错。
+
+默认货币符号为 ($)，金额最多支持两位小数。
+
+`,
+
+	HelpExamplesMsg: `
+查看本月的支出
+例如 "/list"。
+
+查看某个月的支出
+例如 "/list Feb" 查看今年 2 月。
+例如 "/list Feb 2022" 或 "/list 2022-02"。
+
+查看其他时间段的支出
+例如 "/list this week" 或 "/list last month"。
+例如 "/list 2023-Q1" 或 "/list 2023"。
+例如 "/list last 30 days" 或 "/list ytd"。
+例如 "/list 2023-01-15..2023-02-10"。
+
+列出用某个账户支付的支出
+例如 "/list last month ^dbs"。
+
+/stats 和 /export 也使用相同的规则！
+
+如有任何问题，请发邮件至 telegram.expense.bot@gmail.com
+`,
+
+	UserExistsMsg:            "欢迎回来！以下是你的交易摘要：\n",
+	ErrorFindingUserMsg:      "抱歉，获取你的信息时出现问题。\n",
+	ErrorCreatingUserMsg:     "抱歉，注册时出现问题。\n",
+	SignUpSuccessMsg:         "恭喜！\n欢迎使用你的记账机器人！\n输入 /help 了解如何马上开始使用！",
+	CannotRecogniseAmountMsg: "我无法识别这个金额 :(\n输入 /help 了解如何开始记账！",
+	AmountPrecisionMsg:       "%s 金额最多只能有 %d 位小数。",
+	DescriptionTooLongMsg:    "抱歉，你的描述太长了（最多 %d 个字符）:( \n",
+	TransactionListEmptyMsg:  "你在%s没有任何交易。",
+
+	TransactionTypeReplyMsg:          "请选择交易类型",
+	TransactionStartReplyMsg:         "请选择类别",
+	TransactionEndReplyMsg:           "\n<i>%s</i>",
+	TransactionLatestNotFound:        "没有可以删除的交易了。",
+	TransactionDeleteConfirmationMsg: "确定要删除 %s %s 这笔交易吗？",
+	TransactionDeletedReplyMsg:       "%s %s 这笔交易已删除。",
+	TransactionUndoMoreHint:          "\n再次输入 /undo 删除上一笔，或输入 /redo 恢复这一笔。",
+	TransactionRestoredReplyMsg:      "%s %s 这笔交易已恢复。",
+	TransactionNotInTrashMsg:         "这笔交易已不在回收站中。",
+	RedoNothingMsg:                   "没有可恢复的已删除交易。",
+	TrashHeader:                      "<b>回收站</b>\n\n",
+	TrashEmptyMsg:                    "回收站是空的。",
+	TrashFooterMsg:                   "点击编号以恢复对应的交易。已删除的交易会保留 %d 天。",
+	HistoryHeader:                    "<b>#%d 的历史记录</b>\n\n",
+	HistoryEntryLine:                 "<code>%s</code> <b>%s</b> · %s\n%s\n\n",
+	HistoryDiffLine:                  "%s：%s → %s",
+	HistoryNoChangeMsg:               "没有变更",
+	HistoryUsageMsg:                  "请发送 /history 并附上 /list 或 /trash 中 # 后面的编号，例如 /history 12",
+	HistoryNotFoundMsg:               "没有 #%d 的历史记录。",
+	HistoryActionCreate:              "创建",
+	HistoryActionUpdate:              "编辑",
+	HistoryActionDelete:              "删除",
+	HistoryActionRestore:             "恢复",
+	HistoryActionPurge:               "永久删除",
+	HistorySourceBot:                 "机器人",
+	HistorySourceApi:                 "API",
+	HistorySourceApp:                 "小程序",
+	HistorySourceImport:              "导入",
+	HistorySourceRecurring:           "定期",
+	HistorySourceJob:                 "%s 任务",
+
+	StatsTotalLabel:          "总计",
+	ExportCaptionMsg:         "已导出%s的支出",
+	ExportSheetName:          "支出",
+	ExportDateHeader:         "日期",
+	ExportDescHeader:         "描述",
+	ExportAmtHeader:          "金额",
+	ExportCatHeader:          "类别",
+	ExportCurrHeader:         "货币",
+	ExportAccountHeader:      "账户",
+	HistorySheetName:         "历史记录",
+	HistoryTimeHeader:        "变更时间",
+	HistoryTransactionHeader: "交易",
+	HistoryActionHeader:      "变更",
+	HistorySourceHeader:      "来源",
+	HistoryBeforeHeader:      "变更前",
+	HistoryAfterHeader:       "变更后",
+
+	ReminderMsg:               "你今天还没有记录任何支出。有没有用现金付款？",
+	ReminderLogNowPromptMsg:   "请发送金额和描述，例如 5.50 鸡饭",
+	ReminderLogNowPlaceholder: "5.50 鸡饭",
+	ReminderSnoozedReplyMsg:   "好的，我会在 %s 再提醒你。",
+	ReminderNotFoundMsg:       "你还没有设置提醒。输入 /remind 21:00 开始使用。",
+	ReminderUsageMsg: `
+输入 /remind 21:00 在没有记账的日子于晚上 9 点收到提醒。
+输入 /remind off 停止提醒，输入 /remind on 恢复提醒。
+输入 /remind quiet 23:00 07:00 在晚上 11 点到早上 7 点之间不打扰你。
+输入 /remind quiet off 取消免打扰时段。`,
+	ReminderInvalidMsg:     "我看不懂这个提醒设置 :(\n",
+	ReminderQuietCoversMsg: "免打扰时段不能包含提醒时间 %s。\n",
+	ReminderOffMsg:         "提醒已关闭。",
+	ReminderOnMsg:          "如果当天没有记账，我会在 %s 提醒你。",
+	ReminderQuietHoursMsg:  "\n免打扰时段：%s - %s",
+	ReminderSnoozedMsg:     "\n已延后至 %s",
+
+	LanguageSelectMsg:      "请选择语言",
+	LanguageUpdatedMsg:     "语言已设置为中文。",
+	LanguageUnsupportedMsg: "抱歉，我暂时还不支持这个语言 :(",
+
+	PeriodInvalidMsg:   "我看不懂这个时间段 :(\n试试 this week、last month、2023-Q1、2023、last 30 days、ytd 或 2023-01-15..2023-02-10。",
+	PeriodQuarterLabel: "%[2]d年第%[1]d季度",
+
+	InlineTotalTitle:         "%s 总支出",
+	InlineTopCategoriesTitle: "%s 主要类别",
+	InlineRecentTitle:        "%s 最近支出",
+	InlineRecentDescription:  "%d 笔支出",
+	InlineSignUpMsg:          "注册以开始记录支出",
+
+	TokenIssuedMsg:      "这是您的 API 令牌，它不会再次显示：\n<code>%s</code>\n\n请在 Authorization 请求头中以 \"Bearer &lt;令牌&gt;\" 发送。\n输入 /token revoke 撤销您的所有令牌。",
+	TokenRevokedMsg:     "您的所有 API 令牌已被撤销。",
+	TokenPrivateChatMsg: "为了您的安全，请在私聊中向我发送 /token。",
+
+	WebAppOpenMsg:          "点击下面的按钮浏览、编辑您的支出并查看图表。",
+	WebAppNotConfiguredMsg: "此机器人未启用小程序。",
+	WebAppPrivateChatMsg:   "小程序只能在与我的私聊中打开。",
+
+	MenuExpiredMsg:            "此菜单已过期，请重新发送。",
+	DefaultCategoryAppliedMsg: "未及时选择类别，已使用默认类别。\n",
+	NoAccountAppliedMsg:       "未及时选择账户，已添加金额但未关联账户。\n",
+
+	ConversationCancelledMsg: "已取消。",
+	NoConversationMsg:        "没有可以取消的操作。",
+
+	AccountUsageMsg: `
+输入 /account add DBS card 添加账户。
+输入 /account opening ^dbs 1200.50 设置账户在第一笔交易之前的余额。
+输入 /account delete ^dbs 删除没有交易的账户。
+在金额后加上 ^dbs 即可用该账户付款，例如 "5.50 鸡饭 ^dbs"。
+输入 /transfer 100 ^dbs ^cash 在账户之间转账，输入 /balances 查看各账户的余额。`,
+	AccountListMsg:             "你的账户：\n%s",
+	AccountNoneMsg:             "你还没有任何账户。\n",
+	AccountAddedMsg:            "已添加账户 %s。在金额后加上 ^%s 即可用它付款。",
+	AccountExistsMsg:           "你已经有同名的账户了。",
+	AccountNameInvalidMsg:      "账户名称必须是 1 至 %d 个字符，且不含 ^。",
+	AccountOpeningSetMsg:       "%s 的期初余额现在是 %s。",
+	AccountDeletedMsg:          "已删除账户 %s。",
+	AccountInUseMsg:            "%s 有交易或转账记录，无法删除。",
+	AccountUnknownMsg:          "你没有以 ^%s 开头的账户。输入 /account 查看你的账户。",
+	AccountAmbiguousMsg:        "你有多个账户以 ^%s 开头，请输入更完整的名称。",
+	AccountTooManyMsg:          "请只指定一个账户，例如 ^dbs。",
+	AccountSelectMsg:           "选择你付款的账户",
+	AccountFilterLabel:         "%s（%s）",
+	TransactionAccountReplyMsg: "\n用 <b>%s</b> 付款",
+	TransactionSplitMarker:     "✂️ 拆分",
+	TransactionSplitReplyLine:  "\n• %s %s",
+	SplitHeaderMsg:             "正在拆分 %s %s\n",
+	SplitLineMsg:               "• %s %s\n",
+	SplitCategoryAskMsg:        "\n剩余 %s。请点击下一部分的类别。",
+	SplitAmountAskMsg:          "\n剩余的 %[1]s 中有多少属于%[2]s？请发送金额（例如 80）或占总额的百分比（例如 40%%）。",
+	SplitAmountInvalidMsg:      "请发送不超过 %s 的金额，或占总额的百分比（例如 40%%）。",
+	SplitTypeMismatchMsg:       "\n每个部分都必须与%s属于同一类型。请点击其他类别。",
+	SplitNotPositiveMsg:        "\n只有大于 0 的金额才能拆分。请点击一个类别。",
+	TransactionIdReplyMsg:      "\n<code>#%d</code>",
+	TransactionRefundLink:      " ↩️ #%d",
+	RefundUsageMsg: `输入 /refund 从最近的消费中选择一笔全额退款，或输入 /refund 20 退款其中的 20。你也可以回复确认消费的消息并输入 /refund。
+输入 /refund month original 将退款计入消费当月，或输入 /refund month refund 将退款计入退款当月。`,
+	RefundSelectHeader:          "<b>退款</b>\n\n",
+	RefundSelectLine:            "<code>%d. %s #%d\n%s %s %s</code>\n\n",
+	RefundSelectFooterMsg:       "点击要退款的消费编号，退还其剩余金额。",
+	RefundSelectAmountFooterMsg: "点击要退款 %s 的消费编号。",
+	RefundNoneMsg:               "你没有可以退款的消费。",
+	RefundNotFoundMsg:           "该消费已不存在，无法退款。",
+	RefundNotPurchaseMsg:        "#%d 是退款或负数记录，无法退款。",
+	RefundSplitMsg:              "#%d 已拆分到多个类别，无法退款。",
+	RefundTooMuchMsg:            "这超过了剩余可退款的 %s（#%d）。",
+	RefundReplyMsg:              "已退款 %s，来自 #%d %s（%s）。",
+	RefundMonthOriginalMsg:      "统计会将退款计入消费当月。",
+	RefundMonthRefundMsg:        "统计会将退款计入退款当月。",
+	BalancesHeader:              "<b>余额</b>\n",
+	TransferUsageMsg:            "输入 /transfer 100 ^dbs ^cash 提款 将 100 从 ^dbs 转到 ^cash。转账不计入支出。",
+	TransferReplyMsg:            "已将 %s 从 %s 转到 %s。",
+	GoalUsageMsg: `
+输入 /goal add Japan trip 3000 by 2024-12 在 2024 年 12 月前为旅行存下 3000。
+输入 /goal add 200 Japan 向该目标存入 200。
+输入 /goal monthly 250 Japan 在每月 1 日自动存入 250，输入 0 则停止。
+输入 /goal delete Japan 删除该目标。`,
+	GoalListHeader:        "<b>储蓄目标</b>\n\n",
+	GoalNoneMsg:           "您还没有储蓄目标。\n",
+	GoalAddedMsg:          "已添加目标 %s，需存下 %s。",
+	GoalAddedByMsg:        "已添加目标 %s，需在 %s 前存下 %s，即每月 %s。",
+	GoalExistsMsg:         "您已有同名的目标。",
+	GoalNameInvalidMsg:    "目标名称必须为 1 至 %d 个字符。",
+	GoalUnknownMsg:        "您没有以 %s 开头的目标。输入 /goal 查看您的目标。",
+	GoalAmbiguousMsg:      "有多个目标以 %s 开头，请输入更完整的名称。",
+	GoalContributedMsg:    "已向 %[2]s 存入 %[1]s。\n\n",
+	GoalReachedMsg:        "🎉 恭喜！您已达成目标 %s（%s）！🎉",
+	GoalMonthlySetMsg:     "每月 1 日将自动向 %[2]s 存入 %[1]s。",
+	GoalMonthlyStoppedMsg: "已停止每月自动存入 %s。",
+	GoalDeletedMsg:        "已删除目标 %s。",
+	GoalPaceMsg:           "%[2]s 前每月需存 %[1]s，目前每月存 %[3]s",
+	GoalOverdueMsg:        "已超过期限 %s，目前每月存 %s",
+	GoalActualPaceMsg:     "目前每月存 %s",
+	GoalReachedLabel:      "🎉 已达成！",
+	GoalMonthlyLabel:      "\n每月 1 日自动存入 %s",
+	IouUsageMsg: `
+当您为 Alice 支付 50 时，输入 /lent 50 Alice concert。
+当 Bob 为您支付 20 时，输入 /borrowed 20 Bob taxi。
+当您与 Alice 之间还款 20 时（无论谁欠谁），输入 /repaid 20 Alice。
+输入 /owed remind 30 以在欠款超过 30 天时收到提醒，输入 /owed remind off 停止提醒。
+借出和借入的钱不计入支出。`,
+	IouListHeader:       "<b>欠款</b>\n",
+	IouNoneMsg:          "没有人欠您钱，您也不欠任何人钱。\n",
+	IouOwesYouLine:      "%s 欠您 %s（自 %s 起）\n",
+	IouYouOweLine:       "您欠 %s %s（自 %s 起）\n",
+	IouOwesYouMsg:       "%s 欠您 %s。",
+	IouYouOweMsg:        "您欠 %s %s。",
+	IouSettledMsg:       "您与 %s 已两清。",
+	IouLentMsg:          "已借给 %[2]s %[1]s。\n",
+	IouBorrowedMsg:      "已向 %[2]s 借入 %[1]s。\n",
+	IouRepaidYouMsg:     "%s 已还您 %s。\n",
+	IouYouRepaidMsg:     "您已还给 %s %s。\n",
+	IouNothingOwedMsg:   "您与 %s 之间没有欠款。",
+	IouRepaidTooMuchMsg: "这超过了您与 %[2]s 之间未结清的 %[1]s。",
+	IouPersonInvalidMsg: "姓名须为 1 至 %d 个字符。",
+	IouReminderSetMsg:   "欠款超过 %d 天时我会提醒您。",
+	IouReminderOffMsg:   "欠款提醒已关闭。",
+	IouReminderMsg:      "<b>超过 %d 天未结清的欠款</b>\n",
+
+	CommandStartDesc:    "注册以开始记账",
+	CommandHelpDesc:     "查看机器人的使用方法",
+	CommandStatsDesc:    "查看某段时间的支出分类",
+	CommandSummaryDesc:  "查看本月支出和储蓄目标",
+	CommandListDesc:     "查看某段时间的支出记录",
+	CommandExportDesc:   "导出某段时间的支出记录",
+	CommandUndoDesc:     "撤销最后一笔支出",
+	CommandRedoDesc:     "恢复最后删除的支出",
+	CommandTrashDesc:    "查看并恢复最近删除的支出",
+	CommandHistoryDesc:  "查看一笔支出的变更记录",
+	CommandRemindDesc:   "在当天没有记账时收到提醒",
+	CommandLanguageDesc: "更改机器人的语言",
+	CommandTokenDesc:    "获取 REST API 令牌",
+	CommandAppDesc:      "在小程序中浏览和编辑您的支出",
+	CommandCancelDesc:   "停止选择类别或正在进行的其他步骤",
+	CommandAccountDesc:  "添加和管理你的付款账户",
+	CommandTransferDesc: "在你的账户之间转账",
+	CommandBalancesDesc: "查看各账户的余额",
+	CommandGoalDesc:     "设定储蓄目标并跟踪进度",
+	CommandLentDesc:     "记录借给别人的钱",
+	CommandBorrowedDesc: "记录向别人借的钱",
+	CommandRepaidDesc:   "记录您与别人之间的还款",
+	CommandOwedDesc:     "查看欠款或设置欠款提醒",
+	CommandRefundDesc:   "退还一笔消费的部分或全部",
+
+	RateLimitedMsg:   "您发送消息太快了，请稍等片刻。",
+	NotRegisteredMsg: "请先发送 /start 注册。",
+
+	YesButton:         "是",
+	RestoreButton:     "♻️ %d",
+	SplitButton:       "✂️ 拆分",
+	RefundButton:      "↩️ %d",
+	CancelButton:      "取消",
+	LogNowButton:      "马上记账",
+	SnoozeButton:      "%d 分钟后提醒",
+	SnoozeHrButton:    "%d 小时后提醒",
+	WebAppButton:      "打开支出",
+	AccountNoneButton: "不指定账户",
+
+	GenericErrReplyMsg: "出了点问题 :(",
+	WorkInProgressMsg:  "抱歉，这个功能还在开发中。",
+
+	TransactionTypeReplyKey(1): "你在 <b>%[2]s</b> 上花了 <b>%[1]s</b>\n",
+
+	CategoryKey("Bills"):     "账单",
+	CategoryKey("Education"): "教育",
+	CategoryKey("Family"):    "家庭",
+	CategoryKey("Food"):      "餐饮",
+	CategoryKey("Fun"):       "娱乐",
+	CategoryKey("Gifts"):     "礼物",
+	CategoryKey("Grocery"):   "杂货",
+	CategoryKey("Health"):    "健康",
+	CategoryKey("Holiday"):   "旅行",
+	CategoryKey("Housing"):   "住房",
+	CategoryKey("Insurance"): "保险",
+	CategoryKey("Shopping"):  "购物",
+	CategoryKey("Transport"): "交通",
+	CategoryKey("Other"):     "其他",
+}
@@ -0,0 +1,189 @@
+package message
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Rhymond/go-money"
+)
+
+const DefaultLocale = "en"
+
+// Locale holds the message catalog and the number and date formats of a language
+type Locale struct {
+	Code           string
+	Name           string
+	DecimalSep     string
+	ThousandSep    string
+	DateTimeLayout string
+	DateLayout     string
+	TimeLayout     string
+	ExcelDateFmt   string
+	Months         [12]string
+	catalog        map[Key]string
+}
+
+var locales = []Locale{
+	{
+		Code:           "en",
+		Name:           "English",
+		DecimalSep:     ".",
+		ThousandSep:    ",",
+		DateTimeLayout: "02/01/06 15:04",
+		DateLayout:     "02/01/06",
+		TimeLayout:     "15:04",
+		ExcelDateFmt:   "dd/mm/yyyy hh:mm",
+		Months:         [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		catalog:        enCatalog,
+	},
+	{
+		Code:           "zh",
+		Name:           "中文",
+		DecimalSep:     ".",
+		ThousandSep:    ",",
+		DateTimeLayout: "06/01/02 15:04",
+		DateLayout:     "06/01/02",
+		TimeLayout:     "15:04",
+		ExcelDateFmt:   "yyyy/mm/dd hh:mm",
+		Months:         [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		catalog:        zhCatalog,
+	},
+	{
+		Code:           "ms",
+		Name:           "Bahasa Melayu",
+		DecimalSep:     ".",
+		ThousandSep:    ",",
+		DateTimeLayout: "02/01/06 15:04",
+		DateLayout:     "02/01/06",
+		TimeLayout:     "15:04",
+		ExcelDateFmt:   "dd/mm/yyyy hh:mm",
+		Months:         [12]string{"Januari", "Februari", "Mac", "April", "Mei", "Jun", "Julai", "Ogos", "September", "Oktober", "November", "Disember"},
+		catalog:        msCatalog,
+	},
+	{
+		Code:           "id",
+		Name:           "Bahasa Indonesia",
+		DecimalSep:     ",",
+		ThousandSep:    ".",
+		DateTimeLayout: "02/01/06 15.04",
+		DateLayout:     "02/01/06",
+		TimeLayout:     "15.04",
+		ExcelDateFmt:   "dd/mm/yyyy hh.mm",
+		Months:         [12]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"},
+		catalog:        idCatalog,
+	},
+}
+
+// SupportedLocales returns all the locales with a message catalog
+func SupportedLocales() []Locale {
+	return locales
+}
+
+// MatchLocale finds the supported locale of a language code such as "en" or "zh-hans"
+func MatchLocale(code string) (Locale, bool) {
+	language, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(code)), "-")
+	for _, l := range locales {
+		if l.Code == language {
+			return l, true
+		}
+	}
+	return Locale{}, false
+}
+
+// GetLocale returns the locale of the language code, falling back to the default locale
+func GetLocale(code string) Locale {
+	l, ok := MatchLocale(code)
+	if !ok {
+		l, _ = MatchLocale(DefaultLocale)
+	}
+	return l
+}
+
+// Lookup returns the message of the key in this locale only
+func (l Locale) Lookup(key Key) (string, bool) {
+	s, ok := l.catalog[key]
+	return s, ok
+}
+
+// Get returns the message of the key formatted with the args, falling back to the default locale
+func (l Locale) Get(key Key, args ...any) string {
+	s, ok := l.Lookup(key)
+	if !ok {
+		s, ok = enCatalog[key]
+	}
+	if !ok {
+		s = string(key)
+	}
+	if len(args) == 0 {
+		return s
+	}
+	return fmt.Sprintf(s, args...)
+}
+
+// GetOrDefault returns the translated message of the key, or the default message if there is no translation
+func (l Locale) GetOrDefault(key Key, defaultMsg string) string {
+	s, ok := l.Lookup(key)
+	if !ok {
+		return defaultMsg
+	}
+	return s
+}
+
+// CategoryName translates the name of a category seeded in the database
+func (l Locale) CategoryName(name string) string {
+	return l.GetOrDefault(CategoryKey(name), name)
+}
+
+func (l Locale) MonthName(month time.Month) string {
+	if month < time.January || month > time.December {
+		return month.String()
+	}
+	return l.Months[month-1]
+}
+
+func (l Locale) FormatDateTime(t time.Time) string {
+	return t.Format(l.DateTimeLayout)
+}
+
+func (l Locale) FormatDate(t time.Time) string {
+	return t.Format(l.DateLayout)
+}
+
+func (l Locale) FormatTime(t time.Time) string {
+	return t.Format(l.TimeLayout)
+}
+
+// FormatMoney displays the money with the currency symbol of the money and the separators of the locale
+func (l Locale) FormatMoney(m *money.Money) string {
+	c := m.Currency()
+	return money.NewFormatter(c.Fraction, l.DecimalSep, l.ThousandSep, c.Grapheme, c.Template).Format(m.Amount())
+}
+
+// FormatNumber formats a float with the given number of decimal places and the separators of the locale
+func (l Locale) FormatNumber(value float64, decimals int) string {
+	s := strconv.FormatFloat(value, 'f', decimals, 64)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	for i := len(intPart) - 3; i > 0; i -= 3 {
+		intPart = intPart[:i] + l.ThousandSep + intPart[i:]
+	}
+	if fracPart != "" {
+		intPart += l.DecimalSep + fracPart
+	}
+	if negative {
+		intPart = "-" + intPart
+	}
+	return intPart
+}
+
+func CategoryKey(name string) Key {
+	return Key("category." + name)
+}
+
+func TransactionTypeReplyKey(id int) Key {
+	return Key("transaction_type." + strconv.Itoa(id))
+}
@@ -0,0 +1,97 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/Rhymond/go-money"
+)
+
+func TestMatchLocale(t *testing.T) {
+	tests := []struct {
+		code   string
+		want   string
+		wantOk bool
+	}{
+		{"en", "en", true},
+		{"zh-hans", "zh", true},
+		{"MS", "ms", true},
+		{" id ", "id", true},
+		{"fr", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			got, ok := MatchLocale(tt.code)
+			if ok != tt.wantOk || got.Code != tt.want {
+				t.Errorf("MatchLocale(%q) = %q, %v, want %q, %v", tt.code, got.Code, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestGetLocale_Fallback(t *testing.T) {
+	if got := GetLocale("fr").Code; got != DefaultLocale {
+		t.Errorf("expected %s, got %s", DefaultLocale, got)
+	}
+}
+
+func TestLocale_Get(t *testing.T) {
+	zh := GetLocale("zh")
+	if got := zh.Get(CancelButton); got != "取消" {
+		t.Errorf("expected 取消, got %s", got)
+	}
+	if got := zh.Get(DescriptionTooLongMsg, 10); got == string(DescriptionTooLongMsg) {
+		t.Errorf("expected formatted message, got %s", got)
+	}
+	if got := zh.Get(Key("missing")); got != "missing" {
+		t.Errorf("expected key as fallback, got %s", got)
+	}
+}
+
+func TestLocale_CategoryName(t *testing.T) {
+	if got := GetLocale("id").CategoryName("Food"); got != "Makanan" {
+		t.Errorf("expected Makanan, got %s", got)
+	}
+	if got := GetLocale("id").CategoryName("Custom"); got != "Custom" {
+		t.Errorf("expected Custom, got %s", got)
+	}
+}
+
+func TestLocale_FormatMoney(t *testing.T) {
+	m := money.New(123456, money.SGD)
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"en", "$1,234.56"},
+		{"id", "$1.234,56"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := GetLocale(tt.code).FormatMoney(m); got != tt.want {
+				t.Errorf("FormatMoney() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocale_FormatNumber(t *testing.T) {
+	tests := []struct {
+		code     string
+		value    float64
+		decimals int
+		want     string
+	}{
+		{"en", 1234567.891, 2, "1,234,567.89"},
+		{"id", 1234567.891, 2, "1.234.567,89"},
+		{"en", -62.5, 1, "-62.5"},
+		{"en", 100, 0, "100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := GetLocale(tt.code).FormatNumber(tt.value, tt.decimals); got != tt.want {
+				t.Errorf("FormatNumber() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
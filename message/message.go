@@ -1,49 +1,229 @@
 package message
 
+// Key identifies a message in the catalog of every supported locale
+type Key string
+
 const (
-	HelpMsg = `
-Message directly with the format "[amount] [description]" to start recording an expenses. 
-Make sure the amount is purely a number or decimal with no currency code or symbol.
-The description can be made up of any characters.
-
-Example:
-✔️ "5.50 Chicken Rice" (without the quotes) to record an expense of $5.50 with the description "Chicken Rice".
-✔️ "100 Ikea Table" (without the quotes) to record an expense of $100 with the description "Ikea Table".
-❌ "Computer 2400" (without the quotes) will give an error".
-❌ "$20.78 Pizza" (without the quotes) will give an error".
-	
-The recorded dollar ($) is the default currency symbol with support to up to 2 decimal places for the cents.
-
-Type /stats [month] [year] to view the breakdown for the month.
-Type /list [month] [year] to view the expenses for the month.
-Type /export [month] [year] to export the expenses for the month.
-Type /undo to revert the last recorded expenses.
-
-List the expenses for current month and year
-E.g. "/list".
-
-List the expenses for the month of current year
-E.g. "/list 2".
-E.g. "/list Feb".
-E.g. "/list February".
-
-List the expenses for the month of February 2022
-E.g. "/list 2 2022".
-E.g. "/list Feb 2022".
-E.g. "/list February 2022".
-
-Stats and export follow the same rules as well!
-
-If you have any questions or problems, email me at telegram.expense.bot@gmail.com
-`
-
-	TransactionTypeReplyMsg          = "Select a transaction type"
-	TransactionStartReplyMsg         = "Select a category"
-	TransactionEndReplyMsg           = "\n<i>%s</i>"
-	TransactionLatestNotFound        = "You have no more transaction to delete."
-	TransactionDeleteConfirmationMsg = "Do you want to delete your transaction of %s %s ?"
-	TransactionDeletedReplyMsg       = "Your transaction of %s %s has been deleted."
-
-	GenericErrReplyMsg = "Something went wrong :("
-	WorkInProgressMsg  = "Sorry this function is still a work in progress."
+	HelpMsg         Key = "help"
+	HelpExamplesMsg Key = "help_examples"
+
+	UserExistsMsg            Key = "user_exists"
+	ErrorFindingUserMsg      Key = "error_finding_user"
+	ErrorCreatingUserMsg     Key = "error_creating_user"
+	SignUpSuccessMsg         Key = "sign_up_success"
+	CannotRecogniseAmountMsg Key = "cannot_recognise_amount"
+	AmountPrecisionMsg       Key = "amount_precision"
+	DescriptionTooLongMsg    Key = "description_too_long"
+	TransactionListEmptyMsg  Key = "transaction_list_empty"
+
+	TransactionTypeReplyMsg          Key = "transaction_type_reply"
+	TransactionStartReplyMsg         Key = "transaction_start_reply"
+	TransactionEndReplyMsg           Key = "transaction_end_reply"
+	TransactionLatestNotFound        Key = "transaction_latest_not_found"
+	TransactionDeleteConfirmationMsg Key = "transaction_delete_confirmation"
+	TransactionDeletedReplyMsg       Key = "transaction_deleted_reply"
+	TransactionUndoMoreHint          Key = "transaction_undo_more_hint"
+	TransactionRestoredReplyMsg      Key = "transaction_restored_reply"
+	TransactionNotInTrashMsg         Key = "transaction_not_in_trash"
+	RedoNothingMsg                   Key = "redo_nothing"
+	TrashHeader                      Key = "trash_header"
+	TrashEmptyMsg                    Key = "trash_empty"
+	TrashFooterMsg                   Key = "trash_footer"
+	HistoryHeader                    Key = "history_header"
+	HistoryEntryLine                 Key = "history_entry_line"
+	HistoryDiffLine                  Key = "history_diff_line"
+	HistoryNoChangeMsg               Key = "history_no_change"
+	HistoryUsageMsg                  Key = "history_usage"
+	HistoryNotFoundMsg               Key = "history_not_found"
+	HistoryActionCreate              Key = "history_action_create"
+	HistoryActionUpdate              Key = "history_action_update"
+	HistoryActionDelete              Key = "history_action_delete"
+	HistoryActionRestore             Key = "history_action_restore"
+	HistoryActionPurge               Key = "history_action_purge"
+	HistorySourceBot                 Key = "history_source_bot"
+	HistorySourceApi                 Key = "history_source_api"
+	HistorySourceApp                 Key = "history_source_app"
+	HistorySourceImport              Key = "history_source_import"
+	HistorySourceRecurring           Key = "history_source_recurring"
+	HistorySourceJob                 Key = "history_source_job"
+
+	StatsTotalLabel          Key = "stats_total_label"
+	ExportCaptionMsg         Key = "export_caption"
+	ExportSheetName          Key = "export_sheet_name"
+	ExportDateHeader         Key = "export_date_header"
+	ExportDescHeader         Key = "export_description_header"
+	ExportAmtHeader          Key = "export_amount_header"
+	ExportCatHeader          Key = "export_category_header"
+	ExportCurrHeader         Key = "export_currency_header"
+	ExportAccountHeader      Key = "export_account_header"
+	HistorySheetName         Key = "history_sheet_name"
+	HistoryTimeHeader        Key = "history_time_header"
+	HistoryTransactionHeader Key = "history_transaction_header"
+	HistoryActionHeader      Key = "history_action_header"
+	HistorySourceHeader      Key = "history_source_header"
+	HistoryBeforeHeader      Key = "history_before_header"
+	HistoryAfterHeader       Key = "history_after_header"
+
+	ReminderMsg               Key = "reminder"
+	ReminderLogNowPromptMsg   Key = "reminder_log_now_prompt"
+	ReminderLogNowPlaceholder Key = "reminder_log_now_placeholder"
+	ReminderSnoozedReplyMsg   Key = "reminder_snoozed_reply"
+	ReminderNotFoundMsg       Key = "reminder_not_found"
+	ReminderUsageMsg          Key = "reminder_usage"
+	ReminderInvalidMsg        Key = "reminder_invalid"
+	ReminderQuietCoversMsg    Key = "reminder_quiet_covers"
+	ReminderOffMsg            Key = "reminder_off"
+	ReminderOnMsg             Key = "reminder_on"
+	ReminderQuietHoursMsg     Key = "reminder_quiet_hours"
+	ReminderSnoozedMsg        Key = "reminder_snoozed"
+
+	LanguageSelectMsg      Key = "language_select"
+	LanguageUpdatedMsg     Key = "language_updated"
+	LanguageUnsupportedMsg Key = "language_unsupported"
+
+	PeriodInvalidMsg   Key = "period_invalid"
+	PeriodQuarterLabel Key = "period_quarter_label"
+
+	InlineTotalTitle         Key = "inline_total_title"
+	InlineTopCategoriesTitle Key = "inline_top_categories_title"
+	InlineRecentTitle        Key = "inline_recent_title"
+	InlineRecentDescription  Key = "inline_recent_description"
+	InlineSignUpMsg          Key = "inline_sign_up"
+
+	TokenIssuedMsg      Key = "token_issued"
+	TokenRevokedMsg     Key = "token_revoked"
+	TokenPrivateChatMsg Key = "token_private_chat"
+
+	WebAppOpenMsg          Key = "web_app_open"
+	WebAppNotConfiguredMsg Key = "web_app_not_configured"
+	WebAppPrivateChatMsg   Key = "web_app_private_chat"
+
+	MenuExpiredMsg            Key = "menu_expired"
+	DefaultCategoryAppliedMsg Key = "default_category_applied"
+	NoAccountAppliedMsg       Key = "no_account_applied"
+
+	ConversationCancelledMsg Key = "conversation_cancelled"
+	NoConversationMsg        Key = "no_conversation"
+
+	AccountUsageMsg             Key = "account_usage"
+	AccountListMsg              Key = "account_list"
+	AccountNoneMsg              Key = "account_none"
+	AccountAddedMsg             Key = "account_added"
+	AccountExistsMsg            Key = "account_exists"
+	AccountNameInvalidMsg       Key = "account_name_invalid"
+	AccountOpeningSetMsg        Key = "account_opening_set"
+	AccountDeletedMsg           Key = "account_deleted"
+	AccountInUseMsg             Key = "account_in_use"
+	AccountUnknownMsg           Key = "account_unknown"
+	AccountAmbiguousMsg         Key = "account_ambiguous"
+	AccountTooManyMsg           Key = "account_too_many"
+	AccountSelectMsg            Key = "account_select"
+	AccountFilterLabel          Key = "account_filter_label"
+	TransactionAccountReplyMsg  Key = "transaction_account_reply"
+	TransactionSplitMarker      Key = "transaction_split_marker"
+	TransactionSplitReplyLine   Key = "transaction_split_reply_line"
+	SplitHeaderMsg              Key = "split_header"
+	SplitLineMsg                Key = "split_line"
+	SplitCategoryAskMsg         Key = "split_category_ask"
+	SplitAmountAskMsg           Key = "split_amount_ask"
+	SplitAmountInvalidMsg       Key = "split_amount_invalid"
+	SplitTypeMismatchMsg        Key = "split_type_mismatch"
+	SplitNotPositiveMsg         Key = "split_not_positive"
+	TransactionIdReplyMsg       Key = "transaction_id_reply"
+	TransactionRefundLink       Key = "transaction_refund_link"
+	RefundUsageMsg              Key = "refund_usage"
+	RefundSelectHeader          Key = "refund_select_header"
+	RefundSelectLine            Key = "refund_select_line"
+	RefundSelectFooterMsg       Key = "refund_select_footer"
+	RefundSelectAmountFooterMsg Key = "refund_select_amount_footer"
+	RefundNoneMsg               Key = "refund_none"
+	RefundNotFoundMsg           Key = "refund_not_found"
+	RefundNotPurchaseMsg        Key = "refund_not_purchase"
+	RefundSplitMsg              Key = "refund_split"
+	RefundTooMuchMsg            Key = "refund_too_much"
+	RefundReplyMsg              Key = "refund_reply"
+	RefundMonthOriginalMsg      Key = "refund_month_original"
+	RefundMonthRefundMsg        Key = "refund_month_refund"
+	BalancesHeader              Key = "balances_header"
+	TransferUsageMsg            Key = "transfer_usage"
+	TransferReplyMsg            Key = "transfer_reply"
+	GoalUsageMsg                Key = "goal_usage"
+	GoalListHeader              Key = "goal_list_header"
+	GoalNoneMsg                 Key = "goal_none"
+	GoalAddedMsg                Key = "goal_added"
+	GoalAddedByMsg              Key = "goal_added_by"
+	GoalExistsMsg               Key = "goal_exists"
+	GoalNameInvalidMsg          Key = "goal_name_invalid"
+	GoalUnknownMsg              Key = "goal_unknown"
+	GoalAmbiguousMsg            Key = "goal_ambiguous"
+	GoalContributedMsg          Key = "goal_contributed"
+	GoalReachedMsg              Key = "goal_reached"
+	GoalMonthlySetMsg           Key = "goal_monthly_set"
+	GoalMonthlyStoppedMsg       Key = "goal_monthly_stopped"
+	GoalDeletedMsg              Key = "goal_deleted"
+	GoalPaceMsg                 Key = "goal_pace"
+	GoalOverdueMsg              Key = "goal_overdue"
+	GoalActualPaceMsg           Key = "goal_actual_pace"
+	GoalReachedLabel            Key = "goal_reached_label"
+	GoalMonthlyLabel            Key = "goal_monthly_label"
+	IouUsageMsg                 Key = "iou_usage"
+	IouListHeader               Key = "iou_list_header"
+	IouNoneMsg                  Key = "iou_none"
+	IouOwesYouLine              Key = "iou_owes_you_line"
+	IouYouOweLine               Key = "iou_you_owe_line"
+	IouOwesYouMsg               Key = "iou_owes_you"
+	IouYouOweMsg                Key = "iou_you_owe"
+	IouSettledMsg               Key = "iou_settled"
+	IouLentMsg                  Key = "iou_lent"
+	IouBorrowedMsg              Key = "iou_borrowed"
+	IouRepaidYouMsg             Key = "iou_repaid_you"
+	IouYouRepaidMsg             Key = "iou_you_repaid"
+	IouNothingOwedMsg           Key = "iou_nothing_owed"
+	IouRepaidTooMuchMsg         Key = "iou_repaid_too_much"
+	IouPersonInvalidMsg         Key = "iou_person_invalid"
+	IouReminderSetMsg           Key = "iou_reminder_set"
+	IouReminderOffMsg           Key = "iou_reminder_off"
+	IouReminderMsg              Key = "iou_reminder"
+
+	CommandStartDesc    Key = "command_start_desc"
+	CommandHelpDesc     Key = "command_help_desc"
+	CommandStatsDesc    Key = "command_stats_desc"
+	CommandSummaryDesc  Key = "command_summary_desc"
+	CommandListDesc     Key = "command_list_desc"
+	CommandExportDesc   Key = "command_export_desc"
+	CommandUndoDesc     Key = "command_undo_desc"
+	CommandRedoDesc     Key = "command_redo_desc"
+	CommandTrashDesc    Key = "command_trash_desc"
+	CommandHistoryDesc  Key = "command_history_desc"
+	CommandRemindDesc   Key = "command_remind_desc"
+	CommandLanguageDesc Key = "command_language_desc"
+	CommandTokenDesc    Key = "command_token_desc"
+	CommandAppDesc      Key = "command_app_desc"
+	CommandCancelDesc   Key = "command_cancel_desc"
+	CommandAccountDesc  Key = "command_account_desc"
+	CommandTransferDesc Key = "command_transfer_desc"
+	CommandBalancesDesc Key = "command_balances_desc"
+	CommandGoalDesc     Key = "command_goal_desc"
+	CommandLentDesc     Key = "command_lent_desc"
+	CommandBorrowedDesc Key = "command_borrowed_desc"
+	CommandRepaidDesc   Key = "command_repaid_desc"
+	CommandOwedDesc     Key = "command_owed_desc"
+	CommandRefundDesc   Key = "command_refund_desc"
+
+	RateLimitedMsg   Key = "rate_limited"
+	NotRegisteredMsg Key = "not_registered"
+
+	YesButton         Key = "button_yes"
+	RestoreButton     Key = "button_restore"
+	SplitButton       Key = "button_split"
+	RefundButton      Key = "button_refund"
+	CancelButton      Key = "button_cancel"
+	LogNowButton      Key = "button_log_now"
+	SnoozeButton      Key = "button_snooze"
+	SnoozeHrButton    Key = "button_snooze_hours"
+	WebAppButton      Key = "button_web_app"
+	AccountNoneButton Key = "button_account_none"
+
+	GenericErrReplyMsg Key = "generic_error"
+	WorkInProgressMsg  Key = "work_in_progress"
 )
@@ -0,0 +1,128 @@
+// Package metrics exposes the prometheus metrics of the bot on /metrics
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "expense_bot"
+
+var (
+	registry = prometheus.NewRegistry()
+
+	updatesReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "updates_received_total",
+		Help:      "Telegram updates received, by type of update.",
+	}, []string{"type"})
+
+	handlerDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "handler_duration_seconds",
+		Help:      "Time taken to handle an update, by command, callback type or inline query.",
+		Buckets:   []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"handler"})
+
+	genericErrorReplies = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "generic_error_replies_total",
+		Help:      "Generic error messages sent to users, by handler.",
+	}, []string{"handler"})
+
+	updatesDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "dispatch_updates_dropped_total",
+		Help:      "Updates dropped because the backlog of their chat was full.",
+	})
+
+	telegramRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telegram_requests_total",
+		Help:      "Telegram Bot API calls, by method and result (ok, error or rate_limited).",
+	}, []string{"method", "result"})
+)
+
+var (
+	telegramRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telegram_retries_total",
+		Help:      "Telegram Bot API calls retried by the sender, by reason (rate_limited or server_error).",
+	}, []string{"reason"})
+
+	telegramFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telegram_failures_total",
+		Help:      "Telegram Bot API calls the sender gave up on.",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		updatesReceived,
+		handlerDuration,
+		genericErrorReplies,
+		updatesDropped,
+		telegramRequests,
+		telegramRetries,
+		telegramFailures,
+	)
+}
+
+// Handler serves the metrics in the prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveUpdate records an update of the type, e.g. message or callback_query
+func ObserveUpdate(updateType string) {
+	updatesReceived.WithLabelValues(updateType).Inc()
+}
+
+// ObserveHandler records the time taken by the handler since start
+func ObserveHandler(handler string, start time.Time) {
+	handlerDuration.WithLabelValues(handler).Observe(time.Since(start).Seconds())
+}
+
+// ObserveUpdateDropped records an update dropped because the backlog of its chat was full
+func ObserveUpdateDropped() {
+	updatesDropped.Inc()
+}
+
+// ObserveTelegramRetry records a call to the Bot API that is retried for the reason
+func ObserveTelegramRetry(reason string) {
+	telegramRetries.WithLabelValues(reason).Inc()
+}
+
+// ObserveTelegramFailure records a call to the Bot API that could not be sent after all the retries
+func ObserveTelegramFailure() {
+	telegramFailures.Inc()
+}
+
+// RegisterQueueDepth reports the number of updates received but not dispatched yet, as returned by depth
+func RegisterQueueDepth(depth func() int) {
+	registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "update_queue_depth",
+		Help:      "Updates received but not dispatched yet.",
+	}, func() float64 {
+		return float64(depth())
+	}))
+}
+
+// RegisterDispatchDepth reports the number of updates dispatched and waiting for a worker, as returned by depth
+func RegisterDispatchDepth(depth func() int) {
+	registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "dispatch_depth",
+		Help:      "Updates dispatched and waiting for a worker.",
+	}, func() float64 {
+		return float64(depth())
+	}))
+}
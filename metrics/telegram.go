@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+
+	"github.com/aattwwss/telegram-expense-bot/message"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// genericErrors holds the generic error message of every locale
+var genericErrors = genericErrorTexts()
+
+// telegramClient counts the calls made to the Bot API on behalf of a handler
+type telegramClient struct {
+	next    tgbotapi.HTTPClient
+	handler string
+}
+
+// InstrumentBot returns a copy of the bot that records its calls to the Bot API, and the generic error messages it
+// sends, under the handler. The copy shares the token and http client of the bot.
+func InstrumentBot(bot *tgbotapi.BotAPI, handler string) *tgbotapi.BotAPI {
+	instrumented := *bot
+	instrumented.Client = telegramClient{
+		next:    bot.Client,
+		handler: handler,
+	}
+	return &instrumented
+}
+
+func (c telegramClient) Do(req *http.Request) (*http.Response, error) {
+	method := path.Base(req.URL.Path)
+	if method == "sendMessage" && c.isGenericError(req) {
+		genericErrorReplies.WithLabelValues(c.handler).Inc()
+	}
+
+	resp, err := c.next.Do(req)
+	switch {
+	case err != nil:
+		telegramRequests.WithLabelValues(method, "error").Inc()
+	case resp.StatusCode == http.StatusTooManyRequests:
+		telegramRequests.WithLabelValues(method, "rate_limited").Inc()
+	case resp.StatusCode >= http.StatusBadRequest:
+		telegramRequests.WithLabelValues(method, "error").Inc()
+	default:
+		telegramRequests.WithLabelValues(method, "ok").Inc()
+	}
+	return resp, err
+}
+
+// isGenericError reads the text of the form sent by the bot library, and puts the body back for the actual request
+func (c telegramClient) isGenericError(req *http.Request) bool {
+	if req.Body == nil {
+		return false
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return false
+	}
+	return slices.Contains(genericErrors, values.Get("text"))
+}
+
+func genericErrorTexts() []string {
+	var texts []string
+	for _, l := range message.SupportedLocales() {
+		texts = append(texts, l.Get(message.GenericErrReplyMsg))
+	}
+	return texts
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aattwwss/telegram-expense-bot/message"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakeClient struct {
+	status   int
+	err      error
+	gotTexts []string
+}
+
+func (c *fakeClient) Do(req *http.Request) (*http.Response, error) {
+	body, _ := io.ReadAll(req.Body)
+	c.gotTexts = append(c.gotTexts, string(body))
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &http.Response{
+		StatusCode: c.status,
+		Body:       io.NopCloser(strings.NewReader(`{"ok":true,"result":{}}`)),
+	}, nil
+}
+
+func newTestBot(client *fakeClient) *tgbotapi.BotAPI {
+	bot := &tgbotapi.BotAPI{Token: "dummy", Client: client}
+	bot.SetAPIEndpoint(tgbotapi.APIEndpoint)
+	return bot
+}
+
+func TestInstrumentBot_TelegramRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		client     *fakeClient
+		wantResult string
+	}{
+		{"ok", &fakeClient{status: http.StatusOK}, "ok"},
+		{"rate limited", &fakeClient{status: http.StatusTooManyRequests}, "rate_limited"},
+		{"bad request", &fakeClient{status: http.StatusBadRequest}, "error"},
+		{"network error", &fakeClient{err: errors.New("connection reset")}, "error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := testutil.ToFloat64(telegramRequests.WithLabelValues("deleteMessage", tt.wantResult))
+
+			bot := InstrumentBot(newTestBot(tt.client), "command:undo")
+			bot.Request(tgbotapi.NewDeleteMessage(1, 2))
+
+			after := testutil.ToFloat64(telegramRequests.WithLabelValues("deleteMessage", tt.wantResult))
+			if after-before != 1 {
+				t.Errorf("expected one %s deleteMessage request, got %v", tt.wantResult, after-before)
+			}
+		})
+	}
+}
+
+func TestInstrumentBot_GenericErrorReplies(t *testing.T) {
+	client := &fakeClient{status: http.StatusOK}
+	bot := InstrumentBot(newTestBot(client), "command:stats")
+	before := testutil.ToFloat64(genericErrorReplies.WithLabelValues("command:stats"))
+
+	bot.Request(tgbotapi.NewMessage(1, message.GetLocale("zh").Get(message.GenericErrReplyMsg)))
+	bot.Request(tgbotapi.NewMessage(1, "Hello"))
+
+	if got := testutil.ToFloat64(genericErrorReplies.WithLabelValues("command:stats")) - before; got != 1 {
+		t.Errorf("expected one generic error reply, got %v", got)
+	}
+	if len(client.gotTexts) != 2 || !strings.Contains(client.gotTexts[1], "text=Hello") {
+		t.Errorf("expected the request body to be passed on, got %v", client.gotTexts)
+	}
+}
+
+func TestInstrumentBot_DoesNotChangeOriginal(t *testing.T) {
+	client := &fakeClient{status: http.StatusOK}
+	bot := newTestBot(client)
+	InstrumentBot(bot, "inline")
+
+	if bot.Client != client {
+		t.Errorf("expected the original bot to keep its client")
+	}
+}
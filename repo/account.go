@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/dao"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+type AccountRepo struct {
+	accountDao  dao.AccountDAO
+	transferDao dao.TransferDAO
+}
+
+func NewAccountRepo(accountDao dao.AccountDAO, transferDao dao.TransferDAO) AccountRepo {
+	return AccountRepo{accountDao: accountDao, transferDao: transferDao}
+}
+
+func (repo AccountRepo) FindAllByUserId(ctx context.Context, userId int64) (domain.Accounts, error) {
+	entities, err := repo.accountDao.FindAllByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	accounts := domain.Accounts{}
+	for _, e := range entities {
+		accounts = append(accounts, domain.AccountFromEntity(e))
+	}
+	return accounts, nil
+}
+
+func (repo AccountRepo) GetById(ctx context.Context, id int, userId int64) (domain.Account, error) {
+	e, err := repo.accountDao.GetById(ctx, id, userId)
+	if err != nil {
+		return domain.Account{}, err
+	}
+	return domain.AccountFromEntity(e), nil
+}
+
+func (repo AccountRepo) Add(ctx context.Context, account domain.Account) (int, error) {
+	return repo.accountDao.Insert(ctx, entity.Account{
+		UserId:         account.UserId,
+		Name:           account.Name,
+		OpeningBalance: account.OpeningBalance.Amount(),
+		Currency:       account.OpeningBalance.Currency().Code,
+	})
+}
+
+func (repo AccountRepo) SetOpeningBalance(ctx context.Context, id int, userId int64, openingBalance *money.Money) error {
+	return repo.accountDao.UpdateOpeningBalance(ctx, id, userId, openingBalance.Amount())
+}
+
+func (repo AccountRepo) DeleteById(ctx context.Context, id int, userId int64) error {
+	return repo.accountDao.DeleteById(ctx, id, userId)
+}
+
+func (repo AccountRepo) GetBalances(ctx context.Context, userId int64) (domain.AccountBalances, error) {
+	entities, err := repo.accountDao.FindBalancesByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	balances := domain.AccountBalances{}
+	for _, e := range entities {
+		balances = append(balances, domain.AccountBalanceFromEntity(e))
+	}
+	return balances, nil
+}
+
+func (repo AccountRepo) Transfer(ctx context.Context, transfer domain.Transfer) (int, error) {
+	return repo.transferDao.Insert(ctx, entity.Transfer{
+		Datetime:      transfer.Datetime,
+		UserId:        transfer.UserId,
+		FromAccountId: transfer.FromAccountId,
+		ToAccountId:   transfer.ToAccountId,
+		Amount:        transfer.Amount.Amount(),
+		Currency:      transfer.Amount.Currency().Code,
+		Description:   transfer.Description,
+	})
+}
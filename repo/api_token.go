@@ -0,0 +1,50 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aattwwss/telegram-expense-bot/dao"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+type ApiTokenRepo struct {
+	apiTokenDao dao.ApiTokenDAO
+}
+
+func NewApiTokenRepo(apiTokenDao dao.ApiTokenDAO) ApiTokenRepo {
+	return ApiTokenRepo{apiTokenDao: apiTokenDao}
+}
+
+// Issue creates a new token for the user. Only the hash is stored, so the returned token cannot be retrieved again.
+func (repo ApiTokenRepo) Issue(ctx context.Context, userId int64) (string, error) {
+	token, err := domain.NewApiToken()
+	if err != nil {
+		return "", err
+	}
+	_, err = repo.apiTokenDao.Insert(ctx, entity.ApiToken{
+		UserId:    userId,
+		TokenHash: domain.HashApiToken(token),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Authenticate returns the id of the user who owns the token
+func (repo ApiTokenRepo) Authenticate(ctx context.Context, token string) (int64, error) {
+	userId, err := repo.apiTokenDao.FindUserIdByTokenHash(ctx, domain.HashApiToken(token))
+	if err != nil {
+		return 0, err
+	}
+	if userId == nil {
+		return 0, fmt.Errorf("api token %w", entity.ErrNotFound)
+	}
+	return *userId, nil
+}
+
+func (repo ApiTokenRepo) RevokeAll(ctx context.Context, userId int64) error {
+	return repo.apiTokenDao.DeleteByUserId(ctx, userId)
+}
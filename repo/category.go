@@ -15,8 +15,8 @@ func NewCategoryRepo(categoryDao dao.CategoryDAO) CategoryRepo {
 	return CategoryRepo{categoryDao: categoryDao}
 }
 
-func (repo CategoryRepo) FindAll(ctx context.Context) ([]*entity.Category, error) {
-	return repo.categoryDao.FindAll(ctx)
+func (repo CategoryRepo) FindAllByUserId(ctx context.Context, userId int64) ([]*entity.Category, error) {
+	return repo.categoryDao.FindAllByUserId(ctx, userId)
 }
 
 func (repo CategoryRepo) FindByTransactionTypeId(ctx context.Context, transactionTypeId int) ([]*entity.Category, error) {
@@ -30,3 +30,15 @@ func (repo CategoryRepo) GetById(ctx context.Context, id int) (*entity.Category,
 	}
 	return &e, nil
 }
+
+func (repo CategoryRepo) Add(ctx context.Context, category entity.Category) (int, error) {
+	return repo.categoryDao.Insert(ctx, category)
+}
+
+func (repo CategoryRepo) Rename(ctx context.Context, id int, userId int64, name string) error {
+	return repo.categoryDao.UpdateName(ctx, id, userId, name)
+}
+
+func (repo CategoryRepo) DeleteById(ctx context.Context, id int, userId int64) error {
+	return repo.categoryDao.DeleteById(ctx, id, userId)
+}
@@ -0,0 +1,50 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/dao"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+// ConversationRepo stores the conversations of the conversation.Manager
+type ConversationRepo struct {
+	conversationDao dao.ConversationDAO
+}
+
+func NewConversationRepo(conversationDao dao.ConversationDAO) ConversationRepo {
+	return ConversationRepo{conversationDao: conversationDao}
+}
+
+func (repo ConversationRepo) Insert(ctx context.Context, c entity.Conversation) (int, error) {
+	return repo.conversationDao.Insert(ctx, c)
+}
+
+func (repo ConversationRepo) GetById(ctx context.Context, id int) (*entity.Conversation, error) {
+	return repo.conversationDao.GetById(ctx, id)
+}
+
+func (repo ConversationRepo) FindLatestAwaitingText(ctx context.Context, chatId int64, userId int64, now time.Time) (*entity.Conversation, error) {
+	return repo.conversationDao.FindLatestAwaitingText(ctx, chatId, userId, now)
+}
+
+func (repo ConversationRepo) Extend(ctx context.Context, id int, now time.Time, expiresAt time.Time) error {
+	return repo.conversationDao.Extend(ctx, id, now, expiresAt)
+}
+
+func (repo ConversationRepo) Update(ctx context.Context, c entity.Conversation) error {
+	return repo.conversationDao.Update(ctx, c)
+}
+
+func (repo ConversationRepo) DeleteById(ctx context.Context, id int) error {
+	return repo.conversationDao.DeleteById(ctx, id)
+}
+
+func (repo ConversationRepo) TakeByChatIdAndUserId(ctx context.Context, chatId int64, userId int64) ([]entity.Conversation, error) {
+	return repo.conversationDao.TakeByChatIdAndUserId(ctx, chatId, userId)
+}
+
+func (repo ConversationRepo) TakeExpired(ctx context.Context, now time.Time) ([]entity.Conversation, error) {
+	return repo.conversationDao.TakeExpired(ctx, now)
+}
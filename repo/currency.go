@@ -0,0 +1,20 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/aattwwss/telegram-expense-bot/dao"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+type CurrencyRepo struct {
+	currencyDao dao.CurrencyDAO
+}
+
+func NewCurrencyRepo(currencyDao dao.CurrencyDAO) CurrencyRepo {
+	return CurrencyRepo{currencyDao: currencyDao}
+}
+
+func (repo CurrencyRepo) GetAll(ctx context.Context) ([]entity.Currency, error) {
+	return repo.currencyDao.GetAll(ctx)
+}
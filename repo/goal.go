@@ -0,0 +1,97 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/dao"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+type GoalRepo struct {
+	goalDao dao.GoalDAO
+}
+
+func NewGoalRepo(goalDao dao.GoalDAO) GoalRepo {
+	return GoalRepo{goalDao: goalDao}
+}
+
+func (repo GoalRepo) FindAllByUserId(ctx context.Context, userId int64) (domain.Goals, error) {
+	entities, err := repo.goalDao.FindAllByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	goals := domain.Goals{}
+	for _, e := range entities {
+		goals = append(goals, domain.GoalFromEntity(e))
+	}
+	return goals, nil
+}
+
+func (repo GoalRepo) Add(ctx context.Context, goal domain.Goal) (int, error) {
+	return repo.goalDao.Insert(ctx, entity.Goal{
+		UserId:   goal.UserId,
+		ChatId:   goal.ChatId,
+		Name:     goal.Name,
+		Target:   goal.Target.Amount(),
+		Currency: goal.Target.Currency().Code,
+		Deadline: goal.Deadline,
+	})
+}
+
+func (repo GoalRepo) DeleteById(ctx context.Context, id int, userId int64) error {
+	return repo.goalDao.DeleteById(ctx, id, userId)
+}
+
+// SetMonthlyAmount puts the amount towards the goal every month from next, or stops when it is zero
+func (repo GoalRepo) SetMonthlyAmount(ctx context.Context, id int, userId int64, amount *money.Money, next time.Time) error {
+	return repo.goalDao.UpdateMonthlyAmount(ctx, id, userId, amount.Amount(), next)
+}
+
+// Contribute puts the amount towards a goal of the user, and returns the goal with true when it is reached for the
+// first time
+func (repo GoalRepo) Contribute(ctx context.Context, id int, userId int64, amount *money.Money, now time.Time) (domain.Goal, bool, error) {
+	err := repo.goalDao.InsertContribution(ctx, entity.GoalContribution{
+		GoalId:   id,
+		Datetime: now,
+		Amount:   amount.Amount(),
+		Currency: amount.Currency().Code,
+	}, userId)
+	if err != nil {
+		return domain.Goal{}, false, err
+	}
+	return repo.markReached(ctx, id, now)
+}
+
+// ContributeMonthly puts the monthly amount towards every goal due at now, and returns the goals reached by it
+func (repo GoalRepo) ContributeMonthly(ctx context.Context, now time.Time) (domain.Goals, error) {
+	ids, err := repo.goalDao.InsertMonthlyContributions(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	reached := domain.Goals{}
+	for _, id := range ids {
+		goal, ok, err := repo.markReached(ctx, id, now)
+		if err != nil {
+			return reached, err
+		}
+		if ok {
+			reached = append(reached, goal)
+		}
+	}
+	return reached, nil
+}
+
+func (repo GoalRepo) markReached(ctx context.Context, id int, now time.Time) (domain.Goal, bool, error) {
+	ok, err := repo.goalDao.MarkReached(ctx, id, now)
+	if err != nil {
+		return domain.Goal{}, false, err
+	}
+	e, err := repo.goalDao.GetById(ctx, id)
+	if err != nil {
+		return domain.Goal{}, false, err
+	}
+	return domain.GoalFromEntity(e), ok, nil
+}
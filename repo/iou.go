@@ -0,0 +1,94 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/dao"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+type IouRepo struct {
+	iouDao dao.IouDAO
+}
+
+func NewIouRepo(iouDao dao.IouDAO) IouRepo {
+	return IouRepo{iouDao: iouDao}
+}
+
+// Add records the iou, settles the entries of the person once they add up to nothing, and returns the balance with the
+// person afterwards
+func (repo IouRepo) Add(ctx context.Context, iou domain.Iou) (*money.Money, error) {
+	currency := iou.Amount.Currency().Code
+	_, err := repo.iouDao.Insert(ctx, entity.Iou{
+		UserId:      iou.UserId,
+		Person:      iou.Person,
+		Amount:      iou.Amount.Amount(),
+		Currency:    currency,
+		Description: iou.Description,
+		Datetime:    iou.Datetime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = repo.iouDao.SettleIfClear(ctx, iou.UserId, iou.Person, currency, iou.Datetime)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetBalance(ctx, iou.UserId, iou.Person, currency)
+}
+
+// GetBalance returns what the person owes the user in the currency, which is negative when the user owes them
+func (repo IouRepo) GetBalance(ctx context.Context, userId int64, person string, currency string) (*money.Money, error) {
+	balance, err := repo.iouDao.GetBalance(ctx, userId, person, currency)
+	if err != nil {
+		return nil, err
+	}
+	return money.New(balance, currency), nil
+}
+
+func (repo IouRepo) GetBalances(ctx context.Context, userId int64) (domain.IouBalances, error) {
+	entities, err := repo.iouDao.FindBalancesByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	balances := domain.IouBalances{}
+	for _, e := range entities {
+		balances = append(balances, domain.IouBalanceFromEntity(e))
+	}
+	return balances, nil
+}
+
+func (repo IouRepo) SetReminder(ctx context.Context, userId int64, chatId int64, days int) error {
+	return repo.iouDao.UpsertReminder(ctx, entity.IouReminder{
+		UserId: userId,
+		ChatId: chatId,
+		Days:   days,
+	})
+}
+
+func (repo IouRepo) DeleteReminder(ctx context.Context, userId int64) error {
+	return repo.iouDao.DeleteReminder(ctx, userId)
+}
+
+func (repo IouRepo) FindDueReminders(ctx context.Context, now time.Time) ([]domain.IouReminder, error) {
+	var reminders []domain.IouReminder
+	entities, err := repo.iouDao.FindDueReminders(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entities {
+		reminder, err := domain.IouReminderFromEntity(e)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, *reminder)
+	}
+	return reminders, nil
+}
+
+func (repo IouRepo) MarkReminded(ctx context.Context, userId int64, now time.Time) error {
+	return repo.iouDao.MarkReminderSent(ctx, userId, now)
+}
@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/aattwwss/telegram-expense-bot/dao"
@@ -10,19 +11,22 @@ import (
 
 type MessageContextRepo struct {
 	messageContextDAO dao.MessageContextDAO
+	ttl               time.Duration
 }
 
-func NewMessageContextRepo(messageContextDAO dao.MessageContextDAO) MessageContextRepo {
-	return MessageContextRepo{messageContextDAO: messageContextDAO}
+// NewMessageContextRepo returns a repo whose menus expire after ttl
+func NewMessageContextRepo(messageContextDAO dao.MessageContextDAO, ttl time.Duration) MessageContextRepo {
+	return MessageContextRepo{messageContextDAO: messageContextDAO, ttl: ttl}
 }
 
 func (repo MessageContextRepo) Add(ctx context.Context, chatId int64, messageId int, message string) (int, error) {
-
+	now := time.Now()
 	id, err := repo.messageContextDAO.Insert(ctx, entity.MessageContext{
 		ChatId:    chatId,
 		MessageId: messageId,
 		Message:   message,
-		CreatedAt: time.Now(),
+		CreatedAt: now,
+		ExpiresAt: now.Add(repo.ttl),
 	})
 
 	if err != nil {
@@ -32,11 +36,15 @@ func (repo MessageContextRepo) Add(ctx context.Context, chatId int64, messageId
 	return id, nil
 }
 
+// GetMessageById returns the message of the context, or entity.ErrNotFound once it is deleted or expired
 func (repo MessageContextRepo) GetMessageById(ctx context.Context, id int) (string, error) {
 	e, err := repo.messageContextDAO.GetById(ctx, id)
 	if err != nil {
 		return "", err
 	}
+	if !time.Now().Before(e.ExpiresAt) {
+		return "", fmt.Errorf("message context %w: id=%d expired at %v", entity.ErrNotFound, id, e.ExpiresAt)
+	}
 	return e.Message, nil
 }
 
@@ -47,3 +55,7 @@ func (repo MessageContextRepo) DeleteById(ctx context.Context, id int) error {
 	}
 	return nil
 }
+
+func (repo MessageContextRepo) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return repo.messageContextDAO.DeleteExpired(ctx, now)
+}
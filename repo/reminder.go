@@ -0,0 +1,64 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/dao"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+)
+
+type ReminderRepo struct {
+	reminderDao dao.ReminderDAO
+}
+
+func NewReminderRepo(reminderDao dao.ReminderDAO) ReminderRepo {
+	return ReminderRepo{reminderDao: reminderDao}
+}
+
+func (repo ReminderRepo) GetByUserId(ctx context.Context, userId int64) (*domain.Reminder, error) {
+	e, err := repo.reminderDao.GetByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, nil
+	}
+	return domain.ReminderFromEntity(*e)
+}
+
+func (repo ReminderRepo) FindAllEnabled(ctx context.Context) ([]domain.Reminder, error) {
+	var reminders []domain.Reminder
+	entities, err := repo.reminderDao.FindAllEnabled(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entities {
+		reminder, err := domain.ReminderFromEntity(*e)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, *reminder)
+	}
+	return reminders, nil
+}
+
+func (repo ReminderRepo) Save(ctx context.Context, reminder domain.Reminder) error {
+	return repo.reminderDao.Upsert(ctx, entity.Reminder{
+		UserId:     reminder.UserId,
+		ChatId:     reminder.ChatId,
+		Enabled:    reminder.Enabled,
+		RemindAt:   reminder.RemindAt,
+		QuietStart: reminder.QuietStart,
+		QuietEnd:   reminder.QuietEnd,
+	})
+}
+
+func (repo ReminderRepo) Snooze(ctx context.Context, userId int64, until time.Time) error {
+	return repo.reminderDao.UpdateSnoozedUntil(ctx, userId, until)
+}
+
+func (repo ReminderRepo) MarkSent(ctx context.Context, userId int64, localDate time.Time) error {
+	return repo.reminderDao.UpdateLastSentOn(ctx, userId, localDate)
+}
@@ -2,7 +2,6 @@ package repo
 
 import (
 	"context"
-	"fmt"
 	"math"
 	"time"
 
@@ -20,32 +19,33 @@ func NewTransactionRepo(transactionDao dao.TransactionDAO) TransactionRepo {
 	return TransactionRepo{transactionDao: transactionDao}
 }
 
-func (repo TransactionRepo) Add(ctx context.Context, t domain.Transaction) error {
+func (repo TransactionRepo) Add(ctx context.Context, t domain.Transaction) (int, error) {
 
-	err := repo.transactionDao.Insert(ctx, entity.Transaction{
-		Id:           t.Id,
-		Datetime:     t.Datetime,
-		CategoryId:   t.CategoryId,
-		CategoryName: t.CategoryName,
-		Description:  t.Description,
-		UserId:       t.UserId,
-		Amount:       t.Amount.Amount(),
-		Currency:     t.Amount.Currency().Code,
-	})
+	id, err := repo.transactionDao.Insert(ctx, transactionToEntity(t))
 
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	return id, nil
 }
 
+func (repo TransactionRepo) Update(ctx context.Context, t domain.Transaction) error {
+	return repo.transactionDao.Update(ctx, transactionToEntity(t))
+}
+
+// GetById returns the transaction of the user with its category lines when it is split
 func (repo TransactionRepo) GetById(ctx context.Context, id int, userId int64) (domain.Transaction, error) {
 	e, err := repo.transactionDao.GetById(ctx, id, userId)
 	if err != nil {
 		return domain.Transaction{}, err
 	}
-	return domain.TransactionFromEntity(e), nil
+	entities := []entity.Transaction{e}
+	err = repo.attachSplits(ctx, entities)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	return domain.TransactionFromEntity(entities[0]), nil
 }
 
 func (repo TransactionRepo) FindLastestByUserId(ctx context.Context, userId int64) (*domain.Transaction, error) {
@@ -70,23 +70,100 @@ func (repo TransactionRepo) DeleteById(ctx context.Context, id int, userId int64
 	return nil
 }
 
-func (repo TransactionRepo) GetTransactionBreakdownByCategory(ctx context.Context, month time.Month, year int, user domain.User) (domain.Breakdowns, *money.Money, error) {
-	breakdowns := domain.Breakdowns{}
+// FindDeleted lists up to limit transactions in the trash of the user, the most recently deleted first
+func (repo TransactionRepo) FindDeleted(ctx context.Context, userId int64, limit int) (domain.Transactions, error) {
+	entities, err := repo.transactionDao.FindDeletedByUserId(ctx, userId, limit)
+	if err != nil {
+		return nil, err
+	}
+	transactions := domain.Transactions{}
+	for _, e := range entities {
+		transactions = append(transactions, domain.TransactionFromEntity(e))
+	}
+	return transactions, nil
+}
 
-	dateFromString := fmt.Sprintf("%v-%02d-01", year, int(month))
-	dateFrom, err := time.ParseInLocation("2006-01-02", dateFromString, user.Location)
+// FindRefundable lists up to limit of the latest purchases of the user that are not refunded in full, with what is
+// refunded of them so far
+func (repo TransactionRepo) FindRefundable(ctx context.Context, userId int64, limit int) (domain.Transactions, error) {
+	entities, err := repo.transactionDao.FindRefundableByUserId(ctx, userId, limit)
+	if err != nil {
+		return nil, err
+	}
+	transactions := domain.Transactions{}
+	for _, e := range entities {
+		transactions = append(transactions, domain.TransactionFromEntity(e))
+	}
+	return transactions, nil
+}
 
+// Restore takes the transaction out of the trash and returns it, or entity.ErrNotFound when it is not in the trash
+func (repo TransactionRepo) Restore(ctx context.Context, id int, userId int64) (domain.Transaction, error) {
+	err := repo.transactionDao.Restore(ctx, id, userId)
 	if err != nil {
-		return nil, nil, err
+		return domain.Transaction{}, err
+	}
+	return repo.GetById(ctx, id, userId)
+}
+
+// RestoreLatest takes the most recently deleted transaction out of the trash and returns it, or nil when the trash is
+// empty
+func (repo TransactionRepo) RestoreLatest(ctx context.Context, userId int64) (*domain.Transaction, error) {
+	id, err := repo.transactionDao.RestoreLatest(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if id == 0 {
+		return nil, nil
+	}
+	t, err := repo.GetById(ctx, id, userId)
+	if err != nil {
+		return nil, err
 	}
+	return &t, nil
+}
 
-	dateTo := dateFrom.AddDate(0, 1, 0)
+// PurgeDeleted deletes for good the transactions moved to the trash before the time
+func (repo TransactionRepo) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	return repo.transactionDao.PurgeDeleted(ctx, before)
+}
 
+// GetHistory returns the changes to the transaction of the user, the oldest first, including after it is purged
+func (repo TransactionRepo) GetHistory(ctx context.Context, id int, userId int64) (domain.TransactionHistory, error) {
+	entities, err := repo.transactionDao.FindAuditByTransactionId(ctx, id, userId)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	return transactionHistoryFromEntities(entities), nil
+}
+
+// ListHistory returns the changes to the transactions of the user made from dateFrom up to but excluding dateTo
+func (repo TransactionRepo) ListHistory(ctx context.Context, userId int64, dateFrom time.Time, dateTo time.Time) (domain.TransactionHistory, error) {
+	entities, err := repo.transactionDao.FindAuditByUserId(ctx, userId, dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+	return transactionHistoryFromEntities(entities), nil
+}
+
+func transactionHistoryFromEntities(entities []entity.TransactionAudit) domain.TransactionHistory {
+	history := domain.TransactionHistory{}
+	for _, e := range entities {
+		history = append(history, domain.TransactionChangeFromEntity(e))
 	}
+	return history
+}
 
-	entities, err := repo.transactionDao.GetBreakdownByCategory(ctx, dateFrom, dateTo, user.Id)
+// GetTransactionBreakdownByCategory returns the total of each category from dateFrom up to but excluding dateTo, with
+// the refunds netted against the category of their purchase in the month set by the user. The refunds can bring the
+// total to 0 or below, and then no category has a percentage of it.
+func (repo TransactionRepo) GetTransactionBreakdownByCategory(ctx context.Context, dateFrom time.Time, dateTo time.Time, user domain.User) (domain.Breakdowns, *money.Money, error) {
+	breakdowns := domain.Breakdowns{}
+
+	entities, err := repo.transactionDao.GetBreakdownByCategory(ctx, dateFrom, dateTo, user.Id, user.RefundsByPurchase())
+	if err != nil {
+		return nil, nil, err
+	}
 	var totalAmount int64
 
 	for _, e := range entities {
@@ -94,11 +171,14 @@ func (repo TransactionRepo) GetTransactionBreakdownByCategory(ctx context.Contex
 	}
 
 	for _, e := range entities {
-		percent := float64(e.Amount) / float64(totalAmount) * 100
+		var percent float64
+		if totalAmount > 0 {
+			percent = math.Round(float64(e.Amount)/float64(totalAmount)*1000) / 10
+		}
 		breakdown := domain.Breakdown{
 			CategoryName: e.CategoryName,
 			Amount:       money.New(e.Amount, user.Currency.Code),
-			Percent:      math.Round(percent*10) / 10,
+			Percent:      percent,
 		}
 		breakdowns = append(breakdowns, breakdown)
 	}
@@ -106,19 +186,41 @@ func (repo TransactionRepo) GetTransactionBreakdownByCategory(ctx context.Contex
 	return breakdowns, money.New(totalAmount, user.Currency.Code), nil
 }
 
-func (repo TransactionRepo) ListByMonthAndYear(ctx context.Context, q entity.TransactionListQuery) (domain.Transactions, int, error) {
+func (repo TransactionRepo) ListByDateRange(ctx context.Context, q entity.TransactionListQuery) (domain.Transactions, int, error) {
 	var transactions domain.Transactions
 
-	dateFromString := fmt.Sprintf("%v-%02d-01", q.Year, int(q.Month))
-	dateFrom, err := time.ParseInLocation("2006-01-02", dateFromString, q.Location)
+	totalCount, err := repo.transactionDao.CountListByMonthAndYear(ctx, q.DateFrom, q.DateTo, q.UserId, q.AccountId)
+	if err != nil {
+		return transactions, 0, err
+	}
+	if totalCount == 0 {
+		return transactions, totalCount, nil
+	}
 
+	entities, err := repo.transactionDao.ListByMonthAndYear(ctx, q.DateFrom, q.DateTo, q.Offset, q.Limit, q.Asc, q.UserId, q.AccountId)
 	if err != nil {
-		return nil, 0, err
+		return transactions, 0, err
+	}
+	err = repo.attachSplits(ctx, entities)
+	if err != nil {
+		return transactions, 0, err
 	}
 
-	dateTo := dateFrom.AddDate(0, 1, 0)
+	for _, e := range entities {
+		transactions = append(transactions, domain.TransactionFromEntity(e))
+	}
+
+	return transactions, totalCount, nil
+}
 
-	totalCount, err := repo.transactionDao.CountListByMonthAndYear(ctx, dateFrom, dateTo, q.UserId)
+func (repo TransactionRepo) CountByDateRange(ctx context.Context, userId int64, dateFrom time.Time, dateTo time.Time) (int, error) {
+	return repo.transactionDao.CountListByMonthAndYear(ctx, dateFrom, dateTo, userId, 0)
+}
+
+func (repo TransactionRepo) List(ctx context.Context, filter entity.TransactionFilter) (domain.Transactions, int, error) {
+	transactions := domain.Transactions{}
+
+	totalCount, err := repo.transactionDao.Count(ctx, filter)
 	if err != nil {
 		return transactions, 0, err
 	}
@@ -126,7 +228,7 @@ func (repo TransactionRepo) ListByMonthAndYear(ctx context.Context, q entity.Tra
 		return transactions, totalCount, nil
 	}
 
-	entities, err := repo.transactionDao.ListByMonthAndYear(ctx, dateFrom, dateTo, q.Offset, q.Limit, q.Asc, q.UserId)
+	entities, err := repo.transactionDao.List(ctx, filter)
 	if err != nil {
 		return transactions, 0, err
 	}
@@ -137,3 +239,49 @@ func (repo TransactionRepo) ListByMonthAndYear(ctx context.Context, q entity.Tra
 
 	return transactions, totalCount, nil
 }
+
+// attachSplits reads the category lines of the transactions that are split
+func (repo TransactionRepo) attachSplits(ctx context.Context, entities []entity.Transaction) error {
+	if len(entities) == 0 {
+		return nil
+	}
+	ids := make([]int, len(entities))
+	for i, e := range entities {
+		ids[i] = e.Id
+	}
+	splits, err := repo.transactionDao.FindSplitsByTransactionIds(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range entities {
+		for _, s := range splits {
+			if s.TransactionId == entities[i].Id {
+				entities[i].Splits = append(entities[i].Splits, s)
+			}
+		}
+	}
+	return nil
+}
+
+func transactionToEntity(t domain.Transaction) entity.Transaction {
+	e := entity.Transaction{
+		Id:           t.Id,
+		Datetime:     t.Datetime,
+		CategoryId:   t.CategoryId,
+		CategoryName: t.CategoryName,
+		Description:  t.Description,
+		UserId:       t.UserId,
+		Amount:       t.Amount.Amount(),
+		Currency:     t.Amount.Currency().Code,
+	}
+	if t.AccountId != 0 {
+		e.AccountId = &t.AccountId
+	}
+	if t.RefundOf != 0 {
+		e.RefundOf = &t.RefundOf
+	}
+	for _, s := range t.Splits {
+		e.Splits = append(e.Splits, entity.TransactionSplit{CategoryId: s.CategoryId, Amount: s.Amount.Amount()})
+	}
+	return e
+}
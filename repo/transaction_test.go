@@ -25,7 +25,7 @@ func TestTransactionRepo_Add(t *testing.T) {
 	repo := newTestTransactionRepo()
 
 	dt := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
-	err := repo.Add(ctx, domain.Transaction{
+	_, err := repo.Add(ctx, domain.Transaction{
 		Datetime:     dt,
 		CategoryId:   1,
 		CategoryName: "Bills",
@@ -71,6 +71,24 @@ func TestTransactionRepo_GetById(t *testing.T) {
 	if got.CategoryName != "Education" {
 		t.Errorf("CategoryName = %s, want Education", got.CategoryName)
 	}
+
+	id, err := repo.Add(ctx, domain.Transaction{
+		Datetime:   time.Date(2024, 3, 16, 10, 30, 0, 0, time.UTC),
+		CategoryId: 1,
+		UserId:     100,
+		Amount:     money.New(12000, "SGD"),
+		Splits: []domain.TransactionSplit{
+			{CategoryId: 1, Amount: money.New(8000, "SGD")},
+			{CategoryId: 2, Amount: money.New(4000, "SGD")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Add split: %v", err)
+	}
+	split, err := repo.GetById(ctx, id, 100)
+	if err != nil || !split.IsSplit() || len(split.Splits) != 2 {
+		t.Errorf("expected the split read with its lines, got %+v: %v", split, err)
+	}
 }
 
 func TestTransactionRepo_FindLastestByUserId(t *testing.T) {
@@ -151,7 +169,8 @@ func TestTransactionRepo_GetTransactionBreakdownByCategory(t *testing.T) {
 
 	repo := newTestTransactionRepo()
 
-	breakdowns, total, err := repo.GetTransactionBreakdownByCategory(ctx, time.June, 2024, user)
+	june := time.Date(2024, time.June, 1, 0, 0, 0, 0, loc)
+	breakdowns, total, err := repo.GetTransactionBreakdownByCategory(ctx, june, june.AddDate(0, 1, 0), user)
 	if err != nil {
 		t.Fatalf("GetTransactionBreakdownByCategory: %v", err)
 	}
@@ -177,7 +196,56 @@ func TestTransactionRepo_GetTransactionBreakdownByCategory(t *testing.T) {
 	}
 }
 
-func TestTransactionRepo_ListByMonthAndYear(t *testing.T) {
+func TestTransactionRepo_GetTransactionBreakdownByCategory_Refunded(t *testing.T) {
+	ctx := context.Background()
+	clearTables(t, ctx)
+
+	loc, _ := time.LoadLocation("Asia/Singapore")
+	user := domain.User{
+		Id:          100,
+		Locale:      "en",
+		Currency:    money.GetCurrency("SGD"),
+		Location:    loc,
+		RefundMonth: domain.RefundMonthRefund,
+	}
+	seedUserRow(t, ctx, 100, "en", "SGD", "Asia/Singapore")
+
+	// Food bought in May and refunded in full in June, and Transport bought and refunded in full in June
+	seedRefundedTxnRows(t, ctx, "2024-05-20T10:00:00+08:00", "2024-06-05T10:00:00+08:00", 4, 500)
+	seedRefundedTxnRows(t, ctx, "2024-06-10T10:00:00+08:00", "2024-06-12T10:00:00+08:00", 13, 200)
+
+	repo := newTestTransactionRepo()
+
+	june := time.Date(2024, time.June, 1, 0, 0, 0, 0, loc)
+	breakdowns, total, err := repo.GetTransactionBreakdownByCategory(ctx, june, june.AddDate(0, 1, 0), user)
+	if err != nil {
+		t.Fatalf("GetTransactionBreakdownByCategory: %v", err)
+	}
+	if len(breakdowns) != 1 || breakdowns[0].CategoryName != "Food" || breakdowns[0].Amount.Amount() != -500 {
+		t.Fatalf("expected only the refund of Food, got %+v", breakdowns)
+	}
+	if total.Amount() != -500 || breakdowns[0].Percent != 0 {
+		t.Errorf("expected no percentage of a total of %d, got %f", total.Amount(), breakdowns[0].Percent)
+	}
+}
+
+// seedRefundedTxnRows seeds a purchase of the category refunded in full
+func seedRefundedTxnRows(t *testing.T, ctx context.Context, dt string, refundDt string, catId int, amount int64) {
+	t.Helper()
+	_, err := testPool.Exec(ctx, `
+		WITH purchase AS (
+			INSERT INTO transaction (datetime, category_id, description, user_id, amount, currency)
+			VALUES ($1, $3, 'refunded', 100, $4, 'SGD')
+			RETURNING id
+		)
+		INSERT INTO transaction (datetime, category_id, description, user_id, amount, currency, refund_of)
+		SELECT $2, $3, 'refunded', 100, -$4::bigint, 'SGD', id FROM purchase`, dt, refundDt, catId, amount)
+	if err != nil {
+		t.Fatalf("seed refunded txn: %v", err)
+	}
+}
+
+func TestTransactionRepo_ListByDateRange(t *testing.T) {
 	ctx := context.Background()
 	clearTables(t, ctx)
 	seedUserRow(t, ctx, 100, "en", "SGD", "Asia/Singapore")
@@ -192,19 +260,19 @@ func TestTransactionRepo_ListByMonthAndYear(t *testing.T) {
 	loc, _ := time.LoadLocation("Asia/Singapore")
 	repo := newTestTransactionRepo()
 
+	june := time.Date(2024, time.June, 1, 0, 0, 0, 0, loc)
 	q := entity.TransactionListQuery{
-		Month:    time.June,
-		Year:     2024,
+		DateFrom: june,
+		DateTo:   june.AddDate(0, 1, 0),
 		Offset:   0,
 		Limit:    2,
 		Asc:      false,
 		UserId:   100,
-		Location: loc,
 	}
 
-	trxs, total, err := repo.ListByMonthAndYear(ctx, q)
+	trxs, total, err := repo.ListByDateRange(ctx, q)
 	if err != nil {
-		t.Fatalf("ListByMonthAndYear: %v", err)
+		t.Fatalf("ListByDateRange: %v", err)
 	}
 	if total != 3 {
 		t.Errorf("total = %d, want 3", total)
@@ -218,7 +286,7 @@ func TestTransactionRepo_ListByMonthAndYear(t *testing.T) {
 	}
 }
 
-func TestTransactionRepo_ListByMonthAndYear_Empty(t *testing.T) {
+func TestTransactionRepo_ListByDateRange_Empty(t *testing.T) {
 	ctx := context.Background()
 	clearTables(t, ctx)
 	seedUserRow(t, ctx, 100, "en", "SGD", "Asia/Singapore")
@@ -226,19 +294,19 @@ func TestTransactionRepo_ListByMonthAndYear_Empty(t *testing.T) {
 	loc, _ := time.LoadLocation("Asia/Singapore")
 	repo := newTestTransactionRepo()
 
+	january := time.Date(2024, time.January, 1, 0, 0, 0, 0, loc)
 	q := entity.TransactionListQuery{
-		Month:    time.January,
-		Year:     2024,
+		DateFrom: january,
+		DateTo:   january.AddDate(0, 1, 0),
 		Offset:   0,
 		Limit:    10,
 		Asc:      false,
 		UserId:   100,
-		Location: loc,
 	}
 
-	trxs, total, err := repo.ListByMonthAndYear(ctx, q)
+	trxs, total, err := repo.ListByDateRange(ctx, q)
 	if err != nil {
-		t.Fatalf("ListByMonthAndYear: %v", err)
+		t.Fatalf("ListByDateRange: %v", err)
 	}
 	if total != 0 {
 		t.Errorf("total = %d, want 0", total)
@@ -40,3 +40,12 @@ func (repo UserRepo) Add(ctx context.Context, user domain.User) error {
 	}
 	return nil
 }
+
+func (repo UserRepo) UpdateLocale(ctx context.Context, id int64, locale string) error {
+	return repo.userDao.UpdateLocale(ctx, id, locale)
+}
+
+// UpdateRefundMonth sets the month stats count refunds in, domain.RefundMonthOriginal or domain.RefundMonthRefund
+func (repo UserRepo) UpdateRefundMonth(ctx context.Context, id int64, refundMonth string) error {
+	return repo.userDao.UpdateRefundMonth(ctx, id, refundMonth)
+}
@@ -0,0 +1,137 @@
+package router
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/logging"
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	"github.com/aattwwss/telegram-expense-bot/util"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const idleLimitTtl = 10 * time.Minute
+
+// Recover logs a panic of the handler with its stack and replies with the generic error, instead of the panic taking
+// the bot down. It can wrap the handlers of any update.
+func Recover() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+				log.Ctx(ctx).Error().Str("stack", string(debug.Stack())).Msgf("Recovered from panic: %v", p)
+				if chat := update.FromChat(); chat != nil {
+					util.BotSendMessage(bot, chat.ID, clientLocale(update).Get(message.GenericErrReplyMsg))
+				}
+			}()
+			next(ctx, bot, update)
+		}
+	}
+}
+
+// Logging logs the messages received, with the text redacted when redaction is enabled
+func Logging() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+			if update.Message != nil {
+				log.Ctx(ctx).Info().Str("text", logging.Text(update.Message.Text)).Msg("Received message")
+			}
+			next(ctx, bot, update)
+		}
+	}
+}
+
+// RateLimit allows each user perMinute messages a minute with bursts of up to burst messages. The first message over
+// the limit is answered, the rest are dropped until the user is allowed again.
+func RateLimit(perMinute int, burst int) Middleware {
+	limiter := newUserLimiter(float64(perMinute)/60, burst)
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+			from := update.SentFrom()
+			if from == nil {
+				next(ctx, bot, update)
+				return
+			}
+			allowed, warn := limiter.allow(from.ID, time.Now())
+			if allowed {
+				next(ctx, bot, update)
+				return
+			}
+			log.Ctx(ctx).Info().Msg("Dropped message over the rate limit")
+			if chat := update.FromChat(); warn && chat != nil {
+				util.BotSendMessage(bot, chat.ID, clientLocale(update).Get(message.RateLimitedMsg))
+			}
+		}
+	}
+}
+
+type userBucket struct {
+	tokens float64
+	last   time.Time
+	warned bool
+}
+
+// userLimiter holds a token bucket for every user seen recently
+type userLimiter struct {
+	mu          sync.Mutex
+	rate        float64
+	burst       float64
+	users       map[int64]*userBucket
+	lastCleanup time.Time
+}
+
+func newUserLimiter(rate float64, burst int) *userLimiter {
+	return &userLimiter{rate: rate, burst: float64(burst), users: map[int64]*userBucket{}}
+}
+
+// allow takes a token of the user, and returns whether there was one and whether the user should be told otherwise
+func (l *userLimiter) allow(userId int64, now time.Time) (bool, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cleanup(now)
+
+	bucket, ok := l.users[userId]
+	if !ok {
+		bucket = &userBucket{tokens: l.burst, last: now}
+		l.users[userId] = bucket
+	}
+	if now.After(bucket.last) {
+		bucket.tokens = min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+		bucket.last = now
+	}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		bucket.warned = false
+		return true, false
+	}
+	warn := !bucket.warned
+	bucket.warned = true
+	return false, warn
+}
+
+// cleanup forgets the users idle long enough for their bucket to be full again
+func (l *userLimiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < idleLimitTtl {
+		return
+	}
+	l.lastCleanup = now
+	for userId, bucket := range l.users {
+		if now.Sub(bucket.last) > idleLimitTtl {
+			delete(l.users, userId)
+		}
+	}
+}
+
+func clientLocale(update tgbotapi.Update) message.Locale {
+	if from := update.SentFrom(); from != nil {
+		return message.GetLocale(from.LanguageCode)
+	}
+	return message.GetLocale(message.DefaultLocale)
+}
@@ -0,0 +1,153 @@
+// Package router dispatches the messages sent to the bot to the commands registered with it. Each command declares its
+// name, description, argument syntax and middleware, so /help and the command menu of telegram are generated from the
+// registry rather than kept in sync by hand.
+package router
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type HandlerFunc func(ctx context.Context, bot *sender.Sender, update tgbotapi.Update)
+
+// Middleware wraps a handler, e.g. to load the user before it runs or to stop it from running
+type Middleware func(next HandlerFunc) HandlerFunc
+
+type Command struct {
+	// Name is the command without the slash, in lowercase letters, digits and underscores
+	Name        string
+	Description message.Key
+	// Args is the syntax of the arguments shown by /help, e.g. "[period]"
+	Args    string
+	Handler HandlerFunc
+	// Middleware runs after the middleware of the router, in order
+	Middleware []Middleware
+	// Hidden commands are handled but left out of /help and the command menu
+	Hidden bool
+}
+
+type Router struct {
+	middleware []Middleware
+	commands   []Command
+	handlers   map[string]HandlerFunc
+	text       HandlerFunc
+	fallback   string
+}
+
+// New returns a router whose middleware runs first for every command and text, in order
+func New(middleware ...Middleware) *Router {
+	return &Router{middleware: middleware, handlers: map[string]HandlerFunc{}}
+}
+
+// Handle registers a command, in the order it is listed by /help and the command menu
+func (r *Router) Handle(cmd Command) {
+	if _, ok := r.handlers[cmd.Name]; ok {
+		panic(fmt.Sprintf("router: command %s is registered twice", cmd.Name))
+	}
+	r.commands = append(r.commands, cmd)
+	r.handlers[cmd.Name] = r.chain(cmd.Handler, cmd.Middleware)
+}
+
+// HandleText registers the handler of the messages that are not commands
+func (r *Router) HandleText(handler HandlerFunc, middleware ...Middleware) {
+	r.text = r.chain(handler, middleware)
+}
+
+// Fallback sets the registered command that handles the commands the router does not know
+func (r *Router) Fallback(name string) {
+	r.fallback = name
+}
+
+// Dispatch runs the handler of the message of the update
+func (r *Router) Dispatch(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+	if update.Message == nil {
+		return
+	}
+	name := r.Name(update)
+	if name == "" {
+		if r.text != nil {
+			r.text(ctx, bot, update)
+		}
+		return
+	}
+	if handler, ok := r.handlers[name]; ok {
+		handler(ctx, bot, update)
+	}
+}
+
+// Name returns the command that handles the message of the update, which is the fallback for unknown commands, or an
+// empty string for a text
+func (r *Router) Name(update tgbotapi.Update) string {
+	if update.Message == nil || !update.Message.IsCommand() {
+		return ""
+	}
+	name := strings.ToLower(update.Message.Command())
+	if _, ok := r.handlers[name]; ok {
+		return name
+	}
+	return r.fallback
+}
+
+// Commands returns the commands listed by /help and the command menu
+func (r *Router) Commands() []Command {
+	var commands []Command
+	for _, cmd := range r.commands {
+		if !cmd.Hidden {
+			commands = append(commands, cmd)
+		}
+	}
+	return commands
+}
+
+// Usage returns a line for every listed command with its arguments and description, e.g.
+// "/stats [period] - View the breakdown for a period"
+func (r *Router) Usage(locale message.Locale) string {
+	var b strings.Builder
+	for _, cmd := range r.Commands() {
+		b.WriteString("/" + cmd.Name)
+		if cmd.Args != "" {
+			b.WriteString(" " + cmd.Args)
+		}
+		b.WriteString(" - " + locale.Get(cmd.Description) + "\n")
+	}
+	return b.String()
+}
+
+// SetMyCommands sets the command menu of the bot in every supported language, the default locale is also used for the
+// languages that are not supported
+func (r *Router) SetMyCommands(bot *sender.Sender) error {
+	_, err := bot.Request(tgbotapi.NewSetMyCommands(r.botCommands(message.GetLocale(message.DefaultLocale))...))
+	if err != nil {
+		return fmt.Errorf("setting default commands: %w", err)
+	}
+	for _, locale := range message.SupportedLocales() {
+		config := tgbotapi.NewSetMyCommandsWithScopeAndLanguage(tgbotapi.NewBotCommandScopeDefault(), locale.Code, r.botCommands(locale)...)
+		_, err := bot.Request(config)
+		if err != nil {
+			return fmt.Errorf("setting %s commands: %w", locale.Code, err)
+		}
+	}
+	return nil
+}
+
+func (r *Router) botCommands(locale message.Locale) []tgbotapi.BotCommand {
+	var commands []tgbotapi.BotCommand
+	for _, cmd := range r.Commands() {
+		commands = append(commands, tgbotapi.BotCommand{Command: cmd.Name, Description: locale.Get(cmd.Description)})
+	}
+	return commands
+}
+
+// chain wraps the handler in the middleware of the router then the middleware given, so the first one runs first
+func (r *Router) chain(handler HandlerFunc, middleware []Middleware) HandlerFunc {
+	all := append(append([]Middleware{}, r.middleware...), middleware...)
+	for i := len(all) - 1; i >= 0; i-- {
+		handler = all[i](handler)
+	}
+	return handler
+}
@@ -0,0 +1,206 @@
+package router
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/message"
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// recordingClient records the telegram methods called with their form and replies ok
+type recordingClient struct {
+	requests []string
+}
+
+func (c *recordingClient) Do(req *http.Request) (*http.Response, error) {
+	body, _ := io.ReadAll(req.Body)
+	c.requests = append(c.requests, path.Base(req.URL.Path)+" "+string(body))
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"ok":true,"result":true}`))}, nil
+}
+
+func newRecordingSender() (*sender.Sender, *recordingClient) {
+	client := &recordingClient{}
+	bot := &tgbotapi.BotAPI{Token: "dummy", Client: client}
+	bot.SetAPIEndpoint(tgbotapi.APIEndpoint)
+	return sender.New(bot, sender.Options{GlobalRate: 1000, GlobalBurst: 1000, ChatRate: 1000, ChatBurst: 1000}), client
+}
+
+func textUpdate(text string) tgbotapi.Update {
+	msg := &tgbotapi.Message{From: &tgbotapi.User{ID: 1}, Chat: &tgbotapi.Chat{ID: 2}, Text: text}
+	if strings.HasPrefix(text, "/") {
+		command := strings.SplitN(text, " ", 2)[0]
+		msg.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}}
+	}
+	return tgbotapi.Update{Message: msg}
+}
+
+// record returns a handler and a middleware that append their name to calls
+func record(calls *[]string, name string) (HandlerFunc, Middleware) {
+	handler := func(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+		*calls = append(*calls, name)
+	}
+	middleware := func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+			*calls = append(*calls, name)
+			next(ctx, bot, update)
+		}
+	}
+	return handler, middleware
+}
+
+func TestRouter_Dispatch(t *testing.T) {
+	var calls []string
+	_, global := record(&calls, "global")
+	_, loadUser := record(&calls, "load_user")
+	stats, _ := record(&calls, "stats")
+	help, _ := record(&calls, "help")
+	text, _ := record(&calls, "text")
+
+	r := New(global)
+	r.Handle(Command{Name: "stats", Handler: stats, Middleware: []Middleware{loadUser}})
+	r.Handle(Command{Name: "help", Handler: help})
+	r.HandleText(text, loadUser)
+	r.Fallback("help")
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"/stats feb", "global load_user stats"},
+		{"/STATS", "global load_user stats"},
+		{"/unknown", "global help"},
+		{"5.50 lunch", "global load_user text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			calls = nil
+			r.Dispatch(context.Background(), nil, textUpdate(tt.text))
+			if got := strings.Join(calls, " "); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRouter_Name(t *testing.T) {
+	r := New()
+	r.Handle(Command{Name: "help"})
+	r.Fallback("help")
+
+	if got := r.Name(textUpdate("/unknown")); got != "help" {
+		t.Errorf("expected an unknown command to fall back to help, got %q", got)
+	}
+	if got := r.Name(textUpdate("5 coffee")); got != "" {
+		t.Errorf("expected no command for a text, got %q", got)
+	}
+}
+
+func TestRouter_Handle_Duplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic registering a command twice")
+		}
+	}()
+	r := New()
+	r.Handle(Command{Name: "help"})
+	r.Handle(Command{Name: "help"})
+}
+
+func TestRouter_Usage(t *testing.T) {
+	r := New()
+	r.Handle(Command{Name: "stats", Args: "[period]", Description: message.CommandStatsDesc})
+	r.Handle(Command{Name: "app", Description: message.CommandAppDesc, Hidden: true})
+	r.Handle(Command{Name: "undo", Description: message.CommandUndoDesc})
+
+	want := "/stats [period] - View the breakdown for a period\n/undo - Revert the last recorded expense\n"
+	if got := r.Usage(message.GetLocale("en")); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestRouter_SetMyCommands(t *testing.T) {
+	r := New()
+	r.Handle(Command{Name: "stats", Args: "[period]", Description: message.CommandStatsDesc})
+	r.Handle(Command{Name: "app", Description: message.CommandAppDesc, Hidden: true})
+	bot, client := newRecordingSender()
+
+	err := r.SetMyCommands(bot)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.requests) != 1+len(message.SupportedLocales()) {
+		t.Fatalf("expected the default commands and the commands of every locale, got %v", client.requests)
+	}
+	for _, request := range client.requests {
+		if !strings.HasPrefix(request, "setMyCommands") || strings.Contains(request, "app") {
+			t.Errorf("expected the listed commands to be set, got %s", request)
+		}
+	}
+	if !strings.Contains(client.requests[0], "View+the+breakdown") || strings.Contains(client.requests[0], "language_code") {
+		t.Errorf("expected the default commands in the default locale, got %s", client.requests[0])
+	}
+}
+
+func TestRecover(t *testing.T) {
+	bot, client := newRecordingSender()
+	handler := Recover()(func(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+		panic("boom")
+	})
+
+	handler(context.Background(), bot, textUpdate("/stats"))
+
+	if len(client.requests) != 1 || !strings.HasPrefix(client.requests[0], "sendMessage") || !strings.Contains(client.requests[0], "chat_id=2") {
+		t.Errorf("expected the generic error reply, got %v", client.requests)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	bot, client := newRecordingSender()
+	handled := 0
+	handler := RateLimit(60, 2)(func(ctx context.Context, bot *sender.Sender, update tgbotapi.Update) {
+		handled++
+	})
+
+	for i := 0; i < 4; i++ {
+		handler(context.Background(), bot, textUpdate("5 coffee"))
+	}
+
+	if handled != 2 {
+		t.Errorf("expected the burst of 2 to be handled, got %d", handled)
+	}
+	if len(client.requests) != 1 || !strings.Contains(client.requests[0], "too+quickly") {
+		t.Errorf("expected a single reply about the limit, got %v", client.requests)
+	}
+}
+
+func TestUserLimiter(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	l := newUserLimiter(1, 1)
+
+	if allowed, _ := l.allow(1, now); !allowed {
+		t.Error("expected the first message allowed")
+	}
+	if allowed, warn := l.allow(1, now); allowed || !warn {
+		t.Errorf("expected the second message dropped with a warning, got %v, %v", allowed, warn)
+	}
+	if allowed, warn := l.allow(1, now); allowed || warn {
+		t.Errorf("expected the third message dropped silently, got %v, %v", allowed, warn)
+	}
+	if allowed, _ := l.allow(2, now); !allowed {
+		t.Error("expected another user to have their own limit")
+	}
+	if allowed, _ := l.allow(1, now.Add(time.Second)); !allowed {
+		t.Error("expected the message allowed once the bucket refilled")
+	}
+	if allowed, warn := l.allow(1, now.Add(time.Second)); allowed || !warn {
+		t.Errorf("expected a new warning after being allowed again, got %v, %v", allowed, warn)
+	}
+}
@@ -0,0 +1,29 @@
+BEGIN;
+
+create table reminder
+(
+    user_id       bigint                   not null
+        constraint reminder_pk
+            primary key
+        references app_user,
+    chat_id       bigint                   not null,
+    enabled       boolean  default true    not null,
+    remind_at     smallint                 not null
+        constraint check_remind_at
+            check (remind_at >= 0 and remind_at < 1440),
+    quiet_start   smallint
+        constraint check_quiet_start
+            check (quiet_start >= 0 and quiet_start < 1440),
+    quiet_end     smallint
+        constraint check_quiet_end
+            check (quiet_end >= 0 and quiet_end < 1440),
+    snoozed_until timestamp with time zone,
+    last_sent_on  date,
+    create_time   timestamp with time zone not null default NOW(),
+    update_time   timestamp with time zone not null default NOW()
+);
+
+comment on column reminder.remind_at is 'Minutes after local midnight';
+comment on column reminder.last_sent_on is 'Local date of the user when the reminder was last sent';
+
+COMMIT;
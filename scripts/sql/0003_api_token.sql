@@ -0,0 +1,18 @@
+BEGIN;
+
+create table api_token
+(
+    id             serial
+        primary key,
+    user_id        bigint                   not null
+        references app_user,
+    token_hash     char(64)                 not null
+        constraint api_token_hash_key
+            unique,
+    last_used_time timestamp with time zone,
+    create_time    timestamp with time zone not null default NOW()
+);
+
+comment on column api_token.token_hash is 'Hex encoded SHA-256 of the personal access token, the token itself is never stored';
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+alter table category
+    add column user_id bigint references app_user;
+
+comment on column category.user_id is 'Owner of a custom category, null for the categories shared by all users';
+
+alter table category
+    drop constraint category_name_transaction_type_id_key;
+
+create unique index category_shared_name_key
+    on category (name, transaction_type_id)
+    where user_id is null;
+
+create unique index category_user_name_key
+    on category (user_id, name, transaction_type_id)
+    where user_id is not null;
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+alter table message_context
+    add column user_id bigint not null default 0,
+    add column kind varchar(10) not null default 'menu',
+    add column expires_at timestamp with time zone;
+
+comment on column message_context.kind is 'entry for an amount waiting for its category, menu for the other keyboards';
+
+update message_context
+set expires_at = created_at + interval '1 day';
+
+alter table message_context
+    alter column expires_at set not null;
+
+create index message_context_expires_at_idx
+    on message_context (expires_at);
+
+COMMIT;
@@ -0,0 +1,36 @@
+BEGIN;
+
+create table conversation
+(
+    id            serial primary key,
+    chat_id       bigint                   not null,
+    user_id       bigint                   not null,
+    flow          varchar(30)              not null,
+    state         varchar(30)              not null,
+    data          jsonb                    not null,
+    awaiting_text boolean default false    not null,
+    created_at    timestamp with time zone not null default NOW(),
+    expires_at    timestamp with time zone not null
+);
+
+comment on column conversation.awaiting_text is 'Whether the state of the flow expects a text reply from the user';
+
+create index conversation_chat_id_user_id_idx
+    on conversation (chat_id, user_id);
+
+create index conversation_expires_at_idx
+    on conversation (expires_at);
+
+-- the keyboards already sent carry message context ids, the conversation ids start after them so none is mistaken
+select setval('conversation_id_seq', (select coalesce(max(id), 0) + 1 from message_context), false);
+
+-- the amounts waiting for a category are now conversations, their keyboards expire
+delete
+from message_context
+where kind = 'entry';
+
+alter table message_context
+    drop column user_id,
+    drop column kind;
+
+COMMIT;
@@ -0,0 +1,54 @@
+BEGIN;
+
+create table account
+(
+    id              serial primary key,
+    user_id         bigint                        not null
+        references app_user,
+    name            varchar(50)                   not null,
+    opening_balance bigint  default 0             not null,
+    currency        char(3) default 'SGD'::bpchar not null
+        references currency,
+    create_time     timestamp with time zone      not null default NOW()
+);
+
+comment on column account.opening_balance is 'Balance before the first transaction, normalised to the lowest denominator';
+
+create unique index account_user_name_key
+    on account (user_id, lower(name));
+
+alter table transaction
+    add column account_id integer references account;
+
+comment on column transaction.account_id is 'Account the transaction was paid with, null when none was chosen';
+
+create index transaction_account_id_idx
+    on transaction (account_id);
+
+create table transfer
+(
+    id              serial primary key,
+    datetime        timestamp with time zone not null,
+    user_id         bigint                   not null
+        references app_user,
+    from_account_id integer                  not null
+        references account,
+    to_account_id   integer                  not null
+        references account,
+    amount          bigint                   not null,
+    currency        char(3)                  not null
+        references currency,
+    description     text default ''          not null,
+    constraint transfer_accounts_check check (from_account_id <> to_account_id),
+    constraint transfer_amount_check check (amount > 0)
+);
+
+comment on table transfer is 'Money moved between the accounts of a user, such as a withdrawal, which is not spending';
+
+create index transfer_from_account_id_idx
+    on transfer (from_account_id);
+
+create index transfer_to_account_id_idx
+    on transfer (to_account_id);
+
+COMMIT;
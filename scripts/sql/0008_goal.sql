@@ -0,0 +1,49 @@
+BEGIN;
+
+create table goal
+(
+    id                     serial primary key,
+    user_id                bigint                        not null
+        references app_user,
+    chat_id                bigint                        not null,
+    name                   varchar(50)                   not null,
+    target                 bigint                        not null,
+    currency               char(3) default 'SGD'::bpchar not null
+        references currency,
+    deadline               date,
+    monthly_amount         bigint  default 0             not null,
+    next_contribution_time timestamp with time zone,
+    reached_time           timestamp with time zone,
+    create_time            timestamp with time zone      not null default NOW(),
+    constraint goal_target_check check (target > 0),
+    constraint goal_monthly_amount_check check (monthly_amount >= 0)
+);
+
+comment on column goal.chat_id is 'Chat the goal was added in, where it is celebrated when reached';
+comment on column goal.deadline is 'First day of the month the goal should be reached by, null when there is none';
+comment on column goal.monthly_amount is 'Amount contributed automatically every month, 0 when there is none';
+comment on column goal.reached_time is 'When the contributions first reached the target';
+
+create unique index goal_user_name_key
+    on goal (user_id, lower(name));
+
+create index goal_next_contribution_time_idx
+    on goal (next_contribution_time)
+    where monthly_amount > 0;
+
+create table goal_contribution
+(
+    id        serial primary key,
+    goal_id   integer                  not null
+        references goal on delete cascade,
+    datetime  timestamp with time zone not null,
+    amount    bigint                   not null,
+    currency  char(3)                  not null
+        references currency,
+    automatic boolean default false    not null
+);
+
+create index goal_contribution_goal_id_idx
+    on goal_contribution (goal_id);
+
+COMMIT;
@@ -0,0 +1,42 @@
+BEGIN;
+
+create table iou
+(
+    id           serial primary key,
+    user_id      bigint                   not null
+        references app_user,
+    person       varchar(50)              not null,
+    amount       bigint                   not null,
+    currency     char(3)                  not null
+        references currency,
+    description  text default ''          not null,
+    datetime     timestamp with time zone not null,
+    settled_time timestamp with time zone,
+    constraint iou_amount_check check (amount <> 0)
+);
+
+comment on table iou is 'Money lent to or borrowed from a person, which is not spending';
+comment on column iou.amount is 'Positive when the person owes the user, negative when the user owes the person';
+comment on column iou.settled_time is 'When the entries of the person added up to nothing, which settles them';
+
+create index iou_user_person_idx
+    on iou (user_id, lower(person))
+    where settled_time is null;
+
+create table iou_reminder
+(
+    user_id        bigint                   not null
+        constraint iou_reminder_pk
+            primary key
+        references app_user,
+    chat_id        bigint                   not null,
+    days           smallint                 not null
+        constraint check_days
+            check (days > 0),
+    last_sent_time timestamp with time zone,
+    create_time    timestamp with time zone not null default NOW()
+);
+
+comment on table iou_reminder is 'Reminds the user of the debts outstanding for longer than days';
+
+COMMIT;
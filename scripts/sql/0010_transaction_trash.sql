@@ -0,0 +1,12 @@
+BEGIN;
+
+alter table transaction
+    add column deleted_time timestamp with time zone;
+
+comment on column transaction.deleted_time is 'When the transaction was moved to the trash, null when it is not deleted';
+
+create index transaction_user_deleted_time_idx
+    on transaction (user_id, deleted_time)
+    where deleted_time is not null;
+
+COMMIT;
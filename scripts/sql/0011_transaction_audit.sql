@@ -0,0 +1,32 @@
+BEGIN;
+
+create table transaction_audit
+(
+    id             bigserial primary key,
+    transaction_id integer                  not null,
+    user_id        bigint                   not null
+        references app_user,
+    action         varchar(10)              not null
+        constraint transaction_audit_action_check
+            check (action in ('create', 'update', 'delete', 'restore', 'purge')),
+    before         jsonb,
+    after          jsonb,
+    actor          varchar(50)              not null,
+    source         varchar(20)              not null,
+    create_time    timestamp with time zone not null default NOW()
+);
+
+comment on table transaction_audit is 'Append-only log of the changes to the transactions, kept after a transaction is purged';
+comment on column transaction_audit.transaction_id is 'Not a foreign key, so the history outlives the transaction';
+comment on column transaction_audit.before is 'The values of the transaction before the change, null when it is created or restored';
+comment on column transaction_audit.after is 'The values of the transaction after the change, null when it is deleted or purged';
+comment on column transaction_audit.actor is 'user, or job:<name> for a change made by a system job';
+comment on column transaction_audit.source is 'Where the change came from: bot, api, import, recurring or job';
+
+create index transaction_audit_transaction_idx
+    on transaction_audit (transaction_id, id);
+
+create index transaction_audit_user_time_idx
+    on transaction_audit (user_id, create_time);
+
+COMMIT;
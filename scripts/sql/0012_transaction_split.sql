@@ -0,0 +1,22 @@
+BEGIN;
+
+create table transaction_split
+(
+    id             serial primary key,
+    transaction_id integer not null
+        references transaction
+            on delete cascade,
+    category_id    integer not null
+        references category,
+    amount         bigint  not null
+        constraint transaction_split_amount_check
+            check (amount > 0)
+);
+
+comment on table transaction_split is 'The category lines of a transaction split across categories, which add up to its amount';
+comment on column transaction_split.amount is 'Normalised to the lowest denominator, in the currency of the transaction';
+
+create index transaction_split_transaction_idx
+    on transaction_split (transaction_id);
+
+COMMIT;
@@ -0,0 +1,21 @@
+BEGIN;
+
+alter table transaction
+    add column refund_of integer
+        references transaction
+            on delete set null;
+
+comment on column transaction.refund_of is 'The purchase a refund is of, null when the transaction is not a refund. A refund has a negative amount';
+
+create index transaction_refund_of_idx
+    on transaction (refund_of)
+    where refund_of is not null;
+
+alter table app_user
+    add column refund_month varchar(10) default 'original' not null
+        constraint app_user_refund_month_check
+            check (refund_month in ('original', 'refund'));
+
+comment on column app_user.refund_month is 'Whether stats count a refund in the month of its purchase or in the month it was refunded';
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+-- a purchase is only purged along with its refunds, so deleting one that still has a refund fails instead of unlinking
+-- the refund, which would turn it into a negative expense
+alter table transaction
+    drop constraint transaction_refund_of_fkey,
+    add constraint transaction_refund_of_fkey
+        foreign key (refund_of) references transaction;
+
+COMMIT;
@@ -0,0 +1,105 @@
+package sender
+
+import (
+	"sync"
+	"time"
+)
+
+const idleBucketTtl = time.Minute
+
+// tokenBucket allows rate tokens per second with bursts of up to burst tokens
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+// reserve takes a token, going into debt if there is none, and returns how long to wait before the token is available
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	if now.After(b.last) {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// limiter holds the global bucket, a bucket for every chat seen recently and the pauses asked by telegram
+type limiter struct {
+	mu          sync.Mutex
+	global      *tokenBucket
+	chats       map[int64]*tokenBucket
+	chatRate    float64
+	chatBurst   int
+	pausedUntil map[int64]time.Time
+	lastCleanup time.Time
+}
+
+func newLimiter(globalRate float64, globalBurst int, chatRate float64, chatBurst int, now time.Time) *limiter {
+	return &limiter{
+		global:      newTokenBucket(globalRate, globalBurst, now),
+		chats:       map[int64]*tokenBucket{},
+		chatRate:    chatRate,
+		chatBurst:   chatBurst,
+		pausedUntil: map[int64]time.Time{},
+		lastCleanup: now,
+	}
+}
+
+// reserve returns how long to wait before sending to the chat, a chat id of 0 is only limited by the global bucket
+func (l *limiter) reserve(chatId int64, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cleanup(now)
+
+	wait := l.global.reserve(now)
+	if chatId != 0 {
+		bucket, ok := l.chats[chatId]
+		if !ok {
+			bucket = newTokenBucket(l.chatRate, l.chatBurst, now)
+			l.chats[chatId] = bucket
+		}
+		wait = max(wait, bucket.reserve(now))
+	}
+	for _, id := range []int64{0, chatId} {
+		if until, ok := l.pausedUntil[id]; ok {
+			wait = max(wait, until.Sub(now))
+		}
+	}
+	return wait
+}
+
+// pause holds back the chat, or every chat for a chat id of 0, until the time given by a 429 response
+func (l *limiter) pause(chatId int64, until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until.After(l.pausedUntil[chatId]) {
+		l.pausedUntil[chatId] = until
+	}
+}
+
+// cleanup forgets the chats that have been idle long enough for their bucket to be full again
+func (l *limiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < idleBucketTtl {
+		return
+	}
+	l.lastCleanup = now
+	for chatId, bucket := range l.chats {
+		if now.Sub(bucket.last) >= idleBucketTtl {
+			delete(l.chats, chatId)
+		}
+	}
+	for chatId, until := range l.pausedUntil {
+		if now.After(until) {
+			delete(l.pausedUntil, chatId)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package sender
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter_ChatBurstThenRate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newLimiter(30, 30, 1, 3, now)
+
+	for i := 0; i < 3; i++ {
+		if wait := l.reserve(1, now); wait != 0 {
+			t.Fatalf("expected message %d of the burst to be sent at once, waited %v", i+1, wait)
+		}
+	}
+	if wait := l.reserve(1, now); wait != time.Second {
+		t.Errorf("expected the 4th message to wait 1s, got %v", wait)
+	}
+	if wait := l.reserve(2, now); wait != 0 {
+		t.Errorf("expected another chat not to wait, got %v", wait)
+	}
+}
+
+func TestLimiter_Global(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newLimiter(2, 2, 1, 1, now)
+
+	l.reserve(1, now)
+	l.reserve(2, now)
+	if wait := l.reserve(0, now); wait != 500*time.Millisecond {
+		t.Errorf("expected the global limit to wait 500ms, got %v", wait)
+	}
+	if wait := l.reserve(3, now.Add(time.Second)); wait != 0 {
+		t.Errorf("expected the global bucket to refill, got %v", wait)
+	}
+}
+
+func TestLimiter_Pause(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newLimiter(30, 30, 10, 10, now)
+
+	l.pause(1, now.Add(5*time.Second))
+	if wait := l.reserve(1, now); wait != 5*time.Second {
+		t.Errorf("expected the paused chat to wait 5s, got %v", wait)
+	}
+	if wait := l.reserve(2, now); wait != 0 {
+		t.Errorf("expected another chat not to wait, got %v", wait)
+	}
+
+	l.pause(0, now.Add(2*time.Second))
+	if wait := l.reserve(2, now); wait != 2*time.Second {
+		t.Errorf("expected a global pause to hold back every chat, got %v", wait)
+	}
+}
+
+func TestLimiter_CleanupIdleChats(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newLimiter(30, 30, 1, 1, now)
+	l.reserve(1, now)
+	l.pause(1, now.Add(time.Second))
+
+	l.reserve(2, now.Add(2*idleBucketTtl))
+
+	if _, ok := l.chats[1]; ok {
+		t.Error("expected the idle chat to be forgotten")
+	}
+	if _, ok := l.pausedUntil[1]; ok {
+		t.Error("expected the expired pause to be forgotten")
+	}
+}
@@ -0,0 +1,179 @@
+// Package sender sends requests to the Telegram Bot API within its rate limits, retrying the ones telegram asks to
+// retry later
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/metrics"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// Options are the limits of the sender, the defaults follow https://core.telegram.org/bots/faq#my-bot-is-hitting-limits-how-do-i-avoid-this
+type Options struct {
+	GlobalRate  float64 // requests per second across all chats
+	GlobalBurst int
+	ChatRate    float64 // requests per second to a chat
+	ChatBurst   int
+	MaxRetries  int
+	Backoff     time.Duration // wait before the first retry of a server error, doubled on every retry
+	// OnFailure is called with the requests that could not be sent after all the retries
+	OnFailure func(ctx context.Context, c tgbotapi.Chattable, err error)
+}
+
+func DefaultOptions() Options {
+	return Options{
+		GlobalRate:  30,
+		GlobalBurst: 30,
+		ChatRate:    1,
+		ChatBurst:   3,
+		MaxRetries:  3,
+		Backoff:     500 * time.Millisecond,
+		OnFailure: func(ctx context.Context, c tgbotapi.Chattable, err error) {
+			log.Ctx(ctx).Error().Msgf("bot send chattable error: %v", err)
+		},
+	}
+}
+
+// Sender is shared by all the handlers, WithBot and WithContext return copies that share its limits
+type Sender struct {
+	bot     *tgbotapi.BotAPI
+	ctx     context.Context
+	limiter *limiter
+	options Options
+	sleep   func(ctx context.Context, d time.Duration) error
+	// quiet leaves the requests rejected by telegram to the caller instead of reporting them
+	quiet bool
+}
+
+func New(bot *tgbotapi.BotAPI, options Options) *Sender {
+	return &Sender{
+		bot:     bot,
+		ctx:     context.Background(),
+		limiter: newLimiter(options.GlobalRate, options.GlobalBurst, options.ChatRate, options.ChatBurst, time.Now()),
+		options: options,
+		sleep:   sleep,
+	}
+}
+
+// WithBot returns a sender that sends with the bot, such as a copy of the bot instrumented for a handler
+func (s *Sender) WithBot(bot *tgbotapi.BotAPI) *Sender {
+	c := *s
+	c.bot = bot
+	return &c
+}
+
+// WithContext returns a sender that gives up waiting for the limits or a retry once the context is done
+func (s *Sender) WithContext(ctx context.Context) *Sender {
+	c := *s
+	c.ctx = ctx
+	return &c
+}
+
+// Request sends the chattable once the limits allow it, retrying rate limited requests after the time given by
+// telegram and server errors with a backoff. OnFailure is called when it still fails.
+func (s *Sender) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	chatId := chatIdOf(c)
+	backoff := s.options.Backoff
+	for attempt := 0; ; attempt++ {
+		err := s.sleep(s.ctx, s.limiter.reserve(chatId, time.Now()))
+		if err != nil {
+			s.fail(c, err)
+			return nil, err
+		}
+
+		resp, err := s.bot.Request(c)
+		if err == nil {
+			return resp, nil
+		}
+
+		// requests rejected by telegram, such as a bad request, fail as they are
+		var tgErr *tgbotapi.Error
+		rejected := errors.As(err, &tgErr) && tgErr.Code != 429 && tgErr.Code < 500
+		if rejected || attempt >= s.options.MaxRetries {
+			if !rejected || !s.quiet {
+				s.fail(c, err)
+			}
+			return resp, err
+		}
+
+		switch {
+		case tgErr != nil && tgErr.Code == 429:
+			retryAfter := time.Duration(max(tgErr.RetryAfter, 1)) * time.Second
+			s.limiter.pause(chatId, time.Now().Add(retryAfter))
+			metrics.ObserveTelegramRetry("rate_limited")
+			log.Ctx(s.ctx).Warn().Msgf("Rate limited by telegram, retrying after %v", retryAfter)
+		default:
+			// a server error, or the request did not reach telegram
+			metrics.ObserveTelegramRetry("server_error")
+			err = s.sleep(s.ctx, backoff)
+			if err != nil {
+				s.fail(c, err)
+				return nil, err
+			}
+			backoff *= 2
+		}
+	}
+}
+
+// Attempt sends the chattable like Request, but leaves the requests rejected by telegram to the caller instead of
+// reporting them, for requests with a fallback such as editing a message that may be too old to edit
+func (s *Sender) Attempt(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	q := *s
+	q.quiet = true
+	return q.Request(c)
+}
+
+// Send sends the chattable like Request and returns the message sent
+func (s *Sender) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	resp, err := s.Request(c)
+	if err != nil {
+		return tgbotapi.Message{}, err
+	}
+	var msg tgbotapi.Message
+	err = json.Unmarshal(resp.Result, &msg)
+	return msg, err
+}
+
+func (s *Sender) fail(c tgbotapi.Chattable, err error) {
+	metrics.ObserveTelegramFailure()
+	if s.options.OnFailure != nil {
+		s.options.OnFailure(s.ctx, c, err)
+	}
+}
+
+// chatIdOf returns the chat the chattable is sent to, or 0 for requests not sent to a chat such as inline answers
+func chatIdOf(c tgbotapi.Chattable) int64 {
+	switch v := c.(type) {
+	case tgbotapi.MessageConfig:
+		return v.ChatID
+	case tgbotapi.DocumentConfig:
+		return v.ChatID
+	case tgbotapi.EditMessageTextConfig:
+		return v.ChatID
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		return v.ChatID
+	case tgbotapi.DeleteMessageConfig:
+		return v.ChatID
+	default:
+		return 0
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
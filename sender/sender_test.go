@@ -0,0 +1,209 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakeClient replies with the responses in order, repeating the last one
+type fakeClient struct {
+	responses []string
+	calls     int
+}
+
+func (c *fakeClient) Do(req *http.Request) (*http.Response, error) {
+	resp := c.responses[min(c.calls, len(c.responses)-1)]
+	c.calls++
+	if resp == "" {
+		return nil, errors.New("connection reset")
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(resp))}, nil
+}
+
+const (
+	okResponse          = `{"ok":true,"result":{"message_id":7}}`
+	rateLimitedResponse = `{"ok":false,"error_code":429,"description":"Too Many Requests","parameters":{"retry_after":3}}`
+	badRequestResponse  = `{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`
+	serverErrResponse   = `{"ok":false,"error_code":502,"description":"Bad Gateway"}`
+)
+
+func newTestSender(client *fakeClient, failures *[]error) (*Sender, *[]time.Duration) {
+	bot := &tgbotapi.BotAPI{Token: "dummy", Client: client}
+	bot.SetAPIEndpoint(tgbotapi.APIEndpoint)
+	s := New(bot, Options{
+		GlobalRate:  1000,
+		GlobalBurst: 1000,
+		ChatRate:    1000,
+		ChatBurst:   1000,
+		MaxRetries:  3,
+		Backoff:     100 * time.Millisecond,
+		OnFailure: func(ctx context.Context, c tgbotapi.Chattable, err error) {
+			*failures = append(*failures, err)
+		},
+	})
+	var slept []time.Duration
+	s.sleep = func(ctx context.Context, d time.Duration) error {
+		if d > 0 {
+			slept = append(slept, d)
+		}
+		return ctx.Err()
+	}
+	return s, &slept
+}
+
+func TestSender_Send(t *testing.T) {
+	var failures []error
+	s, _ := newTestSender(&fakeClient{responses: []string{okResponse}}, &failures)
+
+	msg, err := s.Send(tgbotapi.NewMessage(1, "hello"))
+
+	if err != nil || msg.MessageID != 7 {
+		t.Errorf("expected message 7, got %v %v", msg.MessageID, err)
+	}
+	if len(failures) != 0 {
+		t.Errorf("expected no failures, got %v", failures)
+	}
+}
+
+func TestSender_RetryAfterRateLimited(t *testing.T) {
+	var failures []error
+	client := &fakeClient{responses: []string{rateLimitedResponse, okResponse}}
+	s, slept := newTestSender(client, &failures)
+
+	_, err := s.Send(tgbotapi.NewMessage(1, "hello"))
+
+	if err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+	if client.calls != 2 {
+		t.Errorf("expected 2 calls, got %d", client.calls)
+	}
+	if len(*slept) != 1 || (*slept)[0] < 2*time.Second || (*slept)[0] > 3*time.Second {
+		t.Errorf("expected to wait about the 3s given by telegram, got %v", *slept)
+	}
+	if wait := s.limiter.reserve(2, time.Now()); wait != 0 {
+		t.Errorf("expected other chats not to be paused, got %v", wait)
+	}
+}
+
+func TestSender_NoRetryOnBadRequest(t *testing.T) {
+	var failures []error
+	client := &fakeClient{responses: []string{badRequestResponse}}
+	s, _ := newTestSender(client, &failures)
+
+	_, err := s.Send(tgbotapi.NewMessage(1, "hello"))
+
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) || tgErr.Code != 400 {
+		t.Errorf("expected the bad request error, got %v", err)
+	}
+	if client.calls != 1 {
+		t.Errorf("expected 1 call, got %d", client.calls)
+	}
+	if len(failures) != 1 {
+		t.Errorf("expected OnFailure to be called once, got %v", failures)
+	}
+}
+
+func TestSender_Attempt(t *testing.T) {
+	var failures []error
+	s, _ := newTestSender(&fakeClient{responses: []string{badRequestResponse}}, &failures)
+
+	_, err := s.Attempt(tgbotapi.NewEditMessageText(1, 2, "hello"))
+
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) || tgErr.Code != 400 {
+		t.Errorf("expected the bad request error, got %v", err)
+	}
+	if len(failures) != 0 {
+		t.Errorf("expected the rejected request left to the caller, got %v", failures)
+	}
+
+	s, _ = newTestSender(&fakeClient{responses: []string{serverErrResponse}}, &failures)
+	_, err = s.Attempt(tgbotapi.NewEditMessageText(1, 2, "hello"))
+
+	if err == nil || len(failures) != 1 {
+		t.Errorf("expected a server error to be reported, got %v, %v", err, failures)
+	}
+}
+
+func TestSender_BackoffOnServerError(t *testing.T) {
+	var failures []error
+	client := &fakeClient{responses: []string{serverErrResponse, "", okResponse}}
+	s, slept := newTestSender(client, &failures)
+
+	_, err := s.Request(tgbotapi.NewDeleteMessage(1, 2))
+
+	if err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}
+	if len(*slept) != 2 || (*slept)[0] != want[0] || (*slept)[1] != want[1] {
+		t.Errorf("expected backoff %v, got %v", want, *slept)
+	}
+	if len(failures) != 0 {
+		t.Errorf("expected no failures, got %v", failures)
+	}
+}
+
+func TestSender_GiveUpAfterMaxRetries(t *testing.T) {
+	var failures []error
+	client := &fakeClient{responses: []string{serverErrResponse}}
+	s, _ := newTestSender(client, &failures)
+
+	_, err := s.Send(tgbotapi.NewMessage(1, "hello"))
+
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if client.calls != 4 {
+		t.Errorf("expected 1 call and 3 retries, got %d calls", client.calls)
+	}
+	if len(failures) != 1 {
+		t.Errorf("expected OnFailure to be called once, got %v", failures)
+	}
+}
+
+func TestSender_ContextDone(t *testing.T) {
+	var failures []error
+	client := &fakeClient{responses: []string{okResponse}}
+	s, _ := newTestSender(client, &failures)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.WithContext(ctx).Send(tgbotapi.NewMessage(1, "hello"))
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+	if client.calls != 0 {
+		t.Errorf("expected nothing sent, got %d calls", client.calls)
+	}
+}
+
+func TestChatIdOf(t *testing.T) {
+	tests := []struct {
+		name string
+		c    tgbotapi.Chattable
+		want int64
+	}{
+		{"message", tgbotapi.NewMessage(1, "hello"), 1},
+		{"edit", tgbotapi.NewEditMessageText(2, 3, "hello"), 2},
+		{"delete", tgbotapi.NewDeleteMessage(4, 5), 4},
+		{"inline answer", tgbotapi.InlineConfig{InlineQueryID: "q"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chatIdOf(tt.c); got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
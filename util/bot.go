@@ -1,26 +1,95 @@
 package util
 
 import (
+	"errors"
+	"strings"
+
+	"github.com/aattwwss/telegram-expense-bot/sender"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	"github.com/rs/zerolog/log"
 )
 
-func BotSendWrapper(bot *tgbotapi.BotAPI, chattables ...tgbotapi.Chattable) {
+// BotSendWrapper sends the chattables in order through the sender, stopping at the first one that fails. The sender
+// reports the failure.
+func BotSendWrapper(bot *sender.Sender, chattables ...tgbotapi.Chattable) {
 	for _, c := range chattables {
 		_, err := bot.Request(c)
 		if err != nil {
-			log.Error().Msgf("bot send chattable error: %v", err)
 			return
 		}
 	}
 }
 
-func BotSendMessage(bot *tgbotapi.BotAPI, chatId int64, message string) {
+func BotSendMessage(bot *sender.Sender, chatId int64, message string) {
 	m := tgbotapi.NewMessage(chatId, message)
 	BotSendWrapper(bot, m)
 }
 
-func BotDeleteMessage(bot *tgbotapi.BotAPI, chatId int64, messageId int) {
+func BotDeleteMessage(bot *sender.Sender, chatId int64, messageId int) {
 	m := tgbotapi.NewDeleteMessage(chatId, messageId)
 	BotSendWrapper(bot, m)
 }
+
+// BotEditWrapper edits the text and the buttons of a message sent by the bot, the buttons are removed when the edit
+// has none. The message is sent as a new one instead when it can no longer be edited, such as a message older than 48
+// hours or one deleted by the user.
+func BotEditWrapper(bot *sender.Sender, edit tgbotapi.EditMessageTextConfig) {
+	_, err := bot.Attempt(edit)
+	if !isUneditable(err) {
+		return
+	}
+	msg := tgbotapi.NewMessage(edit.ChatID, edit.Text)
+	msg.ParseMode = edit.ParseMode
+	if edit.ReplyMarkup != nil {
+		msg.ReplyMarkup = *edit.ReplyMarkup
+	}
+	BotSendWrapper(bot, msg)
+}
+
+// BotEditMessage replaces the text of a message sent by the bot and removes its buttons, see BotEditWrapper
+func BotEditMessage(bot *sender.Sender, chatId int64, messageId int, message string) {
+	BotEditWrapper(bot, tgbotapi.NewEditMessageText(chatId, messageId, message))
+}
+
+// BotRemoveKeyboard removes the buttons of a message sent by the bot, a message that can no longer be edited is left
+// as it is
+func BotRemoveKeyboard(bot *sender.Sender, chatId int64, messageId int) {
+	bot.Attempt(NewEditEmptyInlineKeyboard(chatId, messageId))
+}
+
+// BotAnswerCallback shows the text as an alert to the user who tapped the button
+func BotAnswerCallback(bot *sender.Sender, callbackQueryId string, text string) {
+	c := tgbotapi.NewCallbackWithAlert(callbackQueryId, text)
+	BotSendWrapper(bot, c)
+}
+
+// CallbackAnswer answers a callback query, which stops the loading spinner of the button tapped. A query is answered
+// once only, so handlers defer Send and call Alert on the paths that have something to show the user.
+type CallbackAnswer struct {
+	bot  *sender.Sender
+	id   string
+	text string
+}
+
+func NewCallbackAnswer(bot *sender.Sender, callbackQueryId string) *CallbackAnswer {
+	return &CallbackAnswer{bot: bot, id: callbackQueryId}
+}
+
+// Alert shows the text as an alert when the query is answered
+func (a *CallbackAnswer) Alert(text string) {
+	a.text = text
+}
+
+func (a *CallbackAnswer) Send() {
+	if a.text != "" {
+		BotAnswerCallback(a.bot, a.id, a.text)
+		return
+	}
+	BotSendWrapper(a.bot, tgbotapi.NewCallback(a.id, ""))
+}
+
+// isUneditable returns true when telegram rejected an edit because the message cannot be edited, rather than because
+// the message is already as edited
+func isUneditable(err error) bool {
+	var tgErr *tgbotapi.Error
+	return errors.As(err, &tgErr) && tgErr.Code == 400 && !strings.Contains(tgErr.Message, "message is not modified")
+}
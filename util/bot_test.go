@@ -0,0 +1,104 @@
+package util
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/aattwwss/telegram-expense-bot/sender"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// editClient replies to the edits with the edit response and to anything else with ok, recording the methods called
+type editClient struct {
+	editResponse string
+	requests     []string
+}
+
+func (c *editClient) Do(req *http.Request) (*http.Response, error) {
+	body, _ := io.ReadAll(req.Body)
+	method := path.Base(req.URL.Path)
+	c.requests = append(c.requests, method+" "+string(body))
+	resp := `{"ok":true,"result":true}`
+	if strings.HasPrefix(method, "editMessage") {
+		resp = c.editResponse
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(resp))}, nil
+}
+
+func newEditSender(editResponse string) (*sender.Sender, *editClient, *[]error) {
+	client := &editClient{editResponse: editResponse}
+	bot := &tgbotapi.BotAPI{Token: "dummy", Client: client}
+	bot.SetAPIEndpoint(tgbotapi.APIEndpoint)
+	var failures []error
+	s := sender.New(bot, sender.Options{
+		GlobalRate:  1000,
+		GlobalBurst: 1000,
+		ChatRate:    1000,
+		ChatBurst:   1000,
+		OnFailure: func(ctx context.Context, c tgbotapi.Chattable, err error) {
+			failures = append(failures, err)
+		},
+	})
+	return s, client, &failures
+}
+
+func TestBotEditWrapper(t *testing.T) {
+	tests := []struct {
+		name         string
+		editResponse string
+		wantMethods  []string
+	}{
+		{"edited", `{"ok":true,"result":true}`, []string{"editMessageText"}},
+		{"not modified", `{"ok":false,"error_code":400,"description":"Bad Request: message is not modified"}`, []string{"editMessageText"}},
+		{"too old", `{"ok":false,"error_code":400,"description":"Bad Request: message can't be edited"}`, []string{"editMessageText", "sendMessage"}},
+		{"deleted", `{"ok":false,"error_code":400,"description":"Bad Request: message to edit not found"}`, []string{"editMessageText", "sendMessage"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, client, failures := newEditSender(tt.editResponse)
+			keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(">", "next")))
+			edit := tgbotapi.NewEditMessageTextAndMarkup(1, 2, "<b>page 2</b>", keyboard)
+			edit.ParseMode = tgbotapi.ModeHTML
+
+			BotEditWrapper(bot, edit)
+
+			if len(client.requests) != len(tt.wantMethods) {
+				t.Fatalf("expected %v, got %v", tt.wantMethods, client.requests)
+			}
+			for i, method := range tt.wantMethods {
+				if !strings.HasPrefix(client.requests[i], method) {
+					t.Errorf("expected %s, got %s", method, client.requests[i])
+				}
+			}
+			if len(*failures) != 0 {
+				t.Errorf("expected no failure reported, got %v", *failures)
+			}
+			if len(client.requests) == 2 && (!strings.Contains(client.requests[1], "parse_mode=HTML") || !strings.Contains(client.requests[1], "reply_markup")) {
+				t.Errorf("expected the new message with the parse mode and the buttons of the edit, got %s", client.requests[1])
+			}
+		})
+	}
+}
+
+func TestCallbackAnswer(t *testing.T) {
+	bot, client, _ := newEditSender("")
+
+	NewCallbackAnswer(bot, "q1").Send()
+	answer := NewCallbackAnswer(bot, "q2")
+	answer.Alert("This menu has expired")
+	answer.Send()
+
+	if len(client.requests) != 2 {
+		t.Fatalf("expected 2 answers, got %v", client.requests)
+	}
+	if !strings.Contains(client.requests[0], "callback_query_id=q1") || strings.Contains(client.requests[0], "show_alert=true") {
+		t.Errorf("expected a plain answer, got %s", client.requests[0])
+	}
+	if !strings.Contains(client.requests[1], "callback_query_id=q2") || !strings.Contains(client.requests[1], "show_alert=true") {
+		t.Errorf("expected an alert, got %s", client.requests[1])
+	}
+}
@@ -0,0 +1,107 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrTooManyDecimals is returned when an amount has more decimal places than the minor units of its currency
+var ErrTooManyDecimals = errors.New("too many decimal places")
+
+// ErrInvalidAmount is returned when a text is not a decimal amount. The errors of ParseMinorUnits leave out the text,
+// which is typed by the user and kept out of the logs.
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// ParseMinorUnits parses a decimal amount such as -12.5 into the minor units of a currency with the number of
+// decimal places, e.g. -1250 for 2, without going through a float. Trailing zeros beyond the decimal places are
+// allowed as they do not change the amount.
+func ParseMinorUnits(s string, decimals int) (int64, error) {
+	digits, negative := strings.CutPrefix(s, "-")
+	whole, fraction, _ := strings.Cut(digits, ".")
+	if whole == "" || !isDigits(whole) || !isDigits(fraction) {
+		return 0, ErrInvalidAmount
+	}
+	if len(fraction) > decimals {
+		if strings.Trim(fraction[decimals:], "0") != "" {
+			return 0, ErrTooManyDecimals
+		}
+		fraction = fraction[:decimals]
+	}
+
+	value, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", decimals-len(fraction)), 10, 64)
+	if err != nil {
+		// the error of strconv quotes the digits, so only its cause is kept, e.g. value out of range
+		return 0, fmt.Errorf("%w: %w", ErrInvalidAmount, errors.Unwrap(err))
+	}
+	if negative {
+		value = -value
+	}
+	return value, nil
+}
+
+// maxExponent bounds the exponent of a number in ExpandExponent, well beyond the digits of any amount
+const maxExponent = 100
+
+// ExpandExponent writes a number in exponent form such as 1.25e2, which JSON allows, as a plain decimal such as 125 for
+// ParseMinorUnits. Numbers without an exponent are returned as they are.
+func ExpandExponent(s string) (string, error) {
+	mantissa, exponent, found := strings.Cut(strings.ToLower(s), "e")
+	if !found {
+		return s, nil
+	}
+	exp, err := strconv.Atoi(exponent)
+	if err != nil || exp > maxExponent || exp < -maxExponent {
+		return "", ErrInvalidAmount
+	}
+	digits, negative := strings.CutPrefix(mantissa, "-")
+	whole, fraction, _ := strings.Cut(digits, ".")
+	if whole == "" || !isDigits(whole) || !isDigits(fraction) {
+		return "", ErrInvalidAmount
+	}
+
+	digits = whole + fraction
+	point := len(whole) + exp
+	switch {
+	case point <= 0:
+		digits = "0." + strings.Repeat("0", -point) + digits
+	case point >= len(digits):
+		digits += strings.Repeat("0", point-len(digits))
+	default:
+		digits = digits[:point] + "." + digits[point:]
+	}
+	if negative {
+		return "-" + digits, nil
+	}
+	return digits, nil
+}
+
+// FormatMinorUnits formats an amount in the minor units of a currency with the number of decimal places as a plain
+// decimal, e.g. -1250 as -12.50 for 2
+func FormatMinorUnits(amount int64, decimals int) string {
+	abs := uint64(amount)
+	if amount < 0 {
+		abs = -abs
+	}
+	digits := strconv.FormatUint(abs, 10)
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	if decimals > 0 {
+		digits = digits[:len(digits)-decimals] + "." + digits[len(digits)-decimals:]
+	}
+	if amount < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+func isDigits(s string) bool {
+	for _, ch := range s {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return true
+}
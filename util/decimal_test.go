@@ -0,0 +1,228 @@
+package util
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/Rhymond/go-money"
+	"github.com/aattwwss/telegram-expense-bot/domain"
+	"github.com/aattwwss/telegram-expense-bot/entity"
+	"github.com/aattwwss/telegram-expense-bot/message"
+)
+
+func TestParseMinorUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		decimals int
+		want     int64
+		wantErr  error
+	}{
+		{"cents", "5.50", 2, 550, nil},
+		{"one decimal", "5.5", 2, 550, nil},
+		{"whole", "100", 2, 10000, nil},
+		{"trailing dot", "100.", 2, 10000, nil},
+		{"smallest unit", "0.01", 2, 1, nil},
+		{"negative", "-5.50", 2, -550, nil},
+		{"no minor units", "1500", 0, 1500, nil},
+		{"three minor units", "1.234", 3, 1234, nil},
+		{"four minor units", "0.0001", 4, 1, nil},
+		{"float would round", "0.29", 2, 29, nil},
+		{"beyond a float", "92233720368547758.07", 2, math.MaxInt64, nil},
+		{"trailing zeros", "5.500", 2, 550, nil},
+		{"decimals where there are none", "1500.5", 0, 0, ErrTooManyDecimals},
+		{"too many decimals", "1.2345", 3, 0, ErrTooManyDecimals},
+		{"overflow", "92233720368547758.08", 2, 0, errAny},
+		{"empty", "", 2, 0, errAny},
+		{"no whole part", ".5", 2, 0, errAny},
+		{"sign only", "-", 2, 0, errAny},
+		{"plus sign", "+5", 2, 0, errAny},
+		{"exponent", "1e3", 2, 0, errAny},
+		{"two dots", "5.5.0", 2, 0, errAny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMinorUnits(tt.s, tt.decimals)
+			if (err != nil) != (tt.wantErr != nil) || (tt.wantErr != errAny && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("ParseMinorUnits(%q, %d) error = %v, want %v", tt.s, tt.decimals, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMinorUnits(%q, %d) = %d, want %d", tt.s, tt.decimals, got, tt.want)
+			}
+			if err != nil && tt.s != "" && tt.s != "-" && strings.Contains(err.Error(), tt.s) {
+				t.Errorf("expected the amount typed kept out of the error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestExpandExponent(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    string
+		wantErr bool
+	}{
+		{"5.50", "5.50", false},
+		{"1e2", "100", false},
+		{"1E2", "100", false},
+		{"1.25e2", "125", false},
+		{"1.255e2", "125.5", false},
+		{"12.5e1", "125", false},
+		{"-5e-1", "-0.5", false},
+		{"5e-3", "0.005", false},
+		{"125e-2", "1.25", false},
+		{"1.5e+1", "15", false},
+		{"0e0", "0", false},
+		{"1e", "", true},
+		{"1e1000", "", true},
+		{".5e1", "", true},
+		{"1.2.3e1", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ExpandExponent(tt.s)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ExpandExponent(%q) = %q, %v, want %q, wantErr %v", tt.s, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// errAny stands for any error in the tests
+var errAny = errors.New("any error")
+
+func TestFormatMinorUnits(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		decimals int
+		want     string
+	}{
+		{550, 2, "5.50"},
+		{-550, 2, "-5.50"},
+		{1, 2, "0.01"},
+		{-1, 3, "-0.001"},
+		{0, 2, "0.00"},
+		{1500, 0, "1500"},
+		{1, 4, "0.0001"},
+		{math.MinInt64, 2, "-92233720368547758.08"},
+	}
+
+	for _, tt := range tests {
+		got := FormatMinorUnits(tt.amount, tt.decimals)
+		if got != tt.want {
+			t.Errorf("FormatMinorUnits(%d, %d) = %q, want %q", tt.amount, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+// TestMinorUnits_RoundTrip checks that any amount formatted and parsed back in every number of minor units is the
+// same amount, and that any amount typed is formatted back as typed up to its trailing zeros
+func TestMinorUnits_RoundTrip(t *testing.T) {
+	for decimals := 0; decimals <= 4; decimals++ {
+		formatted := func(amount int64) bool {
+			if amount == math.MinInt64 {
+				return true
+			}
+			got, err := ParseMinorUnits(FormatMinorUnits(amount, decimals), decimals)
+			return err == nil && got == amount
+		}
+		if err := quick.Check(formatted, &quick.Config{MaxCount: 10000}); err != nil {
+			t.Errorf("%d decimals: %v", decimals, err)
+		}
+
+		typed := func(whole uint32, fraction uint16, negative bool) bool {
+			s := decimalString(whole, fraction, decimals, negative)
+			amount, err := ParseMinorUnits(s, decimals)
+			return err == nil && FormatMinorUnits(amount, decimals) == canonical(s, decimals)
+		}
+		if err := quick.Check(typed, &quick.Config{MaxCount: 10000, Rand: rand.New(rand.NewSource(int64(decimals)))}); err != nil {
+			t.Errorf("%d decimals: %v", decimals, err)
+		}
+	}
+}
+
+// TestMinorUnits_RoundTripLocales checks that any amount of a currency in every number of minor units typed, stored
+// in minor units and displayed in every locale reads back as the same amount
+func TestMinorUnits_RoundTripLocales(t *testing.T) {
+	// TZS is in 2 decimal places in the currency table where money has none
+	currencies := []entity.Currency{{Code: "JPY", Denominator: 1}, {Code: "SGD", Denominator: 100}, {Code: "TZS", Denominator: 100}, {Code: "KWD", Denominator: 1000}, {Code: "CLF", Denominator: 10000}}
+	registerCurrencies(t, currencies)
+
+	for _, c := range currencies {
+		currency := *money.GetCurrency(c.Code)
+		if minorUnits, _ := domain.MinorUnits(c.Denominator); currency.Fraction != minorUnits {
+			t.Fatalf("expected %s in %d decimal places, got %d", c.Code, minorUnits, currency.Fraction)
+		}
+		roundTrip := func(amount int64) bool {
+			if amount == math.MinInt64 {
+				return true
+			}
+			stored, err := ParseMinorUnits(FormatMinorUnits(amount, currency.Fraction), currency.Fraction)
+			if err != nil || stored != amount {
+				return false
+			}
+			for _, code := range []string{"en", "zh", "ms", "id"} {
+				locale := message.GetLocale(code)
+				formatted := locale.FormatMoney(money.New(stored, c.Code))
+				displayed := strings.Replace(formatted, currency.Grapheme, "", 1)
+				displayed = strings.ReplaceAll(strings.ReplaceAll(displayed, locale.ThousandSep, ""), " ", "")
+				read, err := ParseMinorUnits(strings.ReplaceAll(displayed, locale.DecimalSep, "."), currency.Fraction)
+				if err != nil || read != amount {
+					t.Logf("%s %s: %d displayed as %q", c.Code, code, amount, formatted)
+					return false
+				}
+			}
+			return true
+		}
+		if err := quick.Check(roundTrip, &quick.Config{MaxCount: 1000}); err != nil {
+			t.Errorf("%s: %v", c.Code, err)
+		}
+	}
+}
+
+// registerCurrencies registers currencies money knows for a test, restoring them as money knew them after it
+func registerCurrencies(t *testing.T, currencies []entity.Currency) {
+	t.Helper()
+	for _, c := range currencies {
+		known := *money.GetCurrency(c.Code)
+		t.Cleanup(func() {
+			*money.GetCurrency(known.Code) = known
+		})
+	}
+	if err := domain.RegisterCurrencies(currencies); err != nil {
+		t.Fatalf("RegisterCurrencies() error = %v", err)
+	}
+}
+
+// decimalString returns a decimal with up to the number of decimal places, e.g. 12.3 or 12 for 2
+func decimalString(whole uint32, fraction uint16, decimals int, negative bool) string {
+	s := FormatMinorUnits(int64(whole), 0)
+	if negative {
+		s = "-" + s
+	}
+	if decimals == 0 {
+		return s
+	}
+	digits := FormatMinorUnits(int64(fraction)%int64(math.Pow10(decimals)), decimals)
+	places := int(fraction) % (decimals + 1)
+	if places == 0 {
+		return s
+	}
+	return s + "." + digits[len(digits)-decimals:][:places]
+}
+
+// canonical pads a decimal typed to the number of decimal places, dropping the sign of zero
+func canonical(s string, decimals int) string {
+	whole, fraction, _ := strings.Cut(s, ".")
+	if decimals > 0 {
+		whole += "." + fraction + strings.Repeat("0", decimals-len(fraction))
+	}
+	if strings.Trim(whole, "-0.") == "" {
+		return strings.TrimPrefix(whole, "-")
+	}
+	return whole
+}
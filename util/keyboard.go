@@ -5,6 +5,7 @@ import (
 
 	"github.com/aattwwss/telegram-expense-bot/domain"
 	"github.com/aattwwss/telegram-expense-bot/enum"
+	"github.com/aattwwss/telegram-expense-bot/message"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
@@ -13,7 +14,7 @@ type InlineKeyboardConfig struct {
 	data  string
 }
 
-func NewUndoConfirmationKeyboard(transactionId int, messageContextId int, colSize int) ([][]tgbotapi.InlineKeyboardButton, error) {
+func NewUndoConfirmationKeyboard(transactionId int, messageContextId int, colSize int, locale message.Locale) ([][]tgbotapi.InlineKeyboardButton, error) {
 	var configs []InlineKeyboardConfig
 	undoButton := domain.UndoCallback{
 		Callback: domain.Callback{
@@ -22,15 +23,55 @@ func NewUndoConfirmationKeyboard(transactionId int, messageContextId int, colSiz
 		},
 		TransactionId: transactionId,
 	}
-	undoButtonJson, err := ToJson(undoButton)
+	undoButtonJson, err := domain.EncodeCallback(undoButton)
 	if err != nil {
 		return nil, err
 	}
-	configs = append(configs, NewInlineKeyboardConfig("Yes", undoButtonJson))
-	return NewInlineKeyboard(configs, messageContextId, colSize, true), nil
+	configs = append(configs, NewInlineKeyboardConfig(locale.Get(message.YesButton), undoButtonJson))
+	return NewInlineKeyboard(configs, messageContextId, colSize, true, locale), nil
 }
 
-func NewPaginationKeyboard(totalCount int, currentOffset int, limit int, messageContextId int, colSize int) ([][]tgbotapi.InlineKeyboardButton, error) {
+// NewTrashKeyboard returns a button numbered as each transaction in the trash is listed, which restores it
+func NewTrashKeyboard(transactions domain.Transactions, colSize int, locale message.Locale) ([][]tgbotapi.InlineKeyboardButton, error) {
+	var configs []InlineKeyboardConfig
+	for i, t := range transactions {
+		restoreButton := domain.RestoreCallback{
+			Callback: domain.Callback{
+				Type: enum.Restore,
+			},
+			TransactionId: t.Id,
+		}
+		restoreButtonData, err := domain.EncodeCallback(restoreButton)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, NewInlineKeyboardConfig(locale.Get(message.RestoreButton, i+1), restoreButtonData))
+	}
+	return NewInlineKeyboard(configs, 0, colSize, false, locale), nil
+}
+
+// NewRefundKeyboard returns a button numbered as each purchase is listed, which refunds the amount of it, or what is
+// left of it when the amount is 0
+func NewRefundKeyboard(transactions domain.Transactions, amount int64, colSize int, locale message.Locale) ([][]tgbotapi.InlineKeyboardButton, error) {
+	var configs []InlineKeyboardConfig
+	for i, t := range transactions {
+		refundButton := domain.RefundCallback{
+			Callback: domain.Callback{
+				Type: enum.Refund,
+			},
+			TransactionId: t.Id,
+			Amount:        amount,
+		}
+		refundButtonData, err := domain.EncodeCallback(refundButton)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, NewInlineKeyboardConfig(locale.Get(message.RefundButton, i+1), refundButtonData))
+	}
+	return NewInlineKeyboard(configs, 0, colSize, false, locale), nil
+}
+
+func NewPaginationKeyboard(totalCount int, currentOffset int, limit int, messageContextId int, colSize int, locale message.Locale) ([][]tgbotapi.InlineKeyboardButton, error) {
 	var configs []InlineKeyboardConfig
 
 	if currentOffset != 0 {
@@ -44,7 +85,7 @@ func NewPaginationKeyboard(totalCount int, currentOffset int, limit int, message
 			Limit:  limit,
 		}
 
-		prevButtonJson, err := ToJson(prevButton)
+		prevButtonJson, err := domain.EncodeCallback(prevButton)
 		if err != nil {
 			return nil, err
 		}
@@ -63,7 +104,7 @@ func NewPaginationKeyboard(totalCount int, currentOffset int, limit int, message
 			Limit:  limit,
 		}
 
-		nextButtonJson, err := ToJson(nextButton)
+		nextButtonJson, err := domain.EncodeCallback(nextButton)
 		if err != nil {
 			return nil, err
 		}
@@ -71,7 +112,61 @@ func NewPaginationKeyboard(totalCount int, currentOffset int, limit int, message
 	}
 
 	showCancelButton := len(configs) > 0
-	return NewInlineKeyboard(configs, messageContextId, colSize, showCancelButton), nil
+	return NewInlineKeyboard(configs, messageContextId, colSize, showCancelButton, locale), nil
+}
+
+func NewReminderKeyboard(snoozeMinutes int, colSize int, locale message.Locale) ([][]tgbotapi.InlineKeyboardButton, error) {
+	var configs []InlineKeyboardConfig
+
+	logNowButton := domain.GenericCallback{
+		Callback: domain.Callback{
+			Type: enum.LogNow,
+		},
+	}
+	logNowButtonJson, err := domain.EncodeCallback(logNowButton)
+	if err != nil {
+		return nil, err
+	}
+	configs = append(configs, NewInlineKeyboardConfig(locale.Get(message.LogNowButton), logNowButtonJson))
+
+	snoozeButton := domain.SnoozeCallback{
+		Callback: domain.Callback{
+			Type: enum.Snooze,
+		},
+		Minutes: snoozeMinutes,
+	}
+	snoozeButtonJson, err := domain.EncodeCallback(snoozeButton)
+	if err != nil {
+		return nil, err
+	}
+	snoozeLabel := locale.Get(message.SnoozeButton, snoozeMinutes)
+	if snoozeMinutes%60 == 0 {
+		snoozeLabel = locale.Get(message.SnoozeHrButton, snoozeMinutes/60)
+	}
+	configs = append(configs, NewInlineKeyboardConfig(snoozeLabel, snoozeButtonJson))
+
+	return NewInlineKeyboard(configs, 0, colSize, false, locale), nil
+}
+
+// WebAppInlineKeyboardMarkup is an inline keyboard with a button that opens a mini app, which the telegram bot library
+// does not support yet
+type WebAppInlineKeyboardMarkup struct {
+	InlineKeyboard [][]WebAppInlineKeyboardButton `json:"inline_keyboard"`
+}
+
+type WebAppInlineKeyboardButton struct {
+	Text   string     `json:"text"`
+	WebApp WebAppInfo `json:"web_app"`
+}
+
+type WebAppInfo struct {
+	Url string `json:"url"`
+}
+
+func NewWebAppKeyboard(label string, url string) WebAppInlineKeyboardMarkup {
+	return WebAppInlineKeyboardMarkup{
+		InlineKeyboard: [][]WebAppInlineKeyboardButton{{{Text: label, WebApp: WebAppInfo{Url: url}}}},
+	}
 }
 
 func NewEditEmptyInlineKeyboard(chatId int64, messageId int) tgbotapi.EditMessageReplyMarkupConfig {
@@ -91,7 +186,7 @@ func NewInlineKeyboardConfig(label string, data string) InlineKeyboardConfig {
 	}
 }
 
-func NewInlineKeyboard(configs []InlineKeyboardConfig, messageContextId int, colSize int, cancellable bool) [][]tgbotapi.InlineKeyboardButton {
+func NewInlineKeyboard(configs []InlineKeyboardConfig, messageContextId int, colSize int, cancellable bool, locale message.Locale) [][]tgbotapi.InlineKeyboardButton {
 	numOfRows := roundUpDivision(len(configs), colSize)
 	itemsKeyboards := make([][]tgbotapi.InlineKeyboardButton, 0, numOfRows+1)
 
@@ -116,9 +211,9 @@ func NewInlineKeyboard(configs []InlineKeyboardConfig, messageContextId int, col
 				MessageContextId: messageContextId,
 			},
 		}
-		dataJson, _ := ToJson(cancelCallback)
+		dataJson, _ := domain.EncodeCallback(cancelCallback)
 		row := tgbotapi.NewInlineKeyboardRow()
-		button := tgbotapi.NewInlineKeyboardButtonData("Cancel", dataJson)
+		button := tgbotapi.NewInlineKeyboardButtonData(locale.Get(message.CancelButton), dataJson)
 		row = append(row, button)
 		itemsKeyboards = append(itemsKeyboards, row)
 	}
@@ -126,6 +221,37 @@ func NewInlineKeyboard(configs []InlineKeyboardConfig, messageContextId int, col
 	return itemsKeyboards
 }
 
+// NewCancelConversationRow returns a row with a button that cancels the conversation the menu belongs to
+func NewCancelConversationRow(conversationId int, locale message.Locale) ([]tgbotapi.InlineKeyboardButton, error) {
+	cancelCallback := domain.GenericCallback{
+		Callback: domain.Callback{
+			Type:             enum.CancelConversation,
+			MessageContextId: conversationId,
+		},
+	}
+	dataJson, err := domain.EncodeCallback(cancelCallback)
+	if err != nil {
+		return nil, err
+	}
+	return tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(locale.Get(message.CancelButton), dataJson)), nil
+}
+
+// NewSplitConversationRow returns a row with a button that splits the amount of the entry conversation across
+// categories
+func NewSplitConversationRow(conversationId int, locale message.Locale) ([]tgbotapi.InlineKeyboardButton, error) {
+	splitCallback := domain.GenericCallback{
+		Callback: domain.Callback{
+			Type:             enum.Split,
+			MessageContextId: conversationId,
+		},
+	}
+	dataJson, err := domain.EncodeCallback(splitCallback)
+	if err != nil {
+		return nil, err
+	}
+	return tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(locale.Get(message.SplitButton), dataJson)), nil
+}
+
 func roundUpDivision(dividend int, divisor int) int {
 	quotient := float64(dividend) / float64(divisor)
 	quotientCeiling := math.Ceil(quotient)
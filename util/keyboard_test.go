@@ -1,13 +1,15 @@
 package util
 
 import (
-	"encoding/json"
 	"testing"
 
 	"github.com/aattwwss/telegram-expense-bot/domain"
 	"github.com/aattwwss/telegram-expense-bot/enum"
+	"github.com/aattwwss/telegram-expense-bot/message"
 )
 
+var en = message.GetLocale("en")
+
 func TestNewInlineKeyboard(t *testing.T) {
 	configs := []InlineKeyboardConfig{
 		{label: "A", data: "data-a"},
@@ -16,7 +18,7 @@ func TestNewInlineKeyboard(t *testing.T) {
 	}
 
 	// 3 items, 2 cols, cancellable → 2 data rows + 1 cancel = 3
-	kb := NewInlineKeyboard(configs, 1, 2, true)
+	kb := NewInlineKeyboard(configs, 1, 2, true, en)
 	if len(kb) != 3 {
 		t.Fatalf("expected 3 rows, got %d", len(kb))
 	}
@@ -28,13 +30,13 @@ func TestNewInlineKeyboard(t *testing.T) {
 	}
 
 	// non-cancellable → 2 data rows = 2
-	kb = NewInlineKeyboard(configs, 1, 2, false)
+	kb = NewInlineKeyboard(configs, 1, 2, false, en)
 	if len(kb) != 2 {
 		t.Errorf("expected 2 rows without cancel, got %d", len(kb))
 	}
 
 	// empty configs with cancel → cancel row only = 1
-	kb = NewInlineKeyboard(nil, 1, 2, true)
+	kb = NewInlineKeyboard(nil, 1, 2, true, en)
 	if len(kb) != 1 {
 		t.Fatalf("expected 1 row, got %d", len(kb))
 	}
@@ -45,7 +47,7 @@ func TestNewInlineKeyboard(t *testing.T) {
 
 func TestNewPaginationKeyboard(t *testing.T) {
 	// middle of list: should have prev, next, and cancel
-	kb, err := NewPaginationKeyboard(100, 10, 10, 1, 2)
+	kb, err := NewPaginationKeyboard(100, 10, 10, 1, 2, en)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -60,7 +62,7 @@ func TestNewPaginationKeyboard(t *testing.T) {
 	}
 
 	// first page: only next button + cancel
-	kb, err = NewPaginationKeyboard(100, 0, 10, 1, 2)
+	kb, err = NewPaginationKeyboard(100, 0, 10, 1, 2, en)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -70,7 +72,7 @@ func TestNewPaginationKeyboard(t *testing.T) {
 
 	// only one page: totalCount 8 < limit 10, no next, no prev
 	// len(configs) == 0 so showCancelButton = false → empty result
-	kb, err = NewPaginationKeyboard(8, 0, 10, 1, 2)
+	kb, err = NewPaginationKeyboard(8, 0, 10, 1, 2, en)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -80,7 +82,7 @@ func TestNewPaginationKeyboard(t *testing.T) {
 }
 
 func TestNewUndoConfirmationKeyboard(t *testing.T) {
-	kb, err := NewUndoConfirmationKeyboard(42, 1, 2)
+	kb, err := NewUndoConfirmationKeyboard(42, 1, 2, en)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -94,9 +96,9 @@ func TestNewUndoConfirmationKeyboard(t *testing.T) {
 
 	// Parse callback data to verify transaction id
 	var undo domain.UndoCallback
-	err = json.Unmarshal([]byte(*kb[0][0].CallbackData), &undo)
+	err = domain.DecodeCallback(*kb[0][0].CallbackData, &undo)
 	if err != nil {
-		t.Fatalf("unexpected error unmarshalling callback data: %v", err)
+		t.Fatalf("unexpected error decoding callback data: %v", err)
 	}
 	if undo.TransactionId != 42 {
 		t.Errorf("expected TransactionId 42, got %d", undo.TransactionId)
@@ -111,3 +113,43 @@ func TestNewUndoConfirmationKeyboard(t *testing.T) {
 		t.Errorf("expected 'Cancel' button, got %q", lastRow[0].Text)
 	}
 }
+
+func TestNewReminderKeyboard(t *testing.T) {
+	kb, err := NewReminderKeyboard(60, 2, en)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(kb) != 1 { // no cancel row
+		t.Fatalf("expected 1 row, got %d", len(kb))
+	}
+	if kb[0][0].Text != "Log now" || kb[0][1].Text != "Snooze 1h" {
+		t.Errorf("expected 'Log now' and 'Snooze 1h' buttons, got %q, %q", kb[0][0].Text, kb[0][1].Text)
+	}
+
+	var snooze domain.SnoozeCallback
+	err = domain.DecodeCallback(*kb[0][1].CallbackData, &snooze)
+	if err != nil {
+		t.Fatalf("unexpected error decoding callback data: %v", err)
+	}
+	if snooze.Type != enum.Snooze || snooze.Minutes != 60 {
+		t.Errorf("expected Snooze callback of 60 minutes, got %+v", snooze)
+	}
+}
+
+func TestNewInlineKeyboard_Localized(t *testing.T) {
+	kb := NewInlineKeyboard(nil, 1, 2, true, message.GetLocale("zh"))
+	if kb[0][0].Text != "取消" {
+		t.Errorf("expected localized Cancel button, got %q", kb[0][0].Text)
+	}
+}
+
+func TestNewWebAppKeyboard(t *testing.T) {
+	got, err := ToJson(NewWebAppKeyboard("Open", "https://example.com/app/"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"inline_keyboard":[[{"text":"Open","web_app":{"url":"https://example.com/app/"}}]]}`
+	if got != want {
+		t.Errorf("NewWebAppKeyboard() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,235 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/message"
+)
+
+var ErrInvalidPeriod = errors.New("invalid period")
+
+var (
+	quarterRegex = regexp.MustCompile(`^(\d{4})-q([1-4])$`)
+	yearRegex    = regexp.MustCompile(`^\d{4}$`)
+	monthRegex   = regexp.MustCompile(`^\d{4}-\d{2}$`)
+	dayRegex     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+type periodKind int
+
+const (
+	periodDays periodKind = iota
+	periodMonth
+	periodQuarter
+	periodYear
+)
+
+// Period is the days from From up to but excluding To, starting at midnight in the timezone of the user
+type Period struct {
+	From time.Time
+	To   time.Time
+	kind periodKind
+}
+
+// MonthPeriod returns the month of the year in the location
+func MonthPeriod(month time.Month, year int, loc *time.Location) Period {
+	from := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	return Period{From: from, To: from.AddDate(0, 1, 0), kind: periodMonth}
+}
+
+// ParsePeriod parses the period given to a command, relative to now and in the timezone of now. No period is the
+// current month, otherwise it is one of
+//
+//	feb, feb 2023, 2 2023, 2023-02  a month, of the current year when none is given
+//	this week, last month           the current or previous week, month, quarter or year, weeks start on Monday
+//	2023-q1                         a quarter
+//	2023                            a year
+//	last 30 days                    the days, weeks or months up to and including today
+//	ytd                             the start of the year up to and including today
+//	today, yesterday, 2023-01-15    a day
+//	2023-01-15..2023-02-10          the days in between, inclusive
+func ParsePeriod(s string, now time.Time) (Period, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var p Period
+	ok := false
+	switch len(fields) {
+	case 0:
+		p, ok = MonthPeriod(now.Month(), now.Year(), now.Location()), true
+	case 1:
+		p, ok = parseSinglePeriod(fields[0], today)
+	case 2:
+		p, ok = parseRelativePeriod(fields[0], fields[1], today)
+		if !ok {
+			month, monthOk := parseMonth(fields[0])
+			year, yearOk := parseYear(fields[1])
+			p, ok = MonthPeriod(month, year, today.Location()), monthOk && yearOk
+		}
+	case 3:
+		p, ok = parseRollingPeriod(fields[0], fields[1], fields[2], today)
+	}
+	if !ok {
+		return Period{}, fmt.Errorf("%w: %q", ErrInvalidPeriod, s)
+	}
+	return p, nil
+}
+
+func parseSinglePeriod(s string, today time.Time) (Period, bool) {
+	loc := today.Location()
+	switch s {
+	case "today":
+		return dayPeriod(today), true
+	case "yesterday":
+		return dayPeriod(today.AddDate(0, 0, -1)), true
+	case "ytd":
+		return Period{From: time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, loc), To: today.AddDate(0, 0, 1)}, true
+	}
+
+	if from, to, found := strings.Cut(s, ".."); found {
+		fromDay, err := time.ParseInLocation("2006-01-02", from, loc)
+		if err != nil || !dayRegex.MatchString(from) {
+			return Period{}, false
+		}
+		toDay, err := time.ParseInLocation("2006-01-02", to, loc)
+		if err != nil || !dayRegex.MatchString(to) || toDay.Before(fromDay) {
+			return Period{}, false
+		}
+		return Period{From: fromDay, To: toDay.AddDate(0, 0, 1)}, true
+	}
+
+	if m := quarterRegex.FindStringSubmatch(s); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		quarter, _ := strconv.Atoi(m[2])
+		return quarterPeriod(year, quarter, loc), true
+	}
+	if yearRegex.MatchString(s) {
+		year, _ := strconv.Atoi(s)
+		return yearPeriod(year, loc), true
+	}
+	if monthRegex.MatchString(s) {
+		t, err := time.ParseInLocation("2006-01", s, loc)
+		if err != nil {
+			return Period{}, false
+		}
+		return MonthPeriod(t.Month(), t.Year(), loc), true
+	}
+	if dayRegex.MatchString(s) {
+		t, err := time.ParseInLocation("2006-01-02", s, loc)
+		if err != nil {
+			return Period{}, false
+		}
+		return dayPeriod(t), true
+	}
+	if month, ok := parseMonth(s); ok {
+		return MonthPeriod(month, today.Year(), loc), true
+	}
+	return Period{}, false
+}
+
+// parseRelativePeriod parses this or last followed by week, month, quarter or year
+func parseRelativePeriod(which string, unit string, today time.Time) (Period, bool) {
+	back := 0
+	switch which {
+	case "this":
+	case "last":
+		back = 1
+	default:
+		return Period{}, false
+	}
+
+	loc := today.Location()
+	switch unit {
+	case "week":
+		monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7).AddDate(0, 0, -7*back)
+		return Period{From: monday, To: monday.AddDate(0, 0, 7)}, true
+	case "month":
+		first := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, loc).AddDate(0, -back, 0)
+		return MonthPeriod(first.Month(), first.Year(), loc), true
+	case "quarter":
+		first := time.Date(today.Year(), today.Month()-(today.Month()-1)%3, 1, 0, 0, 0, 0, loc).AddDate(0, -3*back, 0)
+		return quarterPeriod(first.Year(), int(first.Month()-1)/3+1, loc), true
+	case "year":
+		return yearPeriod(today.Year()-back, loc), true
+	}
+	return Period{}, false
+}
+
+// parseRollingPeriod parses last followed by a number of days, weeks or months, which end today
+func parseRollingPeriod(last string, count string, unit string, today time.Time) (Period, bool) {
+	n, err := strconv.Atoi(count)
+	if last != "last" || err != nil || n < 1 || n > 9999 {
+		return Period{}, false
+	}
+
+	to := today.AddDate(0, 0, 1)
+	switch strings.TrimSuffix(unit, "s") {
+	case "day":
+		return Period{From: to.AddDate(0, 0, -n), To: to}, true
+	case "week":
+		return Period{From: to.AddDate(0, 0, -7*n), To: to}, true
+	case "month":
+		return Period{From: to.AddDate(0, -n, 0), To: to}, true
+	}
+	return Period{}, false
+}
+
+func dayPeriod(day time.Time) Period {
+	return Period{From: day, To: day.AddDate(0, 0, 1)}
+}
+
+func quarterPeriod(year int, quarter int, loc *time.Location) Period {
+	from := time.Date(year, time.Month(3*quarter-2), 1, 0, 0, 0, 0, loc)
+	return Period{From: from, To: from.AddDate(0, 3, 0), kind: periodQuarter}
+}
+
+func yearPeriod(year int, loc *time.Location) Period {
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	return Period{From: from, To: from.AddDate(1, 0, 0), kind: periodYear}
+}
+
+// Label returns the name of the period in the locale, e.g. "January 2023", "Q1 2023", "2023" or a range of dates
+func (p Period) Label(locale message.Locale) string {
+	switch p.kind {
+	case periodMonth:
+		return fmt.Sprintf("%s %d", locale.MonthName(p.From.Month()), p.From.Year())
+	case periodQuarter:
+		return locale.Get(message.PeriodQuarterLabel, int(p.From.Month()-1)/3+1, p.From.Year())
+	case periodYear:
+		return strconv.Itoa(p.From.Year())
+	}
+	last := p.To.AddDate(0, 0, -1)
+	if !last.After(p.From) {
+		return locale.FormatDate(p.From)
+	}
+	return locale.FormatDate(p.From) + " - " + locale.FormatDate(last)
+}
+
+// Slug returns the period as the dates it covers, for file names, e.g. "2023-01-01_2023-01-31"
+func (p Period) Slug() string {
+	return p.From.Format("2006-01-02") + "_" + p.To.AddDate(0, 0, -1).Format("2006-01-02")
+}
+
+// parseMonth parses the number, name or short name of a month
+func parseMonth(s string) (time.Month, bool) {
+	for month := time.January; month <= time.December; month++ {
+		name := month.String()
+		if s == strconv.Itoa(int(month)) || strings.EqualFold(s, name) || strings.EqualFold(s, name[:3]) {
+			return month, true
+		}
+	}
+	return 0, false
+}
+
+func parseYear(s string) (int, bool) {
+	if !yearRegex.MatchString(s) {
+		return 0, false
+	}
+	year, _ := strconv.Atoi(s)
+	return year, true
+}
@@ -0,0 +1,130 @@
+package util
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aattwwss/telegram-expense-bot/message"
+)
+
+func TestParsePeriod(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Singapore")
+	// a Wednesday
+	now := time.Date(2024, time.May, 15, 10, 30, 0, 0, loc)
+
+	tests := []struct {
+		input     string
+		wantFrom  string
+		wantTo    string
+		wantLabel string
+	}{
+		{"", "2024-05-01", "2024-06-01", "May 2024"},
+		{"feb", "2024-02-01", "2024-03-01", "February 2024"},
+		{"2", "2024-02-01", "2024-03-01", "February 2024"},
+		{"February 2022", "2022-02-01", "2022-03-01", "February 2022"},
+		{"2 2022", "2022-02-01", "2022-03-01", "February 2022"},
+		{"2022-02", "2022-02-01", "2022-03-01", "February 2022"},
+		{"this week", "2024-05-13", "2024-05-20", "13/05/24 - 19/05/24"},
+		{"last week", "2024-05-06", "2024-05-13", "06/05/24 - 12/05/24"},
+		{"this month", "2024-05-01", "2024-06-01", "May 2024"},
+		{"LAST MONTH", "2024-04-01", "2024-05-01", "April 2024"},
+		{"this quarter", "2024-04-01", "2024-07-01", "Q2 2024"},
+		{"last quarter", "2024-01-01", "2024-04-01", "Q1 2024"},
+		{"this year", "2024-01-01", "2025-01-01", "2024"},
+		{"last year", "2023-01-01", "2024-01-01", "2023"},
+		{"2023-Q1", "2023-01-01", "2023-04-01", "Q1 2023"},
+		{"2023", "2023-01-01", "2024-01-01", "2023"},
+		{"last 30 days", "2024-04-16", "2024-05-16", "16/04/24 - 15/05/24"},
+		{"last 1 day", "2024-05-15", "2024-05-16", "15/05/24"},
+		{"last 2 weeks", "2024-05-02", "2024-05-16", "02/05/24 - 15/05/24"},
+		{"last 3 months", "2024-02-16", "2024-05-16", "16/02/24 - 15/05/24"},
+		{"ytd", "2024-01-01", "2024-05-16", "01/01/24 - 15/05/24"},
+		{"today", "2024-05-15", "2024-05-16", "15/05/24"},
+		{"yesterday", "2024-05-14", "2024-05-15", "14/05/24"},
+		{"2023-01-15", "2023-01-15", "2023-01-16", "15/01/23"},
+		{"2023-01-15..2023-02-10", "2023-01-15", "2023-02-11", "15/01/23 - 10/02/23"},
+	}
+
+	en := message.GetLocale("en")
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p, err := ParsePeriod(tt.input, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.From.Location() != loc || p.From.Hour() != 0 || p.To.Hour() != 0 {
+				t.Errorf("expected the period to start and end at midnight in %v, got %v to %v", loc, p.From, p.To)
+			}
+			if got := p.From.Format("2006-01-02"); got != tt.wantFrom {
+				t.Errorf("expected from %s, got %s", tt.wantFrom, got)
+			}
+			if got := p.To.Format("2006-01-02"); got != tt.wantTo {
+				t.Errorf("expected to %s, got %s", tt.wantTo, got)
+			}
+			if got := p.Label(en); got != tt.wantLabel {
+				t.Errorf("expected label %q, got %q", tt.wantLabel, got)
+			}
+		})
+	}
+}
+
+func TestParsePeriod_Invalid(t *testing.T) {
+	now := time.Date(2024, time.May, 15, 10, 30, 0, 0, time.UTC)
+
+	for _, input := range []string{
+		"13",
+		"smarch",
+		"feb 22",
+		"next week",
+		"last 0 days",
+		"last 30 fortnights",
+		"2023-q5",
+		"2023-13",
+		"2023-02-30",
+		"2023-02-10..2023-01-15",
+		"2023-01-15..",
+		"this week please",
+	} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParsePeriod(input, now)
+			if !errors.Is(err, ErrInvalidPeriod) {
+				t.Errorf("expected an invalid period, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParsePeriod_Timezone(t *testing.T) {
+	// 11pm on 31 May in UTC is already June in Singapore
+	loc, _ := time.LoadLocation("Asia/Singapore")
+	now := time.Date(2024, time.May, 31, 23, 0, 0, 0, time.UTC)
+
+	p, err := ParsePeriod("today", now.In(loc))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2024, time.June, 1, 0, 0, 0, 0, loc); !p.From.Equal(want) {
+		t.Errorf("expected today to start at %v, got %v", want, p.From)
+	}
+}
+
+func TestPeriod_Label_Localized(t *testing.T) {
+	p, _ := ParsePeriod("2023-q1", time.Now())
+
+	if got := p.Label(message.GetLocale("zh")); got != "2023年第1季度" {
+		t.Errorf("unexpected label %q", got)
+	}
+	p, _ = ParsePeriod("mar 2023", time.Now())
+	if got := p.Label(message.GetLocale("id")); got != "Maret 2023" {
+		t.Errorf("unexpected label %q", got)
+	}
+}
+
+func TestPeriod_Slug(t *testing.T) {
+	p := MonthPeriod(time.February, 2024, time.UTC)
+
+	if got := p.Slug(); got != "2024-02-01_2024-02-29" {
+		t.Errorf("unexpected slug %q", got)
+	}
+}
@@ -41,35 +41,6 @@ func TestParseMonthFromString(t *testing.T) {
 	}
 }
 
-func TestParseMonthYearFromMessage(t *testing.T) {
-	now := time.Now()
-	currentMonth := now.Month()
-	currentYear := now.Year()
-
-	tests := []struct {
-		name      string
-		input     string
-		wantMonth time.Month
-		wantYear  int
-	}{
-		{"no args returns current", "/list", currentMonth, currentYear},
-		{"month only", "/list 3", time.March, currentYear},
-		{"short month name", "/list Jan", time.January, currentYear},
-		{"month and year", "/list 2 2022", time.February, 2022},
-		{"month name and year", "/list February 2022", time.February, 2022},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			gotMonth, gotYear := ParseMonthYearFromMessage(tt.input)
-			if gotMonth != tt.wantMonth || gotYear != tt.wantYear {
-				t.Errorf("ParseMonthYearFromMessage(%q) = (%v, %d), want (%v, %d)",
-					tt.input, gotMonth, gotYear, tt.wantMonth, tt.wantYear)
-			}
-		})
-	}
-}
-
 func TestYearMonthString(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -0,0 +1,76 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidInitData = errors.New("invalid web app init data")
+
+// WebAppUser is the telegram user who opened the mini app
+type WebAppUser struct {
+	Id           int64  `json:"id"`
+	FirstName    string `json:"first_name"`
+	Username     string `json:"username"`
+	LanguageCode string `json:"language_code"`
+}
+
+// ValidateWebAppInitData checks that the init data of a mini app was signed with the bot token and is not older than
+// maxAge, following https://core.telegram.org/bots/webapps#validating-data-received-via-the-mini-app
+func ValidateWebAppInitData(initData string, botToken string, maxAge time.Duration, now time.Time) (WebAppUser, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return WebAppUser{}, fmt.Errorf("%w: %v", ErrInvalidInitData, err)
+	}
+	hash := values.Get("hash")
+	if hash == "" {
+		return WebAppUser{}, fmt.Errorf("%w: missing hash", ErrInvalidInitData)
+	}
+	values.Del("hash")
+
+	if !hmac.Equal([]byte(hash), []byte(SignWebAppInitData(values, botToken))) {
+		return WebAppUser{}, fmt.Errorf("%w: hash mismatch", ErrInvalidInitData)
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return WebAppUser{}, fmt.Errorf("%w: invalid auth_date", ErrInvalidInitData)
+	}
+	if now.Sub(time.Unix(authDate, 0)) > maxAge {
+		return WebAppUser{}, fmt.Errorf("%w: expired", ErrInvalidInitData)
+	}
+
+	var user WebAppUser
+	err = json.Unmarshal([]byte(values.Get("user")), &user)
+	if err != nil || user.Id == 0 {
+		return WebAppUser{}, fmt.Errorf("%w: invalid user", ErrInvalidInitData)
+	}
+	return user, nil
+}
+
+// SignWebAppInitData returns the hex encoded hash of the init data fields other than hash
+func SignWebAppInitData(values url.Values, botToken string) string {
+	var pairs []string
+	for key := range values {
+		if key == "hash" {
+			continue
+		}
+		pairs = append(pairs, key+"="+values.Get(key))
+	}
+	sort.Strings(pairs)
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,67 @@
+package util
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testBotToken = "123456:ABC-DEF"
+
+func signedInitData(authDate time.Time, user string) url.Values {
+	values := url.Values{}
+	values.Set("query_id", "AAHdF6IQAAAAAN0XohDhrOrc")
+	values.Set("user", user)
+	values.Set("auth_date", strconv.FormatInt(authDate.Unix(), 10))
+	values.Set("hash", SignWebAppInitData(values, testBotToken))
+	return values
+}
+
+func TestValidateWebAppInitData(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	user := `{"id":7,"first_name":"Alice","username":"alice","language_code":"en"}`
+
+	valid := signedInitData(now.Add(-time.Minute), user)
+
+	tampered := signedInitData(now.Add(-time.Minute), user)
+	tampered.Set("user", `{"id":8,"first_name":"Mallory"}`)
+
+	wrongToken := signedInitData(now.Add(-time.Minute), user)
+	wrongToken.Set("hash", SignWebAppInitData(wrongToken, "654321:XYZ"))
+
+	missingHash := signedInitData(now.Add(-time.Minute), user)
+	missingHash.Del("hash")
+
+	tests := []struct {
+		name     string
+		initData string
+		wantErr  bool
+	}{
+		{"valid", valid.Encode(), false},
+		{"tampered user", tampered.Encode(), true},
+		{"signed with another bot", wrongToken.Encode(), true},
+		{"missing hash", missingHash.Encode(), true},
+		{"expired", signedInitData(now.Add(-25*time.Hour), user).Encode(), true},
+		{"missing user", signedInitData(now, "").Encode(), true},
+		{"not a query string", "%zz", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateWebAppInitData(tt.initData, testBotToken, 24*time.Hour, now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInitData) {
+					t.Errorf("expected ErrInvalidInitData, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Id != 7 || got.Username != "alice" || got.LanguageCode != "en" {
+				t.Errorf("unexpected user %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+"use strict";
+
+const tg = window.Telegram.WebApp;
+const pageSize = 50;
+
+const state = {
+  categories: [],
+  offset: 0,
+  tab: "transactions",
+};
+
+const $ = (id) => document.getElementById(id);
+
+async function api(method, path, body) {
+  const res = await fetch("api/" + path, {
+    method: method,
+    headers: {
+      "Authorization": "tma " + tg.initData,
+      "Content-Type": "application/json",
+    },
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  if (res.status === 204) {
+    return null;
+  }
+  const data = await res.json();
+  if (!res.ok) {
+    throw new Error(data.error || res.statusText);
+  }
+  return data;
+}
+
+function showError(err) {
+  $("error").textContent = err.message;
+  $("error").hidden = false;
+}
+
+function clearError() {
+  $("error").hidden = true;
+}
+
+function selectedMonth() {
+  const [year, month] = $("month").value.split("-").map(Number);
+  return { year: year, month: month };
+}
+
+function pad(n) {
+  return String(n).padStart(2, "0");
+}
+
+function monthRange() {
+  const { year, month } = selectedMonth();
+  const next = month === 12 ? { year: year + 1, month: 1 } : { year: year, month: month + 1 };
+  return {
+    from: `${year}-${pad(month)}-01`,
+    to: `${next.year}-${pad(next.month)}-01`,
+  };
+}
+
+function formatAmount(amount, currency) {
+  return new Intl.NumberFormat(undefined, { style: "currency", currency: currency }).format(amount);
+}
+
+function categoryOptions(selectedId) {
+  return state.categories
+    .filter((c) => c.transaction_type_id === 1)
+    .map((c) => `<option value="${c.id}"${c.id === selectedId ? " selected" : ""}>${escapeHtml(c.name)}</option>`)
+    .join("");
+}
+
+function escapeHtml(s) {
+  const div = document.createElement("div");
+  div.textContent = s;
+  return div.innerHTML;
+}
+
+async function loadCategories() {
+  state.categories = await api("GET", "categories");
+  const filter = $("category-filter");
+  const selected = filter.value;
+  filter.innerHTML = '<option value="">All categories</option>' + categoryOptions(Number(selected));
+  renderCategories();
+}
+
+async function loadTransactions(append) {
+  state.offset = append ? state.offset + pageSize : 0;
+  const range = monthRange();
+  const params = new URLSearchParams({ from: range.from, to: range.to, offset: state.offset, limit: pageSize });
+  if ($("category-filter").value) {
+    params.set("category_id", $("category-filter").value);
+  }
+  const list = await api("GET", "transactions?" + params);
+
+  const body = $("transactions-body");
+  if (!append) {
+    body.innerHTML = "";
+  }
+  for (const t of list.transactions) {
+    body.appendChild(transactionRow(t));
+  }
+  $("transactions-total").textContent = `${list.total_count} transactions`;
+  $("load-more").hidden = state.offset + pageSize >= list.total_count;
+}
+
+function transactionRow(t) {
+  const row = document.createElement("tr");
+  const date = new Date(t.datetime).toLocaleDateString(undefined, { day: "numeric", month: "short" });
+  row.innerHTML = `
+    <td>${date}</td>
+    <td>${escapeHtml(t.category)}</td>
+    <td>${escapeHtml(t.description)}</td>
+    <td class="amount">${formatAmount(t.amount, t.currency)}</td>
+    <td><button class="secondary">Edit</button></td>`;
+  row.querySelector("button").addEventListener("click", () => row.replaceWith(editTransactionRow(t)));
+  return row;
+}
+
+function editTransactionRow(t) {
+  const row = document.createElement("tr");
+  row.innerHTML = `
+    <td colspan="5">
+      <select name="category">${categoryOptions(t.category_id)}</select>
+      <input name="description" maxlength="50" value="${escapeHtml(t.description)}">
+      <input name="amount" type="number" step="any" min="0" value="${t.amount}">
+      <button name="save">Save</button>
+      <button name="delete" class="secondary">Delete</button>
+      <button name="cancel" class="secondary">Cancel</button>
+    </td>`;
+  const field = (name) => row.querySelector(`[name="${name}"]`);
+
+  field("save").addEventListener("click", () => run(async () => {
+    const updated = await api("PATCH", `transactions/${t.id}`, {
+      category_id: Number(field("category").value),
+      description: field("description").value,
+      amount: Number(field("amount").value),
+    });
+    row.replaceWith(transactionRow(updated));
+  }));
+  field("delete").addEventListener("click", () => {
+    tg.showConfirm("Delete this transaction?", (ok) => {
+      if (ok) {
+        run(async () => {
+          await api("DELETE", `transactions/${t.id}`);
+          row.remove();
+        });
+      }
+    });
+  });
+  field("cancel").addEventListener("click", () => row.replaceWith(transactionRow(t)));
+  return row;
+}
+
+async function loadChart() {
+  const { year, month } = selectedMonth();
+  const stats = await api("GET", `stats?month=${month}&year=${year}`);
+  $("chart-total").textContent = "Total " + formatAmount(stats.total, stats.currency);
+
+  const max = Math.max(0, ...stats.categories.map((c) => c.amount));
+  $("chart-bars").innerHTML = stats.categories.map((c) => `
+    <div class="bar">
+      <div class="bar-label"><span>${escapeHtml(c.category)}</span><span>${formatAmount(c.amount, stats.currency)}</span></div>
+      <div class="bar-track"><div class="bar-fill" style="width: ${max === 0 ? 0 : (c.amount / max) * 100}%"></div></div>
+    </div>`).join("");
+}
+
+function renderCategories() {
+  const list = $("categories-list");
+  list.innerHTML = "";
+  for (const c of state.categories) {
+    const item = document.createElement("li");
+    item.innerHTML = `<span>${escapeHtml(c.name)}</span>`;
+    if (c.custom) {
+      const rename = document.createElement("button");
+      rename.className = "secondary";
+      rename.textContent = "Rename";
+      rename.addEventListener("click", () => {
+        const name = prompt("Rename category", c.name);
+        if (name) {
+          run(async () => {
+            await api("PATCH", `categories/${c.id}`, { name: name });
+            await loadCategories();
+          });
+        }
+      });
+      const remove = document.createElement("button");
+      remove.className = "secondary";
+      remove.textContent = "Delete";
+      remove.addEventListener("click", () => run(async () => {
+        await api("DELETE", `categories/${c.id}`);
+        await loadCategories();
+      }));
+      item.append(rename, remove);
+    }
+    list.appendChild(item);
+  }
+}
+
+async function run(fn) {
+  clearError();
+  try {
+    await fn();
+  } catch (err) {
+    showError(err);
+  }
+}
+
+function refresh() {
+  run(async () => {
+    if (state.tab === "transactions") {
+      await loadTransactions(false);
+    } else if (state.tab === "chart") {
+      await loadChart();
+    }
+  });
+}
+
+function showTab(tab) {
+  state.tab = tab;
+  for (const button of document.querySelectorAll(".tab")) {
+    button.classList.toggle("active", button.dataset.tab === tab);
+  }
+  for (const panel of document.querySelectorAll(".panel")) {
+    panel.hidden = panel.id !== tab;
+  }
+  $("category-filter").hidden = tab !== "transactions";
+  refresh();
+}
+
+function init() {
+  tg.ready();
+  tg.expand();
+
+  const now = new Date();
+  $("month").value = `${now.getFullYear()}-${pad(now.getMonth() + 1)}`;
+
+  for (const button of document.querySelectorAll(".tab")) {
+    button.addEventListener("click", () => showTab(button.dataset.tab));
+  }
+  $("month").addEventListener("change", refresh);
+  $("category-filter").addEventListener("change", refresh);
+  $("load-more").addEventListener("click", () => run(() => loadTransactions(true)));
+  $("category-form").addEventListener("submit", (e) => {
+    e.preventDefault();
+    run(async () => {
+      await api("POST", "categories", { name: $("category-name").value });
+      $("category-name").value = "";
+      await loadCategories();
+    });
+  });
+
+  run(async () => {
+    await loadCategories();
+    await loadTransactions(false);
+  });
+}
+
+init();
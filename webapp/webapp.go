@@ -0,0 +1,9 @@
+// Package webapp embeds the Telegram Mini App served under /app
+package webapp
+
+import "embed"
+
+// Static holds the html, scripts and styles of the mini app
+//
+//go:embed static
+var Static embed.FS